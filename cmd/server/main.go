@@ -8,11 +8,13 @@ import (
 	"groops/internal/auth"
 	"groops/internal/database"
 	"groops/internal/handlers"
+	"groops/internal/middleware"
 	"groops/internal/services"
 	"groops/internal/utils"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/joho/godotenv"
 )
 
@@ -35,6 +37,9 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// Give notifications created before deep links existed a link to their group
+	services.BackfillNotificationLinks(database.GetDB())
+
 	// Initialize Google Maps client
 	if err := services.InitMapsClient(); err != nil {
 		log.Printf("Warning: Failed to initialize Google Maps client: %v", err)
@@ -46,22 +51,108 @@ func main() {
 	reminderWorker.Start()
 	log.Println("Event reminder worker started")
 
+	// Initialize and start the poll worker (closes polls past their closing time)
+	pollWorker := services.NewPollWorker()
+	pollWorker.Start()
+	log.Println("Poll worker started")
+
+	// Initialize and start the login log retention worker (data minimization)
+	loginLogRetentionWorker := services.NewLoginLogRetentionWorker()
+	loginLogRetentionWorker.Start()
+	log.Println("Login log retention worker started")
+
+	// Initialize and start the waitlist worker (expires unclaimed spot offers)
+	waitlistWorker := services.NewWaitlistWorker()
+	waitlistWorker.Start()
+	log.Println("Waitlist worker started")
+
+	// Initialize and start the streak worker (celebrates weekly participation milestones)
+	streakWorker := services.NewStreakWorker()
+	streakWorker.Start()
+	log.Println("Streak worker started")
+
+	// Initialize and start the recurrence worker (schedules the next occurrence of recurring groups)
+	recurrenceWorker := services.NewRecurrenceWorker()
+	recurrenceWorker.Start()
+	log.Println("Recurrence worker started")
+
+	// Initialize and start the attendance worker (records no-shows once check-in closes)
+	attendanceWorker := services.NewAttendanceWorker()
+	attendanceWorker.Start()
+	log.Println("Attendance worker started")
+
+	// Initialize and start the RSVP worker (sends organizers the final headcount after the RSVP deadline)
+	rsvpWorker := services.NewRSVPWorker()
+	rsvpWorker.Start()
+	log.Println("RSVP worker started")
+
+	// Initialize and start the group card worker (refreshes the materialized view behind GET /groups)
+	groupCardWorker := services.NewGroupCardWorker()
+	groupCardWorker.Start()
+	log.Println("Group card worker started")
+
+	// Initialize and start the email outbox worker (retries emails queued while SendGrid was failing)
+	emailOutboxWorker := services.NewEmailOutboxWorker()
+	emailOutboxWorker.Start()
+	log.Println("Email outbox worker started")
+
+	// Initialize and start the outbox dispatcher (delivers emails and webhooks queued with handler transactions)
+	outboxDispatcher := services.NewOutboxDispatcher()
+	outboxDispatcher.Start()
+	log.Println("Outbox dispatcher started")
+
+	// Initialize and start the group archive worker (moves ended groups out of the default listings)
+	groupArchiveWorker := services.NewGroupArchiveWorker()
+	groupArchiveWorker.Start()
+	log.Println("Group archive worker started")
+
+	// Initialize and start the soft delete purge worker (removes groups and accounts deleted past the retention period)
+	softDeletePurgeWorker := services.NewSoftDeletePurgeWorker()
+	softDeletePurgeWorker.Start()
+	log.Println("Soft delete purge worker started")
+
+	// Start writing tracked analytics events in batches, and the job rolling them up into daily counts
+	services.GetAnalyticsTracker().Start()
+	analyticsRollupWorker := services.NewAnalyticsRollupWorker()
+	analyticsRollupWorker.Start()
+	log.Println("Analytics tracker and rollup worker started")
+
+	// Start pruning chat presence entries whose heartbeats have stopped
+	services.GetPresenceService().Start()
+	log.Println("Presence cleanup started")
+
+	// Subscribe notifications, emails, webhooks and activity logging to the domain events handlers publish
+	handlers.RegisterEventSubscribers()
+
 	// Set Gin mode based on environment
 	ginMode := os.Getenv("GIN_MODE")
 	if ginMode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Reject JSON payloads with fields the request structs don't declare
+	binding.EnableDecoderDisallowUnknownFields = true
+
+	// Maximum request body sizes per route group
+	maxJSONBodyBytes := int64(utils.GetEnvInt("MAX_JSON_BODY_BYTES", 64<<10))     // 64KB
+	maxUploadBodyBytes := int64(utils.GetEnvInt("MAX_UPLOAD_BODY_BYTES", 11<<20)) // 11MB (10MB avatar + form overhead)
+
 	// Initialize Gin router with custom middleware
 	router := gin.New()
 
+	// Tag each request with an ID for logs and profiling
+	router.Use(middleware.RequestID())
+
+	// Record per-route request metrics (outside recovery so panics count as 500s)
+	router.Use(middleware.RequestMetrics())
+
 	// Add recovery middleware
 	router.Use(gin.Recovery())
 
 	// Add custom logging middleware to show real client IPs
 	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		// Use the utility function for consistent IP extraction
-		clientIP := utils.GetRealClientIP(&gin.Context{Request: param.Request})
+		clientIP := utils.ClientIPFromRequest(param.Request)
 
 		return fmt.Sprintf("[GIN] %s | %d | %v | %s | %s %s\n",
 			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
@@ -73,8 +164,21 @@ func main() {
 		)
 	}))
 
+	// Load-test profiling: log database queries per request (PROFILE_DB_QUERIES) and serve
+	// pprof on an internal-only address (PPROF_ADDR, e.g. 127.0.0.1:6060)
+	if utils.GetEnvBool("PROFILE_DB_QUERIES", false) {
+		router.Use(middleware.QueryCounter())
+		log.Println("Per-request query counting enabled")
+	}
+	if pprofAddr := os.Getenv("PPROF_ADDR"); pprofAddr != "" {
+		utils.StartPprofServer(pprofAddr)
+	}
+
 	// Configure trusted proxies
-	router.SetTrustedProxies(nil) // Trust all proxies for Railway deployment
+	// Only connections from these networks may set forwarding headers (TRUSTED_PROXIES)
+	if err := router.SetTrustedProxies(utils.TrustedProxies()); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
 	// CORS Middleware Configuration - Environment-Based Security
 	var allowedOrigins []string
@@ -100,17 +204,36 @@ func main() {
 	config.AllowCredentials = true
 	router.Use(cors.New(config))
 
+	// Maintenance and read-only switches for migrations (system.mode setting or SYSTEM_MODE)
+	router.Use(middleware.EnforceSystemMode())
+
 	// Public routes
 	router.GET("/", handlers.HomeHandler)
 	router.GET("/health", handlers.HealthHandler)
 	router.GET("/adminmessage", handlers.AdminMessageHandler)
 
+	// Prometheus scrape endpoint, enabled by setting METRICS_TOKEN
+	router.GET("/metrics", middleware.RequireMetricsToken(), handlers.GetMetrics)
+
 	// Public group routes
-	router.GET("/groups", handlers.GetGroups)
-	router.GET("/groups/:group_id", handlers.GetGroupByID)
+	router.GET("/groups", auth.OptionalAuthMiddleware(), handlers.GetGroups)
+	router.GET("/groups/today", handlers.GetGroupsToday)
+	router.GET("/groups/weekend", handlers.GetGroupsThisWeekend)
+	router.GET("/groups/:group_id", auth.OptionalAuthMiddleware(), handlers.GetGroupByID)
 
 	// Public stats route
 	router.GET("/api/stats", handlers.GetStats)
+	router.GET("/api/stats/breakdown", handlers.GetStatsBreakdown)
+
+	// Public city listing for city landing pages
+	router.GET("/api/cities", handlers.GetCities)
+
+	// Public activity taxonomy with display metadata (icons, colors, cover images)
+	router.GET("/api/activity-types", handlers.GetActivityTypes)
+	router.GET("/api/activity-types/trending", handlers.GetTrendingActivityTypes)
+
+	// Public tag listing for tag filters
+	router.GET("/api/tags/popular", handlers.GetPopularTags)
 
 	// Public profile route (safe, limited data only)
 	router.GET("/profiles/:username", handlers.GetPublicProfile)
@@ -118,13 +241,32 @@ func main() {
 	// Public profile image proxy (to avoid CORS issues)
 	router.GET("/profiles/:username/image", handlers.GetProfileImage)
 
+	// Signed download links for group archives (authorized by signature, not session)
+	router.GET("/exports/:export_id/download", handlers.DownloadGroupExport)
+
+	// Public read-only API for third parties, metered per API key
+	publicAPI := router.Group("/public/v1")
+	publicAPI.Use(middleware.RequireAPIKey())
+	{
+		publicAPI.GET("/groups", handlers.GetGroups)
+		publicAPI.GET("/groups/:group_id", handlers.GetGroupByID)
+	}
+
 	// Auth routes
 	router.GET("/auth/login", handlers.LoginHandler)
 	router.GET("/auth/google/callback", handlers.GoogleCallbackHandler)
 	router.GET("/auth/logout", handlers.LogoutHandler)
 
+	// Stateless API auth for mobile clients: exchange a Google ID token, refresh tokens
+	tokenGroup := router.Group("/auth/token")
+	tokenGroup.Use(middleware.LimitRequestBody(maxJSONBodyBytes), middleware.ValidateJSONPayload())
+	{
+		tokenGroup.POST("", auth.ExchangeGoogleToken)
+		tokenGroup.POST("/refresh", auth.RefreshAPIToken)
+	}
+
 	authPageGroup := router.Group("/")
-	authPageGroup.Use(auth.AuthMiddleware())
+	authPageGroup.Use(auth.AuthMiddleware(), middleware.LimitRequestBody(maxUploadBodyBytes), middleware.ValidateJSONPayload())
 	{
 		// Account creation page - requires authentication but not a full user profile
 		authPageGroup.GET("/create-profile", handlers.CreateProfilePageHandler)
@@ -136,31 +278,170 @@ func main() {
 		authPageGroup.GET("/api/auth/me", handlers.GetMyProfile)
 	}
 
+	// Bulk group import takes a file upload, so it gets the larger body limit
+	importGroup := router.Group("/api/groups/import")
+	importGroup.Use(auth.AuthMiddleware(), auth.RequireFullProfileMiddleware(), middleware.RequireCurrentConsent(), middleware.LimitRequestBody(maxUploadBodyBytes), middleware.ValidateJSONPayload())
+	{
+		importGroup.POST("", handlers.ImportGroups)
+	}
+
+	// Sending a chat message may include an image attachment, so it gets the larger body limit
+	messageGroup := router.Group("/api/groups/:group_id/messages")
+	messageGroup.Use(auth.AuthMiddleware(), auth.RequireFullProfileMiddleware(), middleware.RequireCurrentConsent(), middleware.LimitRequestBody(maxUploadBodyBytes), middleware.ValidateJSONPayload())
+	{
+		// Sends and replies share one per-user, per-group budget
+		chatRateLimit := middleware.ChatRateLimit()
+		messageGroup.POST("", chatRateLimit, handlers.SendGroupMessage)
+		messageGroup.POST("/:message_id/replies", chatRateLimit, handlers.SendGroupMessage)
+		messageGroup.POST("/read", handlers.MarkGroupMessagesRead)
+	}
+
 	// Protected API routes - require authentication with a full user profile
 	api := router.Group("/api")
-	api.Use(auth.AuthMiddleware(), auth.RequireFullProfileMiddleware())
+	api.Use(auth.AuthMiddleware(), auth.RequireFullProfileMiddleware(), middleware.RequireCurrentConsent(), middleware.LimitRequestBody(maxJSONBodyBytes), middleware.ValidateJSONPayload())
 	{
+		// API token revocation (signs out every device using bearer tokens)
+		api.POST("/auth/tokens/revoke", auth.RevokeAPITokens)
+
+		// Terms of service and privacy policy consent (exempt from the consent check)
+		api.GET("/consent", handlers.GetConsentStatus)
+		api.POST("/consent", handlers.AcceptPolicies)
+
 		// Account routes
 		api.GET("/accounts/:username", handlers.GetAccount)
 		api.GET("/accounts/:username/history", handlers.GetAccountEventHistory)
+		api.GET("/me/events/export", handlers.ExportMyEvents)
+		api.GET("/me/groups", handlers.ListMyGroups)
+		api.GET("/me/memberships", handlers.ListMyMemberships)
+		api.GET("/experiments", handlers.GetMyExperiments) // The caller's experiment variants
+		api.GET("/me/history/events", handlers.ListMyEventHistory)
 		api.PUT("/profile", handlers.UpdateAccount)
+		api.POST("/profile/phone/verify", handlers.StartPhoneVerification)
+		api.POST("/profile/phone/verify/check", handlers.CheckPhoneVerification)
+		api.GET("/profile/skills", handlers.ListMySkillLevels)
+		api.PUT("/profile/skills", handlers.SetMySkillLevel)
+		api.DELETE("/profile/skills/:activity_type", handlers.DeleteMySkillLevel)
 
 		// Group routes
 		api.POST("/groups", handlers.CreateGroup)
 		api.PUT("/groups/:group_id", handlers.UpdateGroup)
 		api.DELETE("/groups/:group_id", handlers.DeleteGroup)
+		api.POST("/groups/:group_id/cancel", handlers.CancelGroup)
+		api.POST("/groups/:group_id/duplicate", handlers.DuplicateGroup)
+		api.POST("/groups/:group_id/template", handlers.SaveGroupAsTemplate)
 		api.POST("/groups/:group_id/join", handlers.JoinGroup)
+		api.DELETE("/groups/:group_id/join-request", handlers.WithdrawJoinRequest)
 		api.POST("/groups/:group_id/leave", handlers.LeaveGroup)
+		api.POST("/groups/:group_id/reconfirm", handlers.ReconfirmAttendance)
+		api.PUT("/groups/:group_id/guests", handlers.UpdateGuestCount)
+
+		// Waitlist routes
+		api.GET("/groups/:group_id/waitlist", handlers.GetWaitlist)
+		api.POST("/groups/:group_id/waitlist", handlers.JoinWaitlist)
+		api.DELETE("/groups/:group_id/waitlist", handlers.LeaveWaitlist)
 
 		// New endpoints for organiser actions
 		api.GET("/groups/:group_id/pending-members", handlers.ListPendingMembers)
+		api.GET("/organizer/pending-counts", handlers.GetOrganizerPendingCounts)
+		api.GET("/organizer/dashboard", handlers.GetOrganizerDashboard)
 		api.POST("/groups/:group_id/members/:username/approve", handlers.ApproveJoinRequest)
 		api.POST("/groups/:group_id/members/:username/reject", handlers.RejectJoinRequest)
+		api.POST("/groups/:group_id/members/bulk", handlers.BulkUpdateMembers)
 		api.POST("/groups/:group_id/members/:username/remove", handlers.RemoveMember)
+		api.PUT("/groups/:group_id/members/:username/role", handlers.UpdateMemberRole)
+		api.POST("/groups/:group_id/members/:username/checkin", handlers.CheckInGroupMember)
+		api.DELETE("/groups/:group_id/members/:username/checkin", handlers.UndoCheckIn)
+		api.GET("/groups/:group_id/checkin/code", handlers.GetCheckInCode)
+		api.POST("/groups/:group_id/checkin", handlers.SelfCheckIn)
+		api.POST("/groups/:group_id/checkin/scan", handlers.ScanCheckInTicket)
+		api.GET("/groups/:group_id/ticket", handlers.GetCheckInTicket)
+		api.GET("/groups/:group_id/attendance", handlers.GetGroupAttendance)
+		api.GET("/groups/:group_id/analytics", handlers.GetGroupAnalytics)
 
 		// Message routes
 		api.GET("/groups/:group_id/messages", handlers.GetGroupMessages)
-		api.POST("/groups/:group_id/messages", handlers.SendGroupMessage)
+		api.PUT("/groups/:group_id/messages/:message_id", handlers.EditGroupMessage)
+		api.DELETE("/groups/:group_id/messages/:message_id", handlers.DeleteGroupMessage)
+		api.GET("/groups/:group_id/messages/:message_id/thread", handlers.GetMessageThread)
+		api.DELETE("/groups/:group_id/messages/:message_id/moderate", handlers.ModerateDeleteMessage)
+		api.GET("/groups/:group_id/chat/mutes", handlers.ListChatMutes)
+		api.PUT("/groups/:group_id/chat/mutes/:username", handlers.MuteChatMember)
+		api.DELETE("/groups/:group_id/chat/mutes/:username", handlers.UnmuteChatMember)
+		api.PUT("/groups/:group_id/chat/lock", handlers.SetChatLock)
+		api.POST("/groups/:group_id/broadcast", handlers.BroadcastToGroup)
+
+		// Announcement routes (members read, organizer manages)
+		api.GET("/groups/:group_id/announcements", handlers.ListAnnouncements)
+		api.POST("/groups/:group_id/announcements", handlers.CreateAnnouncement)
+		api.PUT("/groups/:group_id/announcements/:announcement_id", handlers.UpdateAnnouncement)
+		api.DELETE("/groups/:group_id/announcements/:announcement_id", handlers.DeleteAnnouncement)
+
+		// Invitation routes (organizer invites, invitee accepts or declines)
+		api.POST("/groups/:group_id/invites", handlers.CreateGroupInvitations)
+		api.GET("/groups/:group_id/invites", handlers.ListGroupInvitations)
+		api.POST("/groups/:group_id/invite-link", handlers.CreateInviteLink)
+		api.GET("/invites", handlers.ListMyInvitations)
+		api.POST("/invites/:invite_id/accept", handlers.AcceptInvitation)
+		api.POST("/invites/:invite_id/decline", handlers.DeclineInvitation)
+
+		// Group template routes (saved settings for events an organizer runs again and again)
+		api.GET("/templates", handlers.ListGroupTemplates)
+		api.POST("/templates", handlers.CreateGroupTemplate)
+		api.PUT("/templates/:template_id", handlers.UpdateGroupTemplate)
+		api.DELETE("/templates/:template_id", handlers.DeleteGroupTemplate)
+		api.POST("/templates/:template_id/groups", handlers.CreateGroupFromTemplate)
+
+		// Following organizers (followers get early access to their new groups)
+		api.POST("/organizers/:username/follow", handlers.FollowOrganizer)
+		api.DELETE("/organizers/:username/follow", handlers.UnfollowOrganizer)
+		api.GET("/me/following", handlers.ListFollowing)
+
+		// Private post-event feedback routes (attendees send, organizer reads and reports)
+		api.POST("/groups/:group_id/feedback", handlers.SubmitEventFeedback)
+		api.GET("/groups/:group_id/feedback", handlers.ListEventFeedback)
+		api.POST("/groups/:group_id/feedback/:feedback_id/report", handlers.ReportEventFeedback)
+
+		// Chat presence and typing indicator routes
+		api.GET("/groups/:group_id/presence", handlers.GetGroupPresence)
+		api.POST("/groups/:group_id/presence", handlers.SendPresenceHeartbeat)
+		api.DELETE("/groups/:group_id/presence", handlers.LeaveGroupPresence)
+
+		// Slack/Discord integration routes (organizer only)
+		api.GET("/groups/:group_id/integrations", handlers.ListGroupIntegrations)
+		api.POST("/groups/:group_id/integrations", handlers.CreateGroupIntegration)
+		api.DELETE("/groups/:group_id/integrations/:integration_id", handlers.DeleteGroupIntegration)
+
+		// Group archive export routes
+		api.POST("/groups/:group_id/export", handlers.RequestGroupExport)
+		api.GET("/groups/:group_id/exports/:export_id", handlers.GetGroupExport)
+
+		// Poll routes
+		api.GET("/groups/:group_id/polls", handlers.ListGroupPolls)
+		api.POST("/groups/:group_id/polls", handlers.CreatePoll)
+		api.GET("/groups/:group_id/polls/:poll_id", handlers.GetPollResults)
+		api.POST("/groups/:group_id/polls/:poll_id/vote", handlers.VotePoll)
+		api.POST("/groups/:group_id/polls/:poll_id/close", handlers.ClosePoll)
+
+		// Checklist routes
+		api.GET("/groups/:group_id/checklist", handlers.ListChecklistItems)
+		api.POST("/groups/:group_id/checklist", handlers.CreateChecklistItem)
+		api.PUT("/groups/:group_id/checklist/:item_id", handlers.UpdateChecklistItem)
+		api.DELETE("/groups/:group_id/checklist/:item_id", handlers.DeleteChecklistItem)
+		api.POST("/groups/:group_id/checklist/:item_id/claim", handlers.ClaimChecklistItem)
+		api.POST("/groups/:group_id/checklist/:item_id/unclaim", handlers.UnclaimChecklistItem)
+
+		// Outbound webhook routes for automation tools
+		api.GET("/integrations/events", handlers.ListWebhookEvents)
+		api.GET("/integrations/webhooks", handlers.ListWebhooks)
+		api.POST("/integrations/webhooks", handlers.CreateWebhook)
+		api.DELETE("/integrations/webhooks/:webhook_id", handlers.DeleteWebhook)
+		api.POST("/integrations/webhooks/:webhook_id/test", handlers.TestWebhook)
+
+		// Public API key management
+		api.GET("/developer/keys", handlers.ListAPIKeys)
+		api.POST("/developer/keys", handlers.CreateAPIKey)
+		api.DELETE("/developer/keys/:key_id", handlers.RevokeAPIKey)
+		api.GET("/developer/keys/:key_id/usage", handlers.GetAPIKeyUsage)
 
 		// Notification routes
 		api.GET("/notifications", handlers.ListNotifications)
@@ -168,6 +449,13 @@ func main() {
 
 		// Location validation route
 		api.GET("/locations/validate", handlers.ValidateLocation)
+
+		// Admin routes (accounts listed in ADMIN_USERNAMES)
+		api.POST("/admin/accounts/merge", middleware.RequireAdmin(), handlers.MergeAccounts)
+		api.GET("/admin/metrics/alert-rules", middleware.RequireAdmin(), handlers.GetAlertRules)
+		api.GET("/admin/groups/deleted", middleware.RequireAdmin(), handlers.ListDeletedGroups)
+		api.POST("/admin/groups/:group_id/restore", middleware.RequireAdmin(), handlers.RestoreGroup)
+		api.POST("/admin/accounts/:username/restore", middleware.RequireAdmin(), handlers.RestoreAccount)
 	}
 
 	// Start the server
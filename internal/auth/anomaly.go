@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// What happens when a session's country or device changes mid-session (SESSION_ANOMALY_ACTION)
+const (
+	AnomalyActionFlag       = "flag"       // Record an audit event and carry on
+	AnomalyActionReauth     = "reauth"     // End this session so the user signs in again
+	AnomalyActionInvalidate = "invalidate" // End every session of the account
+)
+
+// ErrCodeReauthRequired tells clients to send the user back through sign-in
+const ErrCodeReauthRequired = "REAUTH_REQUIRED"
+
+// sessionAnomalyAction returns the configured anomaly action, defaulting to reauth
+func sessionAnomalyAction() string {
+	switch action := strings.ToLower(os.Getenv("SESSION_ANOMALY_ACTION")); action {
+	case AnomalyActionFlag, AnomalyActionReauth, AnomalyActionInvalidate:
+		return action
+	case "":
+		return AnomalyActionReauth
+	default:
+		fmt.Printf("Warning: Unknown SESSION_ANOMALY_ACTION %q, using %s\n", action, AnomalyActionReauth)
+		return AnomalyActionReauth
+	}
+}
+
+// userAgentFamily reduces a user agent to its browser and OS, so browser updates
+// don't look like a different device. It returns "" for agents it doesn't recognise.
+func userAgentFamily(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "edge"
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		browser = "opera"
+	case strings.Contains(ua, "samsungbrowser"):
+		browser = "samsung"
+	case strings.Contains(ua, "firefox/"), strings.Contains(ua, "fxios"):
+		browser = "firefox"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios"):
+		browser = "chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "safari"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		platform = "android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		platform = "ios"
+	case strings.Contains(ua, "windows"):
+		platform = "windows"
+	case strings.Contains(ua, "mac os"):
+		platform = "macos"
+	case strings.Contains(ua, "cros"):
+		platform = "chromeos"
+	case strings.Contains(ua, "linux"):
+		platform = "linux"
+	}
+
+	if browser == "" || platform == "" {
+		return ""
+	}
+	return browser + "/" + platform
+}
+
+// detectSessionAnomaly compares a request with what its session last saw and describes any drastic change
+func detectSessionAnomaly(c *gin.Context, session *models.Session) []string {
+	var changes []string
+
+	country := utils.ClientCountryFromRequest(c.Request)
+	if session.Country != "" && country != "" && country != session.Country {
+		changes = append(changes, fmt.Sprintf("country changed from %s to %s", session.Country, country))
+	}
+
+	previous, current := userAgentFamily(session.UserAgent), userAgentFamily(c.Request.UserAgent())
+	if previous != "" && current != "" && previous != current {
+		changes = append(changes, fmt.Sprintf("device changed from %s to %s", previous, current))
+	}
+
+	return changes
+}
+
+// truncate shortens s to at most n bytes for fixed-size columns
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// recordAuditEvent stores a security event for the session's account. Failures are only logged.
+func recordAuditEvent(c *gin.Context, session *models.Session, event, detail string) {
+	audit := models.AuditEvent{
+		Username:  session.Username,
+		SessionID: session.ID,
+		Event:     event,
+		Detail:    detail,
+		IPAddress: utils.GetRealClientIP(c),
+		UserAgent: truncate(c.Request.UserAgent(), 255),
+		Country:   utils.ClientCountryFromRequest(c.Request),
+		CreatedAt: time.Now(),
+	}
+	if err := database.GetDBWithContext(c.Request.Context()).Create(&audit).Error; err != nil {
+		fmt.Printf("Warning: Failed to record audit event %s for %s: %v\n", event, session.Username, err)
+	}
+}
+
+// checkSessionAnomaly flags a session whose country or device changed drastically and applies the
+// configured action. It returns false when the session was ended and the request must be rejected.
+func checkSessionAnomaly(c *gin.Context, session *models.Session) bool {
+	db := database.GetDBWithContext(c.Request.Context())
+	changes := detectSessionAnomaly(c, session)
+
+	if len(changes) == 0 {
+		// Remember the country the first time a proxy reports one
+		if session.Country == "" {
+			if country := utils.ClientCountryFromRequest(c.Request); country != "" {
+				if err := db.Model(&models.Session{}).Where("id = ?", session.ID).Update("country", country).Error; err != nil {
+					fmt.Printf("Warning: Failed to record session country: %v\n", err)
+				}
+				session.Country = country
+			}
+		}
+		return true
+	}
+
+	action := sessionAnomalyAction()
+	detail := strings.Join(changes, "; ")
+	recordAuditEvent(c, session, models.AuditSessionAnomaly, fmt.Sprintf("%s (action: %s)", detail, action))
+
+	switch action {
+	case AnomalyActionReauth:
+		DeleteSession(c)
+		recordAuditEvent(c, session, models.AuditSessionRevoked, "Session ended: "+detail)
+		return false
+	case AnomalyActionInvalidate:
+		DeleteSession(c)
+		var revoked int64
+		if session.Username != "" {
+			result := db.Where("username = ?", session.Username).Delete(&models.Session{})
+			if result.Error != nil {
+				fmt.Printf("Warning: Failed to end sessions for %s: %v\n", session.Username, result.Error)
+			}
+			revoked = result.RowsAffected
+		}
+		recordAuditEvent(c, session, models.AuditSessionRevoked, fmt.Sprintf("All sessions ended (%d others): %s", revoked, detail))
+		return false
+	}
+
+	// Flagged only: accept the new fingerprint so the change is recorded once rather than on every request
+	country := utils.ClientCountryFromRequest(c.Request)
+	userAgent := truncate(c.Request.UserAgent(), 255)
+	updates := map[string]interface{}{"ip_address": utils.GetRealClientIP(c), "user_agent": userAgent}
+	if country != "" {
+		updates["country"] = country
+	}
+	if err := db.Model(&models.Session{}).Where("id = ?", session.ID).Updates(updates).Error; err != nil {
+		fmt.Printf("Warning: Failed to update flagged session: %v\n", err)
+	}
+	return true
+}
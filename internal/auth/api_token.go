@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GoogleTokenRequest exchanges a Google ID token (obtained by a mobile client) for API tokens
+type GoogleTokenRequest struct {
+	IDToken string `json:"id_token" binding:"required"`
+}
+
+// RefreshTokenRequest exchanges a refresh token for a new token pair
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// apiClientIDs are the OAuth client IDs whose ID tokens may be exchanged for API tokens.
+// Mobile apps use their own client IDs, listed in GOOGLE_API_CLIENT_IDS; the web client is always accepted.
+func apiClientIDs() []string {
+	var ids []string
+	for _, id := range strings.Split(os.Getenv("GOOGLE_API_CLIENT_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if googleOAuthConfig != nil {
+		ids = append(ids, googleOAuthConfig.ClientID)
+	}
+	return ids
+}
+
+// ExchangeGoogleToken issues API tokens for an existing account given a verified Google ID token
+func ExchangeGoogleToken(c *gin.Context) {
+	var request GoogleTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	// Accept the token if any of our client IDs is its audience
+	var userInfo *UserInfo
+	for _, clientID := range apiClientIDs() {
+		payload, err := verifyIDToken(request.IDToken, clientID)
+		if err != nil {
+			continue
+		}
+		if userInfo, err = extractUserInfoFromPayload(payload); err == nil {
+			break
+		}
+	}
+	if userInfo == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid id_token"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var account models.Account
+	if err := db.Where("google_id = ?", userInfo.Sub).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "No account found - sign up first", "needsProfile": true})
+			return
+		}
+		log.Printf("Error: Failed to look up account for token exchange: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+
+	if strings.HasPrefix(account.Username, "temp-") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Complete profile registration first", "needsProfile": true})
+		return
+	}
+
+	// A merged account signs in as the account it was merged into
+	signedIn := account
+	if account.MergedInto != nil {
+		if err := db.Where("username = ?", *account.MergedInto).First(&signedIn).Error; err != nil {
+			log.Printf("Error: Failed to load merged account %s: %v", *account.MergedInto, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+			return
+		}
+	}
+
+	tokens, err := IssueTokenPair(signedIn.Username, signedIn.TokenVersion)
+	if err != nil {
+		log.Printf("Error: Failed to issue API tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+
+	// Exchanging an ID token is a sign-in, so record it like the web callback does
+	if err := SyncAccountOnLogin(account, userInfo); err != nil {
+		log.Printf("Warning: Failed to sync account on login: %v", err)
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RefreshAPIToken issues a new token pair for a valid, unrevoked refresh token
+func RefreshAPIToken(c *gin.Context) {
+	var request RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	claims, err := ParseRefreshToken(request.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired refresh token"})
+		return
+	}
+
+	account, err := loadTokenAccount(claims)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token has been revoked"})
+		return
+	}
+
+	tokens, err := IssueTokenPair(account.Username, account.TokenVersion)
+	if err != nil {
+		log.Printf("Error: Failed to issue API tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeAPITokens invalidates every access and refresh token issued to the current user
+func RevokeAPITokens(c *gin.Context) {
+	username := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+	if err := db.Model(&models.Account{}).Where("username = ?", username).
+		Update("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		log.Printf("Error: Failed to revoke API tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All API tokens revoked"})
+}
+
+// loadTokenAccount returns the account a token was issued to, failing if the token version was revoked
+func loadTokenAccount(claims *TokenClaims) (*models.Account, error) {
+	var account models.Account
+	if err := database.GetDB().Where("username = ?", claims.Username).First(&account).Error; err != nil {
+		return nil, err
+	}
+	if account.TokenVersion != claims.Version {
+		return nil, ErrInvalidToken
+	}
+	return &account, nil
+}
+
+// authenticateBearer validates an API access token and populates the same context keys as a session
+func authenticateBearer(c *gin.Context, token string) {
+	claims, err := ParseAccessToken(token)
+	if err != nil {
+		message := "invalid access token"
+		if errors.Is(err, ErrExpiredToken) {
+			message = "access token expired"
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+		c.Abort()
+		return
+	}
+
+	account, err := loadTokenAccount(claims)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "access token has been revoked"})
+		c.Abort()
+		return
+	}
+
+	c.Set("username", account.Username)
+	c.Set("sub", account.GoogleID)
+	c.Set("email", account.Email)
+	c.Set("name", account.FullName)
+	c.Set("picture", account.AvatarURL)
+	c.Set("email_verified", account.EmailVerified)
+	c.Set("given_name", account.GivenName)
+	c.Set("family_name", account.FamilyName)
+	c.Set("locale", account.Locale)
+
+	c.Next()
+}
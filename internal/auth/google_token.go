@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNoRefreshToken is returned when a user hasn't granted offline access to their Google account
+var ErrNoRefreshToken = errors.New("no Google refresh token stored for user")
+
+// tokenRefreshMargin refreshes access tokens slightly before they expire
+const tokenRefreshMargin = time.Minute
+
+// SaveGoogleTokens encrypts and stores the tokens from an OAuth exchange on the account.
+// Google only returns a refresh token on first consent, so an existing one is kept if none is supplied.
+func SaveGoogleTokens(googleID string, token *oauth2.Token) error {
+	updates := map[string]interface{}{}
+
+	if token.AccessToken != "" {
+		encrypted, err := utils.EncryptString(token.AccessToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt access token: %w", err)
+		}
+		updates["encrypted_access_token"] = encrypted
+		if !token.Expiry.IsZero() {
+			updates["google_token_expiry"] = token.Expiry
+		}
+	}
+
+	if token.RefreshToken != "" {
+		encrypted, err := utils.EncryptString(token.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt refresh token: %w", err)
+		}
+		updates["encrypted_refresh_token"] = encrypted
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return database.GetDB().Model(&models.Account{}).Where("google_id = ?", googleID).Updates(updates).Error
+}
+
+// NeedsTokenRefresh reports whether the stored Google access token is missing or about to expire
+func NeedsTokenRefresh(account models.Account) bool {
+	return account.EncryptedAccessToken == "" ||
+		account.GoogleTokenExpiry == nil ||
+		time.Now().Add(tokenRefreshMargin).After(*account.GoogleTokenExpiry)
+}
+
+// GetGoogleAccessToken returns a valid Google access token for a user, refreshing and
+// persisting it with the stored refresh token when needed. Used by long-lived integrations
+// (e.g. calendar sync) that call Google APIs on the user's behalf.
+func GetGoogleAccessToken(ctx context.Context, username string) (*oauth2.Token, error) {
+	if googleOAuthConfig == nil {
+		return nil, errors.New("google OAuth is not initialized")
+	}
+
+	var account models.Account
+	if err := database.GetDBWithContext(ctx).Where("username = ?", username).First(&account).Error; err != nil {
+		return nil, err
+	}
+
+	if !NeedsTokenRefresh(account) {
+		accessToken, err := utils.DecryptString(account.EncryptedAccessToken)
+		if err == nil {
+			return &oauth2.Token{AccessToken: accessToken, TokenType: "Bearer", Expiry: *account.GoogleTokenExpiry}, nil
+		}
+		// Fall through and refresh if the stored token can't be decrypted
+	}
+
+	if account.EncryptedRefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	refreshToken, err := utils.DecryptString(account.EncryptedRefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt refresh token: %w", err)
+	}
+
+	token, err := googleOAuthConfig.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh Google access token: %w", err)
+	}
+
+	if err := SaveGoogleTokens(account.GoogleID, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
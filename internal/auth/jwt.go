@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// AccessTokenTTL is how long an API access token is valid
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long an API refresh token is valid
+	RefreshTokenTTL = 30 * 24 * time.Hour
+
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrExpiredToken   = errors.New("token expired")
+	ErrNoTokenSecret  = errors.New("JWT_SECRET environment variable not set")
+	errWrongTokenType = errors.New("wrong token type")
+)
+
+// jwtHeader is the fixed HS256 header used for all tokens
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// TokenClaims are the claims carried by API access and refresh tokens.
+// Version must match Account.TokenVersion, so bumping it revokes every outstanding token.
+type TokenClaims struct {
+	Username  string `json:"sub"`
+	Version   int    `json:"ver"`
+	Type      string `json:"typ"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenPair is returned to API clients when they sign in or refresh
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func jwtSecret() ([]byte, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, ErrNoTokenSecret
+	}
+	return []byte(secret), nil
+}
+
+// IssueTokenPair creates a new access and refresh token for a user at the given token version
+func IssueTokenPair(username string, version int) (*TokenPair, error) {
+	now := time.Now()
+
+	access, err := signToken(TokenClaims{
+		Username:  username,
+		Version:   version,
+		Type:      tokenTypeAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(AccessTokenTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := signToken(TokenClaims{
+		Username:  username,
+		Version:   version,
+		Type:      tokenTypeRefresh,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(RefreshTokenTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresAt:    now.Add(AccessTokenTTL),
+	}, nil
+}
+
+// ParseAccessToken verifies an access token's signature and expiry
+func ParseAccessToken(token string) (*TokenClaims, error) {
+	return parseToken(token, tokenTypeAccess)
+}
+
+// ParseRefreshToken verifies a refresh token's signature and expiry
+func ParseRefreshToken(token string) (*TokenClaims, error) {
+	return parseToken(token, tokenTypeRefresh)
+}
+
+func signToken(claims TokenClaims) (string, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + signHS256(signingInput, secret), nil
+}
+
+func parseToken(token, expectedType string) (*TokenClaims, error) {
+	secret, err := jwtSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	expected := signHS256(parts[0]+"."+parts[1], secret)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Type != expectedType {
+		return nil, errWrongTokenType
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func signHS256(signingInput string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
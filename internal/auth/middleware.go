@@ -51,7 +51,9 @@ func GetLoginURL(c *gin.Context) (string, error) {
 	}
 
 	// Generate the authorization URL with the state parameter
+	// Offline access returns a refresh token (on first consent) for long-lived integrations
 	return googleOAuthConfig.AuthCodeURL(state,
+		oauth2.AccessTypeOffline,
 		oauth2.SetAuthURLParam("prompt", "select_account"),
 	), nil
 }
@@ -99,19 +101,31 @@ func HandleGoogleCallback(c *gin.Context) {
 		return
 	}
 
-	// Send admin notification for successful OAuth login
-	emailSvc := services.NewEmailService()
-	if err := emailSvc.SendAdminOAuthNotification(userInfo.Email, userInfo.Name); err != nil {
-		// Just log the error, don't stop the authentication flow
-		fmt.Printf("Warning: Failed to send admin OAuth notification: %v\n", err)
-	} else {
-		fmt.Printf("Admin notification sent for OAuth login: %s (%s)\n", userInfo.Name, userInfo.Email)
-	}
-
 	// Check if user already exists
 	var existingAccount models.Account
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	if err := db.Where("google_id = ?", userInfo.Sub).First(&existingAccount).Error; err == nil {
+		// Keep the user's Google tokens for long-lived integrations
+		if err := SaveGoogleTokens(userInfo.Sub, token); err != nil {
+			fmt.Printf("Warning: Failed to save Google tokens: %v\n", err)
+		}
+
+		// Record the login and pick up Google profile changes
+		if err := SyncAccountOnLogin(existingAccount, userInfo); err != nil {
+			fmt.Printf("Warning: Failed to sync account on login: %v\n", err)
+		}
+
+		// A merged account signs in as the account it was merged into
+		if existingAccount.MergedInto != nil {
+			var mergedAccount models.Account
+			if err := db.Where("username = ?", *existingAccount.MergedInto).First(&mergedAccount).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load merged account"})
+				c.Abort()
+				return
+			}
+			existingAccount = mergedAccount
+		}
+
 		// User exists, create session with username
 		if err := CreateSession(c, userInfo, existingAccount.Username); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
@@ -130,6 +144,14 @@ func HandleGoogleCallback(c *gin.Context) {
 		return
 	}
 
+	// A deleted account keeps its Google ID until it is purged; an admin can restore it meanwhile
+	var deletedCount int64
+	if err := db.Unscoped().Model(&models.Account{}).Where("google_id = ? AND deleted_at IS NOT NULL", userInfo.Sub).Count(&deletedCount).Error; err == nil && deletedCount > 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been deleted. Contact support to restore it."})
+		c.Abort()
+		return
+	}
+
 	// User does not exist
 	// Generate a temporary random username
 	randomID, err := GenerateRandomString(8)
@@ -160,6 +182,14 @@ func HandleGoogleCallback(c *gin.Context) {
 	// Create the account
 	if err := db.Create(&tempAccount).Error; err != nil {
 		fmt.Printf("Warning: Failed to create temporary account: %v\n", err)
+	} else {
+		if err := SaveGoogleTokens(userInfo.Sub, token); err != nil {
+			fmt.Printf("Warning: Failed to save Google tokens: %v\n", err)
+		}
+
+		// First sign-in with this Google account: tell the admins about the new signup
+		services.GetAdminNotifier().Notify(services.AdminEventNewSignup, "New signup",
+			fmt.Sprintf("%s (%s) signed in for the first time", userInfo.Name, userInfo.Email))
 	}
 
 	// Create session with temporary username
@@ -213,9 +243,15 @@ func extractUserInfoFromPayload(payload *idtoken.Payload) (*UserInfo, error) {
 	return userInfo, nil
 }
 
-// AuthMiddleware validates the session
+// AuthMiddleware validates the session, or an API access token sent as "Authorization: Bearer <token>"
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Stateless API clients (e.g. mobile apps) authenticate with a bearer token instead of a cookie
+		if header := c.GetHeader("Authorization"); strings.HasPrefix(header, "Bearer ") {
+			authenticateBearer(c, strings.TrimPrefix(header, "Bearer "))
+			return
+		}
+
 		// Get the session from the request
 		session, err := GetSession(c)
 		if err != nil {
@@ -232,6 +268,13 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// A session that suddenly moves country or device may have been stolen
+		if !checkSessionAnomaly(c, session) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unusual session activity, please log in again", "code": ErrCodeReauthRequired})
+			c.Abort()
+			return
+		}
+
 		// Store user info in context for handlers to use
 		// If session has a username, set it in the context
 		if session.Username != "" {
@@ -250,6 +293,25 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware identifies signed-in users on public routes without requiring a login:
+// a missing, expired or invalid session or token just leaves the request anonymous
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if header := c.GetHeader("Authorization"); strings.HasPrefix(header, "Bearer ") {
+			if claims, err := ParseAccessToken(strings.TrimPrefix(header, "Bearer ")); err == nil {
+				if account, err := loadTokenAccount(claims); err == nil {
+					c.Set("username", account.Username)
+				}
+			}
+		} else if session, err := GetSession(c); err == nil && !session.IsExpired() && session.Username != "" {
+			if checkSessionAnomaly(c, session) {
+				c.Set("username", session.Username)
+			}
+		}
+		c.Next()
+	}
+}
+
 // RequireFullProfileMiddleware ensures the user has completed profile registration
 func RequireFullProfileMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
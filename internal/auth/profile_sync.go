@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SyncAccountOnLogin records a successful sign-in and, unless GOOGLE_PROFILE_SYNC is disabled,
+// copies changed profile details from the verified ID token claims onto the account.
+// The display name and avatar are only refreshed while they still hold Google's values,
+// so a name the user typed or an avatar they uploaded is never overwritten.
+func SyncAccountOnLogin(account models.Account, userInfo *UserInfo) error {
+	updates := map[string]interface{}{
+		"last_login": time.Now(),
+	}
+
+	if utils.GetEnvBool("GOOGLE_PROFILE_SYNC", true) {
+		previousGoogleName := strings.TrimSpace(account.GivenName + " " + account.FamilyName)
+		if userInfo.Name != "" && userInfo.Name != account.FullName &&
+			(account.FullName == "" || account.FullName == previousGoogleName) {
+			updates["full_name"] = userInfo.Name
+		}
+
+		if userInfo.Picture != "" && userInfo.Picture != account.AvatarURL &&
+			(account.AvatarURL == "" || isGoogleAvatar(account.AvatarURL)) {
+			updates["avatar_url"] = userInfo.Picture
+		}
+
+		if userInfo.GivenName != "" {
+			updates["given_name"] = userInfo.GivenName
+		}
+		if userInfo.FamilyName != "" {
+			updates["family_name"] = userInfo.FamilyName
+		}
+		if userInfo.Locale != "" {
+			updates["locale"] = userInfo.Locale
+		}
+		updates["email_verified"] = userInfo.EmailVerified
+	}
+
+	return database.GetDB().Model(&models.Account{}).Where("google_id = ?", account.GoogleID).Updates(updates).Error
+}
+
+// isGoogleAvatar reports whether an avatar URL is a Google profile photo rather than an upload
+func isGoogleAvatar(avatarURL string) bool {
+	parsed, err := url.Parse(avatarURL)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(parsed.Hostname(), ".googleusercontent.com")
+}
@@ -44,7 +44,7 @@ func CreateSession(c *gin.Context, userInfo *UserInfo, username ...string) error
 	}
 
 	// Get database connection
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Get real client IP using the utility function
 	clientIP := utils.GetRealClientIP(c)
@@ -65,6 +65,7 @@ func CreateSession(c *gin.Context, userInfo *UserInfo, username ...string) error
 		UserAgent:     c.Request.UserAgent(),
 		CreatedAt:     time.Now(),
 		ExpiresAt:     time.Now().Add(models.SessionDuration),
+		Country:       utils.ClientCountryFromRequest(c.Request),
 	}
 
 	// Set username and check if it's a temporary account
@@ -124,7 +125,7 @@ func GetSession(c *gin.Context) (*models.Session, error) {
 	}
 
 	// Get the session from the database
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var session models.Session
 	if err := db.Where("id = ?", sessionID).First(&session).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -148,7 +149,7 @@ func DeleteSession(c *gin.Context) {
 	sessionID, err := c.Cookie(SessionCookieName)
 	if err == nil {
 		// Get database connection
-		db := database.GetDB()
+		db := database.GetDBWithContext(c.Request.Context())
 
 		// Update login log with logout time
 		now := time.Now()
@@ -66,6 +66,7 @@ func InitDB() error {
 		PrepareStmt:                              true,  // Enable prepared statement cache
 		SkipDefaultTransaction:                   false, // Keep default transaction for safety
 		DisableForeignKeyConstraintWhenMigrating: false, // Enable foreign key constraints
+		TranslateError:                           true,  // Map unique violations to gorm.ErrDuplicatedKey
 	}
 
 	// Open connection with retry logic
@@ -99,11 +100,39 @@ func InitDB() error {
 	sqlDB.SetMaxOpenConns(100)          // Maximum number of open connections
 	sqlDB.SetConnMaxLifetime(time.Hour) // Maximum lifetime of a connection
 
+	// Bound every statement by DB_QUERY_TIMEOUT so a slow query can't hold a request forever
+	if err := registerQueryTimeout(DB); err != nil {
+		log.Printf("Warning: Failed to register query timeout: %v", err)
+	}
+
+	// Count queries per request when profiling (PROFILE_DB_QUERIES)
+	if utils.GetEnvBool("PROFILE_DB_QUERIES", false) {
+		if err := registerQueryCounter(DB); err != nil {
+			log.Printf("Warning: Failed to register query counter: %v", err)
+		}
+	}
+
 	// Enable PostgreSQL extensions for advanced search
 	if err := enableSearchExtensions(DB); err != nil {
 		log.Printf("Warning: Failed to enable search extensions: %v", err)
 	}
 
+	// Groups created before the member counter caches existed need them filled in once
+	backfillMemberCounts := DB.Migrator().HasTable(&models.Group{}) && !DB.Migrator().HasColumn(&models.Group{}, "approved_member_count")
+
+	// The listing view depends on group columns, so it is rebuilt around the migration when they or
+	// the view changed. Other instances keep reading it meanwhile, so an unchanged view is left alone.
+	groupCardVersion, err := groupCardViewVersion(DB)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	rebuildGroupCards := err != nil || !groupCardViewCurrent(DB, groupCardVersion)
+	if rebuildGroupCards {
+		if err := dropGroupCardView(DB); err != nil {
+			log.Printf("Warning: Failed to drop group_card view: %v", err)
+		}
+	}
+
 	if err := DB.AutoMigrate(
 		&models.Account{},
 		&models.Group{},
@@ -114,19 +143,152 @@ func InitDB() error {
 		&models.LoginLog{},
 		&models.ReminderSent{},
 		&models.Message{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.PollVote{},
+		&models.ChecklistItem{},
+		&models.WeatherForecast{},
+		&models.MemberOffense{},
+		&models.JoinBan{},
+		&models.AppSetting{},
+		&models.GroupExport{},
+		&models.WaitlistEntry{},
+		&models.GroupBroadcast{},
+		&models.UserSkillLevel{},
+		&models.MessageRevision{},
+		&models.GroupIntegration{},
+		&models.WebhookSubscription{},
+		&models.APIKey{},
+		&models.APIKeyUsage{},
+		&models.MessageReadCursor{},
+		&models.StreakMilestone{},
+		&models.Announcement{},
+		&models.EventFeedback{},
+		&models.Invitation{},
+		&models.OrganizerFollow{},
+		&models.ChatMute{},
+		&models.Tag{},
+		&models.GroupTag{},
+		&models.AuditEvent{},
+		&models.GroupTemplate{},
+		&models.ConsentRecord{},
+		&models.QueuedEmail{},
+		&models.OutboxEvent{},
+		&models.AnalyticsEvent{},
+		&models.AnalyticsDailyRollup{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	// Usernames are unique regardless of case; enforced by the database so concurrent claims can't both win
+	if err := DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_account_username_lower ON account (LOWER(username))`).Error; err != nil {
+		log.Printf("Warning: Failed to create case-insensitive username index: %v", err)
+	}
+
+	// Members created before roles existed default to "member", including organisers
+	if err := DB.Exec(`UPDATE group_member SET role = ? FROM "group"
+		WHERE "group".id = group_member.group_id AND "group".organiser_id = group_member.username AND group_member.role <> ?`,
+		models.RoleOrganiser, models.RoleOrganiser).Error; err != nil {
+		log.Printf("Warning: Failed to backfill organiser member roles: %v", err)
+	}
+
+	// Groups created before cities were derived have a NULL city; raw scans expect a string
+	if err := DB.Exec(`UPDATE "group" SET city = '' WHERE city IS NULL`).Error; err != nil {
+		log.Printf("Warning: Failed to backfill group cities: %v", err)
+	}
+
+	if backfillMemberCounts {
+		if err := migrateMemberCounts(DB); err != nil {
+			log.Printf("Warning: Failed to backfill group member counts: %v", err)
+		}
+	}
+
+	if err := migrateReadReceipts(DB); err != nil {
+		log.Printf("Warning: Failed to migrate message read receipts: %v", err)
+	}
+
+	createCompositeIndexes(DB)
+
 	// Set up search indexes and triggers after migration
 	if err := setupSearchIndexes(DB); err != nil {
 		log.Printf("Warning: Failed to setup search indexes: %v", err)
 	}
 
+	// Landing-page listings are served from a materialized view; GET /groups falls back to the
+	// group table if it can't be built
+	if !rebuildGroupCards {
+		groupCardsReady.Store(true)
+	} else if err := createGroupCardView(DB, groupCardVersion); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	registerGroupCardInvalidation(DB)
+
 	log.Println("Database connection established and migrations completed")
 	return nil
 }
 
+// compositeIndexes cover the filter combinations of the hottest queries: listings by date and
+// activity, an organizer's upcoming groups, unread notifications, and member lookups by status
+var compositeIndexes = []struct {
+	Name       string
+	Definition string
+}{
+	{"idx_group_date_time_activity_type", `"group" (date_time, activity_type)`},
+	{"idx_group_organiser_id_date_time", `"group" (organiser_id, date_time)`},
+	{"idx_notification_recipient_read_created_at", `notification (recipient_username, read, created_at)`},
+	{"idx_group_member_group_id_status", `group_member (group_id, status)`},
+}
+
+// createCompositeIndexes creates any missing composite indexes. Failures are logged and skipped.
+func createCompositeIndexes(db *gorm.DB) {
+	for _, index := range compositeIndexes {
+		if err := db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s`, index.Name, index.Definition)).Error; err != nil {
+			log.Printf("Warning: Failed to create index %s: %v", index.Name, err)
+		}
+	}
+}
+
+// migrateMemberCounts fills the member counter caches of existing groups from group_member
+func migrateMemberCounts(db *gorm.DB) error {
+	log.Println("Backfilling group member counts...")
+	return db.Exec(`UPDATE "group" SET
+		approved_member_count = counts.approved,
+		approved_guest_count = counts.guests,
+		pending_count = counts.pending
+		FROM (
+			SELECT group_id,
+				COUNT(*) FILTER (WHERE status = 'approved') AS approved,
+				COALESCE(SUM(guest_count) FILTER (WHERE status = 'approved'), 0) AS guests,
+				COUNT(*) FILTER (WHERE status = 'pending') AS pending
+			FROM group_member GROUP BY group_id
+		) counts
+		WHERE counts.group_id = "group".id`).Error
+}
+
+// migrateReadReceipts converts the old per-message read_by arrays into read cursors, then drops the column.
+// Each reader's cursor starts at the newest message they had read in the group.
+func migrateReadReceipts(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Message{}, "read_by") {
+		return nil
+	}
+
+	log.Println("Migrating message read receipts to read cursors...")
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO message_read_cursor (group_id, username, last_read_message_id, updated_at)
+			SELECT m.group_id, reader.username, MAX(m.id), NOW()
+			FROM message m
+			CROSS JOIN LATERAL jsonb_array_elements_text(m.read_by) AS reader(username)
+			WHERE jsonb_typeof(m.read_by) = 'array'
+			GROUP BY m.group_id, reader.username
+			ON CONFLICT (group_id, username) DO NOTHING
+		`).Error; err != nil {
+			return fmt.Errorf("failed to backfill read cursors: %w", err)
+		}
+		return tx.Exec(`ALTER TABLE message DROP COLUMN read_by`).Error
+	})
+}
+
 // enableSearchExtensions enables PostgreSQL extensions for advanced search
 func enableSearchExtensions(db *gorm.DB) error {
 	extensions := []string{
@@ -170,6 +332,9 @@ func setupSearchIndexes(db *gorm.DB) error {
 				setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
 				setweight(to_tsvector('english', coalesce(NEW.activity_type, '')), 'A') ||
 				setweight(to_tsvector('english', coalesce(NEW.description, '')), 'B') ||
+				setweight(to_tsvector('english', coalesce((
+					SELECT string_agg(tag.name, ' ') FROM group_tag JOIN tag ON tag.id = group_tag.tag_id
+					WHERE group_tag.group_id = NEW.id), '')), 'B') ||
 				setweight(to_tsvector('english', coalesce(NEW.organiser_id, '')), 'D');
 			RETURN NEW;
 		END
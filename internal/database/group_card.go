@@ -0,0 +1,166 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"groops/internal/models"
+	"log"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// groupCardViewSQL defines the group_card materialized view behind GET /groups. It holds only groups
+// that are upcoming, active and public as of the last refresh; queries still filter on those columns.
+const groupCardViewSQL = `CREATE MATERIALIZED VIEW group_card AS
+	SELECT g.*,
+		COALESCE(CASE WHEN a.show_full_name THEN a.full_name END, '') AS organizer_full_name,
+		COALESCE(a.avatar_url, '') AS organizer_avatar_url,
+		COALESCE(a.rating, 0) AS organizer_rating,
+		COALESCE((SELECT string_agg(t.name, ',' ORDER BY t.name)
+			FROM group_tag gt JOIN tag t ON t.id = gt.tag_id WHERE gt.group_id = g.id), '') AS tag_names,
+		n.date_time AS next_occurrence_at
+	FROM "group" g
+	LEFT JOIN account a ON a.username = g.organiser_id
+	LEFT JOIN "group" n ON n.id = g.next_occurrence_id AND n.status = 'active' AND n.deleted_at IS NULL
+	WHERE g.date_time > NOW() AND g.status = 'active' AND g.visibility = 'public' AND g.deleted_at IS NULL`
+
+var (
+	groupCardsReady atomic.Bool
+	// groupCardRefreshesOwed is how many more refreshes the view needs to catch up with writes
+	groupCardRefreshesOwed atomic.Int32
+)
+
+// groupCardViewVersion fingerprints the view definition and the group and account columns it is
+// built from. It is stored as the view's comment so startups only rebuild the view after a change.
+func groupCardViewVersion(db *gorm.DB) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(groupCardViewSQL))
+	for _, model := range []interface{}{&models.Group{}, &models.Account{}} {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return "", fmt.Errorf("failed to parse %T: %w", model, err)
+		}
+		for _, name := range stmt.Schema.DBNames {
+			fmt.Fprintf(hash, "%s.%s %s;", stmt.Schema.Table, name, db.Migrator().FullDataTypeOf(stmt.Schema.FieldsByDBName[name]).SQL)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// groupCardViewCurrent reports whether the view exists and was built for the given version
+func groupCardViewCurrent(db *gorm.DB, version string) bool {
+	var comment *string
+	if err := db.Raw(`SELECT obj_description(to_regclass('group_card'), 'pg_class')`).Scan(&comment).Error; err != nil {
+		log.Printf("Warning: Failed to read group_card view version: %v", err)
+		return false
+	}
+	return comment != nil && *comment == version
+}
+
+// dropGroupCardView removes the view so migrations can alter the group columns it depends on
+func dropGroupCardView(db *gorm.DB) error {
+	groupCardsReady.Store(false)
+	return db.Exec(`DROP MATERIALIZED VIEW IF EXISTS group_card`).Error
+}
+
+// createGroupCardView builds the view (picking up any new group columns) and its unique index,
+// which concurrent refreshes need, and records the version it was built for
+func createGroupCardView(db *gorm.DB, version string) error {
+	if err := db.Exec(groupCardViewSQL).Error; err != nil {
+		return fmt.Errorf("failed to create group_card view: %w", err)
+	}
+	for _, statement := range []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_group_card_id ON group_card (id)`,
+		`CREATE INDEX IF NOT EXISTS idx_group_card_date_time ON group_card (date_time)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to index group_card view: %w", err)
+		}
+	}
+	// The version is a hex digest, so it is safe to inline
+	if err := db.Exec(fmt.Sprintf(`COMMENT ON MATERIALIZED VIEW group_card IS '%s'`, version)).Error; err != nil {
+		return fmt.Errorf("failed to record group_card view version: %w", err)
+	}
+	groupCardsReady.Store(true)
+	return nil
+}
+
+// GroupCardsReady reports whether the group_card view exists and can serve listings
+func GroupCardsReady() bool {
+	return groupCardsReady.Load()
+}
+
+// MarkGroupCardsStale asks for the group_card view to be refreshed soon
+func MarkGroupCardsStale() {
+	oweGroupCardRefreshes(1)
+}
+
+// MarkGroupCardsChanged asks for the group_card view to be refreshed after a write through db. A
+// refresh can run before a transaction's writes commit and miss them, so writes in a transaction
+// keep the view flagged through one further refresh.
+func MarkGroupCardsChanged(db *gorm.DB) {
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); inTransaction {
+		oweGroupCardRefreshes(2)
+		return
+	}
+	oweGroupCardRefreshes(1)
+}
+
+// oweGroupCardRefreshes raises the refreshes owed to at least n
+func oweGroupCardRefreshes(n int32) {
+	for {
+		owed := groupCardRefreshesOwed.Load()
+		if owed >= n || groupCardRefreshesOwed.CompareAndSwap(owed, n) {
+			return
+		}
+	}
+}
+
+// TakeGroupCardsStale reports whether a refresh is owed, counting off the one about to run
+func TakeGroupCardsStale() bool {
+	for {
+		owed := groupCardRefreshesOwed.Load()
+		if owed == 0 {
+			return false
+		}
+		if groupCardRefreshesOwed.CompareAndSwap(owed, owed-1) {
+			return true
+		}
+	}
+}
+
+// RefreshGroupCards rebuilds the group_card view without blocking readers
+func RefreshGroupCards(db *gorm.DB) error {
+	if !GroupCardsReady() {
+		return nil
+	}
+	return db.Exec(`REFRESH MATERIALIZED VIEW CONCURRENTLY group_card`).Error
+}
+
+// markGroupCardsStaleOnWrite flags the view for refresh after writes to the tables it is built from
+func markGroupCardsStaleOnWrite(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement == nil {
+		return
+	}
+	switch tx.Statement.Table {
+	case "group", "group_tag":
+		MarkGroupCardsChanged(tx)
+	}
+}
+
+// registerGroupCardInvalidation marks the view stale whenever a group or its tags change through GORM.
+// Raw statements don't pass through here; callers of those mark it stale themselves.
+func registerGroupCardInvalidation(db *gorm.DB) {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().After("gorm:create").Register("groops:group_card_create", markGroupCardsStaleOnWrite),
+		callback.Update().After("gorm:update").Register("groops:group_card_update", markGroupCardsStaleOnWrite),
+		callback.Delete().After("gorm:delete").Register("groops:group_card_delete", markGroupCardsStaleOnWrite),
+	} {
+		if err != nil {
+			log.Printf("Warning: Failed to register group_card invalidation: %v", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// queryCounterKey is the context key holding a request's query counter
+type queryCounterKey struct{}
+
+// WithQueryCounter returns a context that counts the queries run with it (see GetDBWithContext)
+func WithQueryCounter(ctx context.Context) (context.Context, *int64) {
+	counter := new(int64)
+	return context.WithValue(ctx, queryCounterKey{}, counter), counter
+}
+
+// GetDBWithContext returns the database instance bound to ctx, so cancellation and
+// per-request query counting apply to every query built from it
+func GetDBWithContext(ctx context.Context) *gorm.DB {
+	return DB.WithContext(ctx)
+}
+
+// countQuery bumps the counter of the statement's context, if it carries one
+func countQuery(tx *gorm.DB) {
+	if tx.Statement == nil || tx.Statement.Context == nil {
+		return
+	}
+	if counter, ok := tx.Statement.Context.Value(queryCounterKey{}).(*int64); ok {
+		atomic.AddInt64(counter, 1)
+	}
+}
+
+// registerQueryCounter counts every statement GORM runs against contexts from WithQueryCounter.
+// Preloads run as separate statements, so N+1 loading shows up as a high count.
+func registerQueryCounter(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Query().After("gorm:query").Register("groops:count_query", countQuery),
+		callback.Create().After("gorm:create").Register("groops:count_create", countQuery),
+		callback.Update().After("gorm:update").Register("groops:count_update", countQuery),
+		callback.Delete().After("gorm:delete").Register("groops:count_delete", countQuery),
+		callback.Row().After("gorm:row").Register("groops:count_row", countQuery),
+		callback.Raw().After("gorm:raw").Register("groops:count_raw", countQuery),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"groops/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// queryTimeoutKey is the statement setting holding the timeout started for a statement
+const queryTimeoutKey = "groops:query_timeout"
+
+// queryTimeout is a statement's timeout and the context it replaced
+type queryTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// startQueryTimeout bounds the statement's context by DB_QUERY_TIMEOUT. It runs after any
+// transaction has begun, so the timeout never cancels the transaction itself. The statement is
+// shared by every query a chain runs (e.g. Count then Find), so endQueryTimeout puts the caller's
+// context back for the next one.
+func startQueryTimeout(tx *gorm.DB) {
+	if tx.Statement == nil {
+		return
+	}
+	parent := tx.Statement.Context
+	base := parent
+	if base == nil {
+		base = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(base, utils.DBQueryTimeout())
+	tx.Statement.Context = ctx
+	tx.InstanceSet(queryTimeoutKey, queryTimeout{parent: parent, cancel: cancel})
+}
+
+// endQueryTimeout releases the timeout started for the statement and restores the caller's context
+func endQueryTimeout(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(queryTimeoutKey)
+	if !ok {
+		return
+	}
+	timeout := value.(queryTimeout)
+	timeout.cancel()
+	tx.Statement.Context = timeout.parent
+}
+
+// registerQueryTimeout puts a per-statement timeout on queries, creates, updates and deletes.
+// Row and Rows calls (and Scan, which uses them) are read after GORM returns, so they only
+// follow the caller's context.
+func registerQueryTimeout(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Query().Before("gorm:query").Register("groops:query_timeout", startQueryTimeout),
+		callback.Query().After("gorm:after_query").Register("groops:query_timeout_end", endQueryTimeout),
+		callback.Create().Before("gorm:create").Register("groops:create_timeout", startQueryTimeout),
+		callback.Create().After("gorm:after_create").Register("groops:create_timeout_end", endQueryTimeout),
+		callback.Update().Before("gorm:update").Register("groops:update_timeout", startQueryTimeout),
+		callback.Update().After("gorm:after_update").Register("groops:update_timeout_end", endQueryTimeout),
+		callback.Delete().Before("gorm:delete").Register("groops:delete_timeout", startQueryTimeout),
+		callback.Delete().After("gorm:after_delete").Register("groops:delete_timeout_end", endQueryTimeout),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,15 +1,17 @@
 package handlers
 
 import (
-	"io"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"groops/internal/auth"
 	"groops/internal/database"
 	"groops/internal/models"
 	"groops/internal/services"
+	"groops/internal/utils"
 
 	"log"
 
@@ -47,7 +49,7 @@ func GetMyProfile(c *gin.Context) {
 	}
 
 	// Get full account data
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var account models.Account
 	if err := db.Where("username = ?", username).First(&account).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -64,6 +66,14 @@ func GetMyProfile(c *gin.Context) {
 		return
 	}
 
+	// Participation streaks are a nice-to-have; the profile is still returned without them
+	var stats *services.ParticipationStats
+	if participation, err := services.NewStreakService().GetParticipationStats(account.Username); err != nil {
+		log.Printf("Warning: Failed to compute participation stats for %s: %v", account.Username, err)
+	} else {
+		stats = &participation
+	}
+
 	// Return user profile data
 	c.JSON(http.StatusOK, gin.H{
 		"authenticated": true,
@@ -80,6 +90,7 @@ func GetMyProfile(c *gin.Context) {
 		"lastLogin":     account.LastLogin,
 		"emailVerified": account.EmailVerified,
 		"locale":        account.Locale,
+		"stats":         stats,
 	})
 }
 
@@ -95,7 +106,7 @@ func GetAccount(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var account models.Account
 	if err := db.Preload("Activities").Preload("OwnedGroups").Preload("JoinedGroups").
 		Where("username = ?", username).First(&account).Error; err != nil {
@@ -150,9 +161,33 @@ func CreateProfile(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	if !req.AcceptedPolicies {
+		versions := services.CurrentPolicyVersions()
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":           "You must accept the terms of service and privacy policy",
+			"code":            services.ErrCodeConsentRequired,
+			"terms_version":   versions.Terms,
+			"privacy_version": versions.Privacy,
+		})
+		return
+	}
+
+	if isReservedUsername(req.Username) {
+		log.Printf("Error: Reserved username requested: %s", req.Username)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This username is reserved", "code": "USERNAME_RESERVED"})
+		return
+	}
+
+	if code, msg := services.NewNameValidationService().ValidateUsername(req.Username); code != "" {
+		log.Printf("Error: Username rejected: %s (%s)", req.Username, code)
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg, "code": code})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
 
-	// Check if username is already taken by someone else (case-insensitive)
+	// Check if username is already taken by someone else (case-insensitive).
+	// This is only a fast path; the unique LOWER(username) index decides concurrent claims.
 	var existingUsername models.Account
 	if err := db.Where("LOWER(username) = LOWER(?) AND google_id != ?", req.Username, sub).First(&existingUsername).Error; err == nil {
 		log.Printf("Error: Username already taken")
@@ -200,11 +235,26 @@ func CreateProfile(c *gin.Context) {
 			updates["full_name"] = name
 		}
 
-		if err := db.Model(&tempAccount).Updates(updates).Error; err != nil {
-			log.Printf("Error: Failed to update account: %v", err)
+		// Claim the username in a single conditional update: the unique index rejects a name
+		// taken concurrently, and matching the temp username stops a double submit from renaming twice
+		result := db.Model(&models.Account{}).
+			Where("google_id = ? AND username = ?", sub, oldUsername).
+			Updates(updates)
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			log.Printf("Error: Username already taken (concurrent claim): %s", req.Username)
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already taken"})
+			return
+		}
+		if result.Error != nil {
+			log.Printf("Error: Failed to update account: %v", result.Error)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account"})
 			return
 		}
+		if result.RowsAffected == 0 {
+			log.Printf("Error: Temporary account %s was already claimed", oldUsername)
+			c.JSON(http.StatusConflict, gin.H{"error": "Profile already exists for this user"})
+			return
+		}
 
 		// Get the final chosen name (either provided by user or Google default)
 		chosenName := req.FullName
@@ -262,6 +312,12 @@ func CreateProfile(c *gin.Context) {
 			log.Printf("Session %s updated with new username: %s and name: %s", sessionID, req.Username, chosenName)
 		}
 
+		// 6. Record policy consent given at sign-up
+		if _, err := services.NewConsentService().Accept(req.Username, utils.GetRealClientIP(c), c.Request.UserAgent()); err != nil {
+			log.Printf("Warning: Failed to record policy consent for %s: %v", req.Username, err)
+			// Non-fatal error - they'll be asked to accept again before making changes
+		}
+
 		// Retrieve the updated account
 		if err := db.Where("google_id = ?", sub).First(&tempAccount).Error; err != nil {
 			log.Printf("Error: Failed to retrieve updated account: %v", err)
@@ -270,7 +326,7 @@ func CreateProfile(c *gin.Context) {
 		}
 
 		// Send welcome email to the user
-		emailSvc := services.NewEmailService()
+		emailSvc := services.NewEmailService().WithContext(c.Request.Context())
 		if err := emailSvc.SendWelcomeEmail(email, chosenName); err != nil {
 			log.Printf("Warning: Failed to send welcome email: %v", err)
 			// Non-fatal error - continue with the response
@@ -292,7 +348,36 @@ func CreateProfile(c *gin.Context) {
 	c.JSON(http.StatusBadRequest, gin.H{"error": "No temporary account found. Please try logging in again."})
 }
 
-// UpdateAccount allows a user to update their profile (bio, avatar_url)
+// reservedUsernames can't be claimed because they collide with routes or could pass for staff
+var reservedUsernames = map[string]bool{
+	"admin":         true,
+	"administrator": true,
+	"api":           true,
+	"auth":          true,
+	"dashboard":     true,
+	"groops":        true,
+	"groups":        true,
+	"help":          true,
+	"login":         true,
+	"logout":        true,
+	"me":            true,
+	"moderator":     true,
+	"null":          true,
+	"profile":       true,
+	"profiles":      true,
+	"root":          true,
+	"staff":         true,
+	"support":       true,
+	"system":        true,
+	"undefined":     true,
+}
+
+// isReservedUsername reports whether the username is reserved (case-insensitive)
+func isReservedUsername(username string) bool {
+	return reservedUsernames[strings.ToLower(username)]
+}
+
+// UpdateAccount allows a user to update their profile (bio, avatar_url, date of birth, gender)
 func UpdateAccount(c *gin.Context) {
 	username := c.GetString("username")
 
@@ -310,7 +395,7 @@ func UpdateAccount(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var account models.Account
 	if err := db.Where("username = ?", username).First(&account).Error; err != nil {
 		log.Printf("Error: Account not found: %v", err)
@@ -326,6 +411,20 @@ func UpdateAccount(c *gin.Context) {
 	if req.AvatarURL != "" {
 		updates["avatar_url"] = req.AvatarURL
 	}
+	if req.ShowFullName != nil {
+		updates["show_full_name"] = *req.ShowFullName
+	}
+	if req.DateOfBirth != nil {
+		if req.DateOfBirth.After(time.Now()) || req.DateOfBirth.Before(time.Now().AddDate(-120, 0, 0)) {
+			log.Printf("Error: Invalid date of birth for %s", username)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Date of birth must be a real date in the past"})
+			return
+		}
+		updates["date_of_birth"] = *req.DateOfBirth
+	}
+	if req.Gender != nil {
+		updates["gender"] = *req.Gender
+	}
 	if len(updates) == 0 {
 		log.Printf("Error: No fields to update")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
@@ -341,25 +440,89 @@ func UpdateAccount(c *gin.Context) {
 	c.JSON(http.StatusOK, account)
 }
 
-// GetAccountEventHistory returns a user's event/activity history
+// publicActivityEventTypes are the history entries visible to users other than the account owner
+var publicActivityEventTypes = []string{"create_group", "join_group_approved"}
+
+// activityHistoryEntry is an activity log entry enriched with the group's name
+type activityHistoryEntry struct {
+	ID        uint      `json:"id"`
+	EventType string    `json:"event_type"`
+	GroupID   string    `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// GetAccountEventHistory returns a user's event/activity history.
+// The owner sees their full history; other users only see public events like groups organized or joined.
 func GetAccountEventHistory(c *gin.Context) {
 	username := c.Param("username")
-	db := database.GetDB()
+	requester := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	query := db.Model(&models.ActivityLog{}).
+		Select(`activity_log.id, activity_log.event_type, activity_log.group_id, COALESCE("group".name, '') AS group_name, activity_log.timestamp`).
+		Joins(`LEFT JOIN "group" ON "group".id = activity_log.group_id`).
+		Where("activity_log.username = ?", username)
+
+	// Others only see groups anyone can find, so unlisted, private and deleted ones stay hidden
+	if requester != username {
+		query = query.Where("activity_log.event_type IN ?", publicActivityEventTypes).
+			Where(`"group".visibility = ? AND "group".deleted_at IS NULL`, models.VisibilityPublic)
+	}
+
+	// Optional comma-separated event type filter, e.g. ?type=create_group,leave_group
+	if typeParam := c.Query("type"); typeParam != "" {
+		var eventTypes []string
+		for _, t := range strings.Split(typeParam, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				eventTypes = append(eventTypes, t)
+			}
+		}
+		if len(eventTypes) > 0 {
+			query = query.Where("activity_log.event_type IN ?", eventTypes)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Printf("Error: Failed to count event history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
+		return
+	}
+
+	// Handle pagination with offset and limit (like notifications endpoint)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // max limit
+	}
 
-	var activities []models.ActivityLog
-	if err := db.Where("username = ?", username).Order("timestamp DESC").Find(&activities).Error; err != nil {
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	activities := []activityHistoryEntry{}
+	if err := query.Order("activity_log.timestamp DESC").Limit(limit).Offset(offset).Scan(&activities).Error; err != nil {
 		log.Printf("Error: Failed to fetch event history: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
 		return
 	}
 
-	c.JSON(http.StatusOK, activities)
+	c.JSON(http.StatusOK, gin.H{
+		"events": activities,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
 }
 
 // ListNotifications returns recent notifications for the logged-in user
 func ListNotifications(c *gin.Context) {
 	username := c.GetString("username")
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	var notifications []models.Notification
 	query := db.Where("recipient_username = ?", username).Order("created_at DESC")
@@ -406,7 +569,7 @@ func ListNotifications(c *gin.Context) {
 // GetUnreadNotificationCount returns the unread notification count for the logged-in user
 func GetUnreadNotificationCount(c *gin.Context) {
 	username := c.GetString("username")
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	var count int64
 	if err := db.Model(&models.Notification{}).Where("recipient_username = ? AND read = ?", username, false).Count(&count).Error; err != nil {
@@ -423,7 +586,7 @@ func GetUnreadNotificationCount(c *gin.Context) {
 func GetPublicProfile(c *gin.Context) {
 	username := c.Param("username")
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var account models.Account
 	// Use case-insensitive username lookup to prevent duplicate usernames with different cases
 	if err := db.Where("LOWER(username) = LOWER(?)", username).First(&account).Error; err != nil {
@@ -440,21 +603,38 @@ func GetPublicProfile(c *gin.Context) {
 	// Return only safe, public information
 	publicProfile := gin.H{
 		"username":    account.Username,
-		"full_name":   account.FullName,
 		"avatar_url":  account.AvatarURL,
 		"bio":         account.Bio,
 		"rating":      account.Rating,
 		"date_joined": account.DateJoined,
 	}
+	if account.ShowFullName {
+		publicProfile["full_name"] = account.FullName
+	}
+
+	// Check-ins and no-shows from events where the organizer took attendance
+	if reliability, err := services.NewAttendanceService().GetReliability(account.Username); err != nil {
+		log.Printf("Warning: Failed to compute reliability for %s: %v", account.Username, err)
+	} else {
+		publicProfile["reliability"] = reliability
+	}
 
 	c.JSON(http.StatusOK, publicProfile)
 }
 
-// GetProfileImage proxies profile images to avoid CORS issues
+// maxProfileImageBytes bounds the size of a proxied profile image
+const maxProfileImageBytes = 5 << 20 // 5MB
+
+// profileImageContentTypes are the raster types a proxied profile image may have. SVG is left out
+// since it can carry script, which would run on the API's origin.
+var profileImageContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// GetProfileImage proxies profile images to avoid CORS issues.
+// The avatar URL is fetched with the SSRF-safe client, so it can't be pointed at internal services.
 func GetProfileImage(c *gin.Context) {
 	username := c.Param("username")
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var account models.Account
 	if err := db.Where("username = ?", username).First(&account).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
@@ -467,28 +647,33 @@ func GetProfileImage(c *gin.Context) {
 	}
 
 	// Fetch the image from the external URL
-	resp, err := http.Get(account.AvatarURL)
+	image, err := utils.SafeFetch(account.AvatarURL, utils.FetchOptions{
+		Timeout:             10 * time.Second,
+		MaxBytes:            maxProfileImageBytes,
+		AllowedContentTypes: profileImageContentTypes,
+	})
 	if err != nil {
-		log.Printf("Error fetching image: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch image"})
+		log.Printf("Error fetching image for %s: %v", username, err)
+		switch {
+		case errors.Is(err, utils.ErrBlockedAddress), errors.Is(err, utils.ErrContentTypeNotAllowed):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Avatar URL is not a valid image"})
+		case errors.Is(err, utils.ErrResponseTooLarge):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Avatar image is too large"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch image"})
+		}
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if image.StatusCode != http.StatusOK {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
 		return
 	}
 
 	// Set appropriate headers
-	c.Header("Content-Type", resp.Header.Get("Content-Type"))
 	c.Header("Cache-Control", "public, max-age=3600") // Cache for 1 hour
-
-	// Copy the image data to the response
-	_, err = io.Copy(c.Writer, resp.Body)
-	if err != nil {
-		log.Printf("Error copying image data: %v", err)
-	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, image.ContentType, image.Body)
 }
 
 // UploadAvatar handles avatar image uploads
@@ -503,7 +688,7 @@ func UploadAvatar(c *gin.Context) {
 	}
 
 	// Get the account
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var account models.Account
 	if err := db.Where("username = ?", username).First(&account).Error; err != nil {
 		log.Printf("Error: Account not found: %v", err)
@@ -544,7 +729,11 @@ func UploadAvatar(c *gin.Context) {
 	}
 
 	// Upload to Cloudinary
-	avatarURL, err := imageService.UploadAvatar(file, header.Filename, account.Username)
+	avatarURL, err := imageService.UploadAvatar(c.Request.Context(), file, header.Filename, account.Username)
+	if errors.Is(err, utils.ErrCircuitOpen) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Image uploads are temporarily unavailable, please try again shortly"})
+		return
+	}
 	if err != nil {
 		log.Printf("Error: Failed to upload avatar: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
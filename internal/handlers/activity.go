@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activityTypeEntry is an activity type's display metadata with how many upcoming groups use it
+type activityTypeEntry struct {
+	models.ActivityDisplay
+	UpcomingGroups int64 `json:"upcoming_groups"`
+}
+
+// GetActivityTypes returns the activity taxonomy with display metadata (icon, color, cover image).
+// Known types are always listed; free-form types used by upcoming groups are included too.
+func GetActivityTypes(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var counts []struct {
+		ActivityType string
+		GroupCount   int64
+	}
+	if err := db.Model(&models.Group{}).
+		Select("LOWER(TRIM(activity_type)) AS activity_type, COUNT(*) AS group_count").
+		Where("date_time > NOW() AND status = ? AND visibility = ?", models.GroupStatusActive, models.VisibilityPublic).
+		Group("LOWER(TRIM(activity_type))").
+		Scan(&counts).Error; err != nil {
+		log.Printf("Error: Failed to count activity types: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity types"})
+		return
+	}
+
+	entries := make(map[string]*activityTypeEntry)
+	for _, display := range services.ActivityTaxonomy() {
+		entries[display.ActivityType] = &activityTypeEntry{ActivityDisplay: display}
+	}
+	for _, count := range counts {
+		if count.ActivityType == "" {
+			continue
+		}
+		// Aliases (e.g. "soccer") are counted under the type they display as
+		display := services.ActivityDisplayFor(count.ActivityType)
+		entry, ok := entries[display.ActivityType]
+		if !ok {
+			entry = &activityTypeEntry{ActivityDisplay: display}
+			entries[display.ActivityType] = entry
+		}
+		entry.UpcomingGroups += count.GroupCount
+	}
+
+	activityTypes := make([]activityTypeEntry, 0, len(entries))
+	for _, entry := range entries {
+		activityTypes = append(activityTypes, *entry)
+	}
+	sort.Slice(activityTypes, func(i, j int) bool {
+		if activityTypes[i].UpcomingGroups != activityTypes[j].UpcomingGroups {
+			return activityTypes[i].UpcomingGroups > activityTypes[j].UpcomingGroups
+		}
+		return activityTypes[i].ActivityType < activityTypes[j].ActivityType
+	})
+
+	c.JSON(http.StatusOK, gin.H{"activity_types": activityTypes})
+}
+
+// trendingActivity is an activity type with how many groups were created for it in the
+// current and previous windows
+type trendingActivity struct {
+	models.ActivityDisplay
+	RecentGroups   int64   `json:"recent_groups"`
+	PreviousGroups int64   `json:"previous_groups"`
+	Growth         float64 `json:"growth"`       // Relative change from the previous window (1.0 = doubled)
+	RecentViews    int64   `json:"recent_views"` // Views of the type's groups over the current window
+}
+
+// trendingWindow is the period trending activities are measured over, compared with the one before it
+const trendingWindow = 30 * 24 * time.Hour
+
+// GetTrendingActivityTypes returns the activity types growing fastest over the last 30 days,
+// by groups created compared with the 30 days before, optionally within a city (?city=). Types growing
+// equally are ranked by how often their groups were viewed, then by volume.
+// Types need TRENDING_MIN_GROUPS recent groups to be listed so one-off groups don't top the chart.
+func GetTrendingActivityTypes(c *gin.Context) {
+	limit := 10
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 20 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 20"})
+			return
+		}
+		limit = parsed
+	}
+
+	now := time.Now()
+	recentStart, previousStart := now.Add(-trendingWindow), now.Add(-2*trendingWindow)
+
+	db := database.GetDBWithContext(c.Request.Context())
+	query := db.Model(&models.Group{}).
+		Select("LOWER(TRIM(activity_type)) AS activity_type, "+
+			"COUNT(*) FILTER (WHERE created_at > ?) AS recent_groups, "+
+			"COUNT(*) FILTER (WHERE created_at <= ?) AS previous_groups", recentStart, recentStart).
+		Where("created_at > ?", previousStart).
+		// Only groups anyone can see, that went ahead or still will, and are out of early access
+		Where(`"group".status IN ? AND "group".visibility = ? AND "group".deleted_at IS NULL`, models.GroupStatusesHeld, models.VisibilityPublic).
+		Where(`"group".public_at IS NULL OR "group".public_at <= NOW()`)
+	city := c.Query("city")
+	if city != "" {
+		query = query.Where("LOWER(city) = LOWER(?)", city)
+	}
+
+	var counts []struct {
+		ActivityType   string
+		RecentGroups   int64
+		PreviousGroups int64
+	}
+	if err := query.Group("LOWER(TRIM(activity_type))").Scan(&counts).Error; err != nil {
+		log.Printf("Error: Failed to count trending activity types: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trending activity types"})
+		return
+	}
+
+	views, err := services.CountRecentViewsByActivity(db, recentStart, city)
+	if err != nil {
+		log.Printf("Warning: Failed to count activity type views: %v", err)
+	}
+
+	// Aliases (e.g. "soccer") are counted under the type they display as
+	entries := make(map[string]*trendingActivity)
+	for _, count := range counts {
+		if count.ActivityType == "" {
+			continue
+		}
+		display := services.ActivityDisplayFor(count.ActivityType)
+		entry, ok := entries[display.ActivityType]
+		if !ok {
+			entry = &trendingActivity{ActivityDisplay: display}
+			entries[display.ActivityType] = entry
+		}
+		entry.RecentGroups += count.RecentGroups
+		entry.PreviousGroups += count.PreviousGroups
+		entry.RecentViews += views[count.ActivityType]
+	}
+
+	minGroups := int64(utils.GetEnvInt("TRENDING_MIN_GROUPS", 2))
+	trending := []trendingActivity{}
+	for _, entry := range entries {
+		if entry.RecentGroups < minGroups || entry.RecentGroups <= entry.PreviousGroups {
+			continue
+		}
+		// Types new this window count as growing from one group, so they rank by volume
+		entry.Growth = float64(entry.RecentGroups-entry.PreviousGroups) / math.Max(float64(entry.PreviousGroups), 1)
+		trending = append(trending, *entry)
+	}
+	sort.Slice(trending, func(i, j int) bool {
+		if trending[i].Growth != trending[j].Growth {
+			return trending[i].Growth > trending[j].Growth
+		}
+		if trending[i].RecentViews != trending[j].RecentViews {
+			return trending[i].RecentViews > trending[j].RecentViews
+		}
+		if trending[i].RecentGroups != trending[j].RecentGroups {
+			return trending[i].RecentGroups > trending[j].RecentGroups
+		}
+		return trending[i].ActivityType < trending[j].ActivityType
+	})
+	if len(trending) > limit {
+		trending = trending[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"city":           city,
+		"window_days":    int(trendingWindow.Hours() / 24),
+		"activity_types": trending,
+	})
+}
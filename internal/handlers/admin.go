@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MergeAccounts folds a duplicate account (e.g. one created with a second Google account) into the
+// account the user keeps, reassigning their groups, memberships, messages and notifications (admin only).
+// With dry_run it only reports what would move.
+func MergeAccounts(c *gin.Context) {
+	admin := c.GetString("username")
+
+	var request models.MergeAccountsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid merge input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	merger := services.NewAccountMergeService()
+	var plan services.MergePlan
+	var err error
+	if request.DryRun {
+		plan, err = merger.Plan(request.SourceUsername, request.TargetUsername)
+	} else {
+		plan, err = merger.Merge(request.SourceUsername, request.TargetUsername, admin)
+	}
+	switch {
+	case errors.Is(err, services.ErrMergeAccountMissing):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrMergeSameAccount), errors.Is(err, services.ErrMergeTemporary):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrMergeAlreadyMerged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error: Failed to merge %s into %s: %v", request.SourceUsername, request.TargetUsername, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge accounts"})
+		return
+	}
+
+	if !request.DryRun {
+		log.Printf("Admin %s merged account %s into %s", admin, request.SourceUsername, request.TargetUsername)
+		msg := fmt.Sprintf("Your account %s has been merged into this one. Its groups, messages and notifications are now here.", request.SourceUsername)
+		if err := createNotification(database.GetDBWithContext(c.Request.Context()), request.TargetUsername, "account_merged", msg, ""); err != nil {
+			log.Printf("Warning: Failed to create merge notification: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dry_run": request.DryRun, "plan": plan})
+}
+
+// ListDeletedGroups lists deleted groups that can still be restored, most recently deleted first (admin only)
+func ListDeletedGroups(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // max limit
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	groups, total, err := services.NewSoftDeleteService().ListDeletedGroups(limit, offset)
+	if err != nil {
+		log.Printf("Error: Failed to fetch deleted groups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted groups"})
+		return
+	}
+
+	results := make([]gin.H, len(groups))
+	for i, group := range groups {
+		results[i] = gin.H{"group": group, "deleted_at": group.DeletedAt.Time}
+	}
+	c.JSON(http.StatusOK, gin.H{"groups": results, "total": total, "limit": limit, "offset": offset})
+}
+
+// RestoreGroup undeletes a deleted group with its members and chat (admin only)
+func RestoreGroup(c *gin.Context) {
+	admin := c.GetString("username")
+	groupID := c.Param("group_id")
+
+	group, err := services.NewSoftDeleteService().RestoreGroup(groupID)
+	if errors.Is(err, services.ErrNotDeleted) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted group not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error: Failed to restore group %s: %v", groupID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore group"})
+		return
+	}
+
+	log.Printf("Admin %s restored group %s", admin, groupID)
+	msg := fmt.Sprintf("Your groop '%s' has been restored.", group.Name)
+	if err := createNotification(database.GetDBWithContext(c.Request.Context()), group.OrganiserID, "group_restored", msg, group.ID); err != nil {
+		log.Printf("Warning: Failed to create restore notification: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group restored", "group_id": groupID})
+}
+
+// RestoreAccount undeletes a deleted account so its owner can sign in again (admin only)
+func RestoreAccount(c *gin.Context) {
+	admin := c.GetString("username")
+	username := c.Param("username")
+
+	if _, err := services.NewSoftDeleteService().RestoreAccount(username); errors.Is(err, services.ErrNotDeleted) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted account not found"})
+		return
+	} else if err != nil {
+		log.Printf("Error: Failed to restore account %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore account"})
+		return
+	}
+
+	log.Printf("Admin %s restored account %s", admin, username)
+	c.JSON(http.StatusOK, gin.H{"message": "Account restored", "username": username})
+}
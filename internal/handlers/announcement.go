@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// loadAnnouncementGroup fetches the group for an announcement request, writing the error
+// response and returning false if it doesn't exist or the requester may not act on it
+func loadAnnouncementGroup(c *gin.Context, db *gorm.DB, organizerOnly bool) (models.Group, bool) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	if organizerOnly && !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: User %s is not the organizer of group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can manage announcements"})
+		return group, false
+	}
+	if !organizerOnly && !policy.IsMember(groupUser(db, group, requester), group) {
+		log.Printf("Error: User %s not authorized to view announcements for group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only group members can view announcements"})
+		return group, false
+	}
+
+	return group, true
+}
+
+// loadAnnouncement fetches an announcement of the group by the :announcement_id parameter
+func loadAnnouncement(c *gin.Context, db *gorm.DB, groupID string) (models.Announcement, bool) {
+	var announcement models.Announcement
+	announcementID, err := strconv.ParseUint(c.Param("announcement_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return announcement, false
+	}
+
+	if err := db.Where("id = ? AND group_id = ?", announcementID, groupID).First(&announcement).Error; err != nil {
+		log.Printf("Error: Announcement %d not found in group %s: %v", announcementID, groupID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+		return announcement, false
+	}
+	return announcement, true
+}
+
+// ListAnnouncements returns a group's announcements, pinned first then newest first (members only)
+func ListAnnouncements(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadAnnouncementGroup(c, db, false)
+	if !ok {
+		return
+	}
+
+	announcements := []models.Announcement{}
+	if err := db.Where("group_id = ?", group.ID).
+		Order("pinned DESC, created_at DESC").
+		Find(&announcements).Error; err != nil {
+		log.Printf("Error: Failed to fetch announcements for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"announcements": announcements})
+}
+
+// CreateAnnouncement posts an announcement and notifies and emails every approved member (organizer only)
+func CreateAnnouncement(c *gin.Context) {
+	var request models.CreateAnnouncementRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid announcement input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	title, body := strings.TrimSpace(request.Title), strings.TrimSpace(request.Body)
+	if title == "" || body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Announcements need a title and a body"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadAnnouncementGroup(c, db, true)
+	if !ok {
+		return
+	}
+
+	announcement := models.Announcement{
+		GroupID: group.ID,
+		Author:  group.OrganiserID,
+		Title:   title,
+		Body:    body,
+		Pinned:  request.Pinned,
+	}
+	// Members' notifications and emails are saved with the announcement and sent once it commits
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&announcement).Error; err != nil {
+			return err
+		}
+		return queueAnnouncementNotices(tx, group, announcement)
+	}); err != nil {
+		log.Printf("Error: Failed to create announcement: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create announcement"})
+		return
+	}
+
+	if err := LogActivity(group.OrganiserID, "post_announcement", group.ID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, announcement)
+}
+
+// queueAnnouncementNotices notifies and emails an announcement to the group's approved members,
+// except the organizer, through tx
+func queueAnnouncementNotices(tx *gorm.DB, group models.Group, announcement models.Announcement) error {
+	var usernames []string
+	if err := tx.Model(&models.GroupMember{}).
+		Where("group_id = ? AND status = ? AND username != ?", group.ID, "approved", group.OrganiserID).
+		Pluck("username", &usernames).Error; err != nil {
+		return err
+	}
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("New announcement in '%s': %s", group.Name, announcement.Title)
+	targetID := strconv.FormatUint(uint64(announcement.ID), 10)
+	for _, username := range usernames {
+		if err := createTargetedNotification(tx, username, "announcement", msg, group.ID, targetID); err != nil {
+			return err
+		}
+	}
+
+	var accounts []models.Account
+	if err := tx.Where("username IN ?", usernames).Find(&accounts).Error; err != nil {
+		return err
+	}
+	emailService := services.NewEmailService().WithOutbox(tx)
+	for _, account := range accounts {
+		if err := emailService.SendAnnouncementEmail(account.Email, account.Username, group.Name, announcement.Title, announcement.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateAnnouncement edits or (un)pins an announcement without notifying members again (organizer only)
+func UpdateAnnouncement(c *gin.Context) {
+	var request models.UpdateAnnouncementRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid announcement input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadAnnouncementGroup(c, db, true)
+	if !ok {
+		return
+	}
+	announcement, ok := loadAnnouncement(c, db, group.ID)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if request.Title != nil {
+		title := strings.TrimSpace(*request.Title)
+		if title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+			return
+		}
+		updates["title"] = title
+	}
+	if request.Body != nil {
+		body := strings.TrimSpace(*request.Body)
+		if body == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Body cannot be empty"})
+			return
+		}
+		updates["body"] = body
+	}
+	if request.Title != nil || request.Body != nil {
+		updates["edited_at"] = time.Now()
+	}
+	if request.Pinned != nil {
+		updates["pinned"] = *request.Pinned
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, announcement)
+		return
+	}
+
+	if err := db.Model(&announcement).Updates(updates).Error; err != nil {
+		log.Printf("Error: Failed to update announcement %d: %v", announcement.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update announcement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, announcement)
+}
+
+// DeleteAnnouncement removes an announcement (organizer only)
+func DeleteAnnouncement(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadAnnouncementGroup(c, db, true)
+	if !ok {
+		return
+	}
+	announcement, ok := loadAnnouncement(c, db, group.ID)
+	if !ok {
+		return
+	}
+
+	if err := db.Delete(&announcement).Error; err != nil {
+		log.Printf("Error: Failed to delete announcement %d: %v", announcement.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete announcement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Announcement deleted"})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAPIKeys returns the authenticated user's public API keys with today's usage
+func ListAPIKeys(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var keys []models.APIKey
+	if err := db.Where("username = ? AND revoked_at IS NULL", username).Order("created_at ASC").Find(&keys).Error; err != nil {
+		log.Printf("Error: Failed to fetch API keys for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	keyService := services.NewAPIKeyService()
+	response := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		usedToday := 0
+		if usage, err := keyService.UsageSince(key.ID, time.Now()); err != nil {
+			log.Printf("Warning: Failed to fetch usage for API key %d: %v", key.ID, err)
+		} else if len(usage) > 0 {
+			usedToday = usage[0].Requests
+		}
+		response = append(response, gin.H{
+			"key":        key,
+			"used_today": usedToday,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": response, "quota_resets_at": services.QuotaResetAt()})
+}
+
+// CreateAPIKey issues a new public API key. The raw key is only ever returned in this response.
+func CreateAPIKey(c *gin.Context) {
+	username := c.GetString("username")
+
+	var request models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid API key input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var active int64
+	if err := db.Model(&models.APIKey{}).Where("username = ? AND revoked_at IS NULL", username).Count(&active).Error; err != nil {
+		log.Printf("Error: Failed to count API keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	if limit := services.MaxAPIKeysPerUser(); int(active) >= limit {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can have at most %d active API keys", limit)})
+		return
+	}
+
+	rawKey, err := services.GenerateAPIKey()
+	if err != nil {
+		log.Printf("Error: Failed to generate API key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	key := models.APIKey{
+		Username:   username,
+		Name:       request.Name,
+		KeyPrefix:  rawKey[:11],
+		KeyHash:    services.HashAPIKey(rawKey),
+		DailyQuota: services.DefaultAPIKeyDailyQuota(),
+	}
+	if err := db.Create(&key).Error; err != nil {
+		log.Printf("Error: Failed to create API key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"key":     key,
+		"api_key": rawKey,
+		"message": "Store this key now - it won't be shown again",
+	})
+}
+
+// loadOwnAPIKey fetches one of the authenticated user's keys, writing a 404 if it isn't theirs
+func loadOwnAPIKey(c *gin.Context) (models.APIKey, bool) {
+	var key models.APIKey
+
+	keyID, err := strconv.ParseUint(c.Param("key_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return key, false
+	}
+
+	if err := database.GetDBWithContext(c.Request.Context()).Where("id = ? AND username = ?", keyID, c.GetString("username")).First(&key).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return key, false
+	}
+	return key, true
+}
+
+// RevokeAPIKey permanently disables one of the authenticated user's keys
+func RevokeAPIKey(c *gin.Context) {
+	key, ok := loadOwnAPIKey(c)
+	if !ok {
+		return
+	}
+	if key.RevokedAt != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "API key already revoked"})
+		return
+	}
+
+	if err := database.GetDBWithContext(c.Request.Context()).Model(&key).Update("revoked_at", time.Now()).Error; err != nil {
+		log.Printf("Error: Failed to revoke API key %d: %v", key.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// GetAPIKeyUsage reports a key's daily request counts (?days=, default 30, max 90)
+func GetAPIKeyUsage(c *gin.Context) {
+	key, ok := loadOwnAPIKey(c)
+	if !ok {
+		return
+	}
+
+	days := 30
+	if daysStr := c.Query("days"); daysStr != "" {
+		if parsed, err := strconv.Atoi(daysStr); err == nil && parsed > 0 && parsed <= 90 {
+			days = parsed
+		}
+	}
+
+	usage, err := services.NewAPIKeyService().UsageSince(key.ID, time.Now().AddDate(0, 0, -(days-1)))
+	if err != nil {
+		log.Printf("Error: Failed to fetch usage for API key %d: %v", key.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API key usage"})
+		return
+	}
+
+	total := 0
+	for _, day := range usage {
+		total += day.Requests
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"key_id":      key.ID,
+		"daily_quota": key.DailyQuota,
+		"days":        days,
+		"usage":       usage,
+		"total":       total,
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxBroadcastsPerDay limits how many broadcasts an organizer can send to a group in 24 hours
+func maxBroadcastsPerDay() int {
+	return utils.GetEnvInt("MAX_BROADCASTS_PER_DAY", 5)
+}
+
+// BroadcastToGroup sends an immediate in-app and email message to all approved members (organizer
+// only). Push delivery waits on a push notification service; the response lists the channels used.
+func BroadcastToGroup(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var request models.BroadcastRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid broadcast input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	message := strings.TrimSpace(request.Message)
+	if message == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message cannot be empty"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	// Check if group exists
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	// Check if requester is the organizer
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can broadcast to the group")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can broadcast to the group"})
+		return
+	}
+
+	// Enforce the daily broadcast limit
+	var sentToday int64
+	if err := db.Model(&models.GroupBroadcast{}).
+		Where("group_id = ? AND created_at > ?", groupID, time.Now().Add(-24*time.Hour)).
+		Count(&sentToday).Error; err != nil {
+		log.Printf("Error: Failed to count broadcasts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send broadcast"})
+		return
+	}
+	if limit := maxBroadcastsPerDay(); int(sentToday) >= limit {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": fmt.Sprintf("You can send at most %d broadcasts per day to a group", limit),
+			"code":  "BROADCAST_LIMIT",
+		})
+		return
+	}
+
+	var usernames []string
+	if err := db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND status = ? AND username != ?", groupID, "approved", requester).
+		Pluck("username", &usernames).Error; err != nil {
+		log.Printf("Error: Failed to fetch members for broadcast: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send broadcast"})
+		return
+	}
+
+	broadcast := models.GroupBroadcast{
+		GroupID:    groupID,
+		SentBy:     requester,
+		Message:    message,
+		Recipients: len(usernames),
+	}
+	// Members' notifications and emails are saved with the broadcast; the outbox sends the emails
+	// once it commits, so the organizer isn't kept waiting
+	msg := fmt.Sprintf("Update from the organizer of '%s': %s", group.Name, message)
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&broadcast).Error; err != nil {
+			return err
+		}
+		for _, username := range usernames {
+			if err := createNotification(tx, username, "group_broadcast", msg, groupID); err != nil {
+				return err
+			}
+		}
+		if len(usernames) == 0 {
+			return nil
+		}
+
+		var accounts []models.Account
+		if err := tx.Where("username IN ?", usernames).Find(&accounts).Error; err != nil {
+			return err
+		}
+		emailService := services.NewEmailService().WithOutbox(tx)
+		for _, account := range accounts {
+			if err := emailService.SendBroadcastEmail(account.Email, account.Username, group.Name, message); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Printf("Error: Failed to save broadcast: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send broadcast"})
+		return
+	}
+
+	if err := LogActivity(requester, "broadcast", groupID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+	mirrorToIntegrations(groupID, services.IntegrationEventChatHighlight, msg)
+
+	broadcast.Channels = []string{models.BroadcastChannelInApp, models.BroadcastChannelEmail}
+	c.JSON(http.StatusCreated, broadcast)
+}
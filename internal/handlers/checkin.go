@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// enforceCheckInWindow rejects check-ins for cancelled groups or outside the check-in window,
+// writing the error response and returning false
+func enforceCheckInWindow(c *gin.Context, group models.Group, attendance *services.AttendanceService) bool {
+	if group.IsCancelled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This group has been cancelled"})
+		return false
+	}
+
+	if !attendance.CheckInOpen(group, time.Now()) {
+		closesAt := attendance.CheckInClosesAt(group)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Check-in opens when the event starts and closes " + formatDuration(closesAt.Sub(group.EndsAt())) + " after it ends",
+			"code":      "CHECKIN_CLOSED",
+			"opens_at":  group.DateTime,
+			"closes_at": closesAt,
+		})
+		return false
+	}
+
+	return true
+}
+
+// checkInMember records a member's attendance; checking in twice keeps the first time
+func checkInMember(db *gorm.DB, member models.GroupMember, checkedInBy string) (models.GroupMember, error) {
+	if member.CheckedInAt != nil {
+		return member, nil
+	}
+	now := time.Now()
+	if err := db.Model(&member).Updates(map[string]interface{}{
+		"checked_in_at": now,
+		"checked_in_by": checkedInBy,
+		"no_show":       false,
+	}).Error; err != nil {
+		return member, err
+	}
+	member.CheckedInAt = &now
+	member.CheckedInBy = checkedInBy
+	member.NoShow = false
+
+	if err := LogActivity(member.Username, "check_in", member.GroupID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+	return member, nil
+}
+
+// GetCheckInCode returns the code members enter to check themselves in, creating it on first use
+// (organizer or co-organizer)
+func GetCheckInCode(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "manage check-in")
+	if !ok {
+		return
+	}
+
+	if group.CheckInCode == "" {
+		code, err := services.GenerateCheckInCode()
+		if err != nil {
+			log.Printf("Error: Failed to generate check-in code: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create check-in code"})
+			return
+		}
+		if err := db.Model(&group).Update("check_in_code", code).Error; err != nil {
+			log.Printf("Error: Failed to save check-in code for group %s: %v", group.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create check-in code"})
+			return
+		}
+		group.CheckInCode = code
+	}
+
+	attendance := services.NewAttendanceService()
+	c.JSON(http.StatusOK, gin.H{
+		"code":      group.CheckInCode,
+		"opens_at":  group.DateTime,
+		"closes_at": attendance.CheckInClosesAt(group),
+	})
+}
+
+// SelfCheckIn lets an approved member check in with the code shared at the venue
+func SelfCheckIn(c *gin.Context) {
+	username := c.GetString("username")
+
+	var request models.SelfCheckInRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid check-in input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var group models.Group
+	if err := db.Where("id = ?", c.Param("group_id")).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	if !enforceCheckInWindow(c, group, services.NewAttendanceService()) {
+		return
+	}
+
+	if group.CheckInCode == "" || subtle.ConstantTimeCompare([]byte(group.CheckInCode), []byte(request.Code)) != 1 {
+		log.Printf("Error: Wrong check-in code from %s for group %s", username, group.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "That check-in code isn't right - ask the organizer for the code"})
+		return
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?", group.ID, username, "approved").First(&member).Error; err != nil {
+		log.Printf("Error: User %s is not an approved member of group %s", username, group.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only approved members can check in"})
+		return
+	}
+
+	member, err := checkInMember(db, member, username)
+	if err != nil {
+		log.Printf("Error: Failed to check in %s to group %s: %v", username, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checked in", "checked_in_at": member.CheckedInAt})
+}
+
+// CheckInGroupMember marks an approved member as present (organizer or co-organizer)
+func CheckInGroupMember(c *gin.Context) {
+	requester := c.GetString("username")
+	username := c.Param("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "check members in")
+	if !ok {
+		return
+	}
+	if !enforceCheckInWindow(c, group, services.NewAttendanceService()) {
+		return
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?", group.ID, username, "approved").First(&member).Error; err != nil {
+		log.Printf("Error: Member %s not found in group %s: %v", username, group.ID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	member, err := checkInMember(db, member, requester)
+	if err != nil {
+		log.Printf("Error: Failed to check in %s to group %s: %v", username, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member checked in", "checked_in_at": member.CheckedInAt})
+}
+
+// UndoCheckIn clears a mistaken check-in while check-in is still open (organizer or co-organizer)
+func UndoCheckIn(c *gin.Context) {
+	username := c.Param("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "check members in")
+	if !ok {
+		return
+	}
+	if !enforceCheckInWindow(c, group, services.NewAttendanceService()) {
+		return
+	}
+
+	result := db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND username = ? AND checked_in_at IS NOT NULL", group.ID, username).
+		Updates(map[string]interface{}{"checked_in_at": nil, "checked_in_by": ""})
+	if result.Error != nil {
+		log.Printf("Error: Failed to undo check-in for %s in group %s: %v", username, group.ID, result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo check-in"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member is not checked in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Check-in removed"})
+}
+
+// GetGroupAttendance lists approved members with their check-in status (organizer or co-organizer)
+func GetGroupAttendance(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "view attendance")
+	if !ok {
+		return
+	}
+
+	var members []models.GroupMember
+	if err := db.Where("group_id = ? AND status = ?", group.ID, "approved").
+		Order("username ASC").Find(&members).Error; err != nil {
+		log.Printf("Error: Failed to fetch attendance for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendance"})
+		return
+	}
+
+	checkedIn := 0
+	for _, member := range members {
+		if member.CheckedInAt != nil {
+			checkedIn++
+		}
+	}
+
+	attendance := services.NewAttendanceService()
+	c.JSON(http.StatusOK, gin.H{
+		"members":           members,
+		"checked_in":        checkedIn,
+		"total":             len(members),
+		"check_in_open":     attendance.CheckInOpen(group, time.Now()),
+		"closes_at":         attendance.CheckInClosesAt(group),
+		"no_shows_recorded": group.AttendanceRecordedAt != nil,
+	})
+}
+
+// GetCheckInTicket returns the logged-in member's QR ticket for a group, creating it on first use.
+// Clients render the token as a QR code for the organizer to scan at the venue.
+func GetCheckInTicket(c *gin.Context) {
+	groupID := c.Param("group_id")
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?", groupID, username, "approved").First(&member).Error; err != nil {
+		log.Printf("Error: User %s is not an approved member of group %s", username, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only approved members have a check-in ticket"})
+		return
+	}
+
+	if member.TicketToken == nil {
+		token, err := services.GenerateTicketToken()
+		if err != nil {
+			log.Printf("Error: Failed to generate ticket token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ticket"})
+			return
+		}
+		if err := db.Model(&member).Update("ticket_token", token).Error; err != nil {
+			log.Printf("Error: Failed to save ticket for %s in group %s: %v", username, groupID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ticket"})
+			return
+		}
+		member.TicketToken = &token
+	}
+
+	attendance := services.NewAttendanceService()
+	c.JSON(http.StatusOK, gin.H{
+		"group_id":      group.ID,
+		"group_name":    group.Name,
+		"username":      username,
+		"guest_count":   member.GuestCount,
+		"qr_token":      *member.TicketToken,
+		"checked_in_at": member.CheckedInAt,
+		"opens_at":      group.DateTime,
+		"closes_at":     attendance.CheckInClosesAt(group),
+	})
+}
+
+// ScanCheckInTicket validates a member's QR ticket at the venue and checks them in (organizer or co-organizer).
+// Scanning a ticket twice succeeds but reports already_checked_in so the door can spot reused tickets.
+func ScanCheckInTicket(c *gin.Context) {
+	requester := c.GetString("username")
+
+	var request models.ScanTicketRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid ticket scan input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "scan tickets")
+	if !ok {
+		return
+	}
+	if !enforceCheckInWindow(c, group, services.NewAttendanceService()) {
+		return
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND ticket_token = ?", group.ID, request.Token).First(&member).Error; err != nil {
+		log.Printf("Error: Unknown ticket scanned for group %s", group.ID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "This ticket isn't valid for this event", "code": "INVALID_TICKET"})
+		return
+	}
+	if member.Status != "approved" {
+		log.Printf("Error: Ticket scanned for %s who is no longer approved in group %s", member.Username, group.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": member.Username + " is no longer attending this event", "code": "TICKET_REVOKED"})
+		return
+	}
+
+	alreadyCheckedIn := member.CheckedInAt != nil
+	member, err := checkInMember(db, member, requester)
+	if err != nil {
+		log.Printf("Error: Failed to check in %s to group %s: %v", member.Username, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in member"})
+		return
+	}
+
+	response := gin.H{
+		"username":           member.Username,
+		"guest_count":        member.GuestCount,
+		"checked_in_at":      member.CheckedInAt,
+		"already_checked_in": alreadyCheckedIn,
+	}
+	var account models.Account
+	if err := db.Where("username = ?", member.Username).First(&account).Error; err != nil {
+		log.Printf("Warning: Failed to load account for scanned ticket: %v", err)
+	} else {
+		response["avatar_url"] = account.AvatarURL
+		if account.ShowFullName {
+			response["full_name"] = account.FullName
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListChecklistItems returns a group's "what to bring" checklist (approved members only)
+func ListChecklistItems(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if !policy.IsMember(groupUser(db, group, requester), group) {
+		log.Printf("Error: User %s not authorized to view checklist for group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view group checklist"})
+		return
+	}
+
+	var items []models.ChecklistItem
+	if err := db.Where("group_id = ?", groupID).Order("created_at ASC").Find(&items).Error; err != nil {
+		log.Printf("Error: Failed to fetch checklist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch checklist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// CreateChecklistItem adds an item to a group's checklist (organizer only)
+func CreateChecklistItem(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var request models.ChecklistItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid checklist input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can manage the checklist")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can manage the checklist"})
+		return
+	}
+
+	item := models.ChecklistItem{
+		GroupID:   groupID,
+		Name:      strings.TrimSpace(request.Name),
+		Quantity:  request.Quantity,
+		Notes:     request.Notes,
+		CreatedBy: requester,
+	}
+
+	if err := db.Create(&item).Error; err != nil {
+		log.Printf("Error: Failed to create checklist item: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create checklist item"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateChecklistItem edits a checklist item (organizer only)
+func UpdateChecklistItem(c *gin.Context) {
+	requester := c.GetString("username")
+
+	var request models.ChecklistItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid checklist input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, item, ok := loadGroupChecklistItem(c, db)
+	if !ok {
+		return
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can manage the checklist")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can manage the checklist"})
+		return
+	}
+
+	item.Name = strings.TrimSpace(request.Name)
+	item.Notes = request.Notes
+	if request.Quantity > 0 {
+		item.Quantity = request.Quantity
+	}
+
+	if err := db.Save(&item).Error; err != nil {
+		log.Printf("Error: Failed to update checklist item: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteChecklistItem removes an item from a group's checklist (organizer only)
+func DeleteChecklistItem(c *gin.Context) {
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, item, ok := loadGroupChecklistItem(c, db)
+	if !ok {
+		return
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can manage the checklist")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can manage the checklist"})
+		return
+	}
+
+	if err := db.Delete(&item).Error; err != nil {
+		log.Printf("Error: Failed to delete checklist item: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete checklist item"})
+		return
+	}
+
+	// Let the member who claimed it know they no longer need to bring it
+	if item.ClaimedBy != nil && *item.ClaimedBy != requester {
+		msg := fmt.Sprintf("'%s' was removed from the checklist for '%s'", item.Name, group.Name)
+		if err := createTargetedNotification(db, *item.ClaimedBy, "checklist_item_removed", msg, group.ID, strconv.FormatUint(uint64(item.ID), 10)); err != nil {
+			log.Printf("Warning: Failed to create checklist notification: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist item deleted"})
+}
+
+// ClaimChecklistItem lets an approved member claim an unclaimed checklist item
+func ClaimChecklistItem(c *gin.Context) {
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, item, ok := loadGroupChecklistItem(c, db)
+	if !ok {
+		return
+	}
+
+	if !policy.IsMember(groupUser(db, group, requester), group) {
+		log.Printf("Error: User %s not authorized to claim items in group %s", requester, group.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only approved members can claim items"})
+		return
+	}
+
+	// Claim atomically so two members can't claim the same item
+	now := time.Now()
+	result := db.Model(&models.ChecklistItem{}).
+		Where("id = ? AND claimed_by IS NULL", item.ID).
+		Updates(map[string]interface{}{"claimed_by": requester, "claimed_at": now, "updated_at": now})
+	if result.Error != nil {
+		log.Printf("Error: Failed to claim checklist item: %v", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim item"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Item already claimed"})
+		return
+	}
+
+	item.ClaimedBy = &requester
+	item.ClaimedAt = &now
+	item.UpdatedAt = now
+
+	c.JSON(http.StatusOK, item)
+}
+
+// UnclaimChecklistItem releases a claim (the claiming member or the organizer)
+func UnclaimChecklistItem(c *gin.Context) {
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, item, ok := loadGroupChecklistItem(c, db)
+	if !ok {
+		return
+	}
+
+	if item.ClaimedBy == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Item is not claimed"})
+		return
+	}
+
+	if *item.ClaimedBy != requester && !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: User %s cannot unclaim item claimed by %s", requester, *item.ClaimedBy)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the claiming member or organizer can unclaim this item"})
+		return
+	}
+
+	previousClaimer := *item.ClaimedBy
+	if err := db.Model(&item).Updates(map[string]interface{}{"claimed_by": nil, "claimed_at": nil}).Error; err != nil {
+		log.Printf("Error: Failed to unclaim checklist item: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unclaim item"})
+		return
+	}
+	item.ClaimedBy = nil
+	item.ClaimedAt = nil
+
+	// Organizer released someone else's claim - let them know
+	if previousClaimer != requester {
+		msg := fmt.Sprintf("You are no longer bringing '%s' to '%s'", item.Name, group.Name)
+		if err := createTargetedNotification(db, previousClaimer, "checklist_unclaimed", msg, group.ID, strconv.FormatUint(uint64(item.ID), 10)); err != nil {
+			log.Printf("Warning: Failed to create checklist notification: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// loadGroupChecklistItem loads the group and checklist item referenced by the route, writing an error response on failure
+func loadGroupChecklistItem(c *gin.Context, db *gorm.DB) (models.Group, models.ChecklistItem, bool) {
+	groupID := c.Param("group_id")
+	itemID := c.Param("item_id")
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, models.ChecklistItem{}, false
+	}
+
+	var item models.ChecklistItem
+	if err := db.Where("id = ? AND group_id = ?", itemID, groupID).First(&item).Error; err != nil {
+		log.Printf("Error: Checklist item not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		return group, item, false
+	}
+
+	return group, item, true
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/models"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetConsentStatus returns the current policy versions, whether the user still has to accept them,
+// and their consent history
+func GetConsentStatus(c *gin.Context) {
+	username := c.GetString("username")
+	consent := services.NewConsentService()
+
+	needsConsent, err := consent.NeedsConsent(username)
+	if err != nil {
+		log.Printf("Error: Failed to check policy consent for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch consent status"})
+		return
+	}
+	history, err := consent.History(username)
+	if err != nil {
+		log.Printf("Error: Failed to fetch consent history for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch consent status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"current":       services.CurrentPolicyVersions(),
+		"needs_consent": needsConsent,
+		"history":       history,
+	})
+}
+
+// AcceptPolicies records the user's acceptance of the current terms of service and privacy policy.
+// The versions sent must be the current ones, so a user can't accept a policy they weren't shown.
+func AcceptPolicies(c *gin.Context) {
+	username := c.GetString("username")
+
+	var request models.AcceptPoliciesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid consent input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	current := services.CurrentPolicyVersions()
+	if request.TermsVersion != current.Terms || request.PrivacyVersion != current.Privacy {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "The policies have been updated since they were shown - please review the latest versions",
+			"current": current,
+		})
+		return
+	}
+
+	versions, err := services.NewConsentService().Accept(username, utils.GetRealClientIP(c), c.Request.UserAgent())
+	if err != nil {
+		log.Printf("Error: Failed to record policy consent for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record consent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Thanks for accepting the updated policies", "accepted": versions})
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterEventSubscribers subscribes the handlers' notifications, emails, webhooks and activity
+// logging to the domain events. Call it once at startup, before serving requests.
+func RegisterEventSubscribers() {
+	// Group created
+	services.SubscribeEvent(func(tx *gorm.DB, event services.GroupCreated) error {
+		if event.Imported {
+			return nil
+		}
+		msg := fmt.Sprintf("Your groop '%s' has been created successfully! People can now discover and join your activity.", event.Group.Name)
+		return createNotification(tx, event.Group.OrganiserID, "group_created", msg, event.Group.ID)
+	})
+	services.SubscribeEvent(func(tx *gorm.DB, event services.GroupCreated) error {
+		group := event.Group
+		return services.QueueWebhookEvent(tx, group.OrganiserID, services.WebhookEventGroupCreated, gin.H{
+			"group_id": group.ID, "name": group.Name, "activity_type": group.ActivityType,
+			"date_time": group.DateTime, "city": group.City, "max_members": group.MaxMembers,
+		})
+	})
+	// The first group check counts activity, so it runs after the activity is logged
+	services.SubscribeCommitted(func(event services.GroupCreated) {
+		if err := LogActivity(event.Group.OrganiserID, "create_group", event.Group.ID); err != nil {
+			log.Printf("Warning: Failed to log activity: %v", err)
+		}
+	})
+	services.SubscribeCommitted(func(event services.GroupCreated) {
+		if event.Group.PublicAt != nil && event.Group.IsListed() {
+			notifyFollowersOfEarlyAccess(database.GetDB(), event.Group)
+		}
+	})
+	services.SubscribeCommitted(notifyAdminsOfFirstGroup)
+
+	// Member approved
+	services.SubscribeEvent(func(tx *gorm.DB, event services.MemberApproved) error {
+		return queueApprovalNotices(tx, event)
+	})
+	services.SubscribeCommitted(onMemberApproved)
+	// Being approved uses up any waitlist offer the member held
+	services.SubscribeCommitted(func(event services.MemberApproved) {
+		services.NewWaitlistService().MarkClaimed(event.Group.ID, event.Username)
+	})
+
+	// Group cancelled
+	services.SubscribeEvent(func(tx *gorm.DB, event services.GroupCancelled) error {
+		var attendeeUsernames []string
+		msg := fmt.Sprintf("'%s' has been cancelled by the organizer: %s", event.Group.Name, event.Reason)
+		for _, member := range event.Members {
+			if err := createNotification(tx, member.Username, "group_cancelled", msg, event.Group.ID); err != nil {
+				return err
+			}
+			if member.Status == "approved" {
+				attendeeUsernames = append(attendeeUsernames, member.Username)
+			}
+		}
+		return queueCancellationNotices(tx, event.Group, attendeeUsernames, event.Reason)
+	})
+	services.SubscribeCommitted(func(event services.GroupCancelled) {
+		if err := LogActivity(event.CancelledBy, "cancel_group", event.Group.ID); err != nil {
+			log.Printf("Warning: Failed to log activity: %v", err)
+		}
+	})
+
+	// Message sent
+	services.SubscribeCommitted(func(event services.MessageSent) {
+		if err := LogActivity(event.Message.Username, "send_message", event.Group.ID); err != nil {
+			log.Printf("Warning: Failed to log message activity: %v", err)
+		}
+	})
+	services.SubscribeEvent(notifyReply)
+	services.SubscribeCommitted(mirrorOrganizerMessage)
+	services.SubscribeCommitted(scheduleUnreadNotifications)
+}
+
+// notifyAdminsOfFirstGroup tells the admins when someone organizes for the first time
+func notifyAdminsOfFirstGroup(event services.GroupCreated) {
+	group := event.Group
+	var groupsCreated int64
+	if err := database.GetDB().Model(&models.ActivityLog{}).
+		Where("username = ? AND event_type = ?", group.OrganiserID, "create_group").
+		Count(&groupsCreated).Error; err != nil {
+		log.Printf("Warning: Failed to count groups created by %s: %v", group.OrganiserID, err)
+	} else if groupsCreated == 1 {
+		services.GetAdminNotifier().Notify(services.AdminEventFirstGroup, "First group created",
+			fmt.Sprintf("%s created their first group: %s (%s, %s)", group.OrganiserID, group.Name, group.ActivityType, group.DateTime.Format(time.RFC1123)))
+	}
+}
+
+// notifyReply lets the author of the message being replied to know
+func notifyReply(tx *gorm.DB, event services.MessageSent) error {
+	if event.ReplyTo == "" {
+		return nil
+	}
+	message := event.Message
+	replyMsg := message.Username + " replied to your message in '" + event.Group.Name + "'"
+	return createTargetedNotification(tx, event.ReplyTo, "message_reply", replyMsg, event.Group.ID, strconv.FormatUint(uint64(*message.ParentMessageID), 10))
+}
+
+// mirrorOrganizerMessage mirrors organizer messages, the highlights worth it, to connected chat channels
+func mirrorOrganizerMessage(event services.MessageSent) {
+	if event.Message.Username != event.Group.OrganiserID {
+		return
+	}
+	highlight := event.Message.Content
+	if highlight == "" {
+		highlight = "(shared an image)"
+	}
+	mirrorToIntegrations(event.Group.ID, services.IntegrationEventChatHighlight, "Organizer update in '"+event.Group.Name+"': "+highlight)
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"groops/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyExperiments returns the authenticated user's variant of every running experiment, so clients
+// can switch features on the same way the server does. Assignments are stable for each user.
+func GetMyExperiments(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"experiments": services.AssignExperiments(c.GetString("username"))})
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// exportRetention is how long a generated archive is kept
+	exportRetention = 7 * 24 * time.Hour
+	// exportLinkTTL is how long a signed download link stays valid
+	exportLinkTTL = time.Hour
+)
+
+// RequestGroupExport starts generating an archive of a completed group (organizer only)
+func RequestGroupExport(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can export the group")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can export the group"})
+		return
+	}
+
+	if !group.HasEnded(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Groups can only be exported after the event has ended"})
+		return
+	}
+
+	// Reuse an export that is still being generated
+	var pending models.GroupExport
+	if err := db.Where("group_id = ? AND status = ?", groupID, models.ExportStatusPending).
+		Limit(1).Find(&pending).Error; err == nil && pending.ID != "" {
+		c.JSON(http.StatusAccepted, pending)
+		return
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		log.Printf("Error: Failed to generate export ID: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start export"})
+		return
+	}
+
+	export := models.GroupExport{
+		ID:          hex.EncodeToString(idBytes),
+		GroupID:     groupID,
+		RequestedBy: requester,
+		Status:      models.ExportStatusPending,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(exportRetention),
+	}
+	if err := db.Create(&export).Error; err != nil {
+		log.Printf("Error: Failed to create export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start export"})
+		return
+	}
+
+	go services.NewExportService().GenerateGroupArchive(export.ID)
+
+	if err := LogActivity(requester, "export_group", groupID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusAccepted, export)
+}
+
+// GetGroupExport returns an export's status and, once ready, a signed download URL (organizer only)
+func GetGroupExport(c *gin.Context) {
+	groupID := c.Param("group_id")
+	exportID := c.Param("export_id")
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var export models.GroupExport
+	if err := db.Omit("data").Where("id = ? AND group_id = ?", exportID, groupID).First(&export).Error; err != nil {
+		log.Printf("Error: Export not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+
+	if export.RequestedBy != requester {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to access this export"})
+		return
+	}
+
+	response := gin.H{"export": export}
+	if export.Status == models.ExportStatusReady {
+		secret := os.Getenv("EXPORT_SIGNING_SECRET")
+		if secret == "" {
+			log.Printf("Error: EXPORT_SIGNING_SECRET not set")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Export downloads unavailable"})
+			return
+		}
+		expires := time.Now().Add(exportLinkTTL)
+		response["download_url"] = utils.SignedURL("/exports/"+export.ID+"/download", expires, secret)
+		response["download_url_expires_at"] = expires
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadGroupExport serves a ready archive to holders of a valid signed URL
+func DownloadGroupExport(c *gin.Context) {
+	exportID := c.Param("export_id")
+
+	secret := os.Getenv("EXPORT_SIGNING_SECRET")
+	if secret == "" || !utils.VerifySignedPath(c.Request.URL.Path, c.Query("expires"), c.Query("signature"), secret) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired download link"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var export models.GroupExport
+	if err := db.Where("id = ? AND status = ?", exportID, models.ExportStatusReady).First(&export).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+
+	filename := fmt.Sprintf("groop-%s.zip", export.GroupID)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/zip", export.Data)
+}
+
+// myEventsCSVHeader is the column row of the personal events export
+var myEventsCSVHeader = []string{
+	"group_id", "name", "activity_type", "date_time", "timing", "role", "status",
+	"guests", "cost", "venue", "address", "city", "organizer",
+}
+
+// ExportMyEvents downloads a CSV of every group the user organized or asked to join,
+// past and upcoming, for expense claims and personal records
+func ExportMyEvents(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var memberships []models.GroupMember
+	if err := db.Where("username = ?", username).Find(&memberships).Error; err != nil {
+		log.Printf("Error: Failed to fetch memberships for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export events"})
+		return
+	}
+	membershipByGroup := make(map[string]models.GroupMember, len(memberships))
+	groupIDs := make([]string, 0, len(memberships))
+	for _, membership := range memberships {
+		membershipByGroup[membership.GroupID] = membership
+		groupIDs = append(groupIDs, membership.GroupID)
+	}
+
+	var groups []models.Group
+	if err := db.Where("organiser_id = ? OR id IN ?", username, groupIDs).
+		Order("date_time ASC").Find(&groups).Error; err != nil {
+		log.Printf("Error: Failed to fetch groups for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export events"})
+		return
+	}
+
+	now := time.Now()
+	rows := [][]string{myEventsCSVHeader}
+	for _, group := range groups {
+		membership := membershipByGroup[group.ID]
+
+		role, status := "member", membership.Status
+		if group.OrganiserID == username {
+			role, status = "organizer", "approved"
+		}
+		timing := "upcoming"
+		if group.DateTime.Before(now) {
+			timing = "past"
+		}
+
+		// Names, places and usernames are user input; escape them so they can't run as formulas
+		rows = append(rows, []string{
+			group.ID,
+			utils.CSVSafe(group.Name),
+			utils.CSVSafe(group.ActivityType),
+			group.DateTime.UTC().Format(time.RFC3339),
+			timing,
+			role,
+			status,
+			strconv.Itoa(membership.GuestCount),
+			strconv.FormatFloat(group.Cost, 'f', 2, 64),
+			utils.CSVSafe(group.Location.Name),
+			utils.CSVSafe(group.Location.FormattedAddress),
+			utils.CSVSafe(group.City),
+			utils.CSVSafe(group.OrganiserID),
+		})
+	}
+
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	if err := cw.WriteAll(rows); err != nil {
+		log.Printf("Error: Failed to write events CSV for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export events"})
+		return
+	}
+
+	filename := fmt.Sprintf("groops-events-%s-%s.csv", username, now.UTC().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// loadFeedbackGroup fetches a group for its organizer, writing the error response and returning false otherwise
+func loadFeedbackGroup(c *gin.Context, db *gorm.DB) (models.Group, bool) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: User %s is not the organizer of group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can read event feedback"})
+		return group, false
+	}
+
+	return group, true
+}
+
+// SubmitEventFeedback sends private feedback to the organizer after an event (approved attendees only).
+// Each attendee can leave one piece of feedback per event, within EVENT_FEEDBACK_WINDOW_DAYS of it.
+func SubmitEventFeedback(c *gin.Context) {
+	groupID := c.Param("group_id")
+	username := c.GetString("username")
+
+	var request models.SubmitFeedbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid feedback input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+	message := strings.TrimSpace(request.Message)
+	if message == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Feedback cannot be empty"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if group.OrganiserID == username {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Organizers can't leave feedback on their own events"})
+		return
+	}
+	if !policy.IsMember(groupUser(db, group, username), group) {
+		log.Printf("Error: User %s did not attend group %s", username, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only attendees can leave feedback"})
+		return
+	}
+
+	now := time.Now()
+	if !group.HasEnded(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Feedback opens once the event has taken place"})
+		return
+	}
+	window := time.Duration(utils.GetEnvInt("EVENT_FEEDBACK_WINDOW_DAYS", 30)) * 24 * time.Hour
+	if now.Sub(group.EndsAt()) > window {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The feedback window for this event has closed"})
+		return
+	}
+
+	feedback := models.EventFeedback{
+		GroupID: group.ID,
+		Author:  username,
+		Message: message,
+	}
+	if err := db.Create(&feedback).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already left feedback for this event"})
+			return
+		}
+		log.Printf("Error: Failed to save feedback: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feedback"})
+		return
+	}
+
+	if err := LogActivity(username, "send_feedback", group.ID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	msg := fmt.Sprintf("%s left private feedback on '%s'", username, group.Name)
+	if err := createTargetedNotification(db, group.OrganiserID, "event_feedback", msg, group.ID, strconv.FormatUint(uint64(feedback.ID), 10)); err != nil {
+		log.Printf("Warning: Failed to create feedback notification: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, feedback)
+}
+
+// ListEventFeedback returns the private feedback left on an event, newest first (organizer only)
+func ListEventFeedback(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadFeedbackGroup(c, db)
+	if !ok {
+		return
+	}
+
+	feedback := []models.EventFeedback{}
+	if err := db.Where("group_id = ?", group.ID).Order("created_at DESC").Find(&feedback).Error; err != nil {
+		log.Printf("Error: Failed to fetch feedback for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feedback"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feedback": feedback})
+}
+
+// ReportEventFeedback flags feedback as abusive and alerts the admins (organizer only)
+func ReportEventFeedback(c *gin.Context) {
+	var request models.ReportFeedbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid report input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason cannot be empty"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadFeedbackGroup(c, db)
+	if !ok {
+		return
+	}
+
+	feedbackID, err := strconv.ParseUint(c.Param("feedback_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feedback ID"})
+		return
+	}
+
+	var feedback models.EventFeedback
+	if err := db.Where("id = ? AND group_id = ?", feedbackID, group.ID).First(&feedback).Error; err != nil {
+		log.Printf("Error: Feedback %d not found in group %s: %v", feedbackID, group.ID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feedback not found"})
+		return
+	}
+	if feedback.ReportedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "This feedback has already been reported"})
+		return
+	}
+
+	now := time.Now()
+	if err := db.Model(&feedback).Updates(map[string]interface{}{"reported_at": now, "report_reason": reason}).Error; err != nil {
+		log.Printf("Error: Failed to report feedback %d: %v", feedback.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report feedback"})
+		return
+	}
+
+	services.GetAdminNotifier().Notify(services.AdminEventReportFiled, "Feedback reported",
+		fmt.Sprintf("%s reported feedback #%d from %s on group %s (%s).\n\nReason: %s\n\nFeedback: %s",
+			group.OrganiserID, feedback.ID, feedback.Author, group.Name, group.ID, reason, feedback.Message))
+
+	c.JSON(http.StatusOK, feedback)
+}
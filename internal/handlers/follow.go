@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowOrganizer follows an organizer, giving early access to their groups
+func FollowOrganizer(c *gin.Context) {
+	follower := c.GetString("username")
+	organizer := c.Param("username")
+
+	if organizer == follower {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't follow yourself"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var account models.Account
+	if err := db.Where("username = ?", organizer).First(&account).Error; err != nil {
+		log.Printf("Error: Organizer not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	follow := models.OrganizerFollow{Follower: follower, Organizer: account.Username}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error; err != nil {
+		log.Printf("Error: Failed to follow %s: %v", organizer, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow organizer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Following " + account.Username})
+}
+
+// UnfollowOrganizer stops following an organizer
+func UnfollowOrganizer(c *gin.Context) {
+	follower := c.GetString("username")
+	organizer := c.Param("username")
+
+	result := database.GetDBWithContext(c.Request.Context()).Where("follower = ? AND organizer = ?", follower, organizer).Delete(&models.OrganizerFollow{})
+	if result.Error != nil {
+		log.Printf("Error: Failed to unfollow %s: %v", organizer, result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow organizer"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not following this organizer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unfollowed " + organizer})
+}
+
+// ListFollowing returns the organizers the logged-in user follows
+func ListFollowing(c *gin.Context) {
+	follower := c.GetString("username")
+
+	following := []models.OrganizerFollow{}
+	if err := database.GetDBWithContext(c.Request.Context()).Where("follower = ?", follower).Order("created_at DESC").Find(&following).Error; err != nil {
+		log.Printf("Error: Failed to fetch followed organizers: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch followed organizers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"following": following})
+}
+
+// withEarlyAccess limits a group query to groups the user may see: fully public ones, plus
+// those still in their follower early-access window if the user organizes, follows the
+// organizer of, or is already a member of them. Anonymous users only see public groups.
+func withEarlyAccess(query *gorm.DB, username string) *gorm.DB {
+	if username == "" {
+		return query.Where(`("group".public_at IS NULL OR "group".public_at <= NOW())`)
+	}
+	return query.Where(`("group".public_at IS NULL OR "group".public_at <= NOW() OR "group".organiser_id = ?
+		OR "group".organiser_id IN (SELECT organizer FROM organizer_follow WHERE follower = ?)
+		OR EXISTS (SELECT 1 FROM group_member gm WHERE gm.group_id = "group".id AND gm.username = ?))`,
+		username, username, username)
+}
+
+// notifyFollowersOfEarlyAccess tells an organizer's followers about a new group they can see before everyone else
+func notifyFollowersOfEarlyAccess(db *gorm.DB, group models.Group) {
+	var followers []string
+	if err := db.Model(&models.OrganizerFollow{}).Where("organizer = ?", group.OrganiserID).
+		Pluck("follower", &followers).Error; err != nil {
+		log.Printf("Warning: Failed to fetch followers of %s: %v", group.OrganiserID, err)
+		return
+	}
+
+	msg := group.OrganiserID + " posted a new groop '" + group.Name + "' - you have early access before it opens to everyone"
+	for _, follower := range followers {
+		if err := createNotification(db, follower, "early_access", msg, group.ID); err != nil {
+			log.Printf("Warning: Failed to create early access notification for %s: %v", follower, err)
+		}
+	}
+}
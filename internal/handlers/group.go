@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"groops/internal/database"
 	"groops/internal/models"
+	"groops/internal/policy"
 	"groops/internal/services"
+	"groops/internal/utils"
 	"log"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +27,12 @@ func CreateGroup(c *gin.Context) {
 		return
 	}
 
+	createGroupFromRequest(c, request)
+}
+
+// createGroupFromRequest validates and creates a group for the authenticated organizer, writing the
+// response. It backs CreateGroup as well as duplicating groups and creating groups from templates.
+func createGroupFromRequest(c *gin.Context, request models.CreateGroupRequest) {
 	// Validate that DateTime is in the future
 	if request.DateTime.Before(time.Now()) {
 		log.Printf("Error: Event date %v is before current time", request.DateTime)
@@ -32,6 +40,32 @@ func CreateGroup(c *gin.Context) {
 		return
 	}
 
+	if msg := validateRecurrence(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateRSVPDeadline(request, nil); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateEndDateTime(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateEligibilityRules(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateCostDetails(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	normalizeCost(&request)
+	secondaryLocations, ok := standardizeSecondaryLocations(c, request.SecondaryLocations, nil)
+	if !ok {
+		return
+	}
+
 	// Get the authenticated username from context
 	organizerUsername := c.GetString("username")
 	if organizerUsername == "" {
@@ -40,7 +74,7 @@ func CreateGroup(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Find the organizer account
 	var organizer models.Account
@@ -50,11 +84,53 @@ func CreateGroup(c *gin.Context) {
 		return
 	}
 
+	// Enforce the configured organizer limits
+	limits := services.LoadGroupLimits()
+	if code, msg := validateGroupLimits(limits, request, true); code != "" {
+		log.Printf("Error: Group limit violated by %s: %s", organizerUsername, code)
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg, "code": code})
+		return
+	}
+
+	if code, msg := services.NewNameValidationService().ValidateGroupName(request.Name); code != "" {
+		log.Printf("Error: Group name rejected for %s: %s", organizerUsername, code)
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg, "code": code})
+		return
+	}
+
+	var activeGroups int64
+	if err := db.Model(&models.Group{}).
+		Where("organiser_id = ? AND date_time > NOW() AND status = ?", organizerUsername, models.GroupStatusActive).
+		Count(&activeGroups).Error; err != nil {
+		log.Printf("Error: Failed to count active groups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+		return
+	}
+	if int(activeGroups) >= limits.MaxActiveGroups {
+		log.Printf("Error: Organizer %s has reached the active group limit", organizerUsername)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": fmt.Sprintf("You can organize at most %d upcoming groups at a time", limits.MaxActiveGroups),
+			"code":  services.ErrCodeActiveGroupLimit,
+		})
+		return
+	}
+
+	// Paid and large groups need a verified phone number as a trust signal
+	if requiresPhoneVerification(request.Cost, request.MaxMembers) && !organizer.PhoneVerified {
+		log.Printf("Error: Organizer %s needs a verified phone number", organizerUsername)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Verify your phone number to create paid or large groups",
+			"code":  ErrCodePhoneVerificationRequired,
+		})
+		return
+	}
+
 	// Create the group (use organizerUsername, not request.OrganizerUsername)
 	group := models.Group{
 		Name:         request.Name,
 		DateTime:     request.DateTime,
 		Location:     request.Location,
+		City:         services.ResolveCity(c.Request.Context(), request.Location),
 		Cost:         request.Cost,
 		SkillLevel:   request.SkillLevel,
 		ActivityType: request.ActivityType,
@@ -63,7 +139,35 @@ func CreateGroup(c *gin.Context) {
 		OrganiserID:  organizerUsername,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
+
+		AutoApproveAll:             request.AutoApproveAll,
+		AutoApproveMinRating:       request.AutoApproveMinRating,
+		AutoApprovePreviousMembers: request.AutoApprovePreviousMembers,
+
+		MaxGuestsPerMember: request.MaxGuestsPerMember,
+		CutoffMinutes:      request.CutoffMinutes,
+
+		Recurrence: request.Recurrence,
+
+		ApprovalMessage:  strings.TrimSpace(request.ApprovalMessage),
+		RejectionMessage: strings.TrimSpace(request.RejectionMessage),
+
+		Visibility: request.Visibility,
+
+		RSVPDeadline: request.RSVPDeadline,
+
+		EndDateTime: request.EndDateTime,
+
+		MinAge: request.MinAge,
+		MaxAge: request.MaxAge,
+		Gender: request.Gender,
+
+		CostDetails: request.CostDetails,
+		MemberNotes: strings.TrimSpace(request.MemberNotes),
+
+		SecondaryLocations: secondaryLocations,
 	}
+	group.ApplyEarlyAccess(request.FollowerEarlyAccessHours)
 
 	if err := db.Create(&group).Error; err != nil {
 		log.Printf("Error: Failed to create group: %v", err)
@@ -78,29 +182,33 @@ func CreateGroup(c *gin.Context) {
 		Status:    "approved",
 		JoinedAt:  time.Now(),
 		UpdatedAt: time.Now(),
+		Role:      models.RoleOrganiser,
 	}
 
-	if err := db.Create(&member).Error; err != nil {
+	// GroupCreated is published with the organizer's membership and the tags, which complete the group
+	group.Tags = normalizeTags(request.Tags)
+	if err := services.WithMemberCounts(db, group.ID, func(tx *gorm.DB) error {
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		if err := setGroupTags(tx, group.ID, group.Tags); err != nil {
+			return fmt.Errorf("failed to save tags: %w", err)
+		}
+		return services.PublishEvent(tx, services.GroupCreated{Group: group})
+	}); err != nil {
 		log.Printf("Error: Failed to add organizer as member: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add organizer as member"})
 		return
 	}
+	group.ApprovedMemberCount = 1
 
-	// Log the activity
-	if err := LogActivity(organizerUsername, "create_group", group.ID); err != nil {
-		log.Printf("Warning: Failed to log activity: %v", err)
-	}
-
-	// Notify the creator about successful group creation
-	msg := fmt.Sprintf("Your groop '%s' has been created successfully! People can now discover and join your activity.", group.Name)
-	if err := createNotification(db, organizerUsername, "group_created", msg, group.ID); err != nil {
-		log.Printf("Warning: Failed to create creator notification: %v", err)
-	}
+	// Activity, follower early access and the admins' first group notice
+	services.PublishCommitted(services.GroupCreated{Group: group})
 
 	c.JSON(http.StatusCreated, group)
 }
 
-// UpdateGroup handles updating an existing group (organizer only)
+// UpdateGroup handles updating an existing group (organizer or co-organizer)
 func UpdateGroup(c *gin.Context) {
 	groupID := c.Param("group_id")
 	requester := c.GetString("username")
@@ -119,7 +227,12 @@ func UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	if msg := validateRecurrence(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Check if group exists
 	var group models.Group
@@ -129,57 +242,485 @@ func UpdateGroup(c *gin.Context) {
 		return
 	}
 
-	// Check if requester is the organizer
-	if group.OrganiserID != requester {
-		log.Printf("Error: Only the organizer can update the group")
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can update the group"})
+	// Check if requester is the organizer or a co-organizer
+	if !policy.CanEditGroup(groupUser(db, group, requester), group) {
+		log.Printf("Error: Only the organizer or a co-organizer can update the group")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer or a co-organizer can update the group"})
 		return
 	}
 
 	// Prevent updates if event has already passed
-	if time.Now().After(group.DateTime) {
+	if group.HasEnded(time.Now()) {
 		log.Printf("Error: Attempted to update group after event has ended")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot update group after the event has ended"})
 		return
 	}
 
-	// Prevent updates if event is happening soon (within 1 hour)
-	if time.Until(group.DateTime) < time.Hour {
-		log.Printf("Error: Attempted to update group too close to event time")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot update group within 1 hour of the event"})
+	// Cancelled groups are kept for history only
+	if group.IsCancelled() {
+		log.Printf("Error: Attempted to update cancelled group %s", groupID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot update a cancelled group"})
+		return
+	}
+
+	// Prevent changes once the group's cutoff window has started
+	if !enforceCutoff(c, group, "update the group") {
+		return
+	}
+
+	if msg := validateRSVPDeadline(request, group.RSVPDeadline); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateEndDateTime(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateEligibilityRules(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateCostDetails(request); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	normalizeCost(&request)
+	secondaryLocations, ok := standardizeSecondaryLocations(c, request.SecondaryLocations, group.SecondaryLocations)
+	if !ok {
+		return
+	}
+
+	// Enforce the configured organizer limits (lead time only matters if the date moves)
+	limits := services.LoadGroupLimits()
+	if code, msg := validateGroupLimits(limits, request, !request.DateTime.Equal(group.DateTime)); code != "" {
+		log.Printf("Error: Group limit violated by %s: %s", requester, code)
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg, "code": code})
+		return
+	}
+
+	if code, msg := services.NewNameValidationService().ValidateGroupName(request.Name); code != "" {
+		log.Printf("Error: Group name rejected for %s: %s", requester, code)
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg, "code": code})
 		return
 	}
 
+	// Switching to a paid or large group needs the same phone verification as creating one
+	// (the organizer's phone, even when a co-organizer makes the change)
+	if requiresPhoneVerification(request.Cost, request.MaxMembers) {
+		var organizer models.Account
+		if err := db.Where("username = ?", group.OrganiserID).First(&organizer).Error; err != nil {
+			log.Printf("Error: Organizer not found: %v", err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organizer not found"})
+			return
+		}
+		if !organizer.PhoneVerified {
+			log.Printf("Error: Organizer %s needs a verified phone number", group.OrganiserID)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Verify your phone number to create paid or large groups",
+				"code":  ErrCodePhoneVerificationRequired,
+			})
+			return
+		}
+	}
+
+	// Keep the original details so members can be told exactly what changed
+	previous := group
+
 	// Update the group fields
 	group.Name = request.Name
 	group.DateTime = request.DateTime
+	group.EndDateTime = request.EndDateTime
 	group.Location = request.Location
+	if previous.Location.PlaceID != request.Location.PlaceID || group.City == "" {
+		group.City = services.ResolveCity(c.Request.Context(), request.Location)
+	}
 	group.Cost = request.Cost
+	group.CostDetails = request.CostDetails
+	group.MemberNotes = strings.TrimSpace(request.MemberNotes)
+	group.SecondaryLocations = secondaryLocations
 	group.SkillLevel = request.SkillLevel
 	group.ActivityType = request.ActivityType
 	group.MaxMembers = request.MaxMembers
 	group.Description = request.Description
-
-	if err := db.Save(&group).Error; err != nil {
-		log.Printf("Error: Failed to update group: %v", err)
+	group.AutoApproveAll = request.AutoApproveAll
+	group.AutoApproveMinRating = request.AutoApproveMinRating
+	group.AutoApprovePreviousMembers = request.AutoApprovePreviousMembers
+	group.MaxGuestsPerMember = request.MaxGuestsPerMember
+	group.CutoffMinutes = request.CutoffMinutes
+	group.Recurrence = request.Recurrence
+	group.MinAge = request.MinAge
+	group.MaxAge = request.MaxAge
+	group.Gender = request.Gender
+	group.ApplyEarlyAccess(request.FollowerEarlyAccessHours)
+	group.ApprovalMessage = strings.TrimSpace(request.ApprovalMessage)
+	group.RejectionMessage = strings.TrimSpace(request.RejectionMessage)
+	if request.Visibility != "" {
+		group.Visibility = request.Visibility
+	}
+	if !sameTime(group.RSVPDeadline, request.RSVPDeadline) {
+		// A new deadline gets its own final headcount
+		group.RSVPDeadline = request.RSVPDeadline
+		group.HeadcountSentAt = nil
+	}
+
+	// The member counters are kept by membership changes; a stale copy mustn't overwrite them.
+	// New tags are saved with the group, and members are alerted with the change if the date,
+	// time or venue moved.
+	if request.Tags != nil {
+		group.Tags = normalizeTags(request.Tags)
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(services.MemberCountColumns...).Save(&group).Error; err != nil {
+			return err
+		}
+		if request.Tags != nil {
+			if err := setGroupTags(tx, groupID, group.Tags); err != nil {
+				return fmt.Errorf("failed to save tags: %w", err)
+			}
+		}
+		return notifyEventUpdated(tx, previous, group)
+	}); err != nil {
+		log.Printf("Error: Failed to update group %s: %v", groupID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
 		return
 	}
 
+	if request.Tags == nil {
+		group.Tags = loadGroupTags(db, []string{groupID})[groupID]
+	}
+
 	// Log the activity
 	if err := LogActivity(requester, "update_group", groupID); err != nil {
 		log.Printf("Warning: Failed to log activity: %v", err)
 	}
 
+	// Offer any new spots to the waitlist
+	if group.MaxMembers > previous.MaxMembers {
+		go services.NewWaitlistService().OnCapacityAvailable(groupID)
+	}
+
 	c.JSON(http.StatusOK, group)
 }
 
+// validateRecurrence checks a group's repeat schedule, returning an error message if it is unusable
+func validateRecurrence(request models.CreateGroupRequest) string {
+	if request.Recurrence == nil || request.Recurrence.Until == nil {
+		return ""
+	}
+	if !request.Recurrence.Until.After(request.DateTime) {
+		return "Recurrence end date must be after the event date"
+	}
+	return ""
+}
+
+// notifyEventUpdated tells approved members exactly how the date, time, end or venue changed through
+// tx, flagging them for re-confirmation if the venue moved beyond the configured threshold
+func notifyEventUpdated(tx *gorm.DB, previous, group models.Group) error {
+	changes := services.DiffEventDetails(previous, group)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	needsReconfirmation := false
+	if previous.Location.PlaceID != group.Location.PlaceID {
+		distanceKm := services.HaversineDistanceKm(
+			previous.Location.Latitude, previous.Location.Longitude,
+			group.Location.Latitude, group.Location.Longitude,
+		)
+		needsReconfirmation = distanceKm > utils.GetEnvFloat("LOCATION_RECONFIRM_THRESHOLD_KM", 5)
+	}
+
+	var members []models.GroupMember
+	if err := tx.Where("group_id = ? AND status = ? AND username != ?", group.ID, "approved", group.OrganiserID).
+		Find(&members).Error; err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	if needsReconfirmation {
+		if err := tx.Model(&models.GroupMember{}).
+			Where("group_id = ? AND status = ? AND username != ?", group.ID, "approved", group.OrganiserID).
+			Update("needs_reconfirmation", true).Error; err != nil {
+			return err
+		}
+	}
+
+	descriptions := make([]string, len(changes))
+	for i, change := range changes {
+		descriptions[i] = change.String()
+	}
+	msg := fmt.Sprintf("'%s' was updated. %s.", group.Name, strings.Join(descriptions, ". "))
+	if needsReconfirmation {
+		msg += " Please confirm you can still attend."
+	}
+
+	usernames := make([]string, 0, len(members))
+	for _, member := range members {
+		usernames = append(usernames, member.Username)
+		if err := createNotification(tx, member.Username, "event_updated", msg, group.ID); err != nil {
+			return err
+		}
+	}
+
+	var accounts []models.Account
+	if err := tx.Where("username IN ?", usernames).Find(&accounts).Error; err != nil {
+		return err
+	}
+
+	emailService := services.NewEmailService().WithOutbox(tx)
+	for _, account := range accounts {
+		if err := emailService.SendEventUpdatedEmail(account.Email, account.Username, group.Name,
+			changes, needsReconfirmation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReconfirmAttendance lets an approved member confirm they can still attend after a venue move
+func ReconfirmAttendance(c *gin.Context) {
+	groupID := c.Param("group_id")
+	username := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?", groupID, username, "approved").
+		First(&member).Error; err != nil {
+		log.Printf("Error: Approved membership not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not an approved member of this group"})
+		return
+	}
+
+	if !member.NeedsReconfirmation {
+		c.JSON(http.StatusOK, gin.H{"message": "Attendance already confirmed"})
+		return
+	}
+
+	if err := db.Model(&member).Update("needs_reconfirmation", false).Error; err != nil {
+		log.Printf("Error: Failed to reconfirm attendance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm attendance"})
+		return
+	}
+
+	if err := LogActivity(username, "reconfirm_attendance", groupID); err != nil {
+		log.Printf("Warning: Failed to log reconfirm activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attendance confirmed"})
+}
+
+// validateGroupLimits checks a create/update request against the configured group limits,
+// returning an error code and message if a limit is violated
+func validateGroupLimits(limits services.GroupLimits, request models.CreateGroupRequest, checkLeadTime bool) (string, string) {
+	if request.MaxMembers > limits.MaxMembers {
+		return services.ErrCodeMaxMembersExceeded, fmt.Sprintf("Groups can have at most %d members", limits.MaxMembers)
+	}
+	if checkLeadTime && time.Until(request.DateTime) < limits.MinLeadTime {
+		return services.ErrCodeLeadTimeTooShort, fmt.Sprintf("Events must be scheduled at least %s in advance", formatDuration(limits.MinLeadTime))
+	}
+	if request.EndDateTime != nil && request.EndDateTime.Sub(request.DateTime) > limits.MaxDuration {
+		return services.ErrCodeDurationTooLong, fmt.Sprintf("Events can last at most %s", formatDuration(limits.MaxDuration))
+	}
+	return "", ""
+}
+
+// validateRSVPDeadline checks a group's RSVP deadline against its date, returning an error message if it
+// is unusable. previous is the group's current deadline, which may already have passed if unchanged.
+func validateRSVPDeadline(request models.CreateGroupRequest, previous *time.Time) string {
+	if request.RSVPDeadline == nil {
+		return ""
+	}
+	if !request.RSVPDeadline.Before(request.DateTime) {
+		return "RSVP deadline must be before the event"
+	}
+	if !sameTime(previous, request.RSVPDeadline) && request.RSVPDeadline.Before(time.Now()) {
+		return "RSVP deadline must be in the future"
+	}
+	return ""
+}
+
+// validateEndDateTime checks a group's end time against its date, returning an error message if it is
+// unusable. A recurring event has to end before its next occurrence starts.
+func validateEndDateTime(request models.CreateGroupRequest) string {
+	if request.EndDateTime == nil {
+		return ""
+	}
+	if !request.EndDateTime.After(request.DateTime) {
+		return "Event end must be after its start"
+	}
+	if request.Recurrence != nil && request.Recurrence.Frequency != "" &&
+		request.EndDateTime.After(request.Recurrence.Next(request.DateTime)) {
+		return "A recurring event must end before its next occurrence starts"
+	}
+	return ""
+}
+
+// validateEligibilityRules checks a group's age restrictions make sense together, returning an error
+// message if they don't
+func validateEligibilityRules(request models.CreateGroupRequest) string {
+	if request.MinAge != nil && request.MaxAge != nil && *request.MinAge > *request.MaxAge {
+		return "Minimum age must not be greater than maximum age"
+	}
+	return ""
+}
+
+// validateCostDetails checks a group's cost breakdown, returning an error message if it doesn't add up
+func validateCostDetails(request models.CreateGroupRequest) string {
+	if request.Cost < 0 {
+		return "Cost can't be negative"
+	}
+	details := request.CostDetails
+	if details != nil && details.Total != nil && details.PerPerson > *details.Total {
+		return "Per-person cost can't be more than the total cost"
+	}
+	return ""
+}
+
+// normalizeCost keeps a request's cost and cost breakdown in step: a breakdown's per-person amount
+// becomes the cost, and clients that only send cost get a breakdown of just that amount
+func normalizeCost(request *models.CreateGroupRequest) {
+	if request.CostDetails == nil {
+		request.CostDetails = &models.CostDetails{PerPerson: request.Cost}
+		return
+	}
+	details := *request.CostDetails
+	details.Includes = strings.TrimSpace(details.Includes)
+	details.PaymentNote = strings.TrimSpace(details.PaymentNote)
+	request.CostDetails = &details
+	request.Cost = details.PerPerson
+}
+
+// standardizeSecondaryLocations checks each of a group's extra meeting points with Google Maps and
+// replaces it with the standardized details, keeping the organizer's labels and order. Places the
+// group already had are kept as stored rather than looked up again. It writes the error response
+// and returns false if a place can't be verified.
+func standardizeSecondaryLocations(c *gin.Context, locations, existing models.SecondaryLocations) (models.SecondaryLocations, bool) {
+	known := make(map[string]models.Location, len(existing))
+	for _, secondary := range existing {
+		known[secondary.Location.PlaceID] = secondary.Location
+	}
+
+	standardized := make(models.SecondaryLocations, 0, len(locations))
+	for _, secondary := range locations {
+		label := strings.TrimSpace(secondary.Label)
+		if location, ok := known[secondary.Location.PlaceID]; ok {
+			standardized = append(standardized, models.SecondaryLocation{Label: label, Location: location})
+			continue
+		}
+
+		location, err := services.StandardizeLocation(c.Request.Context(), secondary.Location)
+		if errors.Is(err, utils.ErrCircuitOpen) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Location lookup is temporarily unavailable, please try again shortly"})
+			return nil, false
+		}
+		if errors.Is(err, services.ErrNoAPIKey) {
+			log.Printf("Error: Failed to validate secondary location: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate location"})
+			return nil, false
+		}
+		if err != nil {
+			log.Printf("Error: Invalid secondary location %s: %v", secondary.Location.PlaceID, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Couldn't find the place for %q", label)})
+			return nil, false
+		}
+		standardized = append(standardized, models.SecondaryLocation{Label: label, Location: location})
+	}
+	return standardized, true
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// enforceRSVPDeadline rejects joining or approving members with 400 once the group's RSVP deadline
+// has passed, writing the error response and returning false
+func enforceRSVPDeadline(c *gin.Context, group models.Group, action string) bool {
+	if !group.RSVPClosed(time.Now()) {
+		return true
+	}
+	log.Printf("Error: Attempted to %s for group %s after the RSVP deadline", action, group.ID)
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":         fmt.Sprintf("RSVPs for this group closed on %s, so you can't %s", group.RSVPDeadline.UTC().Format("Jan 2 at 15:04 UTC"), action),
+		"code":          "RSVP_CLOSED",
+		"rsvp_deadline": group.RSVPDeadline,
+	})
+	return false
+}
+
+// ErrCodeNotEligible is returned when a user doesn't meet a group's age or gender restrictions
+const ErrCodeNotEligible = "NOT_ELIGIBLE"
+
+// enforceEligibility rejects joining with 403 if the user doesn't meet the group's age or gender
+// restrictions, writing the error response and returning false
+func enforceEligibility(c *gin.Context, db *gorm.DB, group models.Group, username string) bool {
+	if group.MinAge == nil && group.MaxAge == nil && group.Gender == nil {
+		return true
+	}
+	var account models.Account
+	if err := db.Where("username = ?", username).First(&account).Error; err != nil {
+		log.Printf("Error: Failed to load account %s for eligibility check: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check eligibility"})
+		return false
+	}
+	if msg := policy.CheckEligibility(account, group, time.Now()); msg != "" {
+		log.Printf("Error: User %s is not eligible to join group %s", username, group.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": msg, "code": ErrCodeNotEligible})
+		return false
+	}
+	return true
+}
+
+// enforceCutoff rejects the action with 400 if the group's cutoff window has started,
+// returning false when the handler should stop
+func enforceCutoff(c *gin.Context, group models.Group, action string) bool {
+	if !services.IsPastCutoff(group) {
+		return true
+	}
+	log.Printf("Error: Attempted to %s for group %s after the cutoff", action, group.ID)
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":     fmt.Sprintf("Cannot %s within %s of the event", action, formatDuration(services.GroupCutoff(group))),
+		"code":      "CUTOFF_PASSED",
+		"cutoff_at": services.GroupCutoffTime(group),
+	})
+	return false
+}
+
+// formatDuration renders a duration in whole days, hours or minutes for user-facing messages
+func formatDuration(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		days := int(d / (24 * time.Hour))
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	if d >= time.Hour && d%time.Hour == 0 {
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	minutes := int(d / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
+
 // DeleteGroup handles deleting a group (organizer only)
 func DeleteGroup(c *gin.Context) {
 	groupID := c.Param("group_id")
 	requester := c.GetString("username")
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Check if group exists
 	var group models.Group
@@ -190,26 +731,32 @@ func DeleteGroup(c *gin.Context) {
 	}
 
 	// Check if requester is the organizer
-	if group.OrganiserID != requester {
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
 		log.Printf("Error: Only the organizer can delete the group")
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can delete the group"})
 		return
 	}
 
 	// Prevent deletion if event has already passed
-	if time.Now().After(group.DateTime) {
+	if group.HasEnded(time.Now()) {
 		log.Printf("Error: Attempted to delete group after event has ended")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete group after the event has ended"})
 		return
 	}
 
-	// Prevent deletion if event is happening soon (within 1 hour)
-	if time.Until(group.DateTime) < time.Hour {
-		log.Printf("Error: Attempted to delete group too close to event time")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete group within 1 hour of the event"})
+	// Prevent changes once the group's cutoff window has started
+	if !enforceCutoff(c, group, "delete the group") {
 		return
 	}
 
+	// Remember who was attending so they can be sent a calendar cancellation
+	var attendeeUsernames []string
+	if err := db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND status = ? AND username != ?", groupID, "approved", requester).
+		Pluck("username", &attendeeUsernames).Error; err != nil {
+		log.Printf("Warning: Failed to fetch members for cancellation notices: %v", err)
+	}
+
 	// Start a transaction to delete group and related data
 	tx := db.Begin()
 	defer func() {
@@ -218,14 +765,6 @@ func DeleteGroup(c *gin.Context) {
 		}
 	}()
 
-	// Delete all group members
-	if err := tx.Where("group_id = ?", groupID).Delete(&models.GroupMember{}).Error; err != nil {
-		tx.Rollback()
-		log.Printf("Error: Failed to delete group members: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group members"})
-		return
-	}
-
 	// Delete group notifications
 	if err := tx.Where("group_id = ?", groupID).Delete(&models.Notification{}).Error; err != nil {
 		tx.Rollback()
@@ -234,18 +773,19 @@ func DeleteGroup(c *gin.Context) {
 		return
 	}
 
-	// Delete activity logs
-	if err := tx.Where("group_id = ?", groupID).Delete(&models.ActivityLog{}).Error; err != nil {
+	// Soft-delete the group with its members and chat; the rest of its data is kept so an admin can
+	// restore it, until the purge worker removes everything
+	if err := services.SoftDeleteGroup(tx, groupID); err != nil {
 		tx.Rollback()
-		log.Printf("Error: Failed to delete activity logs: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete activity logs"})
+		log.Printf("Error: Failed to delete group: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
 		return
 	}
 
-	// Finally delete the group
-	if err := tx.Delete(&group).Error; err != nil {
+	// Queue calendar cancellations for the attendees, sent once the deletion commits
+	if err := queueCancellationNotices(tx, group, attendeeUsernames, ""); err != nil {
 		tx.Rollback()
-		log.Printf("Error: Failed to delete group: %v", err)
+		log.Printf("Error: Failed to queue cancellation notices: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
 		return
 	}
@@ -265,224 +805,202 @@ func DeleteGroup(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
 }
 
-// GetGroups handles listing all groups with filtering, sorting, and pagination.
-// Don't know what's going on here with sort parameter validation and numeric type conversion, but it's SQL injection safe.
-func GetGroups(c *gin.Context) {
-	db := database.GetDB()
-	var groups []models.Group
+// CancelGroup calls off an upcoming event without deleting it (organizer only).
+// Members and pending requesters are notified with the reason, attendees are emailed a
+// calendar cancellation, and the group stays viewable with status "cancelled".
+func CancelGroup(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
 
-	query := db.Preload("Members")
+	var request models.CancelGroupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid cancellation input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A cancellation reason is required"})
+		return
+	}
 
-	// Only show future groups (consistent with search behavior)
-	query = query.Where("date_time > NOW()")
-
-	// Location-based filtering and distance calculation
-	var userLat, userLng string
-	var hasUserLocation bool
-	if userLat = c.Query("user_lat"); userLat != "" {
-		if userLng = c.Query("user_lng"); userLng != "" {
-			hasUserLocation = true
-
-			// Add distance calculation using PostgreSQL's earth distance formula
-			// This calculates distance in kilometers using the haversine formula
-			query = query.Select(`"group".*, 
-				ROUND(
-					6371 * acos(
-						cos(radians(?)) * 
-						cos(radians(CAST(location->>'latitude' AS FLOAT))) * 
-						cos(radians(CAST(location->>'longitude' AS FLOAT)) - radians(?)) + 
-						sin(radians(?)) * 
-						sin(radians(CAST(location->>'latitude' AS FLOAT)))
-					)::numeric, 2
-				) AS distance_km`, userLat, userLng, userLat)
-
-			// Apply radius filter only if radius parameter is provided
-			if radiusStr := c.Query("radius"); radiusStr != "" {
-				radius, err := strconv.ParseFloat(radiusStr, 64)
-				if err != nil || radius <= 0 {
-					log.Printf("Warning: Invalid radius parameter '%s', ignoring radius filter", radiusStr)
-				} else {
-					// Filter to only show groups within specified radius using a subquery
-					query = query.Where(`(
-						6371 * acos(
-							cos(radians(?)) * 
-							cos(radians(CAST(location->>'latitude' AS FLOAT))) * 
-							cos(radians(CAST(location->>'longitude' AS FLOAT)) - radians(?)) + 
-							sin(radians(?)) * 
-							sin(radians(CAST(location->>'latitude' AS FLOAT)))
-						)
-					) <= ?`, userLat, userLng, userLat, radius)
-				}
-			}
-		}
+	db := database.GetDBWithContext(c.Request.Context())
+
+	// Check if group exists
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
 	}
 
-	// Search functionality - advanced full-text search with ranking and fuzzy matching
-	// If search is present, we'll get initial results and then apply filters to them
-	var searchResultIDs []string
-	if searchTerm := c.Query("search"); searchTerm != "" {
-		// Use advanced search service to get relevant group IDs
-		searchService := services.NewSearchService()
+	// Check if requester is the organizer
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can cancel the group")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can cancel the group"})
+		return
+	}
+
+	if group.IsCancelled() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Group is already cancelled"})
+		return
+	}
 
-		// Get pagination parameters for search (we'll apply these later)
-		limitStr := c.DefaultQuery("limit", "10")
-		offsetStr := c.DefaultQuery("offset", "0")
+	// Prevent cancellation if event has already passed
+	if group.HasEnded(time.Now()) {
+		log.Printf("Error: Attempted to cancel group after event has ended")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot cancel group after the event has ended"})
+		return
+	}
 
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit <= 0 {
-			limit = 10
-		}
-		if limit > 100 {
-			limit = 100
-		}
+	// The join/leave cutoff deliberately doesn't apply: a last-minute cancellation is exactly
+	// when members most need to hear about it
 
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			offset = 0
+	var members []models.GroupMember
+	if err := db.Where("group_id = ? AND status IN ? AND username != ?", groupID, []string{"approved", "pending"}, requester).
+		Find(&members).Error; err != nil {
+		log.Printf("Error: Failed to fetch members for cancellation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel group"})
+		return
+	}
+
+	// Members' notifications and attendees' calendar cancellations are queued with the cancellation
+	now := time.Now()
+	event := services.GroupCancelled{CancelledBy: requester, Reason: reason, Members: members}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&group).Updates(map[string]interface{}{
+			"status":              models.GroupStatusCancelled,
+			"cancelled_at":        now,
+			"cancellation_reason": reason,
+		}).Error; err != nil {
+			return err
 		}
+		event.Group = group
+		return services.PublishEvent(tx, event)
+	}); err != nil {
+		log.Printf("Error: Failed to cancel group %s: %v", groupID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel group"})
+		return
+	}
+
+	services.PublishCommitted(event)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group cancelled successfully", "group": group})
+}
+
+// queueCancellationNotices queues an email with an iCalendar CANCEL to each attendee through tx, so the
+// event drops off their calendars once the cancellation or deletion commits
+func queueCancellationNotices(tx *gorm.DB, group models.Group, usernames []string, reason string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
 
-		// Get more results from search to account for filtering
-		// We'll get 10x the limit to ensure we have enough after filtering
-		searchLimit := limit * 10
-		if searchLimit > 1000 {
-			searchLimit = 1000 // Cap at reasonable limit
+	// The organizer's address goes on the calendar entry, if they still have an account
+	var organiser models.Account
+	if err := tx.Where("username = ?", group.OrganiserID).Limit(1).Find(&organiser).Error; err != nil {
+		return err
+	}
+
+	var accounts []models.Account
+	if err := tx.Where("username IN ?", usernames).Find(&accounts).Error; err != nil {
+		return err
+	}
+
+	emailService := services.NewEmailService().WithOutbox(tx)
+	for _, account := range accounts {
+		if err := emailService.SendEventCancellationEmail(account.Email, account.Username, group, organiser.Email, reason); err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+// GetGroups handles listing all groups with filtering, sorting, and pagination.
+// Only upcoming groups are listed unless ?status=past (the past events archive, most recent first)
+// or ?include_past=true asks for started ones too.
+// Don't know what's going on here with sort parameter validation and numeric type conversion, but it's SQL injection safe.
+func GetGroups(c *gin.Context) {
+	// Bound to the request so profiling mode can count its queries
+	db := database.GetDBWithContext(c.Request.Context())
+	var groups []models.Group
+
+	var filter GroupFilter
+	filter.Parse(c)
+
+	query := db.Preload("Members")
 
-		// Perform advanced search
-		searchResults, err := searchService.SearchGroups(searchTerm, searchLimit, 0)
+	// Read from the precomputed group_card view when it exists; it is aliased as "group" so the
+	// filters below work against either source. The view only holds upcoming groups.
+	fromCards := database.GroupCardsReady() && filter.Timeframe == groupTimeframeUpcoming
+	if fromCards {
+		query = query.Table(`group_card AS "group"`)
+	}
+
+	// Cancelled, unlisted and private groups stay viewable by ID but aren't listed. The filter
+	// limits results to upcoming or past groups.
+	query = query.Where("visibility = ?", models.VisibilityPublic)
+
+	// Groups in their follower early-access window are hidden from everyone else
+	query = withEarlyAccess(query, c.GetString("username"))
+
+	// Search functionality - advanced full-text search with ranking and fuzzy matching.
+	// Matching groups are then narrowed down by the other filters.
+	if filter.Search != "" && filter.Timeframe != groupTimeframeUpcoming {
+		// The search index only covers upcoming groups, so started ones are matched by name and description
+		pattern := "%" + filter.Search + "%"
+		query = query.Where(`("group".name ILIKE ? OR "group".description ILIKE ?)`, pattern, pattern)
+	} else if filter.Search != "" {
+		services.TrackEvent(models.AnalyticsSearchPerformed, c.GetString("username"), "", filter.Search)
+		searchResults, err := services.NewSearchService().SearchGroups(filter.Search, filter.SearchLimit(), 0)
 		if err != nil {
 			log.Printf("Error: Advanced search failed: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
 			return
 		}
 
-		// Extract group IDs from search results to filter the main query
-		for _, group := range searchResults {
-			searchResultIDs = append(searchResultIDs, group.ID)
-		}
-
 		// If no search results, return empty
-		if len(searchResultIDs) == 0 {
+		if len(searchResults) == 0 {
 			c.JSON(http.StatusOK, []models.Group{})
 			return
 		}
 
-		// Filter main query to only include search results
-		query = query.Where("\"group\".id IN ?", searchResultIDs)
-	}
-
-	// Filtering
-	if activityType := c.Query("activity_type"); activityType != "" {
-		query = query.Where("activity_type = ?", activityType)
-	}
-	if skillLevel := c.Query("skill_level"); skillLevel != "" {
-		query = query.Where("skill_level = ?", skillLevel)
-	}
-	if minPrice := c.Query("min_price"); minPrice != "" {
-		if price, err := strconv.ParseFloat(minPrice, 64); err == nil {
-			query = query.Where("cost >= ?", price)
-		}
-	}
-	if maxPrice := c.Query("max_price"); maxPrice != "" {
-		if price, err := strconv.ParseFloat(maxPrice, 64); err == nil {
-			query = query.Where("cost <= ?", price)
-		}
-	}
-	if dateFrom := c.Query("date_from"); dateFrom != "" {
-		query = query.Where("date_time >= ?", dateFrom)
-	}
-	if dateTo := c.Query("date_to"); dateTo != "" {
-		query = query.Where("date_time <= ?", dateTo)
-	}
-	if minMembers := c.Query("min_members"); minMembers != "" {
-		if members, err := strconv.Atoi(minMembers); err == nil {
-			query = query.Where("max_members >= ?", members)
-		}
-	}
-	if maxMembers := c.Query("max_members"); maxMembers != "" {
-		if members, err := strconv.Atoi(maxMembers); err == nil {
-			query = query.Where("max_members <= ?", members)
+		filter.SearchResultIDs = make([]string, len(searchResults))
+		for i, group := range searchResults {
+			filter.SearchResultIDs[i] = group.ID
 		}
 	}
 
-	// Sorting with validation
-	sortBy := c.DefaultQuery("sort_by", "date_time")
-	// Validate sort column against allowed values
-	validSortColumns := map[string]bool{
-		"date_time": true, "name": true, "cost": true,
-		"skill_level": true, "activity_type": true, "max_members": true,
-		"created_at": true, "updated_at": true,
-		"distance": true, // Add distance as valid sort option
-	}
-	if !validSortColumns[sortBy] {
-		sortBy = "date_time" // Default to safe value if invalid
-	}
+	query = filter.Apply(query)
 
-	// Special handling for distance sorting
-	if sortBy == "distance" && hasUserLocation {
-		// Use the calculated distance_km field for sorting
-		sortOrder := c.DefaultQuery("sort_order", "asc")
-		if sortOrder != "asc" && sortOrder != "desc" {
-			sortOrder = "asc"
+	if fromCards {
+		var cards []models.GroupCard
+		if err := query.Find(&cards).Error; err != nil {
+			log.Printf("Error: Failed to fetch group cards: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+			return
 		}
-		query = query.Order(fmt.Sprintf("distance_km %s", sortOrder))
-	} else if sortBy == "distance" {
-		// If distance sort requested but no user location provided, fallback to date_time
-		log.Printf("Warning: Distance sort requested but no user location provided")
-		sortBy = "date_time"
-		query = query.Order("date_time asc")
-	} else {
-		// Special case: If we have search results, preserve search ranking unless explicitly sorted
-		if len(searchResultIDs) > 0 && sortBy == "date_time" && c.Query("sort_by") == "" {
-			// Preserve search ranking by ordering by the position in searchResultIDs
-			orderClause := "CASE \"group\".id"
-			for i, id := range searchResultIDs {
-				orderClause += fmt.Sprintf(" WHEN '%s' THEN %d", id, i)
-			}
-			orderClause += " END"
-			query = query.Order(orderClause)
-		} else if hasUserLocation {
-			sortOrder := c.DefaultQuery("sort_order", "asc")
-			if sortOrder != "asc" && sortOrder != "desc" {
-				sortOrder = "asc"
-			}
-			// Always sort by distance first when user location is available, then by requested sort
-			query = query.Order(fmt.Sprintf("distance_km asc, %s %s", sortBy, sortOrder))
-		} else {
-			// Validate sort order
-			sortOrder := c.DefaultQuery("sort_order", "asc")
-			if sortOrder != "asc" && sortOrder != "desc" {
-				sortOrder = "asc" // Default to ascending if invalid
-			}
-			query = query.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
+		for i := range cards {
+			cards[i].Fill()
+			display := services.ActivityDisplayFor(cards[i].ActivityType)
+			cards[i].ActivityDisplay = &display
 		}
+		c.JSON(http.StatusOK, cards)
+		return
 	}
 
-	// Pagination with defaults
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 10
-	}
-	if limit > 100 {
-		limit = 100 // max limit
-	}
-
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
-	}
-
-	query = query.Limit(limit).Offset(offset)
-
 	if err := query.Find(&groups).Error; err != nil {
 		log.Printf("Error: Failed to fetch groups: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
 		return
 	}
 
+	for i := range groups {
+		display := services.ActivityDisplayFor(groups[i].ActivityType)
+		groups[i].ActivityDisplay = &display
+	}
+	attachGroupTags(db, groups)
+	services.AttachOrganizers(db, groups)
+
 	c.JSON(http.StatusOK, groups)
 }
 
@@ -505,6 +1023,12 @@ func LogActivity(username string, eventType string, groupID string) error {
 
 // Helper to create a notification
 func createNotification(db *gorm.DB, recipient, notifType, message, groupID string) error {
+	return createTargetedNotification(db, recipient, notifType, message, groupID, "")
+}
+
+// createTargetedNotification is createNotification for notifications that open a specific object
+// in the group (a message, poll, checklist item...) rather than the group page
+func createTargetedNotification(db *gorm.DB, recipient, notifType, message, groupID, targetID string) error {
 	notif := models.Notification{
 		RecipientUsername: recipient,
 		Type:              notifType,
@@ -512,16 +1036,98 @@ func createNotification(db *gorm.DB, recipient, notifType, message, groupID stri
 		GroupID:           groupID,
 		CreatedAt:         time.Now(),
 		Read:              false,
+
+		Link: services.BuildNotificationLink(notifType, groupID, targetID),
 	}
 	return db.Create(&notif).Error
 }
 
+// queueAccountEmail looks up the user's account through tx and has send queue an email to them in
+// the outbox. A missing account only skips the email rather than rolling back the change it reports.
+func queueAccountEmail(tx *gorm.DB, username string, send func(emailService *services.EmailService, account models.Account) error) error {
+	var account models.Account
+	if err := tx.Where("username = ?", username).First(&account).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Warning: Failed to find user account of %s for email", username)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return send(services.NewEmailService().WithOutbox(tx), account)
+}
+
+// groupUser looks up a user's membership in a group for the authorization policy. If the lookup
+// fails the user is only allowed what someone outside the group would be.
+func groupUser(db *gorm.DB, group models.Group, username string) policy.User {
+	user := policy.UserNamed(username)
+	if username == "" {
+		return user
+	}
+	var membership models.GroupMember
+	result := db.Where("group_id = ? AND username = ?", group.ID, username).Limit(1).Find(&membership)
+	if result.Error != nil {
+		log.Printf("Warning: Failed to check membership for %s in group %s: %v", username, group.ID, result.Error)
+	} else if result.RowsAffected > 0 {
+		user.Membership = &membership
+	}
+	return user
+}
+
+// loadManagedGroup fetches the :group_id group for its organizer or a co-organizer, writing the
+// error response and returning false otherwise. action completes "Only the organizer or a co-organizer can ..."
+func loadManagedGroup(c *gin.Context, db *gorm.DB, action string) (models.Group, bool) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	if !policy.CanEditGroup(groupUser(db, group, requester), group) {
+		log.Printf("Error: User %s cannot %s for group %s", requester, action, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer or a co-organizer can " + action})
+		return group, false
+	}
+
+	return group, true
+}
+
+// notifyApprovedMembers creates a notification for every approved member of a group except the given user.
+// targetID is the object the notification opens, or "" for the group itself.
+func notifyApprovedMembers(db *gorm.DB, groupID, exclude, notifType, message, targetID string) {
+	var usernames []string
+	if err := db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND status = ? AND username != ?", groupID, "approved", exclude).
+		Pluck("username", &usernames).Error; err != nil {
+		log.Printf("Warning: Failed to fetch members for %s notifications: %v", notifType, err)
+		return
+	}
+	for _, username := range usernames {
+		if err := createTargetedNotification(db, username, notifType, message, groupID, targetID); err != nil {
+			log.Printf("Warning: Failed to create %s notification for %s: %v", notifType, username, err)
+		}
+	}
+}
+
 // JoinGroup handles a user's request to join a group
 func JoinGroup(c *gin.Context) {
 	groupID := c.Param("group_id")
 	username := c.GetString("username") // Set by auth middleware
 
-	db := database.GetDB()
+	// The intro message is optional, so an empty body is allowed
+	var request models.JoinGroupRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			log.Printf("Error: Invalid join request input: %s", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+			return
+		}
+	}
+	joinMessage := strings.TrimSpace(request.Message)
+
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Check if group exists
 	var group models.Group
@@ -530,18 +1136,45 @@ func JoinGroup(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
 		return
 	}
+	// Counted whether or not the request goes through, so organizers can see who tried
+	services.TrackEvent(models.AnalyticsJoinClicked, username, groupID, "")
 
 	// Prevent joining if event has already passed
-	if time.Now().After(group.DateTime) {
+	if group.HasEnded(time.Now()) {
 		log.Printf("Error: Attempted to join group after event has ended")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot join group after the event has ended"})
 		return
 	}
 
-	// Prevent joining if event is happening soon (within 1 hour)
-	if time.Until(group.DateTime) < time.Hour {
-		log.Printf("Error: Attempted to join group too close to event time")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot join group within 1 hour of the event"})
+	// Cancelled groups can't take new members
+	if group.IsCancelled() {
+		log.Printf("Error: Attempted to join cancelled group %s", groupID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This group has been cancelled"})
+		return
+	}
+
+	// Prevent changes once the group's cutoff window has started
+	if !enforceCutoff(c, group, "join the group") {
+		return
+	}
+	if !enforceRSVPDeadline(c, group, "join the group") {
+		return
+	}
+	if !enforceEligibility(c, db, group, username) {
+		return
+	}
+
+	// Repeat offenders serve a cooldown before they can join again
+	penaltyService := services.NewPenaltyService()
+	if ban, err := penaltyService.GetActiveBan(username); err != nil {
+		log.Printf("Warning: Failed to check join ban for %s: %v", username, err)
+	} else if ban != nil {
+		log.Printf("Error: User %s is in a join cooldown until %v", username, ban.ExpiresAt)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":               "You are temporarily unable to join groups: " + ban.Reason,
+			"code":                "JOIN_COOLDOWN",
+			"cooldown_expires_at": ban.ExpiresAt,
+		})
 		return
 	}
 
@@ -558,24 +1191,58 @@ func JoinGroup(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Join request already pending"})
 			return
 		case "rejected":
+			// Rejected users must wait before asking again
+			if retryAt := member.UpdatedAt.Add(rejoinCooldown()); time.Now().Before(retryAt) {
+				log.Printf("Error: User %s re-requested group %s during rejoin cooldown", username, groupID)
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":          "Your request was recently rejected. You can request to join again after " + retryAt.Format(time.RFC1123),
+					"code":           ErrCodeRejoinCooldown,
+					"retry_after_at": retryAt,
+				})
+				return
+			}
+
+			if limitErr := checkJoinRequestLimits(db, username); limitErr != nil {
+				log.Printf("Error: User %s hit membership limit: %s", username, limitErr.Code)
+				c.JSON(http.StatusForbidden, gin.H{"error": limitErr.Message, "code": limitErr.Code})
+				return
+			}
+
 			// Update status to pending and update timestamps
 			member.Status = "pending"
 			member.UpdatedAt = time.Now()
 			member.JoinedAt = time.Now()
-			if err := db.Save(&member).Error; err != nil {
+			member.JoinMessage = joinMessage
+			skillWarning := skillMismatchWarning(db, username, group)
+			msg := username + " requested to join your group '" + group.Name + "'"
+			if skillWarning != "" {
+				msg += " (" + skillWarning + ")"
+			}
+			if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+				if err := tx.Save(&member).Error; err != nil {
+					return err
+				}
+				if err := createNotification(tx, group.OrganiserID, "join_request", msg, groupID); err != nil {
+					return err
+				}
+				return services.QueueWebhookEvent(tx, group.OrganiserID, services.WebhookEventJoinRequestCreated, gin.H{
+					"group_id": groupID, "group_name": group.Name, "username": username,
+				})
+			}); err != nil {
 				log.Printf("Error: Failed to re-request to join group: %v", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-request to join group"})
 				return
 			}
-			// Log activity, notify organiser, etc.
+			// Log activity, mirror to the group's chat integrations, etc.
 			if err := LogActivity(username, "join_group_request", groupID); err != nil {
 				log.Printf("Warning: Failed to log join request activity: %v", err)
 			}
-			msg := username + " requested to join your group '" + group.Name + "'"
-			if err := createNotification(db, group.OrganiserID, "join_request", msg, groupID); err != nil {
-				log.Printf("Warning: Failed to create notification: %v", err)
+			mirrorToIntegrations(groupID, services.IntegrationEventJoinRequest, msg)
+			response := gin.H{"message": "Join request re-submitted"}
+			if skillWarning != "" {
+				response["skill_warning"] = skillWarning
 			}
-			c.JSON(http.StatusCreated, gin.H{"message": "Join request re-submitted"})
+			c.JSON(http.StatusCreated, response)
 			return
 		}
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -584,50 +1251,154 @@ func JoinGroup(c *gin.Context) {
 		return
 	}
 
-	// Check if group is full (approved members)
-	var approvedCount int64
-	db.Model(&models.GroupMember{}).Where("group_id = ? AND status = ?", groupID, "approved").Count(&approvedCount)
-	if int(approvedCount) >= group.MaxMembers {
+	// Check if group is full (approved members and their guests, plus spots offered to other waitlisted users)
+	waitlistService := services.NewWaitlistService()
+	heldSpots, err := waitlistService.CountActiveOffers(groupID, username)
+	if err != nil {
+		log.Printf("Warning: Failed to count waitlist offers: %v", err)
+	}
+	if group.OccupiedSpots()+int(heldSpots) >= group.MaxMembers {
 		log.Printf("Error: Group is full")
-		c.JSON(http.StatusForbidden, gin.H{"error": "Group is full"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "Group is full", "waitlist_available": true})
 		return
 	}
 
-	// If not a member, create join request (pending status)
+	// Curb spot-hoarding across many groups
+	if limitErr := checkJoinRequestLimits(db, username); limitErr != nil {
+		log.Printf("Error: User %s hit membership limit: %s", username, limitErr.Code)
+		c.JSON(http.StatusForbidden, gin.H{"error": limitErr.Message, "code": limitErr.Code})
+		return
+	}
+
+	// Requests through the organiser's invite link or matching their auto-approval rules skip
+	// the pending state, as long as the user still has room for another upcoming event
+	viaInviteLink := hasValidInviteLink(c, groupID)
+	autoApproved := viaInviteLink || shouldAutoApprove(db, group, username)
+	if autoApproved {
+		if limitErr := checkUpcomingEventLimit(db, username); limitErr != nil {
+			log.Printf("Error: User %s hit membership limit: %s", username, limitErr.Code)
+			c.JSON(http.StatusForbidden, gin.H{"error": limitErr.Message, "code": limitErr.Code})
+			return
+		}
+	}
+
+	// If not a member, create join request (pending status unless auto-approved)
+	status := "pending"
+	if autoApproved {
+		status = "approved"
+	}
 	newMember := models.GroupMember{
 		GroupID:   groupID,
 		Username:  username,
-		Status:    "pending",
+		Status:    status,
 		JoinedAt:  time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	if err := db.Create(&newMember).Error; err != nil {
+	if !autoApproved {
+		newMember.JoinMessage = joinMessage
+	}
+
+	// Flag skill mismatches (e.g. a beginner joining an advanced group) for the organiser
+	skillWarning := skillMismatchWarning(db, username, group)
+	msg := username + " requested to join your group '" + group.Name + "'"
+	if autoApproved {
+		msg = username + " joined your group '" + group.Name + "' (auto-approved)"
+		if viaInviteLink {
+			msg = username + " joined your group '" + group.Name + "' via your invite link"
+		}
+	}
+	if skillWarning != "" {
+		msg += " (" + skillWarning + ")"
+	}
+
+	// Auto-approved joins publish MemberApproved like any other approval
+	var approval services.MemberApproved
+	if autoApproved {
+		approval = services.MemberApproved{Group: group, Username: username, Via: services.ApprovedAutomatically, Notice: msg}
+		if viaInviteLink {
+			approval.Via = services.ApprovedByInviteLink
+		}
+	}
+
+	// The organizer's notification, webhook and email are queued with the membership itself
+	if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+		if err := tx.Create(&newMember).Error; err != nil {
+			return err
+		}
+		if autoApproved {
+			return services.PublishEvent(tx, approval)
+		}
+		if err := createNotification(tx, group.OrganiserID, "join_request", msg, groupID); err != nil {
+			return err
+		}
+		if err := services.QueueWebhookEvent(tx, group.OrganiserID, services.WebhookEventJoinRequestCreated, gin.H{
+			"group_id": groupID, "group_name": group.Name, "username": username,
+		}); err != nil {
+			return err
+		}
+		return queueAccountEmail(tx, group.OrganiserID, func(emailService *services.EmailService, account models.Account) error {
+			return emailService.SendJoinRequestEmail(account.Email, group.OrganiserID, username, group.Name)
+		})
+	}); err != nil {
 		log.Printf("Error: Failed to request to join group: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request to join group"})
 		return
 	}
 
-	// Log activity, notify organiser, etc.
+	if autoApproved {
+		services.PublishCommitted(approval)
+
+		response := gin.H{"message": "Joined group", "status": status}
+		if skillWarning != "" {
+			response["skill_warning"] = skillWarning
+		}
+		c.JSON(http.StatusCreated, response)
+		return
+	}
+
+	// Log activity, mirror to the group's chat integrations, etc.
 	if err := LogActivity(username, "join_group_request", groupID); err != nil {
 		log.Printf("Warning: Failed to log join request activity: %v", err)
 	}
-	msg := username + " requested to join your group '" + group.Name + "'"
-	if err := createNotification(db, group.OrganiserID, "join_request", msg, groupID); err != nil {
-		log.Printf("Warning: Failed to create notification: %v", err)
+	mirrorToIntegrations(groupID, services.IntegrationEventJoinRequest, msg)
+
+	response := gin.H{"message": "Join request submitted", "status": status}
+	if skillWarning != "" {
+		response["skill_warning"] = skillWarning
 	}
+	c.JSON(http.StatusCreated, response)
+}
 
-	// Send email notification to the group organizer
-	emailService := services.NewEmailService()
-	var organiserAccount models.Account
-	if err := db.Where("username = ?", group.OrganiserID).First(&organiserAccount).Error; err != nil {
-		log.Printf("Warning: Failed to find organizer account for email: %v", err)
-	} else {
-		if err := emailService.SendJoinRequestEmail(organiserAccount.Email, group.OrganiserID, username, group.Name); err != nil {
-			log.Printf("Warning: Failed to send join request email: %v", err)
+// shouldAutoApprove checks a join request against the group's auto-approval rules
+func shouldAutoApprove(db *gorm.DB, group models.Group, username string) bool {
+	if group.AutoApproveAll {
+		return true
+	}
+
+	if group.AutoApproveMinRating != nil {
+		var account models.Account
+		if err := db.Select("rating").Where("username = ?", username).First(&account).Error; err != nil {
+			log.Printf("Warning: Failed to fetch rating for auto-approval: %v", err)
+		} else if account.Rating >= *group.AutoApproveMinRating {
+			return true
+		}
+	}
+
+	// Previously attended: approved in one of the organiser's past events
+	if group.AutoApprovePreviousMembers {
+		var count int64
+		if err := db.Model(&models.GroupMember{}).
+			Joins(`JOIN "group" ON "group".id = group_member.group_id`).
+			Where(`group_member.username = ? AND group_member.status = ? AND "group".organiser_id = ? AND "group".date_time < NOW()`,
+				username, "approved", group.OrganiserID).
+			Count(&count).Error; err != nil {
+			log.Printf("Warning: Failed to check previous attendance for auto-approval: %v", err)
+		} else if count > 0 {
+			return true
 		}
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Join request submitted"})
+	return false
 }
 
 // LeaveGroup handles a user's request to leave a group
@@ -635,7 +1406,7 @@ func LeaveGroup(c *gin.Context) {
 	groupID := c.Param("group_id")
 	username := c.GetString("username") // Set by auth middleware
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Check if group exists
 	var group models.Group
@@ -653,16 +1424,14 @@ func LeaveGroup(c *gin.Context) {
 	}
 
 	// Prevent leaving if event has already passed
-	if time.Now().After(group.DateTime) {
+	if group.HasEnded(time.Now()) {
 		log.Printf("Error: Attempted to leave group after event has ended")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot leave group after the event has ended"})
 		return
 	}
 
-	// Prevent leaving if event is happening soon (within 1 hour)
-	if time.Until(group.DateTime) < time.Hour {
-		log.Printf("Error: Attempted to leave group too close to event time")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot leave group within 1 hour of the event"})
+	// Prevent changes once the group's cutoff window has started
+	if !enforceCutoff(c, group, "leave the group") {
 		return
 	}
 
@@ -674,15 +1443,26 @@ func LeaveGroup(c *gin.Context) {
 		return
 	}
 
-	// Only allow approved or pending members to leave
-	if member.Status != "approved" && member.Status != "pending" {
+	// Only approved members can leave; pending requests are withdrawn via WithdrawJoinRequest
+	if member.Status == "pending" {
+		log.Printf("Error: Attempted to leave group with a pending join request")
+		c.JSON(http.StatusConflict, gin.H{"error": "Join request is still pending - withdraw it instead"})
+		return
+	}
+	if member.Status != "approved" {
 		log.Printf("Error: Cannot leave group with current status")
 		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot leave group with current status"})
 		return
 	}
 
-	// Remove membership (delete row)
-	if err := db.Delete(&member).Error; err != nil {
+	// Remove membership (delete row) and notify the organiser
+	if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&member).Error; err != nil {
+			return err
+		}
+		msg := username + " has left your group '" + group.Name + "'"
+		return createNotification(tx, group.OrganiserID, "leave_group", msg, groupID)
+	}); err != nil {
 		log.Printf("Error: Failed to leave group: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave group"})
 		return
@@ -693,21 +1473,62 @@ func LeaveGroup(c *gin.Context) {
 		log.Printf("Warning: Failed to log leave group activity: %v", err)
 	}
 
-	// Notify organiser
-	msg := username + " has left your group '" + group.Name + "'"
-	if err := createNotification(db, group.OrganiserID, "leave_group", msg, groupID); err != nil {
-		log.Printf("Warning: Failed to create leave notification: %v", err)
-	}
+	// An approved member leaving frees a spot for the waitlist
+	go services.NewWaitlistService().OnCapacityAvailable(groupID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Left group successfully"})
 }
 
-// ListPendingMembers returns all pending join requests for a group (organiser only)
+// WithdrawJoinRequest lets a user cancel their own pending join request
+func WithdrawJoinRequest(c *gin.Context) {
+	groupID := c.Param("group_id")
+	username := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	// Check if group exists
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	// Find the pending request
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?",
+		groupID, username, "pending").First(&member).Error; err != nil {
+		log.Printf("Error: Pending join request not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending join request not found"})
+		return
+	}
+
+	// Let the organiser know the request no longer needs review
+	if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&member).Error; err != nil {
+			return err
+		}
+		msg := username + " withdrew their request to join your group '" + group.Name + "'"
+		return createNotification(tx, group.OrganiserID, "join_withdrawn", msg, groupID)
+	}); err != nil {
+		log.Printf("Error: Failed to withdraw join request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw join request"})
+		return
+	}
+
+	if err := LogActivity(username, "withdraw_request", groupID); err != nil {
+		log.Printf("Warning: Failed to log withdraw activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Join request withdrawn"})
+}
+
+// ListPendingMembers returns all pending join requests for a group (organiser or co-organiser)
 func ListPendingMembers(c *gin.Context) {
 	groupID := c.Param("group_id")
 	requester := c.GetString("username")
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var group models.Group
 
 	// Check if group exists
@@ -717,10 +1538,10 @@ func ListPendingMembers(c *gin.Context) {
 		return
 	}
 
-	// Check if requester is the organizer
-	if group.OrganiserID != requester {
-		log.Printf("Error: Only the organizer can view pending members")
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can view pending members"})
+	// Check if requester is the organizer or a co-organizer
+	if !policy.CanEditGroup(groupUser(db, group, requester), group) {
+		log.Printf("Error: Only the organizer or a co-organizer can view pending members")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer or a co-organizer can view pending members"})
 		return
 	}
 
@@ -731,16 +1552,64 @@ func ListPendingMembers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, pendingMembers)
+	// The intro message is hidden everywhere else members are listed
+	type pendingMember struct {
+		models.GroupMember
+		JoinMessage string `json:"join_message,omitempty"`
+	}
+	response := make([]pendingMember, len(pendingMembers))
+	for i, member := range pendingMembers {
+		response[i] = pendingMember{GroupMember: member, JoinMessage: member.JoinMessage}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOrganizerPendingCounts returns pending join-request counts for every group the user organizes or co-organizes
+func GetOrganizerPendingCounts(c *gin.Context) {
+	requester := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	type pendingCount struct {
+		GroupID   string `json:"group_id"`
+		GroupName string `json:"group_name"`
+		Pending   int64  `json:"pending"`
+	}
+
+	// Single grouped query across all of the organizer's groups with pending requests
+	counts := []pendingCount{}
+	if err := db.Model(&models.GroupMember{}).
+		Select(`group_member.group_id, "group".name AS group_name, COUNT(*) AS pending`).
+		Joins(`JOIN "group" ON "group".id = group_member.group_id`).
+		Where(`("group".organiser_id = ? OR EXISTS (
+			SELECT 1 FROM group_member co WHERE co.group_id = "group".id AND co.username = ? AND co.status = ? AND co.role = ?
+		)) AND group_member.status = ?`, requester, requester, "approved", models.RoleCoOrganiser, "pending").
+		Group(`group_member.group_id, "group".name`).
+		Order("pending DESC").
+		Scan(&counts).Error; err != nil {
+		log.Printf("Error: Failed to fetch pending counts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending counts"})
+		return
+	}
+
+	var total int64
+	for _, count := range counts {
+		total += count.Pending
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"groups": counts,
+		"total":  total,
+	})
 }
 
-// ApproveJoinRequest allows organiser to approve a pending join request
+// ApproveJoinRequest allows the organiser or a co-organiser to approve a pending join request
 func ApproveJoinRequest(c *gin.Context) {
 	groupID := c.Param("group_id")
 	username := c.Param("username")
 	requester := c.GetString("username")
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 	var group models.Group
 
 	// Check if group exists
@@ -750,10 +1619,14 @@ func ApproveJoinRequest(c *gin.Context) {
 		return
 	}
 
-	// Check if requester is the organizer
-	if group.OrganiserID != requester {
-		log.Printf("Error: Only the organizer can approve members")
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can approve members"})
+	// Check if requester is the organizer or a co-organizer
+	if !policy.CanEditGroup(groupUser(db, group, requester), group) {
+		log.Printf("Error: Only the organizer or a co-organizer can approve members")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer or a co-organizer can approve members"})
+		return
+	}
+
+	if !enforceRSVPDeadline(c, group, "approve members") {
 		return
 	}
 
@@ -766,69 +1639,63 @@ func ApproveJoinRequest(c *gin.Context) {
 		return
 	}
 
-	// Check if group is full (approved members)
-	var approvedCount int64
-	db.Model(&models.GroupMember{}).Where("group_id = ? AND status = ?", groupID, "approved").Count(&approvedCount)
-	if int(approvedCount) >= group.MaxMembers {
+	// Check if group is full (approved members and their guests, plus spots offered to other waitlisted users)
+	heldSpots, err := services.NewWaitlistService().CountActiveOffers(groupID, username)
+	if err != nil {
+		log.Printf("Warning: Failed to count waitlist offers: %v", err)
+	}
+	if group.OccupiedSpots()+int(heldSpots) >= group.MaxMembers {
 		log.Printf("Error: Group is full")
 		c.JSON(http.StatusForbidden, gin.H{"error": "Group is full"})
 		return
 	}
 
-	// Approve the member
-	if err := db.Model(&member).Update("status", "approved").Error; err != nil {
-		log.Printf("Error: Failed to approve member: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve member"})
+	// Make sure the user hasn't filled their upcoming event quota since requesting
+	if limitErr := checkUpcomingEventLimit(db, username); limitErr != nil {
+		log.Printf("Error: User %s hit membership limit: %s", username, limitErr.Code)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": fmt.Sprintf("%s cannot be approved: they have reached the limit of upcoming events", username),
+			"code":  limitErr.Code,
+		})
 		return
 	}
 
-	if err := LogActivity(username, "join_group_approved", groupID); err != nil {
-		log.Printf("Warning: Failed to log approve join activity: %v", err)
-	}
-
-	// Notify user
-	msg := "Your request to join group '" + group.Name + "' was approved"
-	if err := createNotification(db, username, "join_approved", msg, groupID); err != nil {
-		log.Printf("Warning: Failed to create approval notification: %v", err)
-	}
-
-	// Notify all existing approved group members (except organizer) about the new member
-	var existingMembers []models.GroupMember
-	if err := db.Where("group_id = ? AND status = ? AND username != ?", groupID, "approved", username).Find(&existingMembers).Error; err != nil {
-		log.Printf("Warning: Failed to fetch existing members for new member notifications: %v", err)
-	} else {
-		memberJoinMsg := username + " has joined your group '" + group.Name + "'"
-		for _, existingMember := range existingMembers {
-			// Don't notify the organizer (they initiated the approval)
-			if existingMember.Username != group.OrganiserID {
-				if err := createNotification(db, existingMember.Username, "member_joined", memberJoinMsg, groupID); err != nil {
-					log.Printf("Warning: Failed to create member join notification for %s: %v", existingMember.Username, err)
-				}
-			}
+	// Approve the member
+	event := services.MemberApproved{Group: group, Username: username, ApprovedBy: requester}
+	if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+		if err := tx.Model(&member).Update("status", "approved").Error; err != nil {
+			return err
 		}
+		return services.PublishEvent(tx, event)
+	}); err != nil {
+		log.Printf("Error: Failed to approve member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve member"})
+		return
 	}
 
-	// Send email notification to the approved user
-	emailService := services.NewEmailService()
-	var userAccount models.Account
-	if err := db.Where("username = ?", username).First(&userAccount).Error; err != nil {
-		log.Printf("Warning: Failed to find user account for email: %v", err)
-	} else {
-		if err := emailService.SendJoinApprovalEmail(userAccount.Email, username, group.Name); err != nil {
-			log.Printf("Warning: Failed to send join approval email: %v", err)
-		}
-	}
+	services.PublishCommitted(event)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Member approved"})
 }
 
-// RejectJoinRequest allows organiser to reject a pending join request
+// RejectJoinRequest allows the organiser or a co-organiser to reject a pending join request
 func RejectJoinRequest(c *gin.Context) {
 	groupID := c.Param("group_id")
 	username := c.Param("username")
 	requester := c.GetString("username")
 
-	db := database.GetDB()
+	// The rejection reason is optional, so an empty body is allowed
+	var request models.RejectJoinRequestRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			log.Printf("Error: Invalid rejection input: %s", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+			return
+		}
+	}
+	reason := strings.TrimSpace(request.Reason)
+
+	db := database.GetDBWithContext(c.Request.Context())
 	var group models.Group
 
 	// Check if group exists
@@ -838,10 +1705,10 @@ func RejectJoinRequest(c *gin.Context) {
 		return
 	}
 
-	// Check if requester is the organizer
-	if group.OrganiserID != requester {
-		log.Printf("Error: Only the organizer can reject members")
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can reject members"})
+	// Check if requester is the organizer or a co-organizer
+	if !policy.CanEditGroup(groupUser(db, group, requester), group) {
+		log.Printf("Error: Only the organizer or a co-organizer can reject members")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer or a co-organizer can reject members"})
 		return
 	}
 
@@ -854,30 +1721,122 @@ func RejectJoinRequest(c *gin.Context) {
 		return
 	}
 
-	// Reject the member
-	if err := db.Model(&member).Update("status", "rejected").Error; err != nil {
+	// Reject the member (updated_at marks the start of the rejoin cooldown)
+	if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+		if err := tx.Model(&member).Updates(map[string]interface{}{
+			"status":     "rejected",
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return queueRejectionNotice(tx, group, username, reason)
+	}); err != nil {
 		log.Printf("Error: Failed to reject member: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject member"})
 		return
 	}
 
-	if err := LogActivity(username, "join_group_rejected", groupID); err != nil {
+	if err := LogActivity(username, "join_group_rejected", group.ID); err != nil {
 		log.Printf("Warning: Failed to log reject join activity: %v", err)
 	}
 
+	c.JSON(http.StatusOK, gin.H{"message": "Member rejected"})
+}
+
+// queueApprovalNotices notifies the approved user (or, when nobody approved them by hand, the
+// organizer), queues their approval email and the member.approved webhook, all through the
+// approval's transaction. It also tells the group's other members, unless the approval was part of
+// a bulk approval, which sends them one summary instead. It subscribes to MemberApproved.
+func queueApprovalNotices(tx *gorm.DB, event services.MemberApproved) error {
+	group, username, requester := event.Group, event.Username, event.ApprovedBy
+
+	if event.Via == "" {
+		msg := "Your request to join group '" + group.Name + "' was approved"
+		if group.ApprovalMessage != "" {
+			msg += ". Message from the organizer: " + group.ApprovalMessage
+		}
+		if err := createNotification(tx, username, "join_approved", msg, group.ID); err != nil {
+			return err
+		}
+	} else {
+		notificationType := "member_joined"
+		if event.Via == services.ApprovedByInvitation {
+			notificationType = "invite_accepted"
+		}
+		if err := createNotification(tx, group.OrganiserID, notificationType, event.Notice, group.ID); err != nil {
+			return err
+		}
+	}
+	autoApproved := event.Via == services.ApprovedAutomatically || event.Via == services.ApprovedByInviteLink
+	if err := services.QueueWebhookEvent(tx, group.OrganiserID, services.WebhookEventMemberApproved, gin.H{
+		"group_id": group.ID, "group_name": group.Name, "username": username, "auto_approved": autoApproved,
+	}); err != nil {
+		return err
+	}
+
+	// Notify all existing approved group members (except organizer) about the new member
+	if !event.Bulk {
+		var existingMembers []models.GroupMember
+		if err := tx.Where("group_id = ? AND status = ? AND username != ?", group.ID, "approved", username).Find(&existingMembers).Error; err != nil {
+			return err
+		}
+		memberJoinMsg := username + " has joined your group '" + group.Name + "'"
+		for _, existingMember := range existingMembers {
+			// Don't notify the organizer or whoever approved the request
+			if existingMember.Username != group.OrganiserID && existingMember.Username != requester {
+				if err := createNotification(tx, existingMember.Username, "member_joined", memberJoinMsg, group.ID); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Send email notification to the approved user; people accepting an invitation already know
+	if event.Via == services.ApprovedByInvitation {
+		return nil
+	}
+	return queueAccountEmail(tx, username, func(emailService *services.EmailService, account models.Account) error {
+		return emailService.SendJoinApprovalEmail(account.Email, username, group.Name, group.ApprovalMessage)
+	})
+}
+
+// onMemberApproved logs an approved member and mirrors it to the group's chat integrations, once
+// the approval has committed. It subscribes to MemberApproved.
+func onMemberApproved(event services.MemberApproved) {
+	group, username := event.Group, event.Username
+	activity := "join_group_approved"
+	if event.Via == services.ApprovedByInvitation {
+		activity = "accept_invite"
+	}
+	if err := LogActivity(username, activity, group.ID); err != nil {
+		log.Printf("Warning: Failed to log approve join activity: %v", err)
+	}
+
+	msg := event.Notice
+	if msg == "" {
+		msg = username + " was approved to join '" + group.Name + "'"
+	}
+	mirrorToIntegrations(group.ID, services.IntegrationEventApproval, msg)
+}
+
+// queueRejectionNotice notifies the user of a rejected join request through the rejection's transaction
+func queueRejectionNotice(tx *gorm.DB, group models.Group, username, reason string) error {
 	// Notify user
 	msg := "Your request to join group '" + group.Name + "' was rejected"
-	if err := createNotification(db, username, "join_rejected", msg, groupID); err != nil {
-		log.Printf("Warning: Failed to create rejection notification: %v", err)
+	if reason != "" {
+		msg += ". Reason: " + reason
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Member rejected"})
+	if group.RejectionMessage != "" {
+		msg += ". Message from the organizer: " + group.RejectionMessage
+	}
+	return createNotification(tx, username, "join_rejected", msg, group.ID)
 }
 
 // GetGroupByID handles fetching a single group's details by ID
 func GetGroupByID(c *gin.Context) {
 	groupID := c.Param("group_id")
-	db := database.GetDB()
+	requester := c.GetString("username") // Empty for anonymous visitors
+	db := database.GetDBWithContext(c.Request.Context())
 
 	var group models.Group
 	// Preload organiser and members
@@ -886,6 +1845,7 @@ func GetGroupByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
 		return
 	}
+	services.TrackEvent(models.AnalyticsGroupViewed, requester, group.ID, "")
 
 	// Fetch organiser info
 	var organiser models.Account
@@ -895,6 +1855,33 @@ func GetGroupByID(c *gin.Context) {
 		return
 	}
 
+	// Fetch approved member profiles in one query; full names are hidden unless the member allows it
+	type memberProfile struct {
+		Username   string    `json:"username"`
+		FullName   string    `json:"full_name,omitempty"`
+		AvatarURL  string    `json:"avatar_url"`
+		Rating     float64   `json:"rating"`
+		GuestCount int       `json:"guest_count"`
+		JoinedAt   time.Time `json:"joined_at"`
+	}
+	approvedMembers := []memberProfile{}
+	if err := db.Model(&models.GroupMember{}).
+		Select("group_member.username, CASE WHEN account.show_full_name THEN account.full_name ELSE '' END AS full_name, account.avatar_url, account.rating, group_member.guest_count, group_member.joined_at").
+		Joins("JOIN account ON account.username = group_member.username").
+		Where("group_member.group_id = ? AND group_member.status = ?", groupID, "approved").
+		Order("group_member.joined_at ASC").
+		Scan(&approvedMembers).Error; err != nil {
+		log.Printf("Error: Failed to fetch member profiles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch member profiles"})
+		return
+	}
+
+	// Spots taken by approved members and their guests
+	spotsTaken := 0
+	for _, member := range approvedMembers {
+		spotsTaken += 1 + member.GuestCount
+	}
+
 	// Create frontend-friendly response
 	response := gin.H{
 		"id":                 group.ID,
@@ -902,14 +1889,24 @@ func GetGroupByID(c *gin.Context) {
 		"date_time":          group.DateTime,
 		"location":           group.Location,
 		"cost":               group.Cost,
+		"cost_details":       group.CostDetails,
 		"skill_level":        group.SkillLevel,
 		"activity_type":      group.ActivityType,
+		"activity_display":   services.ActivityDisplayFor(group.ActivityType),
 		"max_members":        group.MaxMembers,
+		"max_guests":         group.MaxGuestsPerMember,
+		"spots_taken":        spotsTaken,
+		"cutoff_minutes":     int(services.GroupCutoff(group) / time.Minute),
+		"cutoff_at":          services.GroupCutoffTime(group),
 		"description":        group.Description,
 		"organizer_username": group.OrganiserID,
 		"members":            group.Members,
+		"approved_members":   approvedMembers,
 		"created_at":         group.CreatedAt,
 		"updated_at":         group.UpdatedAt,
+		"status":             group.Status,
+		"visibility":         group.Visibility,
+		"tags":               loadGroupTags(db, []string{group.ID})[group.ID],
 		"organizer": gin.H{
 			"username":   organiser.Username,
 			"rating":     organiser.Rating,
@@ -918,16 +1915,51 @@ func GetGroupByID(c *gin.Context) {
 		},
 	}
 
+	// Private groups only show who's going to people who are going
+	requesterUser := groupUser(db, group, requester)
+	if !policy.CanViewMembers(requesterUser, group) {
+		response["members"] = []models.GroupMember{}
+		response["approved_members"] = []memberProfile{}
+	}
+
+	// Extra meeting points, in the order the organizer listed them
+	response["secondary_locations"] = group.SecondaryLocations
+
+	// Organizer notes are for people going only
+	if policy.CanViewMemberNotes(requesterUser, group) {
+		response["member_notes"] = group.MemberNotes
+	}
+
+	if group.IsCancelled() {
+		response["cancelled_at"] = group.CancelledAt
+		response["cancellation_reason"] = group.CancellationReason
+	}
+
+	// Include the weather forecast for upcoming events within the forecast horizon
+	if !group.IsCancelled() && time.Until(group.DateTime) > 0 && time.Until(group.DateTime) <= services.WeatherForecastHorizon {
+		weatherService := services.NewWeatherService()
+		forecast, err := weatherService.GetForecast(group.Location.Latitude, group.Location.Longitude, group.DateTime)
+		if err != nil {
+			log.Printf("Warning: Failed to fetch weather for group %s: %v", group.ID, err)
+		} else {
+			response["weather"] = gin.H{
+				"forecast": forecast,
+				"warning":  services.BadWeatherWarning(forecast),
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
-// RemoveMember handles the removal of a member from a group by the organizer
+// RemoveMember handles the removal of a member from a group by the organizer or a co-organizer.
+// Co-organizers can't be removed by other co-organizers; the organizer demotes them first.
 func RemoveMember(c *gin.Context) {
 	groupID := c.Param("group_id")
 	memberUsername := c.Param("username")
 	organizerUsername := c.GetString("username")
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Check if group exists
 	var group models.Group
@@ -937,24 +1969,22 @@ func RemoveMember(c *gin.Context) {
 		return
 	}
 
-	// Check if requester is the organizer
-	if group.OrganiserID != organizerUsername {
-		log.Printf("Error: User %s attempted to remove member from group %s but is not the organizer", organizerUsername, groupID)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can remove members"})
+	// Check if requester is the organizer or a co-organizer
+	if !policy.CanEditGroup(groupUser(db, group, organizerUsername), group) {
+		log.Printf("Error: User %s attempted to remove member from group %s but is not an organizer", organizerUsername, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer or a co-organizer can remove members"})
 		return
 	}
 
 	// Prevent removal if event has already passed
-	if time.Now().After(group.DateTime) {
+	if group.HasEnded(time.Now()) {
 		log.Printf("Error: Attempted to remove member after event has ended")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot remove members after the event has ended"})
 		return
 	}
 
-	// Prevent removal if event is happening soon (within 1 hour)
-	if time.Until(group.DateTime) < time.Hour {
-		log.Printf("Error: Attempted to remove member too close to event time")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot remove members within 1 hour of the event"})
+	// Prevent changes once the group's cutoff window has started
+	if !enforceCutoff(c, group, "remove members") {
 		return
 	}
 
@@ -967,49 +1997,126 @@ func RemoveMember(c *gin.Context) {
 		return
 	}
 
-	// Don't allow removing the organizer (themselves)
-	if memberUsername == organizerUsername {
-		log.Printf("Error: Organizer attempted to remove themselves")
+	// Don't allow removing the organizer, or a co-organizer unless the organizer asks
+	if memberUsername == group.OrganiserID {
+		log.Printf("Error: %s attempted to remove the organizer", organizerUsername)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Organizer cannot be removed"})
 		return
 	}
+	if member.Role == models.RoleCoOrganiser && !policy.IsOrganizer(policy.UserNamed(organizerUsername), group) {
+		log.Printf("Error: Co-organizer %s attempted to remove co-organizer %s", organizerUsername, memberUsername)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can remove a co-organizer"})
+		return
+	}
+
+	// Delete the member record with its offense, and notify and email the removed member.
+	// Removals count towards a join cooldown for repeat offenders.
+	var ban *models.JoinBan
+	if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&member).Error; err != nil {
+			return err
+		}
 
-	// Delete the member record
-	if err := db.Delete(&member).Error; err != nil {
+		var err error
+		if ban, err = services.NewPenaltyService().WithTx(tx).RecordOffense(memberUsername, groupID, models.OffenseRemoved); err != nil {
+			return err
+		}
+		if ban != nil {
+			msg := fmt.Sprintf("You can't join new groups until %s due to repeated removals or no-shows", ban.ExpiresAt.Format("Jan 2, 2006"))
+			if err := createNotification(tx, memberUsername, "join_cooldown", msg, groupID); err != nil {
+				return err
+			}
+		}
+
+		msg := fmt.Sprintf("You have been removed from group '%s'", group.Name)
+		if err := createNotification(tx, memberUsername, "removed_from_group", msg, groupID); err != nil {
+			return err
+		}
+		return queueAccountEmail(tx, memberUsername, func(emailService *services.EmailService, account models.Account) error {
+			return emailService.SendMemberRemovalEmail(account.Email, account.Username, group.Name)
+		})
+	}); err != nil {
 		log.Printf("Error: Failed to remove member: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
 		return
 	}
+	if ban != nil {
+		log.Printf("Applied join cooldown to %s until %v", memberUsername, ban.ExpiresAt)
+	}
 
-	// Create notification for the removed member
-	notification := models.Notification{
-		RecipientUsername: memberUsername,
-		Type:              "removed_from_group",
-		Message:           fmt.Sprintf("You have been removed from group '%s'", group.Name),
-		GroupID:           groupID,
-		CreatedAt:         time.Now(),
-		Read:              false,
+	// Log the activity
+	if err := LogActivity(organizerUsername, "remove_member", groupID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
 	}
 
-	if err := db.Create(&notification).Error; err != nil {
-		log.Printf("Warning: Failed to create notification: %v", err)
+	// Offer the freed spot to the waitlist
+	go services.NewWaitlistService().OnCapacityAvailable(groupID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// UpdateMemberRole promotes an approved member to co-organiser or demotes them back (owner only)
+func UpdateMemberRole(c *gin.Context) {
+	groupID := c.Param("group_id")
+	memberUsername := c.Param("username")
+	requester := c.GetString("username")
+
+	var request models.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid role input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
 	}
 
-	// Get member's email for notification
-	var account models.Account
-	if err := db.Where("username = ?", memberUsername).First(&account).Error; err == nil {
-		emailService := services.NewEmailService()
-		go func() {
-			if err := emailService.SendMemberRemovalEmail(account.Email, account.Username, group.Name); err != nil {
-				log.Printf("Warning: Failed to send email to removed member: %v", err)
-			}
-		}()
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
 	}
 
-	// Log the activity
-	if err := LogActivity(organizerUsername, "remove_member", groupID); err != nil {
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: User %s attempted to change roles in group %s but is not the organizer", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can change member roles"})
+		return
+	}
+	if memberUsername == group.OrganiserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The organizer's role cannot be changed"})
+		return
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?",
+		groupID, memberUsername, "approved").First(&member).Error; err != nil {
+		log.Printf("Error: Member not found or not approved: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found or not approved"})
+		return
+	}
+
+	if member.Role == request.Role {
+		c.JSON(http.StatusOK, member)
+		return
+	}
+
+	if err := db.Model(&member).Update("role", request.Role).Error; err != nil {
+		log.Printf("Error: Failed to update role for %s: %v", memberUsername, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member role"})
+		return
+	}
+
+	if err := LogActivity(requester, "change_member_role", groupID); err != nil {
 		log.Printf("Warning: Failed to log activity: %v", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+	msg := fmt.Sprintf("You are now a co-organiser of '%s' and can manage members and edit the group", group.Name)
+	if request.Role == models.RoleMember {
+		msg = fmt.Sprintf("You are no longer a co-organiser of '%s'", group.Name)
+	}
+	if err := createNotification(db, memberUsername, "role_changed", msg, groupID); err != nil {
+		log.Printf("Warning: Failed to create role notification: %v", err)
+	}
+
+	c.JSON(http.StatusOK, member)
 }
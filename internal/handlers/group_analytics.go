@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetGroupAnalytics returns a group's daily views, unique viewers and join clicks over the last
+// ?days= (default 30, at most 90) for its organizers. Counts come from the hourly rollup, so the last
+// hour may be missing.
+func GetGroupAnalytics(c *gin.Context) {
+	days := 30
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 90 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = parsed
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "view analytics")
+	if !ok {
+		return
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+	daily, err := services.GetGroupDailyAnalytics(db, group.ID, since)
+	if err != nil {
+		log.Printf("Error: Failed to fetch analytics for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch group analytics"})
+		return
+	}
+
+	var views, joinClicks int64
+	for _, day := range daily {
+		views += day.Views
+		joinClicks += day.JoinClicks
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_id":    group.ID,
+		"days":        days,
+		"daily":       daily,
+		"views":       views,
+		"join_clicks": joinClicks,
+		// Where the join clicks ended up now
+		"approved_members": group.ApprovedMemberCount,
+		"pending_requests": group.PendingCount,
+	})
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/models"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Listing page size defaults and bounds
+const (
+	defaultGroupPageSize = 10
+	maxGroupPageSize     = 100
+)
+
+// Which groups a listing covers by event time
+const (
+	groupTimeframeUpcoming = "upcoming" // Active groups that haven't started (the default)
+	groupTimeframePast     = "past"     // Groups that have started, including archived ones (?status=past)
+	groupTimeframeAll      = "all"      // Both (?include_past=true)
+)
+
+// groupSortColumns are the sort_by values listings accept; distance needs the user's location
+var groupSortColumns = map[string]bool{
+	"date_time": true, "name": true, "cost": true,
+	"skill_level": true, "activity_type": true, "max_members": true,
+	"created_at": true, "updated_at": true,
+	"distance": true,
+}
+
+// distanceSQL is the haversine distance in kilometers from a (lat, lng, lat) point to the group's location
+const distanceSQL = `6371 * acos(
+	cos(radians(?)) *
+	cos(radians(CAST(location->>'latitude' AS FLOAT))) *
+	cos(radians(CAST(location->>'longitude' AS FLOAT)) - radians(?)) +
+	sin(radians(?)) *
+	sin(radians(CAST(location->>'latitude' AS FLOAT)))
+)`
+
+// GroupFilter holds the filter, sort and pagination parameters of a group listing. Parse reads them from
+// the query string and Apply adds them to a query over the "group" table (or a view aliased as "group").
+// Invalid values are ignored rather than rejected, so old clients keep getting results.
+type GroupFilter struct {
+	Search string
+
+	// upcoming, past or all; see the groupTimeframe constants
+	Timeframe string
+
+	// Distance from the user; RadiusKm is 0 when results aren't limited to a radius
+	UserLat, UserLng *float64
+	RadiusKm         float64
+
+	ActivityType string
+	SkillLevel   string
+	City         string
+	Tags         []string // Groups must have every tag
+	MinPrice     *float64
+	MaxPrice     *float64
+	DateFrom     string
+	DateTo       string
+	MinMembers   *int
+	MaxMembers   *int
+
+	SortBy    string
+	SortOrder string
+	// Whether sort_by was given; search results keep their ranking otherwise
+	SortExplicit bool
+
+	Limit  int
+	Offset int
+
+	// Group IDs in search ranking order, set once the search term has been run. Results are limited to
+	// these groups.
+	SearchResultIDs []string
+}
+
+// parseOptionalFloat returns a pointer to the parsed value, or nil when s is empty or invalid
+func parseOptionalFloat(s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// parseOptionalInt returns a pointer to the parsed value, or nil when s is empty or invalid
+func parseOptionalInt(s string) *int {
+	if s == "" {
+		return nil
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// Parse reads the listing parameters from the request's query string
+func (f *GroupFilter) Parse(c *gin.Context) {
+	f.Search = c.Query("search")
+
+	f.Timeframe = groupTimeframeUpcoming
+	if c.Query("status") == groupTimeframePast {
+		f.Timeframe = groupTimeframePast
+	} else if c.Query("include_past") == "true" {
+		f.Timeframe = groupTimeframeAll
+	}
+
+	if lat, lng := parseOptionalFloat(c.Query("user_lat")), parseOptionalFloat(c.Query("user_lng")); lat != nil && lng != nil {
+		f.UserLat, f.UserLng = lat, lng
+		if radiusStr := c.Query("radius"); radiusStr != "" {
+			if radius := parseOptionalFloat(radiusStr); radius != nil && *radius > 0 {
+				f.RadiusKm = *radius
+			} else {
+				log.Printf("Warning: Invalid radius parameter '%s', ignoring radius filter", radiusStr)
+			}
+		}
+	}
+
+	f.ActivityType = c.Query("activity_type")
+	f.SkillLevel = c.Query("skill_level")
+	f.City = c.Query("city")
+	if tagsParam := c.Query("tags"); tagsParam != "" {
+		// Comma-separated
+		f.Tags = normalizeTags(strings.Split(tagsParam, ","))
+	}
+	f.MinPrice = parseOptionalFloat(c.Query("min_price"))
+	f.MaxPrice = parseOptionalFloat(c.Query("max_price"))
+	f.DateFrom = c.Query("date_from")
+	f.DateTo = c.Query("date_to")
+	f.MinMembers = parseOptionalInt(c.Query("min_members"))
+	f.MaxMembers = parseOptionalInt(c.Query("max_members"))
+
+	f.SortExplicit = c.Query("sort_by") != ""
+	f.SortBy = c.DefaultQuery("sort_by", "date_time")
+	if !groupSortColumns[f.SortBy] {
+		f.SortBy = "date_time" // Default to safe value if invalid
+	}
+	// The archive is browsed most recent first
+	defaultOrder := "asc"
+	if f.Timeframe == groupTimeframePast {
+		defaultOrder = "desc"
+	}
+	f.SortOrder = c.DefaultQuery("sort_order", defaultOrder)
+	if f.SortOrder != "asc" && f.SortOrder != "desc" {
+		f.SortOrder = defaultOrder
+	}
+	if f.SortBy == "distance" && !f.HasUserLocation() {
+		log.Printf("Warning: Distance sort requested but no user location provided")
+		f.SortBy, f.SortOrder = "date_time", defaultOrder
+	}
+
+	f.Limit = defaultGroupPageSize
+	if limit := parseOptionalInt(c.Query("limit")); limit != nil && *limit > 0 {
+		f.Limit = *limit
+	}
+	if f.Limit > maxGroupPageSize {
+		f.Limit = maxGroupPageSize
+	}
+	if offset := parseOptionalInt(c.Query("offset")); offset != nil && *offset > 0 {
+		f.Offset = *offset
+	}
+}
+
+// HasUserLocation reports whether results are measured from the user's location
+func (f GroupFilter) HasUserLocation() bool {
+	return f.UserLat != nil && f.UserLng != nil
+}
+
+// HasAttributeFilters reports whether any filter on the group's own details (activity type, skill
+// level, city, tags, price, dates or size) is set
+func (f GroupFilter) HasAttributeFilters() bool {
+	return f.ActivityType != "" || f.SkillLevel != "" || f.City != "" || len(f.Tags) > 0 ||
+		f.MinPrice != nil || f.MaxPrice != nil || f.DateFrom != "" || f.DateTo != "" ||
+		f.MinMembers != nil || f.MaxMembers != nil
+}
+
+// SearchLimit is how many search results to fetch before filtering, enough to fill a page after
+// the other filters drop some
+func (f GroupFilter) SearchLimit() int {
+	if limit := f.Limit * 10; limit < 1000 {
+		return limit
+	}
+	return 1000
+}
+
+// ApplyFilters adds the distance column and the WHERE conditions, without sorting or pagination
+func (f GroupFilter) ApplyFilters(query *gorm.DB) *gorm.DB {
+	switch f.Timeframe {
+	case groupTimeframePast:
+		query = query.Where(`"group".date_time <= NOW() AND "group".status IN ?`, models.GroupStatusesHeld)
+	case groupTimeframeAll:
+		query = query.Where(`"group".status IN ?`, models.GroupStatusesHeld)
+	default:
+		query = query.Where(`"group".date_time > NOW() AND "group".status = ?`, models.GroupStatusActive)
+	}
+	if f.HasUserLocation() {
+		lat, lng := *f.UserLat, *f.UserLng
+		query = query.Select(`"group".*, ROUND((`+distanceSQL+`)::numeric, 2) AS distance_km`, lat, lng, lat)
+		if f.RadiusKm > 0 {
+			query = query.Where(`(`+distanceSQL+`) <= ?`, lat, lng, lat, f.RadiusKm)
+		}
+	}
+
+	if f.SearchResultIDs != nil {
+		query = query.Where(`"group".id IN ?`, f.SearchResultIDs)
+	}
+	if f.ActivityType != "" {
+		query = query.Where("activity_type = ?", f.ActivityType)
+	}
+	if f.SkillLevel != "" {
+		query = query.Where("skill_level = ?", f.SkillLevel)
+	}
+	if f.City != "" {
+		query = query.Where("LOWER(city) = LOWER(?)", f.City)
+	}
+	if len(f.Tags) > 0 {
+		query = query.Where(`"group".id IN (
+			SELECT group_tag.group_id FROM group_tag JOIN tag ON tag.id = group_tag.tag_id
+			WHERE tag.name IN ? GROUP BY group_tag.group_id HAVING COUNT(*) = ?
+		)`, f.Tags, len(f.Tags))
+	}
+	if f.MinPrice != nil {
+		query = query.Where("cost >= ?", *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		query = query.Where("cost <= ?", *f.MaxPrice)
+	}
+	if f.DateFrom != "" {
+		query = query.Where("date_time >= ?", f.DateFrom)
+	}
+	if f.DateTo != "" {
+		query = query.Where("date_time <= ?", f.DateTo)
+	}
+	if f.MinMembers != nil {
+		query = query.Where("max_members >= ?", *f.MinMembers)
+	}
+	if f.MaxMembers != nil {
+		query = query.Where("max_members <= ?", *f.MaxMembers)
+	}
+	return query
+}
+
+// Apply adds the filters, sorting and pagination to query
+func (f GroupFilter) Apply(query *gorm.DB) *gorm.DB {
+	query = f.ApplyFilters(query)
+
+	switch {
+	case f.SortBy == "distance":
+		query = query.Order(fmt.Sprintf("distance_km %s", f.SortOrder))
+	case len(f.SearchResultIDs) > 0 && !f.SortExplicit:
+		// Preserve search ranking by ordering by the position in SearchResultIDs
+		orderClause := `CASE "group".id`
+		for i, id := range f.SearchResultIDs {
+			orderClause += fmt.Sprintf(" WHEN '%s' THEN %d", id, i)
+		}
+		orderClause += " END"
+		query = query.Order(orderClause)
+	case f.HasUserLocation():
+		// Nearest first when the user's location is known, then the requested sort
+		query = query.Order(fmt.Sprintf("distance_km asc, %s %s", f.SortBy, f.SortOrder))
+	default:
+		query = query.Order(fmt.Sprintf("%s %s", f.SortBy, f.SortOrder))
+	}
+
+	return query.Limit(f.Limit).Offset(f.Offset)
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"groops/internal/models"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// parseFilter runs GroupFilter.Parse on a request with the given query string
+func parseFilter(t *testing.T, query string) GroupFilter {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/groups?"+query, nil)
+
+	var filter GroupFilter
+	filter.Parse(c)
+	return filter
+}
+
+// filterSQL returns the SQL GroupFilter.Apply generates for a group listing, without a database
+func filterSQL(t *testing.T, filter GroupFilter) string {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost", PreferSimpleProtocol: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		NamingStrategy:         schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open dry-run database: %v", err)
+	}
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var groups []models.Group
+		return filter.Apply(tx.Model(&models.Group{})).Find(&groups)
+	})
+}
+
+func TestGroupFilterParseSort(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		sortBy    string
+		sortOrder string
+	}{
+		{"defaults", "", "date_time", "asc"},
+		{"allowed column", "sort_by=name&sort_order=desc", "name", "desc"},
+		{"unknown column", "sort_by=password", "date_time", "asc"},
+		{"injected column", "sort_by=name%3BDROP%20TABLE%20account", "date_time", "asc"},
+		{"invalid order", "sort_by=cost&sort_order=sideways", "cost", "asc"},
+		{"distance without location", "sort_by=distance&sort_order=desc", "date_time", "asc"},
+		{"distance with location", "sort_by=distance&user_lat=12.97&user_lng=77.59", "distance", "asc"},
+		{"distance with half a location", "sort_by=distance&user_lat=12.97", "date_time", "asc"},
+		{"past defaults to most recent first", "status=past", "date_time", "desc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := parseFilter(t, tt.query)
+			if filter.SortBy != tt.sortBy || filter.SortOrder != tt.sortOrder {
+				t.Errorf("sort = %s %s, want %s %s", filter.SortBy, filter.SortOrder, tt.sortBy, tt.sortOrder)
+			}
+		})
+	}
+}
+
+func TestGroupFilterParsePagination(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		limit  int
+		offset int
+	}{
+		{"defaults", "", defaultGroupPageSize, 0},
+		{"given", "limit=20&offset=40", 20, 40},
+		{"limit capped", "limit=500", maxGroupPageSize, 0},
+		{"zero limit", "limit=0", defaultGroupPageSize, 0},
+		{"negative values", "limit=-5&offset=-10", defaultGroupPageSize, 0},
+		{"not numbers", "limit=ten&offset=abc", defaultGroupPageSize, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := parseFilter(t, tt.query)
+			if filter.Limit != tt.limit || filter.Offset != tt.offset {
+				t.Errorf("limit, offset = %d, %d, want %d, %d", filter.Limit, filter.Offset, tt.limit, tt.offset)
+			}
+		})
+	}
+}
+
+func TestGroupFilterParseTimeframe(t *testing.T) {
+	tests := []struct {
+		query     string
+		timeframe string
+	}{
+		{"", groupTimeframeUpcoming},
+		{"status=past", groupTimeframePast},
+		{"include_past=true", groupTimeframeAll},
+		{"include_past=false", groupTimeframeUpcoming},
+		{"status=past&include_past=true", groupTimeframePast},
+		{"status=bogus", groupTimeframeUpcoming},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if filter := parseFilter(t, tt.query); filter.Timeframe != tt.timeframe {
+				t.Errorf("timeframe = %q, want %q", filter.Timeframe, tt.timeframe)
+			}
+		})
+	}
+}
+
+func TestGroupFilterParseLocation(t *testing.T) {
+	filter := parseFilter(t, "user_lat=12.97&user_lng=77.59&radius=5")
+	if !filter.HasUserLocation() || *filter.UserLat != 12.97 || *filter.UserLng != 77.59 || filter.RadiusKm != 5 {
+		t.Errorf("location = %v, %v within %v, want 12.97, 77.59 within 5", filter.UserLat, filter.UserLng, filter.RadiusKm)
+	}
+
+	for _, query := range []string{"user_lat=12.97&user_lng=77.59&radius=-1", "user_lat=12.97&user_lng=77.59&radius=far"} {
+		if filter := parseFilter(t, query); filter.RadiusKm != 0 {
+			t.Errorf("%s: radius = %v, want it ignored", query, filter.RadiusKm)
+		}
+	}
+	for _, query := range []string{"user_lat=12.97", "user_lat=north&user_lng=77.59", "radius=5"} {
+		if filter := parseFilter(t, query); filter.HasUserLocation() || filter.RadiusKm != 0 {
+			t.Errorf("%s: expected no location filter", query)
+		}
+	}
+}
+
+func TestGroupFilterParseFilters(t *testing.T) {
+	filter := parseFilter(t, "activity_type=football&skill_level=beginner&city=Pune&tags=Outdoor,%20casual&min_price=0&max_price=20.5&min_members=4&max_members=bad")
+	if filter.ActivityType != "football" || filter.SkillLevel != "beginner" || filter.City != "Pune" {
+		t.Errorf("attribute filters = %q, %q, %q", filter.ActivityType, filter.SkillLevel, filter.City)
+	}
+	if len(filter.Tags) != 2 {
+		t.Errorf("tags = %v, want two", filter.Tags)
+	}
+	if filter.MinPrice == nil || *filter.MinPrice != 0 || filter.MaxPrice == nil || *filter.MaxPrice != 20.5 {
+		t.Errorf("price range = %v to %v, want 0 to 20.5", filter.MinPrice, filter.MaxPrice)
+	}
+	if filter.MinMembers == nil || *filter.MinMembers != 4 || filter.MaxMembers != nil {
+		t.Errorf("members range = %v to %v, want 4 to unset", filter.MinMembers, filter.MaxMembers)
+	}
+	if !filter.HasAttributeFilters() {
+		t.Error("HasAttributeFilters = false, want true")
+	}
+	if parseFilter(t, "sort_by=name&limit=5&user_lat=1&user_lng=2").HasAttributeFilters() {
+		t.Error("HasAttributeFilters = true for sorting, paging and location only")
+	}
+}
+
+func TestGroupFilterApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "upcoming by default",
+			query:    "",
+			contains: []string{`"group".date_time > NOW() AND "group".status = 'active'`, "ORDER BY date_time asc", "LIMIT 10"},
+			excludes: []string{"distance_km", "OFFSET"},
+		},
+		{
+			name:     "past",
+			query:    "status=past",
+			contains: []string{`"group".date_time <= NOW() AND "group".status IN ('active','archived')`, "ORDER BY date_time desc"},
+		},
+		{
+			name:     "all",
+			query:    "include_past=true",
+			contains: []string{`"group".status IN ('active','archived')`},
+			excludes: []string{"NOW()"},
+		},
+		{
+			name:     "nearest first within a radius",
+			query:    "user_lat=12.5&user_lng=77.5&radius=10&sort_by=cost&sort_order=desc",
+			contains: []string{`"group".*, ROUND((6371 * acos(`, "AS distance_km", ") <= 10", "ORDER BY distance_km asc, cost desc"},
+		},
+		{
+			name:     "distance sort",
+			query:    "user_lat=12.5&user_lng=77.5&sort_by=distance&sort_order=desc",
+			contains: []string{"ORDER BY distance_km desc"},
+		},
+		{
+			name:  "attribute filters",
+			query: "activity_type=football&skill_level=beginner&city=Pune&min_price=5&max_price=20&date_from=2026-01-01&date_to=2026-02-01&min_members=4&max_members=12",
+			contains: []string{
+				"activity_type = 'football'", "skill_level = 'beginner'", "LOWER(city) = LOWER('Pune')",
+				"cost >= 5", "cost <= 20", "date_time >= '2026-01-01'", "date_time <= '2026-02-01'",
+				"max_members >= 4", "max_members <= 12",
+			},
+		},
+		{
+			name:     "every tag required",
+			query:    "tags=outdoor,casual",
+			contains: []string{"WHERE tag.name IN ('outdoor','casual')", "HAVING COUNT(*) = 2"},
+		},
+		{
+			name:     "page",
+			query:    "limit=25&offset=50",
+			contains: []string{"LIMIT 25 OFFSET 50"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := filterSQL(t, parseFilter(t, tt.query))
+			for _, want := range tt.contains {
+				if !strings.Contains(sql, want) {
+					t.Errorf("SQL missing %q:\n%s", want, sql)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(sql, unwanted) {
+					t.Errorf("SQL unexpectedly contains %q:\n%s", unwanted, sql)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupFilterApplySearchRanking(t *testing.T) {
+	filter := parseFilter(t, "search=football")
+	filter.SearchResultIDs = []string{"g2", "g1"}
+	sql := filterSQL(t, filter)
+	for _, want := range []string{`"group".id IN ('g2','g1')`, `ORDER BY CASE "group".id WHEN 'g2' THEN 0 WHEN 'g1' THEN 1 END`} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL missing %q:\n%s", want, sql)
+		}
+	}
+
+	// An explicit sort replaces the ranking
+	filter = parseFilter(t, "search=football&sort_by=name")
+	filter.SearchResultIDs = []string{"g2", "g1"}
+	if sql := filterSQL(t, filter); !strings.Contains(sql, "ORDER BY name asc") || strings.Contains(sql, "CASE") {
+		t.Errorf("expected name sort instead of ranking:\n%s", sql)
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UpdateGuestCount sets how many guests an approved member is bringing, if the organizer allows guests
+func UpdateGuestCount(c *gin.Context) {
+	groupID := c.Param("group_id")
+	username := c.GetString("username")
+
+	var request models.UpdateGuestCountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid guest count input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+	guestCount := *request.GuestCount
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	// Guest counts lock at the same cutoff as leaving the group
+	if !enforceCutoff(c, group, "change guests") {
+		return
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?", groupID, username, "approved").First(&member).Error; err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only approved members can bring guests"})
+		return
+	}
+
+	if guestCount > 0 && group.MaxGuestsPerMember == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "The organizer does not allow guests for this group"})
+		return
+	}
+	if guestCount > group.MaxGuestsPerMember && guestCount > member.GuestCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("You can bring at most %d guests", group.MaxGuestsPerMember)})
+		return
+	}
+
+	// Extra guests need free spots, including any held for waitlist offers
+	if added := guestCount - member.GuestCount; added > 0 {
+		heldSpots, err := services.NewWaitlistService().CountActiveOffers(groupID, "")
+		if err != nil {
+			log.Printf("Warning: Failed to count waitlist offers: %v", err)
+		}
+		if group.OccupiedSpots()+int(heldSpots)+added > group.MaxMembers {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not enough spots left for your guests"})
+			return
+		}
+	}
+
+	previousGuests := member.GuestCount
+	if err := services.WithMemberCounts(db, groupID, func(tx *gorm.DB) error {
+		return tx.Model(&member).Update("guest_count", guestCount).Error
+	}); err != nil {
+		log.Printf("Error: Failed to update guest count: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update guests"})
+		return
+	}
+
+	if guestCount != previousGuests {
+		msg := fmt.Sprintf("%s is now bringing %d guest(s) to '%s'", username, guestCount, group.Name)
+		if err := createNotification(db, group.OrganiserID, "guest_count_changed", msg, groupID); err != nil {
+			log.Printf("Warning: Failed to create guest notification: %v", err)
+		}
+	}
+
+	// Fewer guests frees spots for the waitlist
+	if guestCount < previousGuests {
+		go services.NewWaitlistService().OnCapacityAvailable(groupID)
+	}
+
+	c.JSON(http.StatusOK, member)
+}
@@ -4,6 +4,7 @@ import (
 	"groops/internal/auth"
 	"groops/internal/database"
 	"groops/internal/models"
+	"groops/internal/services"
 	"log"
 	"net/http"
 	"strings"
@@ -98,6 +99,29 @@ func GetStats(c *gin.Context) {
 	})
 }
 
+// GetStatsBreakdown returns how many groups and members each city has, by activity type, for the
+// marketing site. ?city= narrows it to one city. The counts are cached, so they can lag a few minutes.
+func GetStatsBreakdown(c *gin.Context) {
+	breakdown, err := services.GetStatsService().GetBreakdown()
+	if err != nil {
+		log.Printf("Error: Failed to compute stats breakdown: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statistics"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300") // Cache for 5 minutes
+	if city := strings.TrimSpace(c.Query("city")); city != "" {
+		stats, ok := breakdown.FindCity(city)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No groups found in this city"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"cities": []services.CityStats{stats}, "generated_at": breakdown.GeneratedAt})
+		return
+	}
+	c.JSON(http.StatusOK, breakdown)
+}
+
 // AdminMessageHandler returns a hardcoded admin message
 func AdminMessageHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxImportFileBytes bounds the size of an uploaded CSV or iCal file
+const maxImportFileBytes = 1 << 20 // 1MB
+
+// importedGroup pairs a parsed event with the location it geocoded to
+type importedGroup struct {
+	services.ImportedEvent
+	Location *models.Location `json:"location,omitempty"`
+}
+
+// ImportGroups bulk-creates groups from an uploaded CSV or iCal (.ics) file.
+// With dry_run=true nothing is created and every row is returned with its validation result;
+// otherwise the import is all-or-nothing and fails if any row has errors.
+func ImportGroups(c *gin.Context) {
+	organizerUsername := c.GetString("username")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload a CSV or iCal file in the 'file' field"})
+		return
+	}
+	if fileHeader.Size > maxImportFileBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import files must be 1MB or smaller"})
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".csv":
+			format = services.ImportFormatCSV
+		case ".ics", ".ical":
+			format = services.ImportFormatICal
+		}
+	}
+	if format != services.ImportFormatCSV && format != services.ImportFormatICal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported file type - use .csv or .ics"})
+		return
+	}
+
+	// Times without an explicit zone are read in the organizer's timezone
+	loc := time.UTC
+	if tz := c.PostForm("timezone"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone: " + tz})
+			return
+		}
+	}
+	dryRun := c.PostForm("dry_run") == "true" || c.Query("dry_run") == "true"
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("Error: Failed to open import file: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read import file"})
+		return
+	}
+	defer file.Close()
+
+	events, err := services.ParseImportFile(format, file, loc)
+	if err != nil {
+		log.Printf("Error: Failed to parse %s import for %s: %v", format, organizerUsername, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var organizer models.Account
+	if err := db.Where("username = ?", organizerUsername).First(&organizer).Error; err != nil {
+		log.Printf("Error: Organizer not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organizer not found"})
+		return
+	}
+
+	limits := services.LoadGroupLimits()
+	nameValidator := services.NewNameValidationService()
+	rows := make([]importedGroup, len(events))
+	valid := 0
+	for i, event := range events {
+		rows[i] = validateImportedEvent(c.Request.Context(), event, organizer, limits, nameValidator)
+		if len(rows[i].Errors) == 0 {
+			valid++
+		}
+	}
+
+	// The organizer's active group cap applies to the whole batch
+	var activeGroups int64
+	if err := db.Model(&models.Group{}).
+		Where("organiser_id = ? AND date_time > NOW() AND status = ?", organizerUsername, models.GroupStatusActive).
+		Count(&activeGroups).Error; err != nil {
+		log.Printf("Error: Failed to count active groups: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import groups"})
+		return
+	}
+	var batchError string
+	if int(activeGroups)+len(rows) > limits.MaxActiveGroups {
+		batchError = fmt.Sprintf("You can organize at most %d upcoming groups at a time (you have %d)", limits.MaxActiveGroups, activeGroups)
+	}
+
+	if dryRun || valid < len(rows) || batchError != "" {
+		status := http.StatusOK
+		if !dryRun {
+			status = http.StatusBadRequest
+		}
+		response := gin.H{
+			"dry_run": dryRun,
+			"total":   len(rows),
+			"valid":   valid,
+			"rows":    rows,
+		}
+		if batchError != "" {
+			response["error"] = batchError
+			response["code"] = services.ErrCodeActiveGroupLimit
+		} else if !dryRun {
+			response["error"] = "Some rows have errors - fix them and try again; nothing was imported"
+		}
+		c.JSON(status, response)
+		return
+	}
+
+	// Group IDs normally come from the organizer and creation second, so give each
+	// imported group a sequence suffix to keep a batch from colliding
+	now := time.Now()
+	groups := make([]models.Group, len(rows))
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for i, row := range rows {
+			groups[i] = models.Group{
+				ID:           fmt.Sprintf("%s-%s-%02d", organizerUsername, now.UTC().Format("20060102150405"), i+1),
+				Name:         row.Name,
+				DateTime:     row.DateTime,
+				Location:     *row.Location,
+				City:         row.Location.City,
+				Cost:         row.Cost,
+				SkillLevel:   row.SkillLevel,
+				ActivityType: row.ActivityType,
+				MaxMembers:   row.MaxMembers,
+				Description:  row.Description,
+				OrganiserID:  organizerUsername,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}
+			groups[i].FillCostDetails()
+			if err := tx.Create(&groups[i]).Error; err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			member := models.GroupMember{
+				GroupID:  groups[i].ID,
+				Username: organizerUsername,
+				Status:   "approved",
+				Role:     models.RoleOrganiser,
+			}
+			if err := tx.Create(&member).Error; err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			if err := services.RefreshMemberCounts(tx, groups[i].ID); err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			groups[i].ApprovedMemberCount = 1
+			if err := services.PublishEvent(tx, services.GroupCreated{Group: groups[i], Imported: true}); err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+		}
+		msg := fmt.Sprintf("%d groops were imported successfully and are now open for people to join.", len(groups))
+		return createNotification(tx, organizerUsername, "groups_imported", msg, "")
+	})
+	if err != nil {
+		log.Printf("Error: Failed to import groups for %s: %v", organizerUsername, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import groups"})
+		return
+	}
+
+	for _, group := range groups {
+		services.PublishCommitted(services.GroupCreated{Group: group, Imported: true})
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"imported": len(groups),
+		"groups":   groups,
+	})
+}
+
+// validateImportedEvent applies the same rules as CreateGroup to one imported event and
+// geocodes its address, recording every problem on the row
+func validateImportedEvent(ctx context.Context, event services.ImportedEvent, organizer models.Account, limits services.GroupLimits, nameValidator *services.NameValidationService) importedGroup {
+	row := importedGroup{ImportedEvent: event}
+
+	switch {
+	case row.Name == "":
+		row.AddError("name is required")
+	case len(row.Name) > 100:
+		row.AddError("name must be at most 100 characters")
+	default:
+		if code, msg := nameValidator.ValidateGroupName(row.Name); code != "" {
+			row.AddError("%s", msg)
+		}
+	}
+	if row.Description == "" {
+		row.AddError("description is required")
+	} else if len(row.Description) > 1000 {
+		row.AddError("description must be at most 1000 characters")
+	}
+	if row.ActivityType == "" {
+		row.AddError("activity_type is required")
+	} else if len(row.ActivityType) > 50 {
+		row.AddError("activity_type must be at most 50 characters")
+	}
+	if row.SkillLevel != nil && !models.SkillLevel(*row.SkillLevel).IsValid() {
+		row.AddError("skill_level must be beginner, intermediate or advanced")
+	}
+	if row.Cost < 0 {
+		row.AddError("cost can't be negative")
+	}
+	if row.MaxMembers < 2 {
+		row.AddError("max_members must be at least 2")
+	}
+
+	if row.DateTime.IsZero() {
+		if len(event.Errors) == 0 {
+			row.AddError("date_time is required")
+		}
+	} else {
+		request := models.CreateGroupRequest{DateTime: row.DateTime, MaxMembers: row.MaxMembers}
+		if code, msg := validateGroupLimits(limits, request, true); code != "" {
+			row.AddError("%s", msg)
+		}
+	}
+
+	if requiresPhoneVerification(row.Cost, row.MaxMembers) && !organizer.PhoneVerified {
+		row.AddError("verify your phone number to create paid or large groups")
+	}
+
+	if row.Address == "" {
+		row.AddError("location is required")
+	} else if location, err := services.GeocodeAddress(ctx, row.Address); errors.Is(err, utils.ErrCircuitOpen) {
+		row.AddError("location lookup is temporarily unavailable, please try again shortly")
+	} else if err != nil {
+		log.Printf("Warning: Failed to geocode imported address %q: %v", row.Address, err)
+		row.AddError("couldn't find location %q", row.Address)
+	} else {
+		row.Location = &location
+	}
+
+	return row
+}
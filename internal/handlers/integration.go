@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxIntegrationsPerGroup caps how many chat webhooks a single group can mirror to
+const maxIntegrationsPerGroup = 5
+
+// loadOrganizedGroup fetches a group for its organizer, writing the error response and returning false otherwise
+func loadOrganizedGroup(c *gin.Context) (models.Group, bool) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var group models.Group
+	if err := database.GetDBWithContext(c.Request.Context()).Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: User %s is not the organizer of group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can manage group integrations"})
+		return group, false
+	}
+
+	return group, true
+}
+
+// ListGroupIntegrations returns the chat integrations connected to a group (organizer only)
+func ListGroupIntegrations(c *gin.Context) {
+	group, ok := loadOrganizedGroup(c)
+	if !ok {
+		return
+	}
+
+	var integrations []models.GroupIntegration
+	if err := database.GetDBWithContext(c.Request.Context()).Where("group_id = ?", group.ID).Order("created_at ASC").Find(&integrations).Error; err != nil {
+		log.Printf("Error: Failed to fetch integrations for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch integrations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"integrations": integrations})
+}
+
+// CreateGroupIntegration connects a Slack or Discord incoming webhook to a group (organizer only).
+// A confirmation message is posted first so a mistyped or revoked webhook is caught immediately.
+func CreateGroupIntegration(c *gin.Context) {
+	var request models.CreateGroupIntegrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid integration input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	platform, ok := services.DetectIntegrationPlatform(request.WebhookURL)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook URL must be a Slack or Discord incoming webhook"})
+		return
+	}
+
+	group, ok := loadOrganizedGroup(c)
+	if !ok {
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var existing int64
+	if err := db.Model(&models.GroupIntegration{}).Where("group_id = ?", group.ID).Count(&existing).Error; err != nil {
+		log.Printf("Error: Failed to count integrations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create integration"})
+		return
+	}
+	if existing >= maxIntegrationsPerGroup {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A group can have at most %d integrations", maxIntegrationsPerGroup)})
+		return
+	}
+
+	if err := services.SendChatWebhook(request.WebhookURL, fmt.Sprintf("Groops is now connected: activity from '%s' will be posted here.", group.Name)); err != nil {
+		log.Printf("Error: Webhook verification failed for group %s: %v", group.ID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not post to that webhook - check the URL and try again"})
+		return
+	}
+
+	integration := models.GroupIntegration{
+		GroupID:              group.ID,
+		Platform:             platform,
+		WebhookURL:           request.WebhookURL,
+		CreatedBy:            group.OrganiserID,
+		NotifyJoinRequests:   request.NotifyJoinRequests == nil || *request.NotifyJoinRequests,
+		NotifyApprovals:      request.NotifyApprovals == nil || *request.NotifyApprovals,
+		NotifyChatHighlights: request.NotifyChatHighlights == nil || *request.NotifyChatHighlights,
+	}
+	if err := db.Create(&integration).Error; err != nil {
+		log.Printf("Error: Failed to create integration: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create integration"})
+		return
+	}
+
+	if err := LogActivity(group.OrganiserID, "add_integration", group.ID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, integration)
+}
+
+// DeleteGroupIntegration disconnects a chat integration from a group (organizer only)
+func DeleteGroupIntegration(c *gin.Context) {
+	group, ok := loadOrganizedGroup(c)
+	if !ok {
+		return
+	}
+
+	integrationID, err := strconv.ParseUint(c.Param("integration_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid integration ID"})
+		return
+	}
+
+	result := database.GetDBWithContext(c.Request.Context()).Where("id = ? AND group_id = ?", integrationID, group.ID).Delete(&models.GroupIntegration{})
+	if result.Error != nil {
+		log.Printf("Error: Failed to delete integration %d: %v", integrationID, result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete integration"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Integration not found"})
+		return
+	}
+
+	if err := LogActivity(group.OrganiserID, "remove_integration", group.ID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Integration removed"})
+}
+
+// mirrorToIntegrations posts group activity to any connected chat integrations in the background
+func mirrorToIntegrations(groupID string, event services.IntegrationEvent, text string) {
+	go services.NewGroupIntegrationService().Mirror(groupID, event, text)
+}
@@ -0,0 +1,464 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// skippedInvite explains why someone in an invite request wasn't invited
+type skippedInvite struct {
+	Invitee string `json:"invitee"`
+	Reason  string `json:"reason"`
+}
+
+// CreateGroupInvitations invites users by username or email to join a group (organizer only).
+// Existing users get an in-app notification; email addresses without an account get an invite email.
+func CreateGroupInvitations(c *gin.Context) {
+	var request models.CreateInvitationsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid invitation input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+	if len(request.Usernames)+len(request.Emails) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invite at least one username or email"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadInvitationGroup(c, db)
+	if !ok {
+		return
+	}
+	if time.Now().After(group.DateTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot invite people after the event has ended"})
+		return
+	}
+	if group.IsCancelled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot invite people to a cancelled group"})
+		return
+	}
+
+	invited := []models.Invitation{}
+	skipped := []skippedInvite{}
+	seen := make(map[string]bool)
+
+	invite := func(invitee string, account *models.Account, email string) {
+		key := email
+		if account != nil {
+			key = account.Username
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		if account != nil {
+			if account.Username == group.OrganiserID {
+				skipped = append(skipped, skippedInvite{invitee, "you organize this group"})
+				return
+			}
+			var member models.GroupMember
+			if err := db.Where("group_id = ? AND username = ? AND status = ?", group.ID, account.Username, "approved").
+				First(&member).Error; err == nil {
+				skipped = append(skipped, skippedInvite{invitee, "already a member"})
+				return
+			}
+		}
+
+		pending := db.Where("group_id = ? AND status = ?", group.ID, models.InvitationPending)
+		if account != nil {
+			pending = pending.Where("username = ?", account.Username)
+		} else {
+			pending = pending.Where("email = ?", email)
+		}
+		var existing int64
+		if err := pending.Model(&models.Invitation{}).Count(&existing).Error; err != nil {
+			log.Printf("Warning: Failed to check existing invitations: %v", err)
+		} else if existing > 0 {
+			skipped = append(skipped, skippedInvite{invitee, "already invited"})
+			return
+		}
+
+		invitation := models.Invitation{
+			GroupID:   group.ID,
+			InvitedBy: group.OrganiserID,
+			Email:     email,
+			Status:    models.InvitationPending,
+		}
+		if account != nil {
+			invitation.Username = &account.Username
+		}
+		// Users are notified in the app and everyone else by email, sent through the outbox once the
+		// invitation is saved
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&invitation).Error; err != nil {
+				return err
+			}
+			if account != nil {
+				msg := fmt.Sprintf("%s invited you to join '%s'", group.OrganiserID, group.Name)
+				return createTargetedNotification(tx, account.Username, "group_invite", msg, group.ID, strconv.FormatUint(uint64(invitation.ID), 10))
+			}
+			return services.NewEmailService().WithOutbox(tx).SendGroupInviteEmail(email, group.OrganiserID, group)
+		}); err != nil {
+			log.Printf("Warning: Failed to create invitation for %s: %v", invitee, err)
+			skipped = append(skipped, skippedInvite{invitee, "failed to create invitation"})
+			return
+		}
+		invited = append(invited, invitation)
+	}
+
+	for _, username := range request.Usernames {
+		username = strings.TrimSpace(username)
+		var account models.Account
+		if err := db.Where("username = ?", username).First(&account).Error; err != nil {
+			skipped = append(skipped, skippedInvite{username, "user not found"})
+			continue
+		}
+		invite(username, &account, "")
+	}
+	for _, email := range request.Emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		var account models.Account
+		if err := db.Where("LOWER(email) = ?", email).First(&account).Error; err == nil {
+			invite(email, &account, "")
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			invite(email, nil, email)
+		} else {
+			log.Printf("Warning: Failed to look up account for %s: %v", email, err)
+			skipped = append(skipped, skippedInvite{email, "failed to look up account"})
+		}
+	}
+
+	if len(invited) > 0 {
+		if err := LogActivity(group.OrganiserID, "invite_members", group.ID); err != nil {
+			log.Printf("Warning: Failed to log activity: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invited": invited, "skipped": skipped})
+}
+
+// ListGroupInvitations returns the invitations sent for a group, newest first (organizer only)
+func ListGroupInvitations(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadInvitationGroup(c, db)
+	if !ok {
+		return
+	}
+
+	invitations := []models.Invitation{}
+	if err := db.Where("group_id = ?", group.ID).Order("created_at DESC").Find(&invitations).Error; err != nil {
+		log.Printf("Error: Failed to fetch invitations for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// ListMyInvitations returns the logged-in user's pending invitations to upcoming groups,
+// including ones sent to their email address before they had an account
+func ListMyInvitations(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var account models.Account
+	if err := db.Where("username = ?", username).First(&account).Error; err != nil {
+		log.Printf("Error: Account not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	type myInvitation struct {
+		models.Invitation
+		GroupName string    `json:"group_name"`
+		DateTime  time.Time `json:"date_time"`
+	}
+	invitations := []myInvitation{}
+	if err := db.Model(&models.Invitation{}).
+		Select(`invitation.*, "group".name AS group_name, "group".date_time`).
+		Joins(`JOIN "group" ON "group".id = invitation.group_id`).
+		Where("invitation.status = ? AND (invitation.username = ? OR (invitation.username IS NULL AND invitation.email = ?))",
+			models.InvitationPending, username, strings.ToLower(account.Email)).
+		Where(`"group".date_time > NOW() AND "group".status = ? AND "group".deleted_at IS NULL`, models.GroupStatusActive).
+		Order(`"group".date_time ASC`).
+		Scan(&invitations).Error; err != nil {
+		log.Printf("Error: Failed to fetch invitations for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// AcceptInvitation joins the invited group as an approved member, skipping the join request
+func AcceptInvitation(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	invitation, group, ok := loadMyInvitation(c, db)
+	if !ok {
+		return
+	}
+
+	if group.HasEnded(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot join group after the event has ended"})
+		return
+	}
+	if group.IsCancelled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This group has been cancelled"})
+		return
+	}
+	if !enforceCutoff(c, group, "join the group") {
+		return
+	}
+	if !enforceRSVPDeadline(c, group, "join the group") {
+		return
+	}
+	if !enforceEligibility(c, db, group, username) {
+		return
+	}
+
+	var member models.GroupMember
+	err := db.Where("group_id = ? AND username = ?", group.ID, username).First(&member).Error
+	if err == nil && member.Status == "approved" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already a member"})
+		return
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Error: Failed to check group membership: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check group membership"})
+		return
+	}
+
+	// Invitations don't reserve a spot, so the group may have filled up since
+	heldSpots, spotErr := services.NewWaitlistService().CountActiveOffers(group.ID, username)
+	if spotErr != nil {
+		log.Printf("Warning: Failed to count waitlist offers: %v", spotErr)
+	}
+	if group.OccupiedSpots()+int(heldSpots) >= group.MaxMembers {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Group is full", "waitlist_available": true})
+		return
+	}
+
+	if limitErr := checkUpcomingEventLimit(db, username); limitErr != nil {
+		log.Printf("Error: User %s hit membership limit: %s", username, limitErr.Code)
+		c.JSON(http.StatusForbidden, gin.H{"error": limitErr.Message, "code": limitErr.Code})
+		return
+	}
+
+	isNewMember := errors.Is(err, gorm.ErrRecordNotFound)
+	now := time.Now()
+	approval := services.MemberApproved{
+		Group:    group,
+		Username: username,
+		Via:      services.ApprovedByInvitation,
+		Notice:   fmt.Sprintf("%s accepted your invitation and joined '%s'", username, group.Name),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if isNewMember {
+			member = models.GroupMember{GroupID: group.ID, Username: username, Status: "approved"}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(&member).Update("status", "approved").Error; err != nil {
+			return err
+		}
+		if err := services.RefreshMemberCounts(tx, group.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&invitation).Updates(map[string]interface{}{
+			"status": models.InvitationAccepted, "username": username, "responded_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return services.PublishEvent(tx, approval)
+	})
+	if err != nil {
+		log.Printf("Error: Failed to accept invitation %d: %v", invitation.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	services.PublishCommitted(approval)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Joined group", "status": "approved"})
+}
+
+// DeclineInvitation turns down an invitation and lets the organizer know
+func DeclineInvitation(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	invitation, group, ok := loadMyInvitation(c, db)
+	if !ok {
+		return
+	}
+
+	if err := db.Model(&invitation).Updates(map[string]interface{}{
+		"status": models.InvitationDeclined, "username": username, "responded_at": time.Now(),
+	}).Error; err != nil {
+		log.Printf("Error: Failed to decline invitation %d: %v", invitation.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invitation"})
+		return
+	}
+
+	msg := fmt.Sprintf("%s declined your invitation to '%s'", username, group.Name)
+	if err := createNotification(db, group.OrganiserID, "invite_declined", msg, group.ID); err != nil {
+		log.Printf("Warning: Failed to create notification: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// loadInvitationGroup fetches a group for its organizer, writing the error response and returning false otherwise
+func loadInvitationGroup(c *gin.Context, db *gorm.DB) (models.Group, bool) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: User %s is not the organizer of group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can invite people"})
+		return group, false
+	}
+
+	return group, true
+}
+
+// loadMyInvitation fetches the logged-in user's pending invitation by :invite_id, and its group
+func loadMyInvitation(c *gin.Context, db *gorm.DB) (models.Invitation, models.Group, bool) {
+	username := c.GetString("username")
+
+	var invitation models.Invitation
+	var group models.Group
+	inviteID, err := strconv.ParseUint(c.Param("invite_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return invitation, group, false
+	}
+
+	var account models.Account
+	if err := db.Where("username = ?", username).First(&account).Error; err != nil {
+		log.Printf("Error: Account not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return invitation, group, false
+	}
+
+	if err := db.Where("id = ? AND (username = ? OR (username IS NULL AND email = ?))", inviteID, username, strings.ToLower(account.Email)).
+		First(&invitation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return invitation, group, false
+	}
+	if invitation.Status != models.InvitationPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "This invitation has already been " + invitation.Status})
+		return invitation, group, false
+	}
+
+	if err := db.Where("id = ?", invitation.GroupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return invitation, group, false
+	}
+
+	return invitation, group, true
+}
+
+// defaultInviteLinkTTL is how long a shareable invite link is valid unless the organizer says otherwise
+const defaultInviteLinkTTL = 72 * time.Hour
+
+// inviteLinkPath is the signed value behind a group's invite links; it isn't a route
+func inviteLinkPath(groupID string) string {
+	return "invite-link:/groups/" + groupID
+}
+
+// CreateInviteLink generates a signed, expiring link for a group (organizer only).
+// Anyone logged in who joins through it is approved straight away instead of waiting for review.
+func CreateInviteLink(c *gin.Context) {
+	var request models.CreateInviteLinkRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			log.Printf("Error: Invalid invite link input: %s", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+			return
+		}
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadInvitationGroup(c, db)
+	if !ok {
+		return
+	}
+	if time.Now().After(group.DateTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot invite people after the event has ended"})
+		return
+	}
+	if group.IsCancelled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot invite people to a cancelled group"})
+		return
+	}
+
+	secret := os.Getenv("INVITE_LINK_SIGNING_SECRET")
+	if secret == "" {
+		log.Printf("Error: INVITE_LINK_SIGNING_SECRET not set")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Invite links unavailable"})
+		return
+	}
+
+	ttl := defaultInviteLinkTTL
+	if request.ExpiresInHours > 0 {
+		ttl = time.Duration(request.ExpiresInHours) * time.Hour
+	}
+	// The link is useless once the event has started
+	expires := time.Now().Add(ttl)
+	if expires.After(group.DateTime) {
+		expires = group.DateTime
+	}
+	expires = expires.Truncate(time.Second)
+	signature := utils.SignPath(inviteLinkPath(group.ID), expires, secret)
+
+	if err := LogActivity(group.OrganiserID, "create_invite_link", group.ID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"url": fmt.Sprintf("https://groops.fun/groups/%s?invite_expires=%d&invite_signature=%s",
+			url.PathEscape(group.ID), expires.Unix(), signature),
+		"invite_expires":   expires.Unix(),
+		"invite_signature": signature,
+		"expires_at":       expires,
+	})
+}
+
+// hasValidInviteLink reports whether the request carries an unexpired invite link for the group
+// (invite_expires and invite_signature query parameters, as passed through by the frontend)
+func hasValidInviteLink(c *gin.Context, groupID string) bool {
+	signature := c.Query("invite_signature")
+	if signature == "" {
+		return false
+	}
+	secret := os.Getenv("INVITE_LINK_SIGNING_SECRET")
+	return secret != "" && utils.VerifySignedPath(inviteLinkPath(groupID), c.Query("invite_expires"), signature, secret)
+}
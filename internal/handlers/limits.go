@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Error codes returned when a user exceeds their membership limits
+const (
+	ErrCodePendingRequestLimit = "PENDING_REQUEST_LIMIT"
+	ErrCodeUpcomingEventLimit  = "UPCOMING_EVENT_LIMIT"
+	ErrCodeRejoinCooldown      = "REJOIN_COOLDOWN"
+)
+
+// membershipLimitError describes which membership limit a user has hit
+type membershipLimitError struct {
+	Code    string
+	Message string
+}
+
+// maxPendingJoinRequests is the number of simultaneous pending join requests a user may have
+func maxPendingJoinRequests() int {
+	return utils.GetEnvInt("MAX_PENDING_JOIN_REQUESTS", 10)
+}
+
+// maxUpcomingEvents is the number of upcoming events a user may be approved for at once
+func maxUpcomingEvents() int {
+	return utils.GetEnvInt("MAX_UPCOMING_EVENTS", 20)
+}
+
+// rejoinCooldown is how long a rejected user must wait before requesting to join the same group again
+func rejoinCooldown() time.Duration {
+	return utils.GetEnvDuration("REJOIN_COOLDOWN", 24*time.Hour)
+}
+
+// countUpcomingMemberships counts a user's memberships with the given status in groups that haven't started yet,
+// excluding groups the user organizes
+func countUpcomingMemberships(db *gorm.DB, username, status string) (int64, error) {
+	var count int64
+	err := db.Model(&models.GroupMember{}).
+		Joins(`JOIN "group" ON "group".id = group_member.group_id`).
+		Where(`group_member.username = ? AND group_member.status = ? AND "group".date_time > NOW() AND "group".status = ? AND "group".organiser_id != ?`,
+			username, status, models.GroupStatusActive, username).
+		Count(&count).Error
+	return count, err
+}
+
+// checkJoinRequestLimits verifies a user can submit another join request
+func checkJoinRequestLimits(db *gorm.DB, username string) *membershipLimitError {
+	pendingCount, err := countUpcomingMemberships(db, username, "pending")
+	if err != nil {
+		log.Printf("Warning: Failed to count pending requests for %s: %v", username, err)
+		return nil
+	}
+	if limit := maxPendingJoinRequests(); int(pendingCount) >= limit {
+		return &membershipLimitError{
+			Code:    ErrCodePendingRequestLimit,
+			Message: fmt.Sprintf("You can have at most %d pending join requests at a time", limit),
+		}
+	}
+
+	return checkUpcomingEventLimit(db, username)
+}
+
+// checkUpcomingEventLimit verifies a user can be approved for another upcoming event
+func checkUpcomingEventLimit(db *gorm.DB, username string) *membershipLimitError {
+	approvedCount, err := countUpcomingMemberships(db, username, "approved")
+	if err != nil {
+		log.Printf("Warning: Failed to count upcoming events for %s: %v", username, err)
+		return nil
+	}
+	if limit := maxUpcomingEvents(); int(approvedCount) >= limit {
+		return &membershipLimitError{
+			Code:    ErrCodeUpcomingEventLimit,
+			Message: fmt.Sprintf("You can be a member of at most %d upcoming events at a time", limit),
+		}
+	}
+	return nil
+}
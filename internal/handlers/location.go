@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"groops/internal/database"
 	"groops/internal/models"
 	"groops/internal/services"
+	"groops/internal/utils"
 	"log"
 	"net/http"
 
@@ -17,21 +20,40 @@ func ValidateLocation(c *gin.Context) {
 		return
 	}
 
-	placeDetails, err := services.ValidateLocation(placeID)
+	location, err := services.StandardizeLocation(c.Request.Context(), models.Location{PlaceID: placeID})
+	if errors.Is(err, utils.ErrCircuitOpen) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Location lookup is temporarily unavailable, please try again shortly"})
+		return
+	}
 	if err != nil {
 		log.Printf("Error validating location: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate location"})
 		return
 	}
 
-	// Convert Google Maps API response to our Location model
-	location := models.Location{
-		PlaceID:          placeDetails.PlaceID,
-		Name:             placeDetails.Name,
-		FormattedAddress: placeDetails.FormattedAddress,
-		Latitude:         placeDetails.Geometry.Location.Lat,
-		Longitude:        placeDetails.Geometry.Location.Lng,
+	c.JSON(http.StatusOK, location)
+}
+
+// GetCities returns the cities with upcoming groups and how many each has, for city landing pages
+func GetCities(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+
+	type cityCount struct {
+		City       string `json:"city"`
+		GroupCount int64  `json:"groups"`
+	}
+
+	cities := []cityCount{}
+	if err := db.Model(&models.Group{}).
+		Select("city, COUNT(*) AS group_count").
+		Where("city <> '' AND date_time > NOW() AND status = ? AND visibility = ?", models.GroupStatusActive, models.VisibilityPublic).
+		Group("city").
+		Order("group_count DESC, city ASC").
+		Scan(&cities).Error; err != nil {
+		log.Printf("Error: Failed to fetch cities: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cities"})
+		return
 	}
 
-	c.JSON(http.StatusOK, location)
+	c.JSON(http.StatusOK, cities)
 }
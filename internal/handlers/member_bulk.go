@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bulkMemberError explains why one username in a bulk action couldn't be processed
+type bulkMemberError struct {
+	Username string `json:"username"`
+	Error    string `json:"error"`
+	Code     string `json:"code,omitempty"`
+}
+
+// errBulkMembersRejected aborts a bulk action's transaction when any username fails validation
+var errBulkMembersRejected = errors.New("bulk member action rejected")
+
+// BulkUpdateMembers approves or rejects several pending join requests in one transaction (organizer or
+// co-organizer). The batch is all-or-nothing: if any user isn't pending, or approving them all would
+// overfill the group, nothing changes and the per-user errors are returned.
+func BulkUpdateMembers(c *gin.Context) {
+	var request models.BulkMemberActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid bulk member input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	// The same user listed twice is only processed once
+	usernames := make([]string, 0, len(request.Usernames))
+	seen := make(map[string]bool)
+	for _, username := range request.Usernames {
+		if !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	approve := request.Action == "approve"
+	newStatus := "rejected"
+	if approve {
+		newStatus = "approved"
+	}
+	reason := strings.TrimSpace(request.Reason)
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "manage join requests")
+	if !ok {
+		return
+	}
+	if approve && !enforceRSVPDeadline(c, group, "approve members") {
+		return
+	}
+
+	var failures []bulkMemberError
+	status := http.StatusBadRequest
+	err := services.WithMemberCounts(db, group.ID, func(tx *gorm.DB) error {
+		// Lock the group so concurrent approvals can't both take the last spots
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", group.ID).First(&group).Error; err != nil {
+			return err
+		}
+
+		var members []models.GroupMember
+		if err := tx.Where("group_id = ? AND username IN ? AND status = ?", group.ID, usernames, "pending").
+			Find(&members).Error; err != nil {
+			return err
+		}
+		pending := make(map[string]models.GroupMember, len(members))
+		for _, member := range members {
+			pending[member.Username] = member
+		}
+
+		neededSpots := 0
+		for _, username := range usernames {
+			member, ok := pending[username]
+			if !ok {
+				failures = append(failures, bulkMemberError{Username: username, Error: "Pending join request not found"})
+				continue
+			}
+			if !approve {
+				continue
+			}
+			neededSpots += 1 + member.GuestCount
+			if limitErr := checkUpcomingEventLimit(tx, username); limitErr != nil {
+				failures = append(failures, bulkMemberError{
+					Username: username,
+					Error:    fmt.Sprintf("%s cannot be approved: they have reached the limit of upcoming events", username),
+					Code:     limitErr.Code,
+				})
+			}
+		}
+		if len(failures) > 0 {
+			return errBulkMembersRejected
+		}
+
+		if approve {
+			// Spots offered to waitlisted users are held for them
+			heldSpots, err := services.NewWaitlistService().CountActiveOffers(group.ID, "")
+			if err != nil {
+				log.Printf("Warning: Failed to count waitlist offers: %v", err)
+			}
+			if available := group.MaxMembers - group.OccupiedSpots() - int(heldSpots); neededSpots > available {
+				if available < 0 {
+					available = 0
+				}
+				status = http.StatusConflict
+				for _, username := range usernames {
+					failures = append(failures, bulkMemberError{
+						Username: username,
+						Error:    fmt.Sprintf("Group has %d spots left but approving everyone needs %d", available, neededSpots),
+					})
+				}
+				return errBulkMembersRejected
+			}
+		}
+
+		if err := tx.Model(&models.GroupMember{}).
+			Where("group_id = ? AND username IN ? AND status = ?", group.ID, usernames, "pending").
+			Updates(map[string]interface{}{"status": newStatus, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		for _, username := range usernames {
+			var err error
+			if approve {
+				err = services.PublishEvent(tx, services.MemberApproved{Group: group, Username: username, ApprovedBy: requester, Bulk: true})
+			} else {
+				err = queueRejectionNotice(tx, group, username, reason)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if approve {
+			return notifyBulkMembersJoined(tx, group, usernames, requester)
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkMembersRejected) {
+		log.Printf("Error: Bulk %s for group %s rejected: %d of %d users failed", request.Action, group.ID, len(failures), len(usernames))
+		c.JSON(status, gin.H{"error": "No members were updated", "failures": failures})
+		return
+	}
+	if err != nil {
+		log.Printf("Error: Failed to %s members for group %s: %v", request.Action, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update members"})
+		return
+	}
+
+	for _, username := range usernames {
+		if approve {
+			services.PublishCommitted(services.MemberApproved{Group: group, Username: username, ApprovedBy: requester, Bulk: true})
+		} else if err := LogActivity(username, "join_group_rejected", group.ID); err != nil {
+			log.Printf("Warning: Failed to log reject join activity: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   fmt.Sprintf("%d members %s", len(usernames), newStatus),
+		"action":    request.Action,
+		"count":     len(usernames),
+		"usernames": usernames,
+	})
+}
+
+// notifyBulkMembersJoined sends the group's existing members one notification about everyone approved in
+// a batch, rather than one per new member
+func notifyBulkMembersJoined(tx *gorm.DB, group models.Group, usernames []string, requester string) error {
+	var existing []string
+	if err := tx.Model(&models.GroupMember{}).
+		Where("group_id = ? AND status = ? AND username NOT IN ?", group.ID, "approved", usernames).
+		Pluck("username", &existing).Error; err != nil {
+		return err
+	}
+
+	msg := usernames[0] + " has joined your group '" + group.Name + "'"
+	if len(usernames) > 1 {
+		msg = fmt.Sprintf("%d new members have joined your group '%s': %s", len(usernames), group.Name, strings.Join(usernames, ", "))
+	}
+	for _, username := range existing {
+		// Don't notify the organizer or whoever approved the requests
+		if username == group.OrganiserID || username == requester {
+			continue
+		}
+		if err := createNotification(tx, username, "member_joined", msg, group.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
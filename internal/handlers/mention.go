@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"groops/internal/models"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxMentionsPerMessage caps how many people one message can notify
+const maxMentionsPerMessage = 10
+
+// mentionPattern matches @username where the @ isn't part of a word (so emails don't count)
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9@])@([A-Za-z0-9]{3,30})\b`)
+
+// parseMentions returns the distinct usernames mentioned in a message, lowercased, in order of appearance
+func parseMentions(content string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		username := strings.ToLower(match[1])
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		mentions = append(mentions, username)
+	}
+	return mentions
+}
+
+// notifyMentions notifies the group chat members mentioned in a message through tx and returns their
+// usernames. Mentions of non-members, the sender and anyone in skip (e.g. an already-notified reply
+// author) are ignored.
+func notifyMentions(tx *gorm.DB, group models.Group, message models.Message, skip string) ([]string, error) {
+	mentioned := []string{}
+	mentions := parseMentions(message.Content)
+	if len(mentions) == 0 {
+		return mentioned, nil
+	}
+
+	// Usernames are unique regardless of case, so match mentions case-insensitively
+	members := map[string]string{strings.ToLower(group.OrganiserID): group.OrganiserID}
+	for _, member := range group.Members {
+		if member.Status == "approved" {
+			members[strings.ToLower(member.Username)] = member.Username
+		}
+	}
+
+	for _, mention := range mentions {
+		username, ok := members[mention]
+		if !ok || username == message.Username || username == skip {
+			continue
+		}
+		if len(mentioned) == maxMentionsPerMessage {
+			log.Printf("Warning: Message %d mentions more than %d members; ignoring the rest", message.ID, maxMentionsPerMessage)
+			break
+		}
+		mentioned = append(mentioned, username)
+	}
+	if len(mentioned) == 0 {
+		return mentioned, nil
+	}
+
+	msg := message.Username + " mentioned you in '" + group.Name + "'"
+	messageID := strconv.FormatUint(uint64(message.ID), 10)
+	for _, username := range mentioned {
+		if err := createTargetedNotification(tx, username, "mention", msg, group.ID, messageID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Emails are opt-in for the deployment and skip anyone already looking at the chat
+	if utils.GetEnvBool("MENTION_EMAILS", false) {
+		if err := queueMentionEmails(tx, group, message, mentioned); err != nil {
+			return nil, err
+		}
+	}
+
+	return mentioned, nil
+}
+
+// queueMentionEmails queues emails through tx to mentioned members who aren't currently viewing the
+// group chat
+func queueMentionEmails(tx *gorm.DB, group models.Group, message models.Message, usernames []string) error {
+	viewing := make(map[string]bool)
+	for _, viewer := range services.GetPresenceService().Viewers(group.ID) {
+		viewing[viewer.Username] = true
+	}
+
+	var recipients []string
+	for _, username := range usernames {
+		if !viewing[username] {
+			recipients = append(recipients, username)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	var accounts []models.Account
+	if err := tx.Where("username IN ?", recipients).Find(&accounts).Error; err != nil {
+		return err
+	}
+
+	emailService := services.NewEmailService().WithOutbox(tx)
+	for _, account := range accounts {
+		if err := emailService.SendMentionEmail(account.Email, account.Username, message.Username, group.Name, message.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,15 +1,22 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"groops/internal/database"
 	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"groops/internal/utils"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetGroupMessages handles fetching messages for a group
@@ -23,7 +30,7 @@ func GetGroupMessages(c *gin.Context) {
 		return
 	}
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Check if group exists and user is a member
 	var group models.Group
@@ -33,18 +40,7 @@ func GetGroupMessages(c *gin.Context) {
 		return
 	}
 
-	// Check if user is organizer or approved member
-	isMember := group.OrganiserID == requester
-	if !isMember {
-		for _, member := range group.Members {
-			if member.Username == requester && member.Status == "approved" {
-				isMember = true
-				break
-			}
-		}
-	}
-
-	if !isMember {
+	if !policy.CanViewMessages(policy.MemberOf(group, requester), group) {
 		log.Printf("Error: User %s not authorized to view messages for group %s", requester, groupID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view group messages"})
 		return
@@ -66,8 +62,8 @@ func GetGroupMessages(c *gin.Context) {
 		}
 	}
 
-	// Build query for fetching messages
-	query := db.Where("group_id = ?", groupID)
+	// Build query for fetching messages; replies are fetched per thread
+	query := db.Where("group_id = ? AND parent_message_id IS NULL", groupID)
 
 	// If beforeID is provided, get messages with ID less than it (older messages)
 	if beforeID > 0 {
@@ -84,54 +80,218 @@ func GetGroupMessages(c *gin.Context) {
 		return
 	}
 
-	// Mark messages as read by this user
-	if len(messages) > 0 {
-		for i := range messages {
-			// Parse existing ReadBy array
-			var readByUsers []string
-			if messages[i].ReadBy != nil {
-				if err := json.Unmarshal(messages[i].ReadBy, &readByUsers); err != nil {
-					log.Printf("Warning: Failed to parse ReadBy for message %d: %v", messages[i].ID, err)
-					readByUsers = []string{}
-				}
-			}
+	// Viewing the latest messages moves the reader's cursor forward; the previous position is
+	// returned so clients can mark where the new messages start
+	var cursor models.MessageReadCursor
+	if err := db.Where("group_id = ? AND username = ?", groupID, requester).Limit(1).Find(&cursor).Error; err != nil {
+		log.Printf("Warning: Failed to load read cursor for %s in group %s: %v", requester, groupID, err)
+	}
+	var newestID uint
+	for _, message := range messages {
+		if message.ID > newestID {
+			newestID = message.ID
+		}
+	}
+	if newestID > cursor.LastReadMessageID {
+		if err := advanceReadCursor(db, groupID, requester, newestID); err != nil {
+			log.Printf("Warning: Failed to advance read cursor for %s in group %s: %v", requester, groupID, err)
+		}
+	}
 
-			// Check if user has already read this message
-			hasRead := false
-			for _, user := range readByUsers {
-				if user == requester {
-					hasRead = true
-					break
-				}
-			}
+	attachReplyCounts(db, messages)
+	attachSignedAttachmentURLs(messages)
 
-			// If not read yet, add user to ReadBy array and update database
-			if !hasRead {
-				readByUsers = append(readByUsers, requester)
-				updatedReadBy, err := json.Marshal(readByUsers)
-				if err != nil {
-					log.Printf("Warning: Failed to marshal ReadBy for message %d: %v", messages[i].ID, err)
-					continue
-				}
+	c.JSON(http.StatusOK, gin.H{
+		"messages":             messages,
+		"count":                len(messages),
+		"last_read_message_id": cursor.LastReadMessageID,
+	})
+}
 
-				// Update the message in database
-				if err := db.Model(&messages[i]).Update("read_by", updatedReadBy).Error; err != nil {
-					log.Printf("Warning: Failed to update ReadBy for message %d: %v", messages[i].ID, err)
-				}
+// advanceReadCursor marks every message up to messageID as read by the user.
+// The cursor only ever moves forward, so out-of-order requests can't un-read messages.
+func advanceReadCursor(db *gorm.DB, groupID, username string, messageID uint) error {
+	return db.Exec(`
+		INSERT INTO message_read_cursor (group_id, username, last_read_message_id, updated_at)
+		VALUES (?, ?, ?, NOW())
+		ON CONFLICT (group_id, username) DO UPDATE
+		SET last_read_message_id = GREATEST(message_read_cursor.last_read_message_id, EXCLUDED.last_read_message_id),
+			updated_at = EXCLUDED.updated_at
+	`, groupID, username, messageID).Error
+}
 
-				// Update the local message object for response
-				messages[i].ReadBy = updatedReadBy
-			}
+// countUnreadMessages counts messages from other members past the user's read cursor
+func countUnreadMessages(db *gorm.DB, groupID, username string) (int64, error) {
+	var unread int64
+	err := db.Raw(`
+		SELECT COUNT(*)
+		FROM message
+		WHERE group_id = ?
+		AND deleted_at IS NULL
+		AND username <> ?
+		AND id > COALESCE((
+			SELECT last_read_message_id FROM message_read_cursor WHERE group_id = ? AND username = ?
+		), 0)
+	`, groupID, username, groupID, username).Scan(&unread).Error
+	return unread, err
+}
+
+// MarkGroupMessagesRead moves the requester's read cursor to a message, or to the newest message
+// in the chat when none is given, and returns what is still unread
+func MarkGroupMessagesRead(c *gin.Context) {
+	var request models.MarkMessagesReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			log.Printf("Error: Invalid read receipt input: %s", err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+			return
+		}
+	}
+
+	group, ok := loadChatGroup(c)
+	if !ok {
+		return
+	}
+	requester := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	messageID := request.MessageID
+	if messageID == 0 {
+		if err := db.Model(&models.Message{}).Unscoped().Where("group_id = ?", group.ID).
+			Select("COALESCE(MAX(id), 0)").Scan(&messageID).Error; err != nil {
+			log.Printf("Error: Failed to find latest message in group %s: %v", group.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages as read"})
+			return
+		}
+	} else {
+		var count int64
+		if err := db.Model(&models.Message{}).Unscoped().Where("id = ? AND group_id = ?", messageID, group.ID).Count(&count).Error; err != nil || count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+	}
+
+	if messageID > 0 {
+		if err := advanceReadCursor(db, group.ID, requester, messageID); err != nil {
+			log.Printf("Error: Failed to advance read cursor for %s in group %s: %v", requester, group.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages as read"})
+			return
 		}
 	}
 
+	unread, err := countUnreadMessages(db, group.ID, requester)
+	if err != nil {
+		log.Printf("Warning: Failed to count unread messages for %s: %v", requester, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"messages": messages,
-		"count":    len(messages),
+		"last_read_message_id": messageID,
+		"unread_count":         unread,
 	})
 }
 
-// SendGroupMessage handles sending a message to a group
+// attachReplyCounts fills in how many replies each top-level message has
+func attachReplyCounts(db *gorm.DB, messages []models.Message) {
+	if len(messages) == 0 {
+		return
+	}
+
+	ids := make([]uint, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+
+	var counts []struct {
+		ParentMessageID uint
+		Replies         int
+	}
+	if err := db.Model(&models.Message{}).
+		Select("parent_message_id, COUNT(*) AS replies").
+		Where("parent_message_id IN ?", ids).
+		Group("parent_message_id").
+		Scan(&counts).Error; err != nil {
+		log.Printf("Warning: Failed to count message replies: %v", err)
+		return
+	}
+
+	byParent := make(map[uint]int, len(counts))
+	for _, count := range counts {
+		byParent[count.ParentMessageID] = count.Replies
+	}
+	for i := range messages {
+		messages[i].ReplyCount = byParent[messages[i].ID]
+	}
+}
+
+// GetMessageThread returns a top-level message and its replies, oldest first
+func GetMessageThread(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	if requester == "" {
+		log.Printf("Error: Not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	messageID, err := strconv.ParseUint(c.Param("message_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Preload("Members").Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if !policy.CanViewMessages(policy.MemberOf(group, requester), group) {
+		log.Printf("Error: User %s not authorized to view messages for group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view group messages"})
+		return
+	}
+
+	var parent models.Message
+	if err := db.Where("id = ? AND group_id = ?", messageID, groupID).First(&parent).Error; err != nil {
+		log.Printf("Error: Message %d not found in group %s: %v", messageID, groupID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return
+	}
+
+	// Asking for a reply's thread returns the whole thread it belongs to
+	if parent.ParentMessageID != nil {
+		if err := db.Where("id = ?", *parent.ParentMessageID).First(&parent).Error; err != nil {
+			log.Printf("Error: Thread root for message %d not found: %v", messageID, err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+	}
+
+	var replies []models.Message
+	if err := db.Where("parent_message_id = ?", parent.ID).Order("created_at ASC").Find(&replies).Error; err != nil {
+		log.Printf("Error: Failed to fetch replies for message %d: %v", parent.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch thread"})
+		return
+	}
+	parent.ReplyCount = len(replies)
+
+	thread := append([]models.Message{parent}, replies...)
+	attachSignedAttachmentURLs(thread)
+	parent, replies = thread[0], thread[1:]
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": parent,
+		"replies": replies,
+		"count":   len(replies),
+	})
+}
+
+// SendGroupMessage handles sending a message to a group. When routed with a :message_id
+// (POST .../messages/:message_id/replies) the message is posted as a reply in that thread.
 func SendGroupMessage(c *gin.Context) {
 	groupID := c.Param("group_id")
 	requester := c.GetString("username")
@@ -142,20 +302,38 @@ func SendGroupMessage(c *gin.Context) {
 		return
 	}
 
+	// JSON for text messages, multipart/form-data when an image is attached
 	var request models.SendMessageRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if err := c.ShouldBind(&request); err != nil {
 		log.Printf("Error: Invalid message input: %s", err.Error())
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message content"})
 		return
 	}
 
+	var attachment *multipart.FileHeader
+	if c.ContentType() == "multipart/form-data" {
+		fileHeader, err := c.FormFile("attachment")
+		if err != nil && !errors.Is(err, http.ErrMissingFile) {
+			log.Printf("Error: Failed to read message attachment: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment upload"})
+			return
+		}
+		if fileHeader != nil {
+			if maxSize := messageAttachmentMaxBytes(); fileHeader.Size > maxSize {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Attachments must be %dMB or smaller", maxSize>>20)})
+				return
+			}
+			attachment = fileHeader
+		}
+	}
+
 	// Additional validation
-	if len(request.Content) == 0 {
+	if len(request.Content) == 0 && attachment == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Message cannot be empty"})
 		return
 	}
 
-	db := database.GetDB()
+	db := database.GetDBWithContext(c.Request.Context())
 
 	// Check if group exists and user is a member
 	var group models.Group
@@ -165,23 +343,17 @@ func SendGroupMessage(c *gin.Context) {
 		return
 	}
 
-	// Check if user is organizer or approved member
-	isMember := group.OrganiserID == requester
-	if !isMember {
-		for _, member := range group.Members {
-			if member.Username == requester && member.Status == "approved" {
-				isMember = true
-				break
-			}
-		}
-	}
-
-	if !isMember {
+	if !policy.CanPostMessages(policy.MemberOf(group, requester), group) {
 		log.Printf("Error: User %s not authorized to send messages to group %s", requester, groupID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to send messages to this group"})
 		return
 	}
 
+	// Locked chats and muted members can read but not post
+	if !enforceChatModeration(c, db, group, requester) {
+		return
+	}
+
 	// Create the message
 	message := models.Message{
 		GroupID:  groupID,
@@ -189,30 +361,124 @@ func SendGroupMessage(c *gin.Context) {
 		Content:  request.Content,
 	}
 
-	// Initialize ReadBy with the sender (they've "read" their own message)
-	readByUsers := []string{requester}
-	readByJSON, err := json.Marshal(readByUsers)
-	if err != nil {
-		log.Printf("Warning: Failed to marshal initial ReadBy: %v", err)
-		readByJSON = []byte("[]") // Fallback to empty array
+	// Replies are attached to the thread's root so threads stay one level deep
+	var parent models.Message
+	if parentParam := c.Param("message_id"); parentParam != "" {
+		parentID, err := strconv.ParseUint(parentParam, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+			return
+		}
+		if err := db.Where("id = ? AND group_id = ?", parentID, groupID).First(&parent).Error; err != nil {
+			log.Printf("Error: Parent message %d not found in group %s: %v", parentID, groupID, err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		rootID := parent.ID
+		if parent.ParentMessageID != nil {
+			rootID = *parent.ParentMessageID
+		}
+		message.ParentMessageID = &rootID
+	}
+
+	// Upload the image only once the sender is known to be allowed to post
+	var imageService *services.ImageService
+	if attachment != nil {
+		var err error
+		imageService, err = services.NewImageService()
+		if err != nil {
+			log.Printf("Error: Failed to initialize image service: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Image attachments are unavailable"})
+			return
+		}
+
+		file, err := attachment.Open()
+		if err != nil {
+			log.Printf("Error: Failed to open message attachment: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment upload"})
+			return
+		}
+		uploaded, err := imageService.UploadMessageAttachment(c.Request.Context(), file, groupID)
+		file.Close()
+		if errors.Is(err, utils.ErrCircuitOpen) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Image uploads are temporarily unavailable, please try again shortly"})
+			return
+		}
+		if err != nil {
+			log.Printf("Error: Failed to upload attachment for group %s: %v", groupID, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		message.AttachmentPublicID = uploaded.PublicID
+		message.AttachmentFormat = uploaded.Format
+		message.AttachmentContentType = uploaded.ContentType
+	}
+
+	// The author of the message being replied to hears about it, unless they sent it
+	var replyRecipient string
+	if message.ParentMessageID != nil && parent.Username != requester {
+		replyRecipient = parent.Username
 	}
-	message.ReadBy = readByJSON
 
-	if err := db.Create(&message).Error; err != nil {
+	// Reply and mention notifications and emails are saved with the message. The reply author is
+	// notified by the MessageSent subscribers, so mentioning them doesn't notify twice.
+	var mentioned []string
+	event := services.MessageSent{Group: group, ReplyTo: replyRecipient}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		event.Message = message
+		if err := services.PublishEvent(tx, event); err != nil {
+			return err
+		}
+		var err error
+		mentioned, err = notifyMentions(tx, group, message, replyRecipient)
+		return err
+	}); err != nil {
 		log.Printf("Error: Failed to create message for group %s: %v", groupID, err)
+		if imageService != nil {
+			// Clean up even if the request was cancelled
+			if err := imageService.DeleteMessageAttachment(context.Background(), message.AttachmentPublicID); err != nil {
+				log.Printf("Warning: Failed to clean up attachment %s: %v", message.AttachmentPublicID, err)
+			}
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
 		return
 	}
 
-	// Log the activity
-	if err := LogActivity(requester, "send_message", groupID); err != nil {
-		log.Printf("Warning: Failed to log message activity: %v", err)
+	// The sender has read everything up to their own message
+	if err := advanceReadCursor(db, groupID, requester, message.ID); err != nil {
+		log.Printf("Warning: Failed to advance read cursor for %s: %v", requester, err)
 	}
 
-	// Create unread message notifications after 10 seconds (async)
+	// Sending ends the sender's typing indicator without waiting for it to time out
+	services.GetPresenceService().Heartbeat(groupID, requester, false)
+
+	services.PublishCommitted(event)
+
+	messages := []models.Message{message}
+	attachSignedAttachmentURLs(messages)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  messages[0],
+		"mentions": mentioned,
+		"success":  true,
+	})
+}
+
+// scheduleUnreadNotifications creates unread message notifications 10 seconds after a message is sent
+// (async), for members who haven't read it by then
+func scheduleUnreadNotifications(event services.MessageSent) {
+	group := event.Group
+	groupID := group.ID
+	requester := event.Message.Username
+
 	go func() {
 		time.Sleep(10 * time.Second)
 
+		db := database.GetDB()
+
 		// Get all group members (organizer + approved members)
 		var allMembers []string
 
@@ -233,15 +499,8 @@ func SendGroupMessage(c *gin.Context) {
 			}
 
 			// Check if this member has unread messages in this group
-			var unreadCount int64
-			query := `
-				SELECT COUNT(*) 
-				FROM message 
-				WHERE group_id = ? 
-				AND (read_by IS NULL OR NOT jsonb_exists(read_by, ?))
-			`
-
-			if err := db.Raw(query, groupID, memberUsername).Scan(&unreadCount).Error; err != nil {
+			unreadCount, err := countUnreadMessages(db, groupID, memberUsername)
+			if err != nil {
 				log.Printf("Warning: Failed to count unread messages for %s: %v", memberUsername, err)
 				continue
 			}
@@ -267,9 +526,193 @@ func SendGroupMessage(c *gin.Context) {
 			}
 		}
 	}()
+}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": message,
+// messageAttachmentMaxBytes is the largest image that can be attached to a chat message
+func messageAttachmentMaxBytes() int64 {
+	return int64(utils.GetEnvInt("MESSAGE_ATTACHMENT_MAX_BYTES", 5<<20)) // 5MB
+}
+
+// attachSignedAttachmentURLs fills in a short-lived download link for each message with an image.
+// Attachments are private in Cloudinary, so these links are the only way to view them.
+func attachSignedAttachmentURLs(messages []models.Message) {
+	var imageService *services.ImageService
+	expires := time.Now().Add(utils.GetEnvDuration("MESSAGE_ATTACHMENT_URL_TTL", time.Hour))
+
+	for i := range messages {
+		if messages[i].AttachmentPublicID == "" {
+			continue
+		}
+		if imageService == nil {
+			var err error
+			if imageService, err = services.NewImageService(); err != nil {
+				log.Printf("Warning: Failed to initialize image service for attachment links: %v", err)
+				return
+			}
+		}
+
+		signedURL, err := imageService.SignedAttachmentURL(messages[i].AttachmentPublicID, messages[i].AttachmentFormat, expires)
+		if err != nil {
+			log.Printf("Warning: Failed to sign attachment URL for message %d: %v", messages[i].ID, err)
+			continue
+		}
+		messages[i].AttachmentURL = signedURL
+		messages[i].AttachmentURLExpiresAt = &expires
+	}
+}
+
+// messageEditWindow is how long after sending a message its sender may still edit or delete it
+func messageEditWindow() time.Duration {
+	return utils.GetEnvDuration("MESSAGE_EDIT_WINDOW", 15*time.Minute)
+}
+
+// loadOwnMessage fetches a message for its sender, writing the error response and returning false
+// if it doesn't exist, belongs to someone else, or is past the edit window
+func loadOwnMessage(c *gin.Context, db *gorm.DB, action string) (models.Message, bool) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var message models.Message
+	if requester == "" {
+		log.Printf("Error: Not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return message, false
+	}
+
+	messageID, err := strconv.ParseUint(c.Param("message_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return message, false
+	}
+
+	if err := db.Where("id = ? AND group_id = ?", messageID, groupID).First(&message).Error; err != nil {
+		log.Printf("Error: Message %d not found in group %s: %v", messageID, groupID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return message, false
+	}
+
+	if message.Username != requester {
+		log.Printf("Error: User %s tried to %s message %d sent by %s", requester, action, message.ID, message.Username)
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only " + action + " your own messages"})
+		return message, false
+	}
+
+	window := messageEditWindow()
+	if time.Since(message.CreatedAt) > window {
+		log.Printf("Error: Message %d is past the %v edit window", message.ID, window)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Messages can only be changed within " + window.String() + " of sending",
+			"code":  "EDIT_WINDOW_PASSED",
+		})
+		return message, false
+	}
+
+	return message, true
+}
+
+// EditGroupMessage lets a sender change the content of their own message within the edit window
+func EditGroupMessage(c *gin.Context) {
+	var request models.EditMessageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid message input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message content"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	message, ok := loadOwnMessage(c, db, "edit")
+	if !ok {
+		return
+	}
+
+	// Editing is posting too, so it's blocked while the chat is locked or the sender is muted
+	var group models.Group
+	if err := db.Where("id = ?", message.GroupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	if !enforceChatModeration(c, db, group, message.Username) {
+		return
+	}
+
+	if request.Content == message.Content {
+		c.JSON(http.StatusOK, gin.H{"message": message, "success": true})
+		return
+	}
+
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Keep the previous content so moderators can see what was changed
+		revision := models.MessageRevision{
+			MessageID: message.ID,
+			GroupID:   message.GroupID,
+			Username:  message.Username,
+			Action:    "edit",
+			Content:   message.Content,
+			CreatedAt: now,
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		return tx.Model(&message).Updates(map[string]interface{}{
+			"content":   request.Content,
+			"edited_at": now,
+		}).Error
+	})
+	if err != nil {
+		log.Printf("Error: Failed to edit message %d: %v", message.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit message"})
+		return
+	}
+
+	message.Content = request.Content
+	message.EditedAt = &now
+
+	if err := LogActivity(message.Username, "edit_message", message.GroupID); err != nil {
+		log.Printf("Warning: Failed to log message activity: %v", err)
+	}
+
+	messages := []models.Message{message}
+	attachSignedAttachmentURLs(messages)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": messages[0],
 		"success": true,
 	})
 }
+
+// DeleteGroupMessage lets a sender remove their own message within the edit window
+func DeleteGroupMessage(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	message, ok := loadOwnMessage(c, db, "delete")
+	if !ok {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		revision := models.MessageRevision{
+			MessageID: message.ID,
+			GroupID:   message.GroupID,
+			Username:  message.Username,
+			Action:    "delete",
+			Content:   message.Content,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&message).Error
+	})
+	if err != nil {
+		log.Printf("Error: Failed to delete message %d: %v", message.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
+		return
+	}
+
+	if err := LogActivity(message.Username, "delete_message", message.GroupID); err != nil {
+		log.Printf("Warning: Failed to log message activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted", "success": true})
+}
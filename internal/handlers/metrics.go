@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMetrics serves per-route request metrics in the Prometheus text format
+func GetMetrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := utils.WriteRequestMetrics(c.Writer); err != nil {
+		log.Printf("Error: Failed to write metrics: %v", err)
+	}
+}
+
+// GetAlertRules exports Prometheus alerting rules for the configured latency and 5xx SLOs (admin only)
+func GetAlertRules(c *gin.Context) {
+	config, err := services.LoadSLOConfig()
+	if err != nil {
+		log.Printf("Error: Invalid SLO configuration: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid SLO configuration"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="groops-slo-rules.yml"`)
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", []byte(services.RenderAlertRules(config)))
+}
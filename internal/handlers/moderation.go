@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// loadModeratedGroup fetches a group for its organizer or a co-organizer, writing the error response and returning false otherwise
+func loadModeratedGroup(c *gin.Context, db *gorm.DB) (models.Group, bool) {
+	return loadManagedGroup(c, db, "moderate the chat")
+}
+
+// enforceChatModeration rejects posting when the chat is locked or the user is muted, writing the
+// error response and returning false. The organizer and co-organizers are never blocked.
+func enforceChatModeration(c *gin.Context, db *gorm.DB, group models.Group, username string) bool {
+	if policy.CanEditGroup(groupUser(db, group, username), group) {
+		return true
+	}
+
+	if group.ChatLocked {
+		log.Printf("Error: User %s tried to post in locked chat of group %s", username, group.ID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "The organizer has made this chat read-only", "code": "CHAT_LOCKED"})
+		return false
+	}
+
+	var mute models.ChatMute
+	err := db.Where("group_id = ? AND username = ? AND until > ?", group.ID, username, time.Now()).Limit(1).Find(&mute).Error
+	if err != nil {
+		log.Printf("Warning: Failed to check chat mute for %s in group %s: %v", username, group.ID, err)
+		return true
+	}
+	if mute.Username != "" {
+		log.Printf("Error: Muted user %s tried to post in group %s", username, group.ID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":       "You have been muted in this chat",
+			"code":        "CHAT_MUTED",
+			"muted_until": mute.Until,
+		})
+		return false
+	}
+
+	return true
+}
+
+// ModerateDeleteMessage removes any member's message from a group chat (organizer or co-organizer).
+// The original content is kept as a revision, and the author is told their message was removed.
+func ModerateDeleteMessage(c *gin.Context) {
+	requester := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, ok := loadModeratedGroup(c, db)
+	if !ok {
+		return
+	}
+
+	messageID, err := strconv.ParseUint(c.Param("message_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return
+	}
+
+	var message models.Message
+	if err := db.Where("id = ? AND group_id = ?", messageID, group.ID).First(&message).Error; err != nil {
+		log.Printf("Error: Message %d not found in group %s: %v", messageID, group.ID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return
+	}
+
+	// Co-organizers moderate members, not the owner
+	if message.Username == group.OrganiserID && !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can remove their own messages"})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		revision := models.MessageRevision{
+			MessageID: message.ID,
+			GroupID:   message.GroupID,
+			Username:  message.Username,
+			Action:    "moderate",
+			Content:   message.Content,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&message).Error
+	})
+	if err != nil {
+		log.Printf("Error: Failed to remove message %d: %v", message.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove message"})
+		return
+	}
+
+	if err := LogActivity(requester, "moderate_message", group.ID); err != nil {
+		log.Printf("Warning: Failed to log moderation activity: %v", err)
+	}
+
+	if message.Username != requester {
+		msg := fmt.Sprintf("A moderator removed one of your messages in '%s'", group.Name)
+		if err := createNotification(db, message.Username, "message_removed", msg, group.ID); err != nil {
+			log.Printf("Warning: Failed to create message removal notification: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message removed", "success": true})
+}
+
+// MuteChatMember stops an approved member posting in the group chat for a while (organizer or co-organizer).
+// Muting someone already muted replaces the previous expiry.
+func MuteChatMember(c *gin.Context) {
+	username := c.Param("username")
+	requester := c.GetString("username")
+
+	var request models.MuteChatMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid mute input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadModeratedGroup(c, db)
+	if !ok {
+		return
+	}
+
+	if username == requester {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't mute yourself"})
+		return
+	}
+	if username == group.OrganiserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "The organizer can't be muted"})
+		return
+	}
+
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND username = ? AND status = ?", group.ID, username, "approved").First(&member).Error; err != nil {
+		log.Printf("Error: Member %s not found in group %s: %v", username, group.ID, err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if policy.IsCoOrganizer(policy.User{Username: username, Membership: &member}, group) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Co-organizers can't be muted"})
+		return
+	}
+
+	now := time.Now()
+	mute := models.ChatMute{
+		GroupID:   group.ID,
+		Username:  username,
+		MutedBy:   requester,
+		Until:     now.Add(time.Duration(request.DurationMinutes) * time.Minute),
+		CreatedAt: now,
+	}
+	if err := db.Save(&mute).Error; err != nil {
+		log.Printf("Error: Failed to mute %s in group %s: %v", username, group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mute member"})
+		return
+	}
+
+	if err := LogActivity(requester, "mute_member", group.ID); err != nil {
+		log.Printf("Warning: Failed to log moderation activity: %v", err)
+	}
+
+	msg := fmt.Sprintf("You have been muted in the chat for '%s' for %s", group.Name,
+		formatDuration(time.Duration(request.DurationMinutes)*time.Minute))
+	if err := createNotification(db, username, "chat_muted", msg, group.ID); err != nil {
+		log.Printf("Warning: Failed to create mute notification: %v", err)
+	}
+
+	c.JSON(http.StatusOK, mute)
+}
+
+// UnmuteChatMember lifts a member's chat mute early (organizer or co-organizer)
+func UnmuteChatMember(c *gin.Context) {
+	username := c.Param("username")
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadModeratedGroup(c, db)
+	if !ok {
+		return
+	}
+
+	result := db.Where("group_id = ? AND username = ?", group.ID, username).Delete(&models.ChatMute{})
+	if result.Error != nil {
+		log.Printf("Error: Failed to unmute %s in group %s: %v", username, group.ID, result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmute member"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member is not muted"})
+		return
+	}
+
+	if err := LogActivity(requester, "unmute_member", group.ID); err != nil {
+		log.Printf("Warning: Failed to log moderation activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member unmuted"})
+}
+
+// ListChatMutes returns the group's active chat mutes (organizer or co-organizer)
+func ListChatMutes(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadModeratedGroup(c, db)
+	if !ok {
+		return
+	}
+
+	mutes := []models.ChatMute{}
+	if err := db.Where("group_id = ? AND until > ?", group.ID, time.Now()).Order("until ASC").Find(&mutes).Error; err != nil {
+		log.Printf("Error: Failed to fetch chat mutes for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch chat mutes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mutes": mutes})
+}
+
+// SetChatLock turns read-only mode on or off for a group chat (organizer or co-organizer)
+func SetChatLock(c *gin.Context) {
+	requester := c.GetString("username")
+
+	var request models.SetChatLockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid chat lock input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadModeratedGroup(c, db)
+	if !ok {
+		return
+	}
+
+	if group.ChatLocked == *request.Locked {
+		c.JSON(http.StatusOK, gin.H{"chat_locked": group.ChatLocked})
+		return
+	}
+
+	if err := db.Model(&group).Update("chat_locked", *request.Locked).Error; err != nil {
+		log.Printf("Error: Failed to update chat lock for group %s: %v", group.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update chat lock"})
+		return
+	}
+
+	eventType := "unlock_chat"
+	msg := fmt.Sprintf("The chat for '%s' is open again", group.Name)
+	if *request.Locked {
+		eventType = "lock_chat"
+		msg = fmt.Sprintf("The chat for '%s' is now read-only", group.Name)
+	}
+	if err := LogActivity(requester, eventType, group.ID); err != nil {
+		log.Printf("Warning: Failed to log moderation activity: %v", err)
+	}
+	notifyApprovedMembers(db, group.ID, requester, "chat_lock_changed", msg, "")
+
+	c.JSON(http.StatusOK, gin.H{"chat_locked": *request.Locked})
+}
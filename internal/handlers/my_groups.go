@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Sections of GET /me/groups
+const (
+	myGroupsUpcoming = "upcoming" // Approved, not started yet
+	myGroupsPending  = "pending"  // Join request awaiting approval, not started yet
+	myGroupsPast     = "past"     // Approved, already started
+)
+
+// myGroup is a group the user belongs to, with their membership in it
+type myGroup struct {
+	models.Group
+	Membership models.GroupMember `json:"membership"`
+}
+
+// myGroupsCounts is how many groups each section of GET /me/groups holds
+type myGroupsCounts struct {
+	Upcoming int64 `json:"upcoming"`
+	Pending  int64 `json:"pending"`
+	Past     int64 `json:"past"`
+}
+
+// ListMyGroups returns one section (?status=upcoming, pending or past; default upcoming) of the
+// groups the user has joined or asked to join, paginated, with the size of every section.
+// Upcoming and pending groups come soonest first, past groups most recent first.
+func ListMyGroups(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+	now := time.Now()
+
+	section := c.DefaultQuery("status", myGroupsUpcoming)
+	var condition, order string
+	switch section {
+	case myGroupsUpcoming:
+		condition, order = `group_member.status = 'approved' AND "group".date_time > ?`, `"group".date_time ASC`
+	case myGroupsPending:
+		condition, order = `group_member.status = 'pending' AND "group".date_time > ?`, `"group".date_time ASC`
+	case myGroupsPast:
+		condition, order = `group_member.status = 'approved' AND "group".date_time <= ?`, `"group".date_time DESC`
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be upcoming, pending or past"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // max limit
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	// Every section's size in one pass over the user's memberships
+	var counts myGroupsCounts
+	if err := db.Model(&models.GroupMember{}).
+		Select(`COUNT(*) FILTER (WHERE group_member.status = 'approved' AND "group".date_time > ?) AS upcoming,
+			COUNT(*) FILTER (WHERE group_member.status = 'pending' AND "group".date_time > ?) AS pending,
+			COUNT(*) FILTER (WHERE group_member.status = 'approved' AND "group".date_time <= ?) AS past`, now, now, now).
+		Joins(`JOIN "group" ON "group".id = group_member.group_id`).
+		Where("group_member.username = ?", username).
+		Scan(&counts).Error; err != nil {
+		log.Printf("Error: Failed to count groups for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch your groups"})
+		return
+	}
+
+	var groups []models.Group
+	if err := db.Joins(`JOIN group_member ON group_member.group_id = "group".id AND group_member.username = ?`, username).
+		Where(condition, now).
+		Order(order).
+		Limit(limit).Offset(offset).
+		Find(&groups).Error; err != nil {
+		log.Printf("Error: Failed to fetch groups for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch your groups"})
+		return
+	}
+
+	results, err := withMemberships(db, username, groups)
+	if err != nil {
+		log.Printf("Error: Failed to fetch memberships for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch your groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": section,
+		"groups": results,
+		"counts": counts,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// ListMyEventHistory returns the events the user took part in that have already started, archived
+// ones included, most recent first and paginated. Cancelled events are left out since they never happened.
+func ListMyEventHistory(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // max limit
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	query := db.Model(&models.Group{}).
+		Joins(`JOIN group_member ON group_member.group_id = "group".id AND group_member.username = ?`, username).
+		Where(`group_member.status = 'approved' AND "group".date_time <= ? AND "group".status IN ?`, time.Now(), models.GroupStatusesHeld)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Printf("Error: Failed to count event history for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
+		return
+	}
+
+	var groups []models.Group
+	if err := query.Order(`"group".date_time DESC`).Limit(limit).Offset(offset).Find(&groups).Error; err != nil {
+		log.Printf("Error: Failed to fetch event history for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
+		return
+	}
+
+	results, err := withMemberships(db, username, groups)
+	if err != nil {
+		log.Printf("Error: Failed to fetch memberships for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": results,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// membershipSnapshot is one group the user has joined or asked to join, as GET /me/memberships reports it
+type membershipSnapshot struct {
+	GroupID     string     `json:"group_id"`
+	GroupName   string     `json:"group_name"`
+	GroupStatus string     `json:"group_status"` // active, cancelled or archived
+	Status      string     `json:"status"`       // The user's membership: pending, approved or rejected
+	Role        string     `json:"role"`
+	UnreadCount int64      `json:"unread_count"`            // Chat messages from others past the user's read cursor; 0 unless approved
+	NextEventAt *time.Time `json:"next_event_at,omitempty"` // When the event starts, unless it has ended or was cancelled
+
+	DateTime    time.Time  `json:"-"`
+	EndDateTime *time.Time `json:"-"`
+}
+
+// ListMyMemberships returns every group the user has a membership in, with their status and role,
+// unread chat count and next event time, so clients can load their state in one request on start-up.
+// Groups come in event order, oldest first.
+func ListMyMemberships(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var snapshots []membershipSnapshot
+	if err := db.Table("group_member").
+		Select(`group_member.group_id, "group".name AS group_name, "group".status AS group_status,
+			group_member.status, group_member.role, "group".date_time, "group".end_date_time,
+			CASE WHEN group_member.status = 'approved' THEN (
+				SELECT COUNT(*) FROM message
+				WHERE message.group_id = group_member.group_id
+				AND message.deleted_at IS NULL
+				AND message.username <> group_member.username
+				AND message.id > COALESCE(message_read_cursor.last_read_message_id, 0)
+			) ELSE 0 END AS unread_count`).
+		Joins(`JOIN "group" ON "group".id = group_member.group_id AND "group".deleted_at IS NULL`).
+		Joins("LEFT JOIN message_read_cursor ON message_read_cursor.group_id = group_member.group_id AND message_read_cursor.username = group_member.username").
+		Where("group_member.username = ? AND group_member.deleted_at IS NULL", username).
+		Order(`"group".date_time ASC`).
+		Scan(&snapshots).Error; err != nil {
+		log.Printf("Error: Failed to fetch memberships for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch your memberships"})
+		return
+	}
+
+	now := time.Now()
+	for i := range snapshots {
+		group := models.Group{DateTime: snapshots[i].DateTime, EndDateTime: snapshots[i].EndDateTime, Status: snapshots[i].GroupStatus}
+		if !group.HasEnded(now) && !group.IsCancelled() {
+			snapshots[i].NextEventAt = &snapshots[i].DateTime
+		}
+	}
+	if snapshots == nil {
+		snapshots = []membershipSnapshot{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"memberships": snapshots,
+		"count":       len(snapshots),
+	})
+}
+
+// withMemberships pairs each group with the user's membership in it, filling in tags, organizers and
+// activity display for the response
+func withMemberships(db *gorm.DB, username string, groups []models.Group) ([]myGroup, error) {
+	groupIDs := make([]string, len(groups))
+	for i, group := range groups {
+		groupIDs[i] = group.ID
+	}
+	var memberships []models.GroupMember
+	if err := db.Where("username = ? AND group_id IN ?", username, groupIDs).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	membershipByGroup := make(map[string]models.GroupMember, len(memberships))
+	for _, membership := range memberships {
+		membershipByGroup[membership.GroupID] = membership
+	}
+
+	attachGroupTags(db, groups)
+	services.AttachOrganizers(db, groups)
+
+	results := make([]myGroup, len(groups))
+	for i, group := range groups {
+		display := services.ActivityDisplayFor(group.ActivityType)
+		group.ActivityDisplay = &display
+		results[i] = myGroup{Group: group, Membership: membershipByGroup[group.ID]}
+	}
+	return results, nil
+}
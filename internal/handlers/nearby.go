@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetGroupsToday lists upcoming groups near the user that start before local midnight
+func GetGroupsToday(c *gin.Context) {
+	getNearbyGroups(c, services.NearbyWindowToday)
+}
+
+// GetGroupsThisWeekend lists groups near the user happening this weekend (Friday evening to Sunday)
+func GetGroupsThisWeekend(c *gin.Context) {
+	getNearbyGroups(c, services.NearbyWindowWeekend)
+}
+
+// getNearbyGroups serves a nearby date-window listing for user_lat/user_lng and an optional radius
+// (km, default 25), narrowed by the same activity type, skill level, tag, price and size filters as
+// GET /groups
+func getNearbyGroups(c *gin.Context, window string) {
+	lat, latErr := strconv.ParseFloat(c.Query("user_lat"), 64)
+	lng, lngErr := strconv.ParseFloat(c.Query("user_lng"), 64)
+	if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_lat and user_lng are required"})
+		return
+	}
+
+	radius := 25.0
+	if radiusStr := c.Query("radius"); radiusStr != "" {
+		parsed, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || parsed <= 0 || parsed > services.NearbyMaxRadiusKm {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be between 0 and 50 km"})
+			return
+		}
+		radius = parsed
+	}
+
+	groups, start, end, err := services.GetNearbyService().FindGroups(c.Request.Context(), window, lat, lng, radius)
+	if err != nil {
+		log.Printf("Error: Failed to fetch %s groups near %.3f,%.3f: %v", window, lat, lng, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+
+	var filter GroupFilter
+	filter.Parse(c)
+	groups, err = filterNearbyGroups(database.GetDBWithContext(c.Request.Context()), filter, groups)
+	if err != nil {
+		log.Printf("Error: Failed to filter %s groups: %v", window, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"window":       window,
+		"window_start": start,
+		"window_end":   end,
+		"radius_km":    radius,
+		"groups":       groups,
+	})
+}
+
+// filterNearbyGroups keeps the nearby groups that match the filter's attribute filters, in order.
+// The nearby service has already applied the distance and date window, and its results are cached
+// per area, so the filters run as one query over the candidates' IDs.
+func filterNearbyGroups(db *gorm.DB, filter GroupFilter, groups []services.NearbyGroup) ([]services.NearbyGroup, error) {
+	if !filter.HasAttributeFilters() || len(groups) == 0 {
+		return groups, nil
+	}
+	filter.UserLat, filter.UserLng, filter.RadiusKm = nil, nil, 0
+	filter.Timeframe = groupTimeframeUpcoming
+
+	ids := make([]string, len(groups))
+	for i, group := range groups {
+		ids[i] = group.ID
+	}
+	var matchingIDs []string
+	if err := filter.ApplyFilters(db.Model(&models.Group{}).Where(`"group".id IN ?`, ids)).
+		Pluck(`"group".id`, &matchingIDs).Error; err != nil {
+		return nil, err
+	}
+
+	matching := make(map[string]bool, len(matchingIDs))
+	for _, id := range matchingIDs {
+		matching[id] = true
+	}
+	filtered := []services.NearbyGroup{}
+	for _, group := range groups {
+		if matching[group.ID] {
+			filtered = append(filtered, group)
+		}
+	}
+	return filtered, nil
+}
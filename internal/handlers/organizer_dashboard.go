@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardGroup is one group on the organizer dashboard
+type dashboardGroup struct {
+	ID                  string    `json:"id"`
+	Name                string    `json:"name"`
+	ActivityType        string    `json:"activity_type"`
+	DateTime            time.Time `json:"date_time"`
+	City                string    `json:"city"`
+	Status              string    `json:"status"`
+	Visibility          string    `json:"visibility"`
+	Role                string    `json:"role"`
+	MaxMembers          int       `json:"max_members"`
+	ApprovedMemberCount int       `json:"approved_member_count"`
+	ApprovedGuestCount  int       `json:"approved_guest_count"`
+	PendingCount        int       `json:"pending_count"`
+	UnreadMessages      int64     `json:"unread_messages"`
+	FillPercent         float64   `gorm:"-" json:"fill_percent"`
+}
+
+// GetOrganizerDashboard returns every group the authenticated user organizes or co-organizes, with
+// pending request counts, how full each group is and unread chat messages, in a single query.
+// Past groups are left out unless include_past=true.
+func GetOrganizerDashboard(c *gin.Context) {
+	requester := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	query := db.Table(`"group" AS g`).
+		Select(`g.id, g.name, g.activity_type, g.date_time, g.city, g.status, g.visibility, g.max_members,
+			g.approved_member_count, g.approved_guest_count, g.pending_count,
+			CASE WHEN g.organiser_id = ? THEN ? ELSE ? END AS role,
+			(SELECT COUNT(*) FROM message m
+				WHERE m.group_id = g.id AND m.deleted_at IS NULL AND m.username <> ?
+				AND m.id > COALESCE((
+					SELECT last_read_message_id FROM message_read_cursor r WHERE r.group_id = g.id AND r.username = ?
+				), 0)) AS unread_messages`,
+			requester, models.RoleOrganiser, models.RoleCoOrganiser, requester, requester).
+		Where("g.deleted_at IS NULL").
+		Where(`(g.organiser_id = ? OR EXISTS (
+			SELECT 1 FROM group_member co WHERE co.group_id = g.id AND co.username = ? AND co.status = ? AND co.role = ?
+		))`, requester, requester, "approved", models.RoleCoOrganiser)
+	if c.Query("include_past") != "true" {
+		query = query.Where("g.date_time >= ?", time.Now())
+	}
+
+	groups := []dashboardGroup{}
+	if err := query.Order("g.date_time ASC").Scan(&groups).Error; err != nil {
+		log.Printf("Error: Failed to fetch organizer dashboard for %s: %v", requester, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dashboard"})
+		return
+	}
+
+	var totalPending, totalUnread int64
+	upcoming := 0
+	now := time.Now()
+	for i := range groups {
+		group := &groups[i]
+		if group.MaxMembers > 0 {
+			occupied := group.ApprovedMemberCount + group.ApprovedGuestCount
+			group.FillPercent = math.Round(float64(occupied)*1000/float64(group.MaxMembers)) / 10
+		}
+		totalPending += int64(group.PendingCount)
+		totalUnread += group.UnreadMessages
+		if group.Status == models.GroupStatusActive && group.DateTime.After(now) {
+			upcoming++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"groups":          groups,
+		"total_pending":   totalPending,
+		"total_unread":    totalUnread,
+		"upcoming_groups": upcoming,
+	})
+}
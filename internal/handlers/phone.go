@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"groops/internal/utils"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrCodePhoneVerificationRequired is returned when an action needs a verified phone number
+const ErrCodePhoneVerificationRequired = "PHONE_VERIFICATION_REQUIRED"
+
+// requiresPhoneVerification reports whether creating a group with these settings needs a verified phone
+func requiresPhoneVerification(cost float64, maxMembers int) bool {
+	return cost > 0 || maxMembers > utils.GetEnvInt("PHONE_VERIFICATION_MIN_MEMBERS", 20)
+}
+
+// StartPhoneVerification sends a one-time code to the user's phone number
+func StartPhoneVerification(c *gin.Context) {
+	username := c.GetString("username")
+
+	var req models.StartPhoneVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Error: Invalid phone number: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number must be in international format, e.g. +919876543210"})
+		return
+	}
+
+	phoneService, err := services.NewPhoneVerificationService()
+	if err != nil {
+		log.Printf("Error: Failed to initialize phone verification: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Phone verification unavailable"})
+		return
+	}
+
+	// A number can only be verified by one account
+	db := database.GetDBWithContext(c.Request.Context())
+	var existing int64
+	if err := db.Model(&models.Account{}).
+		Where("phone_number = ? AND phone_verified = ? AND username != ?", req.PhoneNumber, true, username).
+		Count(&existing).Error; err != nil {
+		log.Printf("Error: Failed to check phone number: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start verification"})
+		return
+	}
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Phone number already verified by another account"})
+		return
+	}
+
+	if err := phoneService.StartVerification(req.PhoneNumber); err != nil {
+		log.Printf("Error: Failed to start phone verification for %s: %v", username, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send verification code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent"})
+}
+
+// CheckPhoneVerification confirms the one-time code and marks the phone as verified
+func CheckPhoneVerification(c *gin.Context) {
+	username := c.GetString("username")
+
+	var req models.CheckPhoneVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Error: Invalid verification input: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	phoneService, err := services.NewPhoneVerificationService()
+	if err != nil {
+		log.Printf("Error: Failed to initialize phone verification: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Phone verification unavailable"})
+		return
+	}
+
+	approved, err := phoneService.CheckVerification(req.PhoneNumber, req.Code)
+	if err != nil {
+		log.Printf("Error: Failed to check phone verification for %s: %v", username, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !approved {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification code"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	now := time.Now()
+	if err := db.Model(&models.Account{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"phone_number":      req.PhoneNumber,
+		"phone_verified":    true,
+		"phone_verified_at": now,
+	}).Error; err != nil {
+		log.Printf("Error: Failed to save phone verification: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save verification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Phone number verified",
+		"phone_verified": true,
+	})
+}
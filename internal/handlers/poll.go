@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreatePoll creates a new poll in a group (organizer only) and notifies members
+func CreatePoll(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var request models.CreatePollRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid poll input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	if request.ClosesAt != nil && request.ClosesAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Poll closing time must be in the future"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	// Check if group exists
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	// Check if requester is the organizer
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can create polls")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can create polls"})
+		return
+	}
+
+	poll := models.Poll{
+		GroupID:   groupID,
+		CreatedBy: requester,
+		Question:  strings.TrimSpace(request.Question),
+		Status:    models.PollStatusOpen,
+		ClosesAt:  request.ClosesAt,
+	}
+	for i, text := range request.Options {
+		poll.Options = append(poll.Options, models.PollOption{
+			Text:     strings.TrimSpace(text),
+			Position: i,
+		})
+	}
+
+	// Poll and its options are created together
+	if err := db.Create(&poll).Error; err != nil {
+		log.Printf("Error: Failed to create poll: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create poll"})
+		return
+	}
+
+	if err := LogActivity(requester, "create_poll", groupID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	// Notify members that a new poll is open
+	msg := fmt.Sprintf("A new poll is open in '%s': %s", group.Name, poll.Question)
+	notifyApprovedMembers(db, groupID, requester, "poll_opened", msg, strconv.FormatUint(uint64(poll.ID), 10))
+
+	c.JSON(http.StatusCreated, poll)
+}
+
+// ListGroupPolls returns all polls in a group with their results (approved members only)
+func ListGroupPolls(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if !policy.IsMember(groupUser(db, group, requester), group) {
+		log.Printf("Error: User %s not authorized to view polls for group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view group polls"})
+		return
+	}
+
+	var polls []models.Poll
+	if err := db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("group_id = ?", groupID).Order("created_at DESC").Find(&polls).Error; err != nil {
+		log.Printf("Error: Failed to fetch polls: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch polls"})
+		return
+	}
+
+	pollService := services.NewPollService()
+	response := make([]gin.H, 0, len(polls))
+	for _, poll := range polls {
+		pollResponse, err := buildPollResponse(db, pollService, poll, requester)
+		if err != nil {
+			log.Printf("Error: Failed to compute poll results: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch poll results"})
+			return
+		}
+		response = append(response, pollResponse)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPollResults returns a single poll with its current results (approved members only)
+func GetPollResults(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, poll, ok := loadGroupPoll(c, db)
+	if !ok {
+		return
+	}
+
+	if !policy.IsMember(groupUser(db, group, requester), group) {
+		log.Printf("Error: User %s not authorized to view polls for group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view group polls"})
+		return
+	}
+
+	response, err := buildPollResponse(db, services.NewPollService(), poll, requester)
+	if err != nil {
+		log.Printf("Error: Failed to compute poll results: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch poll results"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// VotePoll records or changes the requester's vote in an open poll
+func VotePoll(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var request models.VotePollRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid vote input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, poll, ok := loadGroupPoll(c, db)
+	if !ok {
+		return
+	}
+
+	if !policy.IsMember(groupUser(db, group, requester), group) {
+		log.Printf("Error: User %s not authorized to vote in group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only approved members can vote"})
+		return
+	}
+
+	if !poll.IsOpen() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Poll is closed"})
+		return
+	}
+
+	// Validate that the option belongs to this poll
+	validOption := false
+	for _, option := range poll.Options {
+		if option.ID == request.OptionID {
+			validOption = true
+			break
+		}
+	}
+	if !validOption {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid poll option"})
+		return
+	}
+
+	// Create the vote or change an existing one
+	var vote models.PollVote
+	err := db.Where("poll_id = ? AND username = ?", poll.ID, requester).First(&vote).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		vote = models.PollVote{
+			PollID:   poll.ID,
+			Username: requester,
+			OptionID: request.OptionID,
+		}
+		err = db.Create(&vote).Error
+	} else if err == nil {
+		vote.OptionID = request.OptionID
+		err = db.Save(&vote).Error
+	}
+	if err != nil {
+		log.Printf("Error: Failed to record vote: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record vote"})
+		return
+	}
+
+	response, err := buildPollResponse(db, services.NewPollService(), poll, requester)
+	if err != nil {
+		log.Printf("Error: Failed to compute poll results: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch poll results"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ClosePoll closes an open poll early (organizer only) and notifies members of the outcome
+func ClosePoll(c *gin.Context) {
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	group, poll, ok := loadGroupPoll(c, db)
+	if !ok {
+		return
+	}
+
+	if !policy.IsOrganizer(policy.UserNamed(requester), group) {
+		log.Printf("Error: Only the organizer can close polls")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the organizer can close polls"})
+		return
+	}
+
+	if poll.Status == models.PollStatusClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Poll is already closed"})
+		return
+	}
+
+	pollService := services.NewPollService()
+	if err := pollService.ClosePoll(&poll); err != nil {
+		log.Printf("Error: Failed to close poll: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close poll"})
+		return
+	}
+
+	if err := LogActivity(requester, "close_poll", group.ID); err != nil {
+		log.Printf("Warning: Failed to log activity: %v", err)
+	}
+
+	response, err := buildPollResponse(db, pollService, poll, requester)
+	if err != nil {
+		log.Printf("Error: Failed to compute poll results: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch poll results"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// loadGroupPoll loads the group and poll referenced by the route, writing an error response on failure
+func loadGroupPoll(c *gin.Context, db *gorm.DB) (models.Group, models.Poll, bool) {
+	groupID := c.Param("group_id")
+	pollID := c.Param("poll_id")
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, models.Poll{}, false
+	}
+
+	var poll models.Poll
+	if err := db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("id = ? AND group_id = ?", pollID, groupID).First(&poll).Error; err != nil {
+		log.Printf("Error: Poll not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Poll not found"})
+		return group, poll, false
+	}
+
+	return group, poll, true
+}
+
+// buildPollResponse combines a poll with its results and the requester's own vote
+func buildPollResponse(db *gorm.DB, pollService *services.PollService, poll models.Poll, username string) (gin.H, error) {
+	results, total, err := pollService.GetResults(poll)
+	if err != nil {
+		return nil, err
+	}
+
+	var myVote *uint
+	var vote models.PollVote
+	if err := db.Where("poll_id = ? AND username = ?", poll.ID, username).First(&vote).Error; err == nil {
+		myVote = &vote.OptionID
+	}
+
+	return gin.H{
+		"id":          poll.ID,
+		"group_id":    poll.GroupID,
+		"created_by":  poll.CreatedBy,
+		"question":    poll.Question,
+		"status":      poll.Status,
+		"is_open":     poll.IsOpen(),
+		"closes_at":   poll.ClosesAt,
+		"closed_at":   poll.ClosedAt,
+		"created_at":  poll.CreatedAt,
+		"results":     results,
+		"total_votes": total,
+		"my_vote":     myVote,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadChatGroup fetches a group for one of its chat members, writing the error response and returning false otherwise
+func loadChatGroup(c *gin.Context) (models.Group, bool) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	var group models.Group
+	if err := database.GetDBWithContext(c.Request.Context()).Preload("Members").Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+
+	if !policy.CanViewMessages(policy.MemberOf(group, requester), group) {
+		log.Printf("Error: User %s not authorized to access chat presence for group %s", requester, groupID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to view group messages"})
+		return group, false
+	}
+
+	return group, true
+}
+
+// GetGroupPresence lists the members currently viewing a group chat and whether they are typing.
+// Clients poll this while the chat is open.
+func GetGroupPresence(c *gin.Context) {
+	group, ok := loadChatGroup(c)
+	if !ok {
+		return
+	}
+
+	presence := services.GetPresenceService()
+	c.JSON(http.StatusOK, gin.H{
+		"viewers":          presence.Viewers(group.ID),
+		"heartbeat_within": presence.TTL().Seconds(),
+	})
+}
+
+// SendPresenceHeartbeat marks the requester as viewing a group chat, optionally as typing.
+// The response carries the current viewers so one call per interval is enough.
+func SendPresenceHeartbeat(c *gin.Context) {
+	var request models.PresenceHeartbeatRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid presence input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	group, ok := loadChatGroup(c)
+	if !ok {
+		return
+	}
+
+	presence := services.GetPresenceService()
+	presence.Heartbeat(group.ID, c.GetString("username"), request.Typing)
+	c.JSON(http.StatusOK, gin.H{
+		"viewers":          presence.Viewers(group.ID),
+		"heartbeat_within": presence.TTL().Seconds(),
+	})
+}
+
+// LeaveGroupPresence removes the requester from a group chat's viewers when they close it
+func LeaveGroupPresence(c *gin.Context) {
+	services.GetPresenceService().Leave(c.Param("group_id"), c.GetString("username"))
+	c.JSON(http.StatusOK, gin.H{"message": "Presence cleared"})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListMySkillLevels returns the logged-in user's skill levels per activity type
+func ListMySkillLevels(c *gin.Context) {
+	username := c.GetString("username")
+	db := database.GetDBWithContext(c.Request.Context())
+
+	skills := []models.UserSkillLevel{}
+	if err := db.Where("username = ?", username).Order("activity_type ASC").Find(&skills).Error; err != nil {
+		log.Printf("Error: Failed to fetch skill levels: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill levels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, skills)
+}
+
+// SetMySkillLevel creates or updates the logged-in user's skill level for an activity type
+func SetMySkillLevel(c *gin.Context) {
+	username := c.GetString("username")
+
+	var request models.SetSkillLevelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid skill level input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	activityType := normalizeActivityType(request.ActivityType)
+	if activityType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Activity type cannot be empty"})
+		return
+	}
+
+	skill := models.UserSkillLevel{
+		Username:     username,
+		ActivityType: activityType,
+		SkillLevel:   models.SkillLevel(request.SkillLevel),
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}, {Name: "activity_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"skill_level", "updated_at"}),
+	}).Create(&skill).Error; err != nil {
+		log.Printf("Error: Failed to save skill level: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save skill level"})
+		return
+	}
+
+	c.JSON(http.StatusOK, skill)
+}
+
+// DeleteMySkillLevel removes the logged-in user's skill level for an activity type
+func DeleteMySkillLevel(c *gin.Context) {
+	username := c.GetString("username")
+	activityType := normalizeActivityType(c.Param("activity_type"))
+
+	db := database.GetDBWithContext(c.Request.Context())
+	result := db.Where("username = ? AND activity_type = ?", username, activityType).Delete(&models.UserSkillLevel{})
+	if result.Error != nil {
+		log.Printf("Error: Failed to delete skill level: %v", result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete skill level"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill level not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill level removed"})
+}
+
+// normalizeActivityType makes activity types comparable regardless of case and spacing
+func normalizeActivityType(activityType string) string {
+	return strings.ToLower(strings.TrimSpace(activityType))
+}
+
+// skillMismatchWarning compares a user's recorded skill for the group's activity with the
+// group's skill level, returning a warning if they differ (empty if they match or either is unknown)
+func skillMismatchWarning(db *gorm.DB, username string, group models.Group) string {
+	if group.SkillLevel == nil {
+		return ""
+	}
+	groupLevel := models.SkillLevel(*group.SkillLevel)
+	if !groupLevel.IsValid() {
+		return ""
+	}
+
+	var skill models.UserSkillLevel
+	if err := db.Where("username = ? AND activity_type = ?", username, normalizeActivityType(group.ActivityType)).
+		First(&skill).Error; err != nil {
+		return ""
+	}
+
+	switch {
+	case skill.SkillLevel.Rank() < groupLevel.Rank():
+		return fmt.Sprintf("%s has %s skill level in %s, but this group is %s", username, skill.SkillLevel, group.ActivityType, groupLevel)
+	case skill.SkillLevel.Rank() > groupLevel.Rank():
+		return fmt.Sprintf("%s has %s skill level in %s, above this group's %s level", username, skill.SkillLevel, group.ActivityType, groupLevel)
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// normalizeTags lowercases and trims tag names, drops a leading '#', and removes blanks and duplicates
+func normalizeTags(raw []string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// setGroupTags replaces a group's tags, creating any tags that don't exist yet.
+// The group's search vector is rebuilt afterwards so its tags are searchable.
+func setGroupTags(tx *gorm.DB, groupID string, names []string) error {
+	if err := tx.Where("group_id = ?", groupID).Delete(&models.GroupTag{}).Error; err != nil {
+		return err
+	}
+
+	if len(names) > 0 {
+		tags := make([]models.Tag, len(names))
+		for i, name := range names {
+			tags[i] = models.Tag{Name: name}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+			return err
+		}
+
+		var tagIDs []uint
+		if err := tx.Model(&models.Tag{}).Where("name IN ?", names).Pluck("id", &tagIDs).Error; err != nil {
+			return err
+		}
+		groupTags := make([]models.GroupTag, len(tagIDs))
+		for i, tagID := range tagIDs {
+			groupTags[i] = models.GroupTag{GroupID: groupID, TagID: tagID}
+		}
+		if err := tx.Create(&groupTags).Error; err != nil {
+			return err
+		}
+	}
+
+	// The search vector trigger reads the group's tags, so re-fire it
+	return tx.Exec(`UPDATE "group" SET search_vector = NULL WHERE id = ?`, groupID).Error
+}
+
+// loadGroupTags returns the tag names of each group, keyed by group ID
+func loadGroupTags(db *gorm.DB, groupIDs []string) map[string][]string {
+	tagsByGroup := make(map[string][]string)
+	if len(groupIDs) == 0 {
+		return tagsByGroup
+	}
+
+	var rows []struct {
+		GroupID string
+		Name    string
+	}
+	if err := db.Model(&models.GroupTag{}).
+		Select("group_tag.group_id, tag.name").
+		Joins("JOIN tag ON tag.id = group_tag.tag_id").
+		Where("group_tag.group_id IN ?", groupIDs).
+		Order("tag.name ASC").
+		Scan(&rows).Error; err != nil {
+		log.Printf("Warning: Failed to load group tags: %v", err)
+		return tagsByGroup
+	}
+	for _, row := range rows {
+		tagsByGroup[row.GroupID] = append(tagsByGroup[row.GroupID], row.Name)
+	}
+	return tagsByGroup
+}
+
+// attachGroupTags fills in Tags on each group with one query
+func attachGroupTags(db *gorm.DB, groups []models.Group) {
+	groupIDs := make([]string, len(groups))
+	for i, group := range groups {
+		groupIDs[i] = group.ID
+	}
+	tagsByGroup := loadGroupTags(db, groupIDs)
+	for i := range groups {
+		groups[i].Tags = tagsByGroup[groups[i].ID]
+	}
+}
+
+// popularTag is a tag with how many upcoming listed groups use it
+type popularTag struct {
+	Name           string `json:"name"`
+	UpcomingGroups int64  `json:"upcoming_groups"`
+}
+
+// GetPopularTags returns the tags used by the most upcoming public groups, optionally within a ?city=
+func GetPopularTags(c *gin.Context) {
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 50 {
+			limit = parsed
+		}
+	}
+
+	query := database.GetDBWithContext(c.Request.Context()).Model(&models.GroupTag{}).
+		Select("tag.name, COUNT(*) AS upcoming_groups").
+		Joins("JOIN tag ON tag.id = group_tag.tag_id").
+		Joins(`JOIN "group" ON "group".id = group_tag.group_id`).
+		Where(`"group".date_time > NOW() AND "group".status = ? AND "group".visibility = ? AND "group".deleted_at IS NULL`, models.GroupStatusActive, models.VisibilityPublic).
+		Where(`"group".public_at IS NULL OR "group".public_at <= NOW()`)
+	if city := c.Query("city"); city != "" {
+		query = query.Where(`LOWER("group".city) = LOWER(?)`, city)
+	}
+
+	tags := []popularTag{}
+	if err := query.Group("tag.name").
+		Order("upcoming_groups DESC, tag.name ASC").
+		Limit(limit).
+		Scan(&tags).Error; err != nil {
+		log.Printf("Error: Failed to fetch popular tags: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch popular tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
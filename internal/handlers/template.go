@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxTemplatesPerOrganizer caps how many group templates one organizer can save
+const maxTemplatesPerOrganizer = 20
+
+// applyTemplateRequest copies a template request onto a template
+func applyTemplateRequest(template *models.GroupTemplate, request models.GroupTemplateRequest) {
+	template.TemplateName = strings.TrimSpace(request.TemplateName)
+	template.Name = request.Name
+	template.Location = request.Location
+	template.Cost = request.Cost
+	template.SkillLevel = request.SkillLevel
+	template.ActivityType = request.ActivityType
+	template.MaxMembers = request.MaxMembers
+	template.Description = request.Description
+	template.AutoApproveAll = request.AutoApproveAll
+	template.AutoApproveMinRating = request.AutoApproveMinRating
+	template.AutoApprovePreviousMembers = request.AutoApprovePreviousMembers
+	template.MaxGuestsPerMember = request.MaxGuestsPerMember
+	template.CutoffMinutes = request.CutoffMinutes
+	template.ApprovalMessage = strings.TrimSpace(request.ApprovalMessage)
+	template.RejectionMessage = strings.TrimSpace(request.RejectionMessage)
+	template.Visibility = request.Visibility
+	template.Tags = normalizeTags(request.Tags)
+	template.DurationMinutes = request.DurationMinutes
+	template.MinAge = request.MinAge
+	template.MaxAge = request.MaxAge
+	template.Gender = request.Gender
+	template.CostDetails = request.CostDetails
+	template.MemberNotes = strings.TrimSpace(request.MemberNotes)
+	template.SecondaryLocations = request.SecondaryLocations
+	if request.CostDetails != nil {
+		template.Cost = request.CostDetails.PerPerson
+	}
+}
+
+// templateFromGroup captures a group's settings, minus its date, as a template
+func templateFromGroup(db *gorm.DB, group models.Group) models.GroupTemplate {
+	tags := loadGroupTags(db, []string{group.ID})[group.ID]
+	if tags == nil {
+		tags = []string{}
+	}
+	return models.GroupTemplate{
+		Name:         group.Name,
+		Location:     group.Location,
+		Cost:         group.Cost,
+		SkillLevel:   group.SkillLevel,
+		ActivityType: group.ActivityType,
+		MaxMembers:   group.MaxMembers,
+		Description:  group.Description,
+
+		AutoApproveAll:             group.AutoApproveAll,
+		AutoApproveMinRating:       group.AutoApproveMinRating,
+		AutoApprovePreviousMembers: group.AutoApprovePreviousMembers,
+		MaxGuestsPerMember:         group.MaxGuestsPerMember,
+		CutoffMinutes:              group.CutoffMinutes,
+		ApprovalMessage:            group.ApprovalMessage,
+		RejectionMessage:           group.RejectionMessage,
+		Visibility:                 group.Visibility,
+		Tags:                       tags,
+		DurationMinutes:            group.DurationMinutes(),
+		MinAge:                     group.MinAge,
+		MaxAge:                     group.MaxAge,
+		Gender:                     group.Gender,
+		CostDetails:                group.CostDetails,
+		MemberNotes:                group.MemberNotes,
+		SecondaryLocations:         group.SecondaryLocations,
+	}
+}
+
+// saveNewTemplate stores a template for the authenticated organizer, enforcing the per-organizer cap
+// and unique names, and writes the response
+func saveNewTemplate(c *gin.Context, db *gorm.DB, template models.GroupTemplate) {
+	template.OrganiserID = c.GetString("username")
+
+	var existing int64
+	if err := db.Model(&models.GroupTemplate{}).Where("organiser_id = ?", template.OrganiserID).Count(&existing).Error; err != nil {
+		log.Printf("Error: Failed to count group templates: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template"})
+		return
+	}
+	if existing >= maxTemplatesPerOrganizer {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can save at most %d templates", maxTemplatesPerOrganizer)})
+		return
+	}
+
+	if err := db.Create(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already have a template with this name"})
+			return
+		}
+		log.Printf("Error: Failed to save group template for %s: %v", template.OrganiserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// loadOwnTemplate fetches one of the authenticated organizer's templates, writing a 404 if it isn't theirs
+func loadOwnTemplate(c *gin.Context, db *gorm.DB) (models.GroupTemplate, bool) {
+	var template models.GroupTemplate
+
+	templateID, err := strconv.ParseUint(c.Param("template_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return template, false
+	}
+
+	if err := db.Where("id = ? AND organiser_id = ?", templateID, c.GetString("username")).First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return template, false
+	}
+	return template, true
+}
+
+// ListGroupTemplates returns the authenticated organizer's saved templates
+func ListGroupTemplates(c *gin.Context) {
+	templates := []models.GroupTemplate{}
+	if err := database.GetDBWithContext(c.Request.Context()).Where("organiser_id = ?", c.GetString("username")).
+		Order("template_name ASC").Find(&templates).Error; err != nil {
+		log.Printf("Error: Failed to fetch group templates: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// CreateGroupTemplate saves a new template from scratch
+func CreateGroupTemplate(c *gin.Context) {
+	var request models.GroupTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid template input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	var template models.GroupTemplate
+	applyTemplateRequest(&template, request)
+	saveNewTemplate(c, database.GetDBWithContext(c.Request.Context()), template)
+}
+
+// SaveGroupAsTemplate saves an existing group's settings as a template (organizer or co-organizer).
+// The template belongs to whoever saves it.
+func SaveGroupAsTemplate(c *gin.Context) {
+	var request models.SaveAsTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid template input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "save this group as a template")
+	if !ok {
+		return
+	}
+
+	template := templateFromGroup(db, group)
+	template.TemplateName = strings.TrimSpace(request.TemplateName)
+	saveNewTemplate(c, db, template)
+}
+
+// UpdateGroupTemplate replaces one of the organizer's templates
+func UpdateGroupTemplate(c *gin.Context) {
+	var request models.GroupTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid template input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	template, ok := loadOwnTemplate(c, db)
+	if !ok {
+		return
+	}
+
+	applyTemplateRequest(&template, request)
+	if err := db.Save(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already have a template with this name"})
+			return
+		}
+		log.Printf("Error: Failed to update group template %d: %v", template.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteGroupTemplate removes one of the organizer's templates
+func DeleteGroupTemplate(c *gin.Context) {
+	db := database.GetDBWithContext(c.Request.Context())
+	template, ok := loadOwnTemplate(c, db)
+	if !ok {
+		return
+	}
+
+	if err := db.Delete(&template).Error; err != nil {
+		log.Printf("Error: Failed to delete group template %d: %v", template.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// CreateGroupFromTemplate creates a new group from one of the organizer's templates on the given date.
+// The group goes through the same checks as CreateGroup.
+func CreateGroupFromTemplate(c *gin.Context) {
+	var request models.ScheduleGroupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	template, ok := loadOwnTemplate(c, database.GetDBWithContext(c.Request.Context()))
+	if !ok {
+		return
+	}
+
+	groupRequest := template.ToCreateRequest(request.DateTime)
+	if name := strings.TrimSpace(request.Name); name != "" {
+		groupRequest.Name = name
+	}
+	createGroupFromRequest(c, groupRequest)
+}
+
+// DuplicateGroup creates a copy of a group on a new date (organizer or co-organizer). The copy is a
+// one-off owned by the requester: members, chat and the recurrence schedule aren't carried over.
+func DuplicateGroup(c *gin.Context) {
+	var request models.ScheduleGroupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	group, ok := loadManagedGroup(c, db, "duplicate this group")
+	if !ok {
+		return
+	}
+
+	groupRequest := templateFromGroup(db, group).ToCreateRequest(request.DateTime)
+	if name := strings.TrimSpace(request.Name); name != "" {
+		groupRequest.Name = name
+	}
+	createGroupFromRequest(c, groupRequest)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/policy"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JoinWaitlist adds the user to a group's waitlist (?type=waitlist, the default) or watch list (?type=watch)
+func JoinWaitlist(c *gin.Context) {
+	groupID := c.Param("group_id")
+	username := c.GetString("username")
+
+	entryType := c.DefaultQuery("type", models.WaitlistTypeWaitlist)
+	if entryType != models.WaitlistTypeWaitlist && entryType != models.WaitlistTypeWatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be 'waitlist' or 'watch'"})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if time.Now().After(group.DateTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot join the waitlist after the event has started"})
+		return
+	}
+
+	// Existing members don't need to wait for a spot
+	var memberCount int64
+	db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND username = ? AND status IN ?", groupID, username, []string{"approved", "pending"}).
+		Count(&memberCount)
+	if memberCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already a member or join request pending"})
+		return
+	}
+
+	// The waitlist is only for full groups; anyone can watch
+	if entryType == models.WaitlistTypeWaitlist {
+		if group.OccupiedSpots() < group.MaxMembers {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Group has open spots - request to join instead"})
+			return
+		}
+	}
+
+	var entry models.WaitlistEntry
+	if err := db.Where("group_id = ? AND username = ?", groupID, username).Limit(1).Find(&entry).Error; err != nil {
+		log.Printf("Error: Failed to check waitlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join waitlist"})
+		return
+	}
+
+	if entry.ID != 0 && (entry.Status == models.WaitlistStatusWaiting || entry.HasActiveOffer()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already on the waitlist"})
+		return
+	}
+
+	// Previously expired or claimed entries rejoin at the back of the queue
+	entry.GroupID = groupID
+	entry.Username = username
+	entry.Type = entryType
+	entry.Status = models.WaitlistStatusWaiting
+	entry.OfferedAt = nil
+	entry.OfferExpiresAt = nil
+	entry.CreatedAt = time.Now()
+
+	if err := db.Save(&entry).Error; err != nil {
+		log.Printf("Error: Failed to join waitlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join waitlist"})
+		return
+	}
+
+	if err := LogActivity(username, "join_waitlist", groupID); err != nil {
+		log.Printf("Warning: Failed to log waitlist activity: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// LeaveWaitlist removes the user from a group's waitlist or watch list
+func LeaveWaitlist(c *gin.Context) {
+	groupID := c.Param("group_id")
+	username := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var entry models.WaitlistEntry
+	if err := db.Where("group_id = ? AND username = ?", groupID, username).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not on the waitlist"})
+		return
+	}
+
+	hadOffer := entry.HasActiveOffer()
+	if err := db.Delete(&entry).Error; err != nil {
+		log.Printf("Error: Failed to leave waitlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave waitlist"})
+		return
+	}
+
+	// Pass a declined offer on to the next person
+	if hadOffer {
+		go services.NewWaitlistService().OnCapacityAvailable(groupID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left waitlist"})
+}
+
+// GetWaitlist returns the full waitlist to the organizer, or the requester's own entry and position
+func GetWaitlist(c *gin.Context) {
+	groupID := c.Param("group_id")
+	requester := c.GetString("username")
+
+	db := database.GetDBWithContext(c.Request.Context())
+
+	var group models.Group
+	if err := db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		log.Printf("Error: Group not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	if policy.IsOrganizer(policy.UserNamed(requester), group) {
+		var entries []models.WaitlistEntry
+		if err := db.Where("group_id = ? AND status IN ?", groupID, []string{models.WaitlistStatusWaiting, models.WaitlistStatusOffered}).
+			Order("created_at ASC").Find(&entries).Error; err != nil {
+			log.Printf("Error: Failed to fetch waitlist: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waitlist"})
+			return
+		}
+		c.JSON(http.StatusOK, entries)
+		return
+	}
+
+	var entry models.WaitlistEntry
+	if err := db.Where("group_id = ? AND username = ?", groupID, requester).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not on the waitlist"})
+		return
+	}
+
+	response := gin.H{"entry": entry}
+	if entry.Type == models.WaitlistTypeWaitlist && entry.Status == models.WaitlistStatusWaiting {
+		var ahead int64
+		db.Model(&models.WaitlistEntry{}).
+			Where("group_id = ? AND type = ? AND status = ? AND created_at < ?",
+				groupID, models.WaitlistTypeWaitlist, models.WaitlistStatusWaiting, entry.CreatedAt).
+			Count(&ahead)
+		response["position"] = ahead + 1
+	}
+
+	c.JSON(http.StatusOK, response)
+}
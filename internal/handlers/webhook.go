@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhooksPerUser caps how many outbound webhook subscriptions one account can hold
+const maxWebhooksPerUser = 10
+
+// ListWebhookEvents returns the documented catalog of events webhooks can subscribe to
+func ListWebhookEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"events": services.WebhookEventCatalog,
+		"delivery": gin.H{
+			"method":    "POST",
+			"headers":   []string{"X-Groops-Event", "X-Groops-Delivery", "X-Groops-Signature"},
+			"signature": "X-Groops-Signature is sha256=<hex HMAC-SHA256 of the raw body keyed with the subscription secret>",
+		},
+	})
+}
+
+// webhookResponse is a subscription as returned to its owner
+func webhookResponse(subscription models.WebhookSubscription) gin.H {
+	return gin.H{
+		"id":               subscription.ID,
+		"target_url":       subscription.TargetURL,
+		"events":           subscription.EventList(),
+		"last_delivery_at": subscription.LastDeliveryAt,
+		"last_status":      subscription.LastStatus,
+		"created_at":       subscription.CreatedAt,
+	}
+}
+
+// ListWebhooks returns the authenticated user's webhook subscriptions
+func ListWebhooks(c *gin.Context) {
+	username := c.GetString("username")
+
+	var subscriptions []models.WebhookSubscription
+	if err := database.GetDBWithContext(c.Request.Context()).Where("username = ?", username).Order("created_at ASC").Find(&subscriptions).Error; err != nil {
+		log.Printf("Error: Failed to fetch webhooks for %s: %v", username, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	webhooks := make([]gin.H, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		webhooks = append(webhooks, webhookResponse(subscription))
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// CreateWebhook subscribes a URL to catalog events. The signing secret is only returned here.
+func CreateWebhook(c *gin.Context) {
+	username := c.GetString("username")
+
+	var request models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Error: Invalid webhook input: %s", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid input: %s", err.Error())})
+		return
+	}
+
+	// De-duplicate and validate event names against the catalog
+	seen := make(map[string]bool)
+	var events []string
+	for _, event := range request.Events {
+		event = strings.TrimSpace(event)
+		if !services.IsWebhookEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event: " + event})
+			return
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+
+	if err := services.ValidateWebhookTarget(request.TargetURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDBWithContext(c.Request.Context())
+	var existing int64
+	if err := db.Model(&models.WebhookSubscription{}).Where("username = ?", username).Count(&existing).Error; err != nil {
+		log.Printf("Error: Failed to count webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	if existing >= maxWebhooksPerUser {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can have at most %d webhooks", maxWebhooksPerUser)})
+		return
+	}
+
+	secret, err := services.GenerateWebhookSecret()
+	if err != nil {
+		log.Printf("Error: Failed to generate webhook secret: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	subscription := models.WebhookSubscription{
+		Username:  username,
+		TargetURL: request.TargetURL,
+		Events:    strings.Join(events, ","),
+		Secret:    secret,
+	}
+	if err := db.Create(&subscription).Error; err != nil {
+		log.Printf("Error: Failed to create webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	response := webhookResponse(subscription)
+	response["secret"] = secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// loadOwnWebhook fetches one of the authenticated user's subscriptions, writing a 404 if it isn't theirs
+func loadOwnWebhook(c *gin.Context) (models.WebhookSubscription, bool) {
+	var subscription models.WebhookSubscription
+
+	webhookID, err := strconv.ParseUint(c.Param("webhook_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return subscription, false
+	}
+
+	if err := database.GetDBWithContext(c.Request.Context()).Where("id = ? AND username = ?", webhookID, c.GetString("username")).First(&subscription).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return subscription, false
+	}
+	return subscription, true
+}
+
+// DeleteWebhook removes one of the authenticated user's subscriptions
+func DeleteWebhook(c *gin.Context) {
+	subscription, ok := loadOwnWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := database.GetDBWithContext(c.Request.Context()).Delete(&subscription).Error; err != nil {
+		log.Printf("Error: Failed to delete webhook %d: %v", subscription.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// TestWebhook sends a sample delivery for each subscribed event (or ?event=) and reports the result
+func TestWebhook(c *gin.Context) {
+	subscription, ok := loadOwnWebhook(c)
+	if !ok {
+		return
+	}
+
+	event := c.Query("event")
+	if event == "" {
+		event = subscription.EventList()[0]
+	}
+	if !services.IsWebhookEvent(event) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event: " + event})
+		return
+	}
+
+	var sample map[string]interface{}
+	for _, info := range services.WebhookEventCatalog {
+		if info.Name == event {
+			sample = info.Sample
+		}
+	}
+
+	status, err := services.NewOutboundWebhookService().Deliver(subscription, services.WebhookDelivery{
+		Event: event,
+		Test:  true,
+		Data:  sample,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"delivered": false, "status": status, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"delivered": true, "status": status, "event": event})
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"groops/internal/policy"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin restricts a route to the accounts listed in ADMIN_USERNAMES. It runs after AuthMiddleware.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.GetString("username")
+		if username == "" || !policy.IsAdmin(username) {
+			log.Printf("Error: Non-admin %q tried to use %s", username, c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"groops/internal/services"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAPIKey authenticates public API requests by their X-API-Key header and enforces
+// the key's daily quota, reporting it through X-RateLimit-* response headers
+func RequireAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := c.GetHeader("X-API-Key")
+		if rawKey == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "X-API-Key header required"})
+			return
+		}
+
+		key, used, err := services.NewAPIKeyService().Authorize(rawKey)
+		switch {
+		case errors.Is(err, services.ErrAPIKeyInvalid):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked API key"})
+			return
+		case err != nil && !errors.Is(err, services.ErrAPIKeyQuotaExceeded):
+			log.Printf("Error: Failed to authorize API key: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authorize API key"})
+			return
+		}
+
+		remaining := key.DailyQuota - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		resetAt := services.QuotaResetAt()
+		c.Header("X-RateLimit-Limit", strconv.Itoa(key.DailyQuota))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+		if errors.Is(err, services.ErrAPIKeyQuotaExceeded) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":    "Daily API quota exceeded",
+				"code":     "API_QUOTA_EXCEEDED",
+				"reset_at": resetAt,
+			})
+			return
+		}
+
+		c.Set("api_key_id", key.ID)
+		c.Next()
+	}
+}
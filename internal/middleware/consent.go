@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"groops/internal/services"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// consentExemptRoutes can be written to without having accepted the current policies
+var consentExemptRoutes = map[string]bool{
+	"/api/consent":            true,
+	"/api/auth/tokens/revoke": true,
+}
+
+// RequireCurrentConsent blocks API writes from users who haven't accepted the current terms of
+// service and privacy policy. Reads keep working so the client can still show the policies.
+func RequireCurrentConsent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		username := c.GetString("username")
+		if username == "" || consentExemptRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		needsConsent, err := services.NewConsentService().NeedsConsent(username)
+		if err != nil {
+			// Don't lock everyone out if the check itself fails
+			log.Printf("Warning: Failed to check policy consent for %s: %v", username, err)
+			c.Next()
+			return
+		}
+		if needsConsent {
+			versions := services.CurrentPolicyVersions()
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":           "Please review and accept the updated terms of service and privacy policy",
+				"code":            services.ErrCodeConsentRequired,
+				"terms_version":   versions.Terms,
+				"privacy_version": versions.Privacy,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
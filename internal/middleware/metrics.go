@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"groops/internal/utils"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners probing random paths
+// all land in one series
+const unmatchedRoute = "unmatched"
+
+// RequestMetrics records latency and status per route template (e.g. /groups/:group_id).
+// Register it before gin.Recovery so panics are counted as the 500s they turn into.
+func RequestMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		utils.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// RequireMetricsToken restricts a route to scrapers presenting METRICS_TOKEN as a bearer token.
+// Without a configured token the route doesn't exist.
+func RequireMetricsToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := os.Getenv("METRICS_TOKEN")
+		if token == "" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid metrics token"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"groops/internal/database"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryCounter logs how many database queries each request ran, with its request ID. Only queries
+// built from database.GetDBWithContext(c.Request.Context()) are counted. Enable with PROFILE_DB_QUERIES.
+func QueryCounter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, counter := database.WithQueryCounter(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		start := time.Now()
+
+		c.Next()
+
+		log.Printf("[QUERIES] request_id=%s %s %s queries=%d duration=%v",
+			c.GetString("request_id"), c.Request.Method, c.FullPath(), atomic.LoadInt64(counter), time.Since(start))
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"groops/internal/utils"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimiterSweepInterval is how often idle buckets are dropped from memory
+const rateLimiterSweepInterval = 10 * time.Minute
+
+// tokenBucket holds the tokens left for one key; it refills continuously up to the burst size
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// tokenBucketLimiter is an in-memory token bucket per key. State is per process,
+// so with several instances each one enforces the limit separately.
+type tokenBucketLimiter struct {
+	rate  float64 // tokens added per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newTokenBucketLimiter(perMinute, burst int) *tokenBucketLimiter {
+	if perMinute < 1 {
+		perMinute = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucketLimiter{
+		rate:      float64(perMinute) / 60,
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow takes a token for key if one is available; otherwise it reports how long until one is
+func (l *tokenBucketLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > rateLimiterSweepInterval {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate)
+	bucket.updated = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely; they behave exactly like new ones
+func (l *tokenBucketLimiter) sweep(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// ChatRateLimit limits how fast each user can post in each group chat, answering 429 with
+// Retry-After once they run out. Register the returned handler on every route that sends
+// messages so they share one budget. Configured by CHAT_RATE_LIMIT_PER_MINUTE and CHAT_RATE_LIMIT_BURST.
+func ChatRateLimit() gin.HandlerFunc {
+	limiter := newTokenBucketLimiter(
+		utils.GetEnvInt("CHAT_RATE_LIMIT_PER_MINUTE", 20),
+		utils.GetEnvInt("CHAT_RATE_LIMIT_BURST", 5),
+	)
+
+	return func(c *gin.Context) {
+		key := c.GetString("username") + "|" + c.Param("group_id")
+		allowed, retryAfter := limiter.allow(key, time.Now())
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":               "You're sending messages too quickly - please wait a moment",
+				"code":                "CHAT_RATE_LIMITED",
+				"retry_after_seconds": seconds,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in and out of the server
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits incoming IDs to something safe to log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID tags each request with an ID, reusing the one set by an upstream proxy when it looks sane.
+// The ID is stored as "request_id" and echoed in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			buf := make([]byte, 8)
+			if _, err := rand.Read(buf); err == nil {
+				id = hex.EncodeToString(buf)
+			} else {
+				id = ""
+			}
+		}
+
+		if id != "" {
+			c.Set("request_id", id)
+			c.Header(RequestIDHeader, id)
+		}
+		c.Next()
+	}
+}
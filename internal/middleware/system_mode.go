@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"groops/internal/services"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// systemModeExemptRoutes keep answering in maintenance and read-only mode, so load balancers don't
+// take instances out of rotation and metrics keep flowing during a migration
+var systemModeExemptRoutes = map[string]bool{
+	"/health":  true,
+	"/metrics": true,
+}
+
+// EnforceSystemMode puts the API into maintenance or read-only mode while the system.mode setting
+// (or SYSTEM_MODE) says so. Maintenance rejects every request; read-only rejects writes and lets
+// reads through. Both answer 503 with a Retry-After header so clients know when to try again.
+func EnforceSystemMode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := services.GetSystemModeService().Status()
+		if status.Mode == services.SystemModeNormal || systemModeExemptRoutes[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		message, code := "Groops is down for scheduled maintenance, please try again shortly", services.ErrCodeMaintenance
+		if status.Mode == services.SystemModeReadOnly {
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				c.Next()
+				return
+			}
+			message, code = "Groops is read-only while we carry out maintenance, so changes can't be saved right now. Please try again shortly.", services.ErrCodeReadOnly
+		}
+		if status.Message != "" {
+			message = status.Message
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(status.RetryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": message, "code": code})
+	}
+}
@@ -17,24 +17,68 @@ type ActivityLog struct {
 
 // Account represents a user account in the system
 type Account struct {
-	GoogleID      string        `gorm:"uniqueIndex;size:128;not null" json:"google_id"`
-	Username      string        `gorm:"primaryKey;size:30;not null" json:"username" binding:"required,alphanum"`
-	Email         string        `gorm:"uniqueIndex;size:255;not null" json:"email" binding:"required,email"`
-	EmailVerified bool          `gorm:"not null;default:false" json:"email_verified"`
-	FullName      string        `gorm:"size:255" json:"full_name"`
-	GivenName     string        `gorm:"size:100" json:"given_name"`
-	FamilyName    string        `gorm:"size:100" json:"family_name"`
-	Locale        string        `gorm:"size:10" json:"locale"`
-	DateJoined    time.Time     `gorm:"not null" json:"date_joined"`
-	Rating        float64       `gorm:"type:decimal(3,2);not null;default:5.0" json:"rating"`
-	Bio           string        `gorm:"type:text" json:"bio"`
-	AvatarURL     string        `gorm:"size:512" json:"avatar_url"`
-	Activities    []ActivityLog `gorm:"foreignKey:Username" json:"activities"`
-	OwnedGroups   []Group       `gorm:"foreignKey:OrganiserID" json:"owned_groups"`
-	JoinedGroups  []GroupMember `gorm:"foreignKey:Username" json:"joined_groups"`
-	LastLogin     time.Time     `gorm:"not null" json:"last_login"`
-	CreatedAt     time.Time     `gorm:"not null" json:"created_at"`
-	UpdatedAt     time.Time     `gorm:"not null" json:"updated_at"`
+	GoogleID        string        `gorm:"uniqueIndex;size:128;not null" json:"google_id"`
+	Username        string        `gorm:"primaryKey;size:30;not null" json:"username" binding:"required,alphanum"`
+	Email           string        `gorm:"uniqueIndex;size:255;not null" json:"email" binding:"required,email"`
+	EmailVerified   bool          `gorm:"not null;default:false" json:"email_verified"`
+	FullName        string        `gorm:"size:255" json:"full_name"`
+	GivenName       string        `gorm:"size:100" json:"given_name"`
+	FamilyName      string        `gorm:"size:100" json:"family_name"`
+	Locale          string        `gorm:"size:10" json:"locale"`
+	DateJoined      time.Time     `gorm:"not null" json:"date_joined"`
+	Rating          float64       `gorm:"type:decimal(3,2);not null;default:5.0" json:"rating"`
+	Bio             string        `gorm:"type:text" json:"bio"`
+	AvatarURL       string        `gorm:"size:512" json:"avatar_url"`
+	PhoneNumber     string        `gorm:"size:20;index" json:"phone_number,omitempty"`
+	PhoneVerified   bool          `gorm:"not null;default:false" json:"phone_verified"`
+	PhoneVerifiedAt *time.Time    `json:"phone_verified_at,omitempty"`
+	ShowFullName    bool          `gorm:"not null;default:true" json:"show_full_name"` // Privacy: show full name to other users
+	TokenVersion    int           `gorm:"not null;default:0" json:"-"`                 // Bumped to revoke all API tokens
+	Activities      []ActivityLog `gorm:"foreignKey:Username" json:"activities"`
+	OwnedGroups     []Group       `gorm:"foreignKey:OrganiserID" json:"owned_groups"`
+	JoinedGroups    []GroupMember `gorm:"foreignKey:Username" json:"joined_groups"`
+	LastLogin       time.Time     `gorm:"not null" json:"last_login"`
+	CreatedAt       time.Time     `gorm:"not null" json:"created_at"`
+	UpdatedAt       time.Time     `gorm:"not null" json:"updated_at"`
+
+	// Google OAuth tokens (AES-GCM encrypted) for integrations that act on the user's behalf
+	EncryptedAccessToken  string     `gorm:"type:text" json:"-"`
+	EncryptedRefreshToken string     `gorm:"type:text" json:"-"`
+	GoogleTokenExpiry     *time.Time `json:"-"`
+
+	// Policy versions the user last accepted; API writes are blocked until they match the current ones
+	TermsVersion   string `gorm:"size:30" json:"terms_version"`
+	PrivacyVersion string `gorm:"size:30" json:"privacy_version"`
+
+	// Set when an admin merged this account into another; signing in lands on that account instead
+	MergedInto *string `gorm:"size:30;index" json:"merged_into,omitempty"`
+
+	// Set when the account is deleted; an admin can restore it until the purge worker removes it
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Optional, only shown to the account owner; groups with age or gender restrictions check them on join
+	DateOfBirth *time.Time `gorm:"type:date" json:"date_of_birth,omitempty"`
+	Gender      *string    `gorm:"size:20" json:"gender,omitempty"`
+}
+
+// Genders an account can list, and a group can be restricted to
+const (
+	GenderFemale    = "female"
+	GenderMale      = "male"
+	GenderNonBinary = "non_binary"
+)
+
+// Age returns the account holder's age in whole years at now, and false if they haven't given a date of birth
+func (a Account) Age(now time.Time) (int, bool) {
+	if a.DateOfBirth == nil {
+		return 0, false
+	}
+	dob := *a.DateOfBirth
+	age := now.Year() - dob.Year()
+	if now.Month() < dob.Month() || (now.Month() == dob.Month() && now.Day() < dob.Day()) {
+		age--
+	}
+	return age, true
 }
 
 // BeforeCreate hook is called before creating a new account
@@ -70,14 +114,33 @@ type CreateAccountRequest struct {
 	FullName  string `json:"full_name"`
 	Bio       string `json:"bio"`
 	AvatarURL string `json:"avatar_url"`
+
+	// Must be true: the user accepts the current terms of service and privacy policy
+	AcceptedPolicies bool `json:"accepted_policies"`
 }
 
 // UpdateAccountRequest for profile updates
-// Only bio and avatar_url are updatable for now
+// Only bio, avatar_url, privacy settings and the eligibility fields are updatable for now
 // You can expand this as needed
 type UpdateAccountRequest struct {
-	Bio       string `json:"bio"`
-	AvatarURL string `json:"avatar_url"`
+	Bio          string `json:"bio"`
+	AvatarURL    string `json:"avatar_url"`
+	ShowFullName *bool  `json:"show_full_name"`
+
+	// Used to check age and gender restrictions on groups
+	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
+	Gender      *string    `json:"gender,omitempty" binding:"omitempty,oneof=female male non_binary"`
+}
+
+// StartPhoneVerificationRequest starts an OTP verification for a phone number (E.164 format)
+type StartPhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+}
+
+// CheckPhoneVerificationRequest submits the OTP received by SMS
+type CheckPhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	Code        string `json:"code" binding:"required,numeric,min=4,max=10"`
 }
 
 // Notification represents a user notification in the system
@@ -90,6 +153,18 @@ type Notification struct {
 	GroupID           string    `gorm:"size:50" json:"group_id"`
 	CreatedAt         time.Time `gorm:"not null" json:"created_at"`
 	Read              bool      `gorm:"not null;default:false" json:"read"`
+
+	// Where the notification leads, so clients can deep link and render rich items
+	Link NotificationLink `gorm:"embedded;embeddedPrefix:link_" json:"link"`
+}
+
+// NotificationLink is the structured destination of a notification.
+// Route is a client-side path; TargetType/TargetID identify the object it opens.
+type NotificationLink struct {
+	Action     string `gorm:"size:30;not null;default:''" json:"action,omitempty"`
+	TargetType string `gorm:"size:30;not null;default:''" json:"target_type,omitempty"`
+	TargetID   string `gorm:"size:64;not null;default:''" json:"target_id,omitempty"`
+	Route      string `gorm:"size:255;not null;default:''" json:"route,omitempty"`
 }
 
 // LoginLog represents a user login/logout history record
@@ -106,3 +181,10 @@ type LoginLog struct {
 	SessionID  string     `gorm:"size:64;uniqueIndex" json:"session_id"`
 	IsTemp     bool       `gorm:"not null" json:"is_temp"` // Flag for temp accounts
 }
+
+// MergeAccountsRequest folds a duplicate account into the account the user keeps (admin only)
+type MergeAccountsRequest struct {
+	SourceUsername string `json:"source_username" binding:"required,max=30"`
+	TargetUsername string `json:"target_username" binding:"required,max=30"`
+	DryRun         bool   `json:"dry_run"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// ActivityDisplay is the server-chosen presentation for an activity type,
+// shared by listings, group pages and emails so they all look the same
+type ActivityDisplay struct {
+	ActivityType  string `json:"activity_type"`
+	Label         string `json:"label"`
+	Icon          string `json:"icon"`  // Material Symbols icon name
+	Color         string `json:"color"` // Hex accent color, e.g. #2E7D32
+	CoverImageURL string `json:"cover_image_url"`
+}
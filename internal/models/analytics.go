@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Analytics event types
+const (
+	AnalyticsGroupViewed     = "group_viewed"
+	AnalyticsSearchPerformed = "search_performed"
+	AnalyticsJoinClicked     = "join_clicked"
+)
+
+// AnalyticsEvent is one tracked interaction, kept raw for ANALYTICS_RETENTION and rolled up daily
+type AnalyticsEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EventType  string    `gorm:"size:30;not null;index" json:"event_type"`
+	Username   string    `gorm:"size:30;index" json:"username"` // Empty for anonymous visitors
+	GroupID    string    `gorm:"size:50;index" json:"group_id"` // Empty for events not about one group
+	Detail     string    `gorm:"size:255" json:"detail"`        // e.g. the search terms
+	OccurredAt time.Time `gorm:"not null;index" json:"occurred_at"`
+}
+
+// AnalyticsDailyRollup counts one event type for one group (or "" for events not about a group) over a UTC day
+type AnalyticsDailyRollup struct {
+	Day         time.Time `gorm:"type:date;primaryKey" json:"day"`
+	EventType   string    `gorm:"size:30;primaryKey" json:"event_type"`
+	GroupID     string    `gorm:"size:50;primaryKey" json:"group_id"`
+	Events      int64     `gorm:"not null;default:0" json:"events"`
+	UniqueUsers int64     `gorm:"not null;default:0" json:"unique_users"` // Signed-in users only
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Announcement is an organizer-only post to a group, kept separate from chat so it doesn't scroll away
+type Announcement struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	GroupID   string     `gorm:"size:50;not null;index" json:"group_id"`
+	Author    string     `gorm:"size:30;not null" json:"author"`
+	Title     string     `gorm:"size:120;not null" json:"title"`
+	Body      string     `gorm:"type:text;not null" json:"body"`
+	Pinned    bool       `gorm:"not null;default:false" json:"pinned"`
+	CreatedAt time.Time  `gorm:"not null;index" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null" json:"updated_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// CreateAnnouncementRequest represents a new announcement
+type CreateAnnouncementRequest struct {
+	Title  string `json:"title" binding:"required,max=120"`
+	Body   string `json:"body" binding:"required,max=5000"`
+	Pinned bool   `json:"pinned"`
+}
+
+// UpdateAnnouncementRequest changes an announcement; omitted fields are left as they are
+type UpdateAnnouncementRequest struct {
+	Title  *string `json:"title" binding:"omitempty,max=120"`
+	Body   *string `json:"body" binding:"omitempty,max=5000"`
+	Pinned *bool   `json:"pinned"`
+}
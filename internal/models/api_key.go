@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey grants read-only access to the public API; only a hash of the key is stored
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Username   string     `gorm:"size:30;not null;index" json:"username"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	KeyPrefix  string     `gorm:"size:16;not null" json:"key_prefix"` // First characters of the key so owners can tell keys apart
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	DailyQuota int        `gorm:"not null" json:"daily_quota"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"not null" json:"created_at"`
+}
+
+// BeforeCreate hook is called before creating a new API key
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// APIKeyUsage counts requests made with a key on one UTC day
+type APIKeyUsage struct {
+	APIKeyID uint      `gorm:"primaryKey" json:"-"`
+	Day      time.Time `gorm:"primaryKey;type:date" json:"day"`
+	Requests int       `gorm:"not null;default:0" json:"requests"`
+}
+
+// CreateAPIKeyRequest names a new public API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
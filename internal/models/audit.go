@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Audit event types
+const (
+	AuditSessionAnomaly = "session_anomaly" // A session's country or device changed mid-session
+	AuditSessionRevoked = "session_revoked" // Sessions were ended because of an anomaly
+	AuditAccountMerged  = "account_merged"  // An admin merged a duplicate account into this one
+)
+
+// AuditEvent records a security-relevant event on an account for later review
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username  string    `gorm:"size:30;index" json:"username"`
+	SessionID string    `gorm:"size:64;index" json:"-"`
+	Event     string    `gorm:"size:30;not null;index" json:"event"`
+	Detail    string    `gorm:"type:text" json:"detail"`
+	IPAddress string    `gorm:"size:45" json:"ip_address"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	Country   string    `gorm:"size:2" json:"country,omitempty"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GroupBroadcast records an urgent message sent by the organizer to all approved members
+type GroupBroadcast struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GroupID    string    `gorm:"size:50;not null;index" json:"group_id"`
+	SentBy     string    `gorm:"size:30;not null" json:"sent_by"`
+	Message    string    `gorm:"type:text;not null" json:"message"`
+	Recipients int       `gorm:"not null;default:0" json:"recipients"`
+	CreatedAt  time.Time `gorm:"not null;index" json:"created_at"`
+
+	// How the broadcast was delivered, returned when it is sent
+	Channels []string `gorm:"-" json:"channels,omitempty"`
+}
+
+// Broadcast delivery channels. There is no push notification service yet, so broadcasts aren't pushed.
+const (
+	BroadcastChannelInApp = "in_app"
+	BroadcastChannelEmail = "email"
+)
+
+// BeforeCreate hook is called before creating a new broadcast
+func (b *GroupBroadcast) BeforeCreate(tx *gorm.DB) error {
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// BroadcastRequest represents a day-of logistics update sent to the group
+type BroadcastRequest struct {
+	Message string `json:"message" binding:"required,max=500"`
+}
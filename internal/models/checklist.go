@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChecklistItem represents an item on a group's "what to bring" checklist
+type ChecklistItem struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	GroupID   string     `gorm:"size:50;not null;index" json:"group_id"`
+	Name      string     `gorm:"size:100;not null" json:"name"`
+	Quantity  int        `gorm:"not null;default:1" json:"quantity"`
+	Notes     string     `gorm:"size:255" json:"notes"`
+	ClaimedBy *string    `gorm:"size:30;index" json:"claimed_by"` // Username of the member bringing it, null if unclaimed
+	ClaimedAt *time.Time `json:"claimed_at"`
+	CreatedBy string     `gorm:"size:30;not null" json:"created_by"`
+	CreatedAt time.Time  `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null" json:"updated_at"`
+}
+
+// BeforeCreate hook is called before creating a new checklist item
+func (i *ChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = now
+	}
+	if i.UpdatedAt.IsZero() {
+		i.UpdatedAt = now
+	}
+	if i.Quantity == 0 {
+		i.Quantity = 1
+	}
+	return nil
+}
+
+// BeforeSave hook is called before saving the checklist item
+func (i *ChecklistItem) BeforeSave(tx *gorm.DB) error {
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// ChecklistItemRequest represents the data needed to create or update a checklist item
+type ChecklistItemRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Quantity int    `json:"quantity" binding:"omitempty,min=1,max=100"`
+	Notes    string `json:"notes" binding:"max=255"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Legal documents users accept
+const (
+	ConsentTerms   = "terms"   // Terms of service
+	ConsentPrivacy = "privacy" // Privacy policy
+)
+
+// ConsentRecord is one acceptance of a policy version, kept as the account's consent history
+type ConsentRecord struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Username   string    `gorm:"size:30;not null;index" json:"-"`
+	Document   string    `gorm:"size:20;not null" json:"document"` // terms or privacy
+	Version    string    `gorm:"size:30;not null" json:"version"`
+	AcceptedAt time.Time `gorm:"not null" json:"accepted_at"`
+	IPAddress  string    `gorm:"size:45" json:"ip_address"`
+	UserAgent  string    `gorm:"size:255" json:"user_agent"`
+}
+
+// AcceptPoliciesRequest accepts the policy versions the user was shown, which must be the current ones
+type AcceptPoliciesRequest struct {
+	TermsVersion   string `json:"terms_version" binding:"required,max=30"`
+	PrivacyVersion string `json:"privacy_version" binding:"required,max=30"`
+}
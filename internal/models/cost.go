@@ -0,0 +1,33 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// CostDetails breaks down what joining a group costs. Group.Cost keeps the per-person amount for
+// filtering, sorting and clients that only read cost.
+type CostDetails struct {
+	Total       *float64 `json:"total,omitempty" binding:"omitempty,min=0"` // What the whole event costs, when it's split between the members
+	PerPerson   float64  `json:"per_person" binding:"min=0"`
+	Includes    string   `json:"includes,omitempty" binding:"max=500"`     // e.g. "Court booking and shuttlecocks"
+	PaymentNote string   `json:"payment_note,omitempty" binding:"max=300"` // e.g. "Pay the organizer by UPI before the game"
+}
+
+// Implement driver.Valuer for JSONB storage
+func (d CostDetails) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Implement sql.Scanner for JSONB retrieval
+func (d *CostDetails) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal CostDetails: %v", value)
+	}
+	return json.Unmarshal(bytes, d)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Group export status values
+const (
+	ExportStatusPending = "pending"
+	ExportStatusReady   = "ready"
+	ExportStatusFailed  = "failed"
+)
+
+// GroupExport is an asynchronously generated archive of a completed group
+type GroupExport struct {
+	ID          string     `gorm:"primaryKey;size:64" json:"id"`
+	GroupID     string     `gorm:"size:50;not null;index" json:"group_id"`
+	RequestedBy string     `gorm:"size:30;not null" json:"requested_by"`
+	Status      string     `gorm:"size:20;not null;default:'pending'" json:"status"` // pending, ready, failed
+	Error       string     `gorm:"size:255" json:"error,omitempty"`
+	Data        []byte     `gorm:"type:bytea" json:"-"` // Zip archive contents
+	SizeBytes   int        `gorm:"not null;default:0" json:"size_bytes"`
+	CreatedAt   time.Time  `gorm:"not null" json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time  `gorm:"not null;index" json:"expires_at"` // Archive is purged after this time
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// EventFeedback is a private note from an attendee to the organizer after an event.
+// Unlike ratings it is never shown publicly; only the organizer can read it.
+type EventFeedback struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GroupID   string    `gorm:"size:50;not null;uniqueIndex:idx_event_feedback_author" json:"group_id"`
+	Author    string    `gorm:"size:30;not null;uniqueIndex:idx_event_feedback_author" json:"author"`
+	Message   string    `gorm:"type:text;not null" json:"message"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+
+	// Set when the organizer reports the feedback as abusive
+	ReportedAt   *time.Time `json:"reported_at,omitempty"`
+	ReportReason string     `gorm:"size:500" json:"report_reason,omitempty"`
+}
+
+// SubmitFeedbackRequest represents feedback sent to an event's organizer
+type SubmitFeedbackRequest struct {
+	Message string `json:"message" binding:"required,max=2000"`
+}
+
+// ReportFeedbackRequest represents an organizer reporting abusive feedback
+type ReportFeedbackRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
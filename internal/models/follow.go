@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// OrganizerFollow records a user following an organizer to hear about their new groups first
+type OrganizerFollow struct {
+	Follower  string    `gorm:"primaryKey;size:30" json:"follower"`
+	Organizer string    `gorm:"primaryKey;size:30;index" json:"organizer"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
@@ -20,6 +20,48 @@ const (
 	Advanced     SkillLevel = "advanced"
 )
 
+// skillLevelRanks orders skill levels from least to most experienced
+var skillLevelRanks = map[SkillLevel]int{
+	Beginner:     1,
+	Intermediate: 2,
+	Advanced:     3,
+}
+
+// IsValid reports whether the skill level is one of the known levels
+func (s SkillLevel) IsValid() bool {
+	_, ok := skillLevelRanks[s]
+	return ok
+}
+
+// Rank returns the level's position in the beginner-to-advanced ordering (0 if unknown)
+func (s SkillLevel) Rank() int {
+	return skillLevelRanks[s]
+}
+
+// Member roles within a group
+const (
+	RoleOrganiser   = "organiser"    // The group's owner (Group.OrganiserID)
+	RoleCoOrganiser = "co-organiser" // Helps manage members and edit the group
+	RoleMember      = "member"
+)
+
+// Group lifecycle statuses
+const (
+	GroupStatusActive    = "active"
+	GroupStatusCancelled = "cancelled" // Called off by the organizer; kept for history instead of deleted
+	GroupStatusArchived  = "archived"  // Ended a while ago; only listed in the past events archive
+)
+
+// GroupStatusesHeld are the statuses of groups that went ahead as planned (not cancelled)
+var GroupStatusesHeld = []string{GroupStatusActive, GroupStatusArchived}
+
+// Group visibility levels
+const (
+	VisibilityPublic   = "public"   // Listed in browse, search and nearby results
+	VisibilityUnlisted = "unlisted" // Only reachable by direct link or invitation
+	VisibilityPrivate  = "private"  // Unlisted, and the member list is hidden from non-members
+)
+
 // Member represents a user's membership status in a group
 type GroupMember struct {
 	GroupID   string    `gorm:"primaryKey;size:50" json:"group_id"`
@@ -27,6 +69,32 @@ type GroupMember struct {
 	Status    string    `gorm:"size:20;not null;default:'pending'" json:"status"` // pending, approved, rejected
 	JoinedAt  time.Time `gorm:"not null" json:"joined_at"`
 	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+
+	// Set when the venue moves far enough that the member must confirm they can still attend
+	NeedsReconfirmation bool `gorm:"not null;default:false" json:"needs_reconfirmation"`
+
+	// Guests (+1s) the member is bringing; each takes a spot counted against MaxMembers
+	GuestCount int `gorm:"not null;default:0" json:"guest_count"`
+
+	// organiser, co-organiser or member; only the organiser can change roles
+	Role string `gorm:"size:20;not null;default:'member'" json:"role"`
+
+	// Attendance: set when the member checks in at the event (CheckedInBy is the member for
+	// self check-in, otherwise the organizer), or flagged as a no-show once check-in closes
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+	CheckedInBy string     `gorm:"size:30" json:"checked_in_by,omitempty"`
+	NoShow      bool       `gorm:"not null;default:false" json:"no_show"`
+
+	// Random token shown to the member as a QR code and scanned by the organizer at the venue;
+	// created the first time the member opens their ticket
+	TicketToken *string `gorm:"size:64;uniqueIndex" json:"-"`
+
+	// Optional intro written with a join request; only the organizers see it, in the pending list
+	JoinMessage string `gorm:"size:500" json:"-"`
+
+	// Set along with the group's DeletedAt when the group is deleted. Leaving or being removed
+	// deletes the row outright, so the user can join again.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // Group represents a group in the system
@@ -35,6 +103,7 @@ type Group struct {
 	Name         string        `gorm:"index;size:100;not null" json:"name"`
 	DateTime     time.Time     `gorm:"index;not null" json:"date_time"`
 	Location     Location      `gorm:"type:jsonb;not null" json:"location"`
+	City         string        `gorm:"size:100;index" json:"city"` // Locality derived from the validated location
 	Cost         float64       `gorm:"type:decimal(10,2);not null;default:0.0" json:"cost"`
 	SkillLevel   *string       `gorm:"type:varchar(20);index" json:"skill_level,omitempty"`
 	ActivityType string        `gorm:"type:varchar(50);index;not null" json:"activity_type"`
@@ -44,6 +113,160 @@ type Group struct {
 	Members      []GroupMember `gorm:"foreignKey:GroupID" json:"members"`
 	CreatedAt    time.Time     `gorm:"not null" json:"created_at"`
 	UpdatedAt    time.Time     `gorm:"not null" json:"updated_at"`
+
+	// Auto-approval settings: join requests matching any enabled rule skip the pending state
+	AutoApproveAll             bool     `gorm:"not null;default:false" json:"auto_approve_all"`
+	AutoApproveMinRating       *float64 `gorm:"type:decimal(3,2)" json:"auto_approve_min_rating,omitempty"`
+	AutoApprovePreviousMembers bool     `gorm:"not null;default:false" json:"auto_approve_previous_members"`
+
+	// Number of guests each approved member may bring (0 disables guests)
+	MaxGuestsPerMember int `gorm:"not null;default:0" json:"max_guests_per_member"`
+
+	// Minutes before the event when joining, leaving and editing lock (nil uses the global default)
+	CutoffMinutes *int `json:"cutoff_minutes,omitempty"`
+
+	// Icon, color and cover image for the activity type, filled in for listings
+	ActivityDisplay *ActivityDisplay `gorm:"-" json:"activity_display,omitempty"`
+
+	// Repeat schedule for recurring groups. Occurrences share a SeriesID (the first group's ID),
+	// and NextOccurrenceID is set once the occurrence after this one has been created.
+	Recurrence       *RecurrenceRule `gorm:"type:jsonb" json:"recurrence,omitempty"`
+	SeriesID         *string         `gorm:"size:50;index" json:"series_id,omitempty"`
+	NextOccurrenceID *string         `gorm:"size:50" json:"next_occurrence_id,omitempty"`
+
+	// Until this time the group is only listed for followers of the organizer (nil means public from the start)
+	PublicAt *time.Time `gorm:"index" json:"public_at,omitempty"`
+
+	// active, cancelled or archived; cancelled groups drop out of listings and reminders but stay viewable
+	Status             string     `gorm:"size:20;not null;default:'active';index" json:"status"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason string     `gorm:"size:500" json:"cancellation_reason,omitempty"`
+
+	// Organizer-written messages appended to the approval and rejection notices members receive
+	ApprovalMessage  string `gorm:"size:500" json:"approval_message,omitempty"`
+	RejectionMessage string `gorm:"size:500" json:"rejection_message,omitempty"`
+
+	// Read-only chat: only the organizer and co-organizers can post while set
+	ChatLocked bool `gorm:"not null;default:false" json:"chat_locked"`
+
+	// public, unlisted or private; only public groups appear in listings
+	Visibility string `gorm:"size:20;not null;default:'public';index" json:"visibility"`
+
+	// Tag names from the group_tag table, filled in for responses
+	Tags []string `gorm:"-" json:"tags,omitempty"`
+
+	// Code the organizer shares at the venue for self check-in, and when no-shows were recorded
+	CheckInCode          string     `gorm:"size:10" json:"-"`
+	AttendanceRecordedAt *time.Time `json:"-"`
+
+	// Optional deadline after which nobody can join or be approved, and when the organizer
+	// was sent the final headcount for it
+	RSVPDeadline    *time.Time `gorm:"index" json:"rsvp_deadline,omitempty"`
+	HeadcountSentAt *time.Time `json:"-"`
+
+	// Counter caches of approved members, their guests and pending requests, refreshed with every
+	// membership change so capacity checks and listings don't count rows
+	ApprovedMemberCount int `gorm:"not null;default:0" json:"approved_member_count"`
+	ApprovedGuestCount  int `gorm:"not null;default:0" json:"approved_guest_count"`
+	PendingCount        int `gorm:"not null;default:0" json:"pending_count"`
+
+	// Organizer's public profile, filled in for listing responses
+	Organizer *OrganizerSummary `gorm:"-" json:"organizer,omitempty"`
+
+	// When the archive worker moved the ended group out of the default listings
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	// Set when the organizer deletes the group; an admin can restore it until the purge worker
+	// removes it for good
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// When the event finishes, for events that run longer than a moment (up to several days).
+	// Without it the event is treated as ending when it starts.
+	EndDateTime *time.Time `gorm:"index" json:"end_date_time,omitempty"`
+
+	// Optional eligibility restrictions checked when someone joins; nil leaves the group open to everyone
+	MinAge *int    `json:"min_age,omitempty"`
+	MaxAge *int    `json:"max_age,omitempty"`
+	Gender *string `gorm:"size:20" json:"gender,omitempty"`
+
+	// Breakdown of the cost; groups created before it existed get one built from Cost when loaded
+	CostDetails *CostDetails `gorm:"type:jsonb" json:"cost_details,omitempty"`
+
+	// Organizer notes for people going (gate codes, parking...). Never serialized with the group;
+	// GetGroupByID adds them for approved members.
+	MemberNotes string `gorm:"type:text;not null;default:''" json:"-"`
+
+	// Extra meeting points besides Location (meet point, venue, after-party), in order
+	SecondaryLocations SecondaryLocations `gorm:"type:jsonb;not null;default:'[]'" json:"secondary_locations,omitempty"`
+}
+
+// OrganizerSummary is the organizer's public profile shown on group cards
+type OrganizerSummary struct {
+	Username  string  `json:"username"`
+	FullName  string  `json:"full_name,omitempty"` // Hidden unless the organizer shows their full name
+	AvatarURL string  `json:"avatar_url"`
+	Rating    float64 `json:"rating"`
+}
+
+// OccupiedSpots returns how many of the group's spots are taken: each approved member plus their guests
+func (g Group) OccupiedSpots() int {
+	return g.ApprovedMemberCount + g.ApprovedGuestCount
+}
+
+// IsListed reports whether the group may appear in browse, search and nearby listings
+func (g Group) IsListed() bool {
+	return g.Visibility == "" || g.Visibility == VisibilityPublic
+}
+
+// RSVPClosed reports whether the group's RSVP deadline has passed
+func (g Group) RSVPClosed(now time.Time) bool {
+	return g.RSVPDeadline != nil && !now.Before(*g.RSVPDeadline)
+}
+
+// EndsAt returns when the event finishes: its end time, or its start for events without one
+func (g Group) EndsAt() time.Time {
+	if g.EndDateTime != nil {
+		return *g.EndDateTime
+	}
+	return g.DateTime
+}
+
+// DurationMinutes returns how long the event runs, or nil for events without an end time
+func (g Group) DurationMinutes() *int {
+	if g.EndDateTime == nil {
+		return nil
+	}
+	minutes := int(g.EndDateTime.Sub(g.DateTime) / time.Minute)
+	return &minutes
+}
+
+// HasEnded reports whether the event is over
+func (g Group) HasEnded(now time.Time) bool {
+	return now.After(g.EndsAt())
+}
+
+// IsCancelled reports whether the organizer has cancelled the group
+func (g Group) IsCancelled() bool {
+	return g.Status == GroupStatusCancelled
+}
+
+// ApplyEarlyAccess sets PublicAt from the follower early-access window, counted from creation
+// so re-saving the same setting never hides a group that has already gone public
+func (g *Group) ApplyEarlyAccess(hours int) {
+	if hours <= 0 {
+		g.PublicAt = nil
+		return
+	}
+	publicAt := g.CreatedAt.Add(time.Duration(hours) * time.Hour)
+	if publicAt.After(g.DateTime) {
+		publicAt = g.DateTime
+	}
+	g.PublicAt = &publicAt
+}
+
+// IsRecurring reports whether the group repeats
+func (g Group) IsRecurring() bool {
+	return g.Recurrence != nil && g.Recurrence.Frequency != ""
 }
 
 // BeforeCreate hook is called before creating a new group
@@ -58,6 +281,12 @@ func (g *Group) BeforeCreate(tx *gorm.DB) error {
 	if g.ID == "" {
 		g.ID = fmt.Sprintf("%s-%s", g.OrganiserID, now.UTC().Format("20060102150405"))
 	}
+	if g.Status == "" {
+		g.Status = GroupStatusActive
+	}
+	if g.Visibility == "" {
+		g.Visibility = VisibilityPublic
+	}
 	return nil
 }
 
@@ -67,6 +296,20 @@ func (g *Group) BeforeSave(tx *gorm.DB) error {
 	return nil
 }
 
+// AfterFind hook is called after loading the group
+func (g *Group) AfterFind(tx *gorm.DB) error {
+	g.FillCostDetails()
+	return nil
+}
+
+// FillCostDetails builds cost details from Cost for groups stored without them, so every group
+// reports its cost the same way
+func (g *Group) FillCostDetails() {
+	if g.CostDetails == nil {
+		g.CostDetails = &CostDetails{PerPerson: g.Cost}
+	}
+}
+
 // BeforeCreate hook is called before creating a new group member
 func (gm *GroupMember) BeforeCreate(tx *gorm.DB) error {
 	now := time.Now()
@@ -91,8 +334,93 @@ type CreateGroupRequest struct {
 	DateTime     time.Time `json:"date_time" binding:"required"`
 	Location     Location  `json:"location" binding:"required"`
 	Cost         float64   `json:"cost"`
-	SkillLevel   *string   `json:"skill_level,omitempty"`
+	SkillLevel   *string   `json:"skill_level,omitempty" binding:"omitempty,oneof=beginner intermediate advanced"`
 	ActivityType string    `json:"activity_type" binding:"required"`
-	MaxMembers   int       `json:"max_members" binding:"required,min=2,max=50"`
+	MaxMembers   int       `json:"max_members" binding:"required,min=2"` // Upper bound is configurable (see services.GroupLimits)
 	Description  string    `json:"description" binding:"required,max=1000"`
+
+	AutoApproveAll             bool     `json:"auto_approve_all"`
+	AutoApproveMinRating       *float64 `json:"auto_approve_min_rating,omitempty" binding:"omitempty,min=0,max=5"`
+	AutoApprovePreviousMembers bool     `json:"auto_approve_previous_members"`
+
+	MaxGuestsPerMember int  `json:"max_guests_per_member" binding:"min=0,max=10"`
+	CutoffMinutes      *int `json:"cutoff_minutes,omitempty" binding:"omitempty,min=0,max=10080"`
+
+	Recurrence *RecurrenceRule `json:"recurrence,omitempty"`
+
+	// Hours after creation during which only the organizer's followers can find the group
+	FollowerEarlyAccessHours int `json:"follower_early_access_hours" binding:"min=0,max=168"`
+
+	// Canned messages appended to join approval and rejection notices
+	ApprovalMessage  string `json:"approval_message" binding:"max=500"`
+	RejectionMessage string `json:"rejection_message" binding:"max=500"`
+
+	// Defaults to public on create; left unchanged on update when omitted
+	Visibility string `json:"visibility,omitempty" binding:"omitempty,oneof=public unlisted private"`
+
+	// Free-form tags; on update, omitting the field keeps the current tags and [] clears them
+	Tags []string `json:"tags,omitempty" binding:"omitempty,max=10,dive,max=30"`
+
+	// Joining and approvals close at this time; must be before the event
+	RSVPDeadline *time.Time `json:"rsvp_deadline,omitempty"`
+
+	// Optional end of the event; must be after date_time, at most services.GroupLimits.MaxDuration later
+	EndDateTime *time.Time `json:"end_date_time,omitempty"`
+
+	// Optional eligibility restrictions; min_age must not exceed max_age
+	MinAge *int    `json:"min_age,omitempty" binding:"omitempty,min=13,max=120"`
+	MaxAge *int    `json:"max_age,omitempty" binding:"omitempty,min=13,max=120"`
+	Gender *string `json:"gender,omitempty" binding:"omitempty,oneof=female male non_binary"`
+
+	// Optional breakdown of the cost; when given, its per_person replaces cost
+	CostDetails *CostDetails `json:"cost_details,omitempty"`
+
+	// Only shown to approved members
+	MemberNotes string `json:"member_notes" binding:"max=2000"`
+
+	// Extra meeting points, each validated with Google Maps like the main location
+	SecondaryLocations SecondaryLocations `json:"secondary_locations,omitempty" binding:"omitempty,max=5,dive"`
+}
+
+// CancelGroupRequest carries the reason sent to members when an organizer cancels a group
+type CancelGroupRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// UpdateGuestCountRequest sets how many guests an approved member is bringing
+type UpdateGuestCountRequest struct {
+	GuestCount *int `json:"guest_count" binding:"required,min=0"`
+}
+
+// SelfCheckInRequest carries the event's check-in code, shared by the organizer at the venue
+type SelfCheckInRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// ScanTicketRequest carries the token read from a member's QR ticket
+type ScanTicketRequest struct {
+	Token string `json:"token" binding:"required,max=64"`
+}
+
+// UpdateMemberRoleRequest promotes a member to co-organiser or demotes them back
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=co-organiser member"`
+}
+
+// JoinGroupRequest carries an optional intro message for the organizer reviewing the request
+type JoinGroupRequest struct {
+	Message string `json:"message" binding:"max=500"`
+}
+
+// RejectJoinRequestRequest carries an optional reason shown to the rejected user
+type RejectJoinRequestRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// BulkMemberActionRequest approves or rejects several pending join requests at once. Reason is only
+// used when rejecting.
+type BulkMemberActionRequest struct {
+	Usernames []string `json:"usernames" binding:"required,min=1,max=100,dive,required,max=30"`
+	Action    string   `json:"action" binding:"required,oneof=approve reject"`
+	Reason    string   `json:"reason" binding:"max=500"`
 }
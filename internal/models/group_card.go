@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// GroupCard is a row of the group_card materialized view: an upcoming listed group together with the
+// organizer profile, tags and next occurrence its listing card shows, so GET /groups reads one relation
+type GroupCard struct {
+	Group
+
+	OrganizerFullName  string  `json:"-"`
+	OrganizerAvatarURL string  `json:"-"`
+	OrganizerRating    float64 `json:"-"`
+	TagNames           string  `json:"-"` // Comma-separated, sorted
+
+	// Start of the series' next occurrence, once it has been scheduled
+	NextOccurrenceAt *time.Time `json:"next_occurrence_at,omitempty"`
+}
+
+// TableName points GroupCard at the materialized view
+func (GroupCard) TableName() string {
+	return "group_card"
+}
+
+// Fill copies the precomputed organizer profile and tags onto the embedded group for responses
+func (c *GroupCard) Fill() {
+	c.Organizer = &OrganizerSummary{
+		Username:  c.OrganiserID,
+		FullName:  c.OrganizerFullName,
+		AvatarURL: c.OrganizerAvatarURL,
+		Rating:    c.OrganizerRating,
+	}
+	if c.TagNames != "" {
+		c.Tags = strings.Split(c.TagNames, ",")
+	}
+}
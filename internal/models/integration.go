@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Chat platforms a group integration can post to
+const (
+	IntegrationPlatformSlack   = "slack"
+	IntegrationPlatformDiscord = "discord"
+)
+
+// GroupIntegration mirrors group activity into an organizer's Slack or Discord channel
+type GroupIntegration struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GroupID    string    `gorm:"size:50;not null;index" json:"group_id"`
+	Platform   string    `gorm:"size:20;not null" json:"platform"` // slack, discord
+	WebhookURL string    `gorm:"type:text;not null" json:"-"`      // Never returned: anyone holding it can post to the channel
+	CreatedBy  string    `gorm:"size:30;not null" json:"created_by"`
+	CreatedAt  time.Time `gorm:"not null" json:"created_at"`
+
+	// Which group events are mirrored. No column defaults: GORM would store a false toggle as the
+	// default, so CreateGroupIntegration fills in the defaults instead.
+	NotifyJoinRequests   bool `gorm:"not null" json:"notify_join_requests"`
+	NotifyApprovals      bool `gorm:"not null" json:"notify_approvals"`
+	NotifyChatHighlights bool `gorm:"not null" json:"notify_chat_highlights"`
+}
+
+// BeforeCreate hook is called before creating a new integration
+func (i *GroupIntegration) BeforeCreate(tx *gorm.DB) error {
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// CreateGroupIntegrationRequest connects a Slack or Discord incoming webhook to a group
+type CreateGroupIntegrationRequest struct {
+	WebhookURL           string `json:"webhook_url" binding:"required,url,max=500"`
+	NotifyJoinRequests   *bool  `json:"notify_join_requests,omitempty"`
+	NotifyApprovals      *bool  `json:"notify_approvals,omitempty"`
+	NotifyChatHighlights *bool  `json:"notify_chat_highlights,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Invitation statuses
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// Invitation is an organizer's invite for a specific person to join a group.
+// Invites by username set Username; invites by email to someone without an account
+// only set Email, and are matched to the account once they sign up with that address.
+type Invitation struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	GroupID     string     `gorm:"size:50;not null;index" json:"group_id"`
+	InvitedBy   string     `gorm:"size:30;not null" json:"invited_by"`
+	Username    *string    `gorm:"size:30;index" json:"username,omitempty"`
+	Email       string     `gorm:"size:255;index" json:"email,omitempty"` // Stored lowercase
+	Status      string     `gorm:"size:20;not null;default:'pending'" json:"status"`
+	CreatedAt   time.Time  `gorm:"not null" json:"created_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
+
+// CreateInvitationsRequest invites people to a group by username and/or email
+type CreateInvitationsRequest struct {
+	Usernames []string `json:"usernames" binding:"max=20,dive,required,max=30"`
+	Emails    []string `json:"emails" binding:"max=20,dive,required,email,max=255"`
+}
+
+// CreateInviteLinkRequest sets how long a shareable invite link stays valid
+type CreateInviteLinkRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
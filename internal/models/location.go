@@ -13,6 +13,7 @@ type Location struct {
 	FormattedAddress string  `json:"formatted_address" binding:"required"`
 	Latitude         float64 `json:"latitude" binding:"required"`
 	Longitude        float64 `json:"longitude" binding:"required"`
+	City             string  `json:"city,omitempty"` // Locality, filled in by location validation
 }
 
 // Implement driver.Valuer for JSONB storage
@@ -31,3 +32,34 @@ func (l *Location) Scan(value interface{}) error {
 	}
 	return json.Unmarshal(bytes, l)
 }
+
+// SecondaryLocation is an extra place a group meets besides its main location, e.g. where to meet
+// before heading to the venue, or the after-party
+type SecondaryLocation struct {
+	Label    string   `json:"label" binding:"required,max=50"` // e.g. "Meet point", "After-party"
+	Location Location `json:"location" binding:"required"`
+}
+
+// SecondaryLocations is a group's extra meeting points, in the order the organizer listed them
+type SecondaryLocations []SecondaryLocation
+
+// Implement driver.Valuer for JSONB storage
+func (s SecondaryLocations) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]SecondaryLocation(s))
+}
+
+// Implement sql.Scanner for JSONB retrieval
+func (s *SecondaryLocations) Scan(value interface{}) error {
+	if value == nil {
+		*s = SecondaryLocations{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal SecondaryLocations: %v", value)
+	}
+	return json.Unmarshal(bytes, (*[]SecondaryLocation)(s))
+}
@@ -3,21 +3,63 @@ package models
 import (
 	"time"
 
-	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 // Message represents a chat message in a group
 type Message struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	GroupID   string         `gorm:"size:50;not null;index:idx_messages_group_created" json:"group_id"`
-	Username  string         `gorm:"size:30;not null;index" json:"username"`
-	Content   string         `gorm:"type:text;not null;size:1000" json:"content"`
-	ReadBy    datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"read_by"`
-	CreatedAt time.Time      `gorm:"not null;index:idx_messages_group_created" json:"created_at"`
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	GroupID   string    `gorm:"size:50;not null;index:idx_messages_group_created" json:"group_id"`
+	Username  string    `gorm:"size:30;not null;index" json:"username"`
+	Content   string    `gorm:"type:text;not null;size:1000" json:"content"`
+	CreatedAt time.Time `gorm:"not null;index:idx_messages_group_created" json:"created_at"`
 
 	// Relationships
 	Group Group `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+
+	// Set when the sender edits or deletes the message; deleted messages are hidden from queries
+	EditedAt  *time.Time     `json:"edited_at,omitempty"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Replies point at the thread's root message; top-level messages leave it nil
+	ParentMessageID *uint `gorm:"index" json:"parent_message_id,omitempty"`
+	ReplyCount      int   `gorm:"-" json:"reply_count"`
+
+	// Optional image stored privately in Cloudinary; AttachmentURL is a short-lived signed link filled in per response
+	AttachmentPublicID     string     `gorm:"size:255" json:"-"`
+	AttachmentFormat       string     `gorm:"size:10" json:"-"`
+	AttachmentContentType  string     `gorm:"size:50" json:"attachment_content_type,omitempty"`
+	AttachmentURL          string     `gorm:"-" json:"attachment_url,omitempty"`
+	AttachmentURLExpiresAt *time.Time `gorm:"-" json:"attachment_url_expires_at,omitempty"`
+}
+
+// MessageRevision keeps the original content of an edited or deleted message for moderation
+type MessageRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MessageID uint      `gorm:"not null;index" json:"message_id"`
+	GroupID   string    `gorm:"size:50;not null;index" json:"group_id"`
+	Username  string    `gorm:"size:30;not null" json:"username"`
+	Action    string    `gorm:"size:10;not null" json:"action"` // edit, delete
+	Content   string    `gorm:"type:text;not null" json:"content"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
+
+// MessageReadCursor records how far a member has read in a group chat.
+// Every message with an ID up to LastReadMessageID counts as read by them.
+type MessageReadCursor struct {
+	GroupID           string    `gorm:"primaryKey;size:50" json:"group_id"`
+	Username          string    `gorm:"primaryKey;size:30" json:"username"`
+	LastReadMessageID uint      `gorm:"not null;default:0" json:"last_read_message_id"`
+	UpdatedAt         time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// ChatMute stops a member posting in a group's chat until it expires
+type ChatMute struct {
+	GroupID   string    `gorm:"primaryKey;size:50" json:"group_id"`
+	Username  string    `gorm:"primaryKey;size:30" json:"username"`
+	MutedBy   string    `gorm:"size:30;not null" json:"muted_by"`
+	Until     time.Time `gorm:"not null;index" json:"until"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
 }
 
 // BeforeCreate hook is called before creating a new message
@@ -28,7 +70,33 @@ func (m *Message) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// SendMessageRequest represents the data needed to send a message
+// SendMessageRequest represents the data needed to send a message.
+// Messages with an image attachment are sent as multipart/form-data and may leave content empty.
 type SendMessageRequest struct {
+	Content string `json:"content" form:"content" binding:"max=1000"`
+}
+
+// EditMessageRequest represents the replacement content for an edited message
+type EditMessageRequest struct {
 	Content string `json:"content" binding:"required,max=1000"`
 }
+
+// MuteChatMemberRequest mutes a member for a number of minutes (up to 30 days)
+type MuteChatMemberRequest struct {
+	DurationMinutes int `json:"duration_minutes" binding:"required,min=1,max=43200"`
+}
+
+// SetChatLockRequest turns a group's read-only chat mode on or off
+type SetChatLockRequest struct {
+	Locked *bool `json:"locked" binding:"required"`
+}
+
+// PresenceHeartbeatRequest is sent periodically while a member has the group chat open
+type PresenceHeartbeatRequest struct {
+	Typing bool `json:"typing"`
+}
+
+// MarkMessagesReadRequest moves the read cursor; leaving MessageID unset marks the whole chat as read
+type MarkMessagesReadRequest struct {
+	MessageID uint `json:"message_id"`
+}
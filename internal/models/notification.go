@@ -10,3 +10,15 @@ type ReminderSent struct {
 	ReminderType string    `gorm:"size:10;not null" json:"reminder_type"` // "24hour" or "1hour"
 	SentAt       time.Time `gorm:"not null" json:"sent_at"`
 }
+
+// QueuedEmail is an email held back while SendGrid is failing, retried by the email outbox worker
+type QueuedEmail struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Recipient     string    `gorm:"size:255;not null" json:"recipient"`
+	Subject       string    `gorm:"size:255;not null" json:"subject"`
+	Payload       string    `gorm:"type:text;not null" json:"-"` // SendGrid v3 mail JSON
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	LastError     string    `gorm:"size:500" json:"last_error,omitempty"`
+	NextAttemptAt time.Time `gorm:"not null;index" json:"next_attempt_at"`
+	CreatedAt     time.Time `gorm:"not null" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OutboxEvent is an email or webhook delivery written in the same transaction as the change that
+// caused it, so it can't be lost if the server stops before sending it. The outbox dispatcher
+// delivers it and deletes the row.
+type OutboxEvent struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Kind          string    `gorm:"size:20;not null" json:"kind"` // "email" or "webhook"
+	Payload       string    `gorm:"type:text;not null" json:"-"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	LastError     string    `gorm:"size:500" json:"last_error,omitempty"`
+	NextAttemptAt time.Time `gorm:"not null;index" json:"next_attempt_at"`
+	CreatedAt     time.Time `gorm:"not null" json:"created_at"`
+}
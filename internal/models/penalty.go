@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Offense types that count towards join cooldowns
+const (
+	OffenseRemoved = "removed"
+	OffenseNoShow  = "no_show"
+)
+
+// MemberOffense records a single infraction by a user in a group (removal, no-show)
+type MemberOffense struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Username  string    `gorm:"size:30;not null;index" json:"username"`
+	GroupID   string    `gorm:"size:50;not null" json:"group_id"`
+	Type      string    `gorm:"size:20;not null" json:"type"` // removed, no_show
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+// JoinBan is a temporary ban from joining groups applied to repeat offenders
+type JoinBan struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Username  string    `gorm:"size:30;not null;index" json:"username"`
+	Reason    string    `gorm:"size:255;not null" json:"reason"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
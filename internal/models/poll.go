@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Poll status values
+const (
+	PollStatusOpen   = "open"
+	PollStatusClosed = "closed"
+)
+
+// Poll represents a question posed to the members of a group (e.g. picking a time or venue)
+type Poll struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	GroupID   string       `gorm:"size:50;not null;index" json:"group_id"`
+	CreatedBy string       `gorm:"size:30;not null" json:"created_by"`
+	Question  string       `gorm:"size:255;not null" json:"question"`
+	Status    string       `gorm:"size:20;not null;default:'open';index" json:"status"` // open, closed
+	ClosesAt  *time.Time   `gorm:"index" json:"closes_at,omitempty"`                    // Optional automatic close time
+	ClosedAt  *time.Time   `json:"closed_at,omitempty"`
+	Options   []PollOption `gorm:"foreignKey:PollID" json:"options"`
+	CreatedAt time.Time    `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time    `gorm:"not null" json:"updated_at"`
+}
+
+// PollOption represents a single choice in a poll
+type PollOption struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	PollID   uint   `gorm:"not null;index" json:"poll_id"`
+	Text     string `gorm:"size:255;not null" json:"text"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+}
+
+// PollVote records a member's vote in a poll (one vote per member per poll)
+type PollVote struct {
+	PollID    uint      `gorm:"primaryKey" json:"poll_id"`
+	Username  string    `gorm:"primaryKey;size:30" json:"username"`
+	OptionID  uint      `gorm:"not null;index" json:"option_id"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// BeforeCreate hook is called before creating a new poll
+func (p *Poll) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = now
+	}
+	if p.Status == "" {
+		p.Status = PollStatusOpen
+	}
+	return nil
+}
+
+// BeforeSave hook is called before saving the poll
+func (p *Poll) BeforeSave(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeforeCreate hook is called before creating a new vote
+func (v *PollVote) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = now
+	}
+	if v.UpdatedAt.IsZero() {
+		v.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeSave hook is called before saving the vote
+func (v *PollVote) BeforeSave(tx *gorm.DB) error {
+	v.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsOpen reports whether the poll still accepts votes
+func (p *Poll) IsOpen() bool {
+	if p.Status != PollStatusOpen {
+		return false
+	}
+	return p.ClosesAt == nil || time.Now().Before(*p.ClosesAt)
+}
+
+// CreatePollRequest represents the data needed to create a new poll
+type CreatePollRequest struct {
+	Question string     `json:"question" binding:"required,max=255"`
+	Options  []string   `json:"options" binding:"required,min=2,max=10,dive,required,max=255"`
+	ClosesAt *time.Time `json:"closes_at,omitempty"`
+}
+
+// VotePollRequest represents a vote for one of the poll's options
+type VotePollRequest struct {
+	OptionID uint `json:"option_id" binding:"required"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Recurrence frequencies
+const (
+	RecurrenceWeekly   = "weekly"
+	RecurrenceBiweekly = "biweekly"
+	RecurrenceMonthly  = "monthly"
+)
+
+// RecurrenceRule makes a group repeat: once an occurrence passes, the next one is created as a new group
+type RecurrenceRule struct {
+	Frequency string     `json:"frequency" binding:"required,oneof=weekly biweekly monthly"`
+	Until     *time.Time `json:"until,omitempty"` // No occurrences are created after this time (nil repeats indefinitely)
+
+	// Approved members are carried over to the next occurrence; otherwise they are asked to RSVP again
+	CarryOverMembers bool `json:"carry_over_members"`
+}
+
+// Next returns when the occurrence after one starting at t begins
+func (r RecurrenceRule) Next(t time.Time) time.Time {
+	switch r.Frequency {
+	case RecurrenceBiweekly:
+		return t.AddDate(0, 0, 14)
+	case RecurrenceMonthly:
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 7)
+}
+
+// Ended reports whether an occurrence starting at t would fall after the rule's end date
+func (r RecurrenceRule) Ended(t time.Time) bool {
+	return r.Until != nil && t.After(*r.Until)
+}
+
+// Implement driver.Valuer for JSONB storage
+func (r RecurrenceRule) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Implement sql.Scanner for JSONB retrieval
+func (r *RecurrenceRule) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal RecurrenceRule: %v", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
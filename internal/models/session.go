@@ -25,6 +25,9 @@ type Session struct {
 	UserAgent     string    `gorm:"size:255" json:"-"`               // User's browser/device info
 	CreatedAt     time.Time `gorm:"not null" json:"-"`
 	ExpiresAt     time.Time `gorm:"index" json:"-"`
+
+	// Anomaly detection
+	Country string `gorm:"size:2" json:"-"` // Country the session was last seen from, when the proxy reports it
 }
 
 // BeforeCreate hook for sessions
@@ -44,4 +47,3 @@ func (s *Session) BeforeCreate(tx *gorm.DB) error {
 func (s *Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
-
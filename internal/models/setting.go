@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// AppSetting is a runtime-configurable value that overrides the environment default
+type AppSetting struct {
+	Key       string    `gorm:"primaryKey;size:100" json:"key"`
+	Value     string    `gorm:"size:255;not null" json:"value"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+}
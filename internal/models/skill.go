@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSkillLevel records a user's self-reported skill level for an activity type
+type UserSkillLevel struct {
+	Username     string     `gorm:"primaryKey;size:30" json:"username"`
+	ActivityType string     `gorm:"primaryKey;size:50" json:"activity_type"` // Stored lowercase
+	SkillLevel   SkillLevel `gorm:"type:varchar(20);not null" json:"skill_level"`
+	UpdatedAt    time.Time  `gorm:"not null" json:"updated_at"`
+}
+
+// BeforeSave hook is called before saving the skill level
+func (u *UserSkillLevel) BeforeSave(tx *gorm.DB) error {
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetSkillLevelRequest sets the user's skill level for an activity type
+type SetSkillLevelRequest struct {
+	ActivityType string `json:"activity_type" binding:"required,max=50"`
+	SkillLevel   string `json:"skill_level" binding:"required,oneof=beginner intermediate advanced"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// StreakMilestone records that a user reached a weekly participation streak milestone, so each
+// milestone is only celebrated once. ActivityType is empty for the streak across all activities.
+type StreakMilestone struct {
+	Username     string    `gorm:"primaryKey;size:30" json:"username"`
+	ActivityType string    `gorm:"primaryKey;size:50" json:"activity_type"`
+	Weeks        int       `gorm:"primaryKey" json:"weeks"`
+	ReachedAt    time.Time `gorm:"not null" json:"reached_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Tag is a free-form label organizers attach to groups (e.g. "sunrise", "dog-friendly").
+// Names are stored normalized: lowercase, trimmed and without a leading '#'.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:30;not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
+
+// GroupTag attaches a tag to a group
+type GroupTag struct {
+	GroupID string `gorm:"primaryKey;size:50" json:"group_id"`
+	TagID   uint   `gorm:"primaryKey;index" json:"tag_id"`
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GroupTemplate is an organizer's saved group settings, used to create the same event again
+// without retyping it. It holds everything a group has except its date, and keeps the event's
+// length rather than its end.
+type GroupTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	OrganiserID  string    `gorm:"size:30;not null;uniqueIndex:idx_group_template_name" json:"organiser_id"`
+	TemplateName string    `gorm:"size:100;not null;uniqueIndex:idx_group_template_name" json:"template_name"`
+	Name         string    `gorm:"size:100;not null" json:"name"`
+	Location     Location  `gorm:"type:jsonb;not null" json:"location"`
+	Cost         float64   `gorm:"type:decimal(10,2);not null;default:0.0" json:"cost"`
+	SkillLevel   *string   `gorm:"type:varchar(20)" json:"skill_level,omitempty"`
+	ActivityType string    `gorm:"type:varchar(50);not null" json:"activity_type"`
+	MaxMembers   int       `gorm:"not null" json:"max_members"`
+	Description  string    `gorm:"type:text;not null" json:"description"`
+	CreatedAt    time.Time `gorm:"not null" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"not null" json:"updated_at"`
+
+	AutoApproveAll             bool     `gorm:"not null;default:false" json:"auto_approve_all"`
+	AutoApproveMinRating       *float64 `gorm:"type:decimal(3,2)" json:"auto_approve_min_rating,omitempty"`
+	AutoApprovePreviousMembers bool     `gorm:"not null;default:false" json:"auto_approve_previous_members"`
+	MaxGuestsPerMember         int      `gorm:"not null;default:0" json:"max_guests_per_member"`
+	CutoffMinutes              *int     `json:"cutoff_minutes,omitempty"`
+	ApprovalMessage            string   `gorm:"size:500" json:"approval_message"`
+	RejectionMessage           string   `gorm:"size:500" json:"rejection_message"`
+	Visibility                 string   `gorm:"size:20;not null;default:'public'" json:"visibility"`
+	DurationMinutes            *int     `json:"duration_minutes,omitempty"` // nil for events without a set length
+	MinAge                     *int     `json:"min_age,omitempty"`
+	MaxAge                     *int     `json:"max_age,omitempty"`
+	Gender                     *string  `gorm:"size:20" json:"gender,omitempty"`
+
+	// Breakdown of the cost, for templates saved with one
+	CostDetails *CostDetails `gorm:"type:jsonb" json:"cost_details,omitempty"`
+	MemberNotes string       `gorm:"type:text;not null;default:''" json:"member_notes"`
+
+	SecondaryLocations SecondaryLocations `gorm:"type:jsonb;not null;default:'[]'" json:"secondary_locations"`
+
+	// Tags are stored comma-separated and exposed as a list
+	TagNames string   `gorm:"type:text;not null;default:''" json:"-"`
+	Tags     []string `gorm:"-" json:"tags"`
+}
+
+// BeforeSave stores the tag list and defaults visibility to public
+func (t *GroupTemplate) BeforeSave(tx *gorm.DB) error {
+	if t.Visibility == "" {
+		t.Visibility = VisibilityPublic
+	}
+	t.TagNames = strings.Join(t.Tags, ",")
+	return nil
+}
+
+// AfterFind restores the tag list
+func (t *GroupTemplate) AfterFind(tx *gorm.DB) error {
+	t.Tags = []string{}
+	if t.TagNames != "" {
+		t.Tags = strings.Split(t.TagNames, ",")
+	}
+	return nil
+}
+
+// ToCreateRequest builds the request for a new group from the template on the given date
+func (t GroupTemplate) ToCreateRequest(dateTime time.Time) CreateGroupRequest {
+	var endDateTime *time.Time
+	if t.DurationMinutes != nil {
+		end := dateTime.Add(time.Duration(*t.DurationMinutes) * time.Minute)
+		endDateTime = &end
+	}
+	return CreateGroupRequest{
+		Name:         t.Name,
+		DateTime:     dateTime,
+		Location:     t.Location,
+		Cost:         t.Cost,
+		SkillLevel:   t.SkillLevel,
+		ActivityType: t.ActivityType,
+		MaxMembers:   t.MaxMembers,
+		Description:  t.Description,
+
+		AutoApproveAll:             t.AutoApproveAll,
+		AutoApproveMinRating:       t.AutoApproveMinRating,
+		AutoApprovePreviousMembers: t.AutoApprovePreviousMembers,
+
+		MaxGuestsPerMember: t.MaxGuestsPerMember,
+		CutoffMinutes:      t.CutoffMinutes,
+
+		ApprovalMessage:  t.ApprovalMessage,
+		RejectionMessage: t.RejectionMessage,
+
+		Visibility: t.Visibility,
+		Tags:       t.Tags,
+
+		EndDateTime: endDateTime,
+
+		MinAge: t.MinAge,
+		MaxAge: t.MaxAge,
+		Gender: t.Gender,
+
+		CostDetails: t.CostDetails,
+		MemberNotes: t.MemberNotes,
+
+		SecondaryLocations: t.SecondaryLocations,
+	}
+}
+
+// GroupTemplateRequest creates or replaces a saved group template
+type GroupTemplateRequest struct {
+	TemplateName string   `json:"template_name" binding:"required,max=100"`
+	Name         string   `json:"name" binding:"required,max=100"`
+	Location     Location `json:"location" binding:"required"`
+	Cost         float64  `json:"cost" binding:"min=0"`
+	SkillLevel   *string  `json:"skill_level,omitempty" binding:"omitempty,oneof=beginner intermediate advanced"`
+	ActivityType string   `json:"activity_type" binding:"required,max=50"`
+	MaxMembers   int      `json:"max_members" binding:"required,min=2"`
+	Description  string   `json:"description" binding:"required,max=1000"`
+
+	AutoApproveAll             bool     `json:"auto_approve_all"`
+	AutoApproveMinRating       *float64 `json:"auto_approve_min_rating,omitempty" binding:"omitempty,min=0,max=5"`
+	AutoApprovePreviousMembers bool     `json:"auto_approve_previous_members"`
+	MaxGuestsPerMember         int      `json:"max_guests_per_member" binding:"min=0,max=10"`
+	CutoffMinutes              *int     `json:"cutoff_minutes,omitempty" binding:"omitempty,min=0,max=10080"`
+	ApprovalMessage            string   `json:"approval_message" binding:"max=500"`
+	RejectionMessage           string   `json:"rejection_message" binding:"max=500"`
+	Visibility                 string   `json:"visibility,omitempty" binding:"omitempty,oneof=public unlisted private"`
+	Tags                       []string `json:"tags,omitempty" binding:"omitempty,max=10,dive,max=30"`
+	DurationMinutes            *int     `json:"duration_minutes,omitempty" binding:"omitempty,min=1"`
+	MinAge                     *int     `json:"min_age,omitempty" binding:"omitempty,min=13,max=120"`
+	MaxAge                     *int     `json:"max_age,omitempty" binding:"omitempty,min=13,max=120"`
+	Gender                     *string  `json:"gender,omitempty" binding:"omitempty,oneof=female male non_binary"`
+
+	CostDetails *CostDetails `json:"cost_details,omitempty"`
+	MemberNotes string       `json:"member_notes" binding:"max=2000"`
+
+	SecondaryLocations SecondaryLocations `json:"secondary_locations,omitempty" binding:"omitempty,max=5,dive"`
+}
+
+// SaveAsTemplateRequest names the template saved from an existing group
+type SaveAsTemplateRequest struct {
+	TemplateName string `json:"template_name" binding:"required,max=100"`
+}
+
+// ScheduleGroupRequest sets the date (and optionally a new name) of a group created by
+// duplicating another group or from a template
+type ScheduleGroupRequest struct {
+	DateTime time.Time `json:"date_time" binding:"required"`
+	Name     string    `json:"name" binding:"max=100"`
+}
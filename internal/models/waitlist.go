@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Waitlist entry types
+const (
+	WaitlistTypeWaitlist = "waitlist" // Offered open spots in order
+	WaitlistTypeWatch    = "watch"    // Notified whenever capacity opens up
+)
+
+// Waitlist entry status values
+const (
+	WaitlistStatusWaiting = "waiting"
+	WaitlistStatusOffered = "offered"
+	WaitlistStatusClaimed = "claimed"
+	WaitlistStatusExpired = "expired"
+)
+
+// WaitlistEntry represents a user waiting for (or watching) a spot in a full group
+type WaitlistEntry struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	GroupID        string     `gorm:"size:50;not null;uniqueIndex:idx_waitlist_group_user;index:idx_waitlist_group_status" json:"group_id"`
+	Username       string     `gorm:"size:30;not null;uniqueIndex:idx_waitlist_group_user" json:"username"`
+	Type           string     `gorm:"size:20;not null;default:'waitlist'" json:"type"`                                  // waitlist, watch
+	Status         string     `gorm:"size:20;not null;default:'waiting';index:idx_waitlist_group_status" json:"status"` // waiting, offered, claimed, expired
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt *time.Time `gorm:"index" json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"not null" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"not null" json:"updated_at"`
+}
+
+// BeforeCreate hook is called before creating a new waitlist entry
+func (w *WaitlistEntry) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = now
+	}
+	if w.UpdatedAt.IsZero() {
+		w.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeSave hook is called before saving the waitlist entry
+func (w *WaitlistEntry) BeforeSave(tx *gorm.DB) error {
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// HasActiveOffer reports whether the entry holds an unexpired spot offer
+func (w *WaitlistEntry) HasActiveOffer() bool {
+	return w.Status == WaitlistStatusOffered && w.OfferExpiresAt != nil && time.Now().Before(*w.OfferExpiresAt)
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// WeatherForecast caches a forecast for a rounded coordinate at a specific hour
+type WeatherForecast struct {
+	ID                       uint      `gorm:"primaryKey" json:"-"`
+	Latitude                 float64   `gorm:"not null;uniqueIndex:idx_weather_location_time" json:"latitude"`
+	Longitude                float64   `gorm:"not null;uniqueIndex:idx_weather_location_time" json:"longitude"`
+	ForecastTime             time.Time `gorm:"not null;uniqueIndex:idx_weather_location_time" json:"forecast_time"` // Hour the forecast applies to (UTC)
+	TemperatureC             float64   `json:"temperature_c"`
+	PrecipitationProbability int       `json:"precipitation_probability"` // Percent
+	WindSpeedKmh             float64   `json:"wind_speed_kmh"`
+	WeatherCode              int       `json:"weather_code"` // WMO weather interpretation code
+	Summary                  string    `gorm:"size:50" json:"summary"`
+	FetchedAt                time.Time `gorm:"not null;index" json:"fetched_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookSubscription delivers events about a user's groups to an automation tool (e.g. Zapier)
+type WebhookSubscription struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Username       string     `gorm:"size:30;not null;index" json:"username"`
+	TargetURL      string     `gorm:"type:text;not null" json:"target_url"`
+	Events         string     `gorm:"type:text;not null" json:"-"` // Comma-separated event names
+	Secret         string     `gorm:"size:64;not null" json:"-"`   // HMAC key for the X-Groops-Signature header
+	LastDeliveryAt *time.Time `json:"last_delivery_at,omitempty"`
+	LastStatus     int        `gorm:"not null;default:0" json:"last_status"` // HTTP status of the last delivery (0 if it failed to connect)
+	CreatedAt      time.Time  `gorm:"not null" json:"created_at"`
+}
+
+// BeforeCreate hook is called before creating a new webhook subscription
+func (w *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// EventList returns the subscribed event names
+func (w WebhookSubscription) EventList() []string {
+	if w.Events == "" {
+		return []string{}
+	}
+	return strings.Split(w.Events, ",")
+}
+
+// Subscribes reports whether the subscription wants an event
+func (w WebhookSubscription) Subscribes(event string) bool {
+	for _, subscribed := range w.EventList() {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookRequest registers an outbound webhook for one or more catalog events
+type CreateWebhookRequest struct {
+	TargetURL string   `json:"target_url" binding:"required,url,max=500"`
+	Events    []string `json:"events" binding:"required,min=1,dive,required"`
+}
@@ -0,0 +1,51 @@
+package policy
+
+import (
+	"fmt"
+	"groops/internal/models"
+	"strings"
+	"time"
+)
+
+// CheckEligibility checks an account against the group's age and gender restrictions, returning a
+// message explaining why they can't join, or "" if they can. Accounts missing a detail a restriction
+// needs are asked to add it to their profile.
+func CheckEligibility(account models.Account, group models.Group, now time.Time) string {
+	if group.MinAge != nil || group.MaxAge != nil {
+		age, ok := account.Age(now)
+		if !ok {
+			return fmt.Sprintf("This group is only open to people %s. Add your date of birth to your profile to join.", describeAgeRange(group))
+		}
+		if (group.MinAge != nil && age < *group.MinAge) || (group.MaxAge != nil && age > *group.MaxAge) {
+			return fmt.Sprintf("This group is only open to people %s", describeAgeRange(group))
+		}
+	}
+
+	if group.Gender != nil {
+		if account.Gender == nil {
+			return fmt.Sprintf("This group is only open to %s members. Add your gender to your profile to join.", describeGender(*group.Gender))
+		}
+		if *account.Gender != *group.Gender {
+			return fmt.Sprintf("This group is only open to %s members", describeGender(*group.Gender))
+		}
+	}
+	return ""
+}
+
+// describeAgeRange phrases a group's age restriction, e.g. "aged 18 to 30" or "aged 21 and over"
+func describeAgeRange(group models.Group) string {
+	switch {
+	case group.MinAge != nil && group.MaxAge != nil:
+		return fmt.Sprintf("aged %d to %d", *group.MinAge, *group.MaxAge)
+	case group.MinAge != nil:
+		return fmt.Sprintf("aged %d and over", *group.MinAge)
+	case group.MaxAge != nil:
+		return fmt.Sprintf("aged %d and under", *group.MaxAge)
+	}
+	return ""
+}
+
+// describeGender turns a gender value into words for messages
+func describeGender(gender string) string {
+	return strings.ReplaceAll(gender, "_", "-")
+}
@@ -0,0 +1,93 @@
+// Package policy holds the authorization rules for groups. Rules are plain functions of who is asking
+// and the group, so handlers look up the requester's membership once and ask the policy instead of
+// repeating organizer and membership checks.
+package policy
+
+import (
+	"groops/internal/models"
+	"os"
+	"strings"
+)
+
+// User is the requester as the rules for one group see them
+type User struct {
+	Username   string
+	Membership *models.GroupMember // The user's row in the group; nil if they have never joined or asked to
+}
+
+// UserNamed is a user whose membership hasn't been looked up, which is all organizer-only checks need
+func UserNamed(username string) User {
+	return User{Username: username}
+}
+
+// MemberOf finds a user's membership among a group's preloaded members
+func MemberOf(group models.Group, username string) User {
+	user := User{Username: username}
+	for i := range group.Members {
+		if group.Members[i].Username == username {
+			user.Membership = &group.Members[i]
+			break
+		}
+	}
+	return user
+}
+
+// approved reports whether the user's join request has been approved
+func (u User) approved() bool {
+	return u.Membership != nil && u.Membership.Status == "approved"
+}
+
+// IsOrganizer reports whether the user owns the group. It only needs the user's Username, so
+// organizer-only checks can pass UserNamed.
+func IsOrganizer(user User, group models.Group) bool {
+	return user.Username != "" && user.Username == group.OrganiserID
+}
+
+// IsCoOrganizer reports whether the user is an approved co-organizer of the group
+func IsCoOrganizer(user User, group models.Group) bool {
+	return user.approved() && user.Membership.Role == models.RoleCoOrganiser
+}
+
+// IsMember reports whether the user is the organizer or an approved member of the group
+func IsMember(user User, group models.Group) bool {
+	return IsOrganizer(user, group) || user.approved()
+}
+
+// CanEditGroup reports whether the user may edit the group, manage its members and moderate its
+// chat: the organizer or a co-organizer
+func CanEditGroup(user User, group models.Group) bool {
+	return IsOrganizer(user, group) || IsCoOrganizer(user, group)
+}
+
+// CanViewMessages reports whether the user may read the group's chat: the organizer or an approved member
+func CanViewMessages(user User, group models.Group) bool {
+	return IsMember(user, group)
+}
+
+// CanPostMessages reports whether the user may post in the group's chat. Locked chats and mutes
+// are moderation, checked when the message is sent.
+func CanPostMessages(user User, group models.Group) bool {
+	return IsMember(user, group)
+}
+
+// CanViewMembers reports whether the user may see who is going. Private groups only show it to
+// people who are going.
+func CanViewMembers(user User, group models.Group) bool {
+	return group.Visibility != models.VisibilityPrivate || IsMember(user, group)
+}
+
+// CanViewMemberNotes reports whether the user may read the organizer's notes for people going
+// (gate codes, parking...): the organizer or an approved member
+func CanViewMemberNotes(user User, group models.Group) bool {
+	return IsMember(user, group)
+}
+
+// IsAdmin reports whether a username is listed in the comma-separated ADMIN_USERNAMES
+func IsAdmin(username string) bool {
+	for _, admin := range strings.Split(os.Getenv("ADMIN_USERNAMES"), ",") {
+		if admin = strings.TrimSpace(admin); admin != "" && strings.EqualFold(admin, username) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"groops/internal/models"
+	"testing"
+	"time"
+)
+
+// membership is a user's row in a group with the given status and role
+func membership(status, role string) *models.GroupMember {
+	return &models.GroupMember{GroupID: "g1", Username: "sam", Status: status, Role: role}
+}
+
+func TestGroupRules(t *testing.T) {
+	public := models.Group{ID: "g1", OrganiserID: "olivia", Visibility: models.VisibilityPublic}
+	private := models.Group{ID: "g1", OrganiserID: "olivia", Visibility: models.VisibilityPrivate}
+
+	tests := []struct {
+		name  string
+		user  User
+		group models.Group
+
+		organizer, coOrganizer, canEdit, canViewMessages, canViewMembers bool
+	}{
+		{"organizer", UserNamed("olivia"), public, true, false, true, true, true},
+		{"organizer of a private group", UserNamed("olivia"), private, true, false, true, true, true},
+		{"anonymous visitor", UserNamed(""), public, false, false, false, false, true},
+		{"anonymous visitor to a private group", UserNamed(""), private, false, false, false, false, false},
+		{"stranger", User{Username: "sam"}, public, false, false, false, false, true},
+		{"stranger to a private group", User{Username: "sam"}, private, false, false, false, false, false},
+		{"pending member", User{Username: "sam", Membership: membership("pending", models.RoleMember)}, public, false, false, false, false, true},
+		{"pending member of a private group", User{Username: "sam", Membership: membership("pending", models.RoleMember)}, private, false, false, false, false, false},
+		{"approved member", User{Username: "sam", Membership: membership("approved", models.RoleMember)}, public, false, false, false, true, true},
+		{"approved member of a private group", User{Username: "sam", Membership: membership("approved", models.RoleMember)}, private, false, false, false, true, true},
+		{"rejected member", User{Username: "sam", Membership: membership("rejected", models.RoleMember)}, private, false, false, false, false, false},
+		{"co-organizer", User{Username: "sam", Membership: membership("approved", models.RoleCoOrganiser)}, private, false, true, true, true, true},
+		{"pending co-organizer", User{Username: "sam", Membership: membership("pending", models.RoleCoOrganiser)}, private, false, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsOrganizer(tt.user, tt.group); got != tt.organizer {
+				t.Errorf("IsOrganizer = %v, want %v", got, tt.organizer)
+			}
+			if got := IsCoOrganizer(tt.user, tt.group); got != tt.coOrganizer {
+				t.Errorf("IsCoOrganizer = %v, want %v", got, tt.coOrganizer)
+			}
+			if got := CanEditGroup(tt.user, tt.group); got != tt.canEdit {
+				t.Errorf("CanEditGroup = %v, want %v", got, tt.canEdit)
+			}
+			if got := CanViewMessages(tt.user, tt.group); got != tt.canViewMessages {
+				t.Errorf("CanViewMessages = %v, want %v", got, tt.canViewMessages)
+			}
+			if got := CanPostMessages(tt.user, tt.group); got != tt.canViewMessages {
+				t.Errorf("CanPostMessages = %v, want %v", got, tt.canViewMessages)
+			}
+			if got := CanViewMembers(tt.user, tt.group); got != tt.canViewMembers {
+				t.Errorf("CanViewMembers = %v, want %v", got, tt.canViewMembers)
+			}
+			if got := CanViewMemberNotes(tt.user, tt.group); got != tt.canViewMessages {
+				t.Errorf("CanViewMemberNotes = %v, want %v", got, tt.canViewMessages)
+			}
+		})
+	}
+}
+
+func TestMemberOf(t *testing.T) {
+	group := models.Group{Members: []models.GroupMember{
+		{Username: "alex", Status: "pending"},
+		{Username: "sam", Status: "approved"},
+	}}
+	if user := MemberOf(group, "sam"); user.Membership == nil || user.Membership.Username != "sam" {
+		t.Errorf("MemberOf(sam) = %+v, want sam's membership", user.Membership)
+	}
+	if user := MemberOf(group, "kim"); user.Username != "kim" || user.Membership != nil {
+		t.Errorf("MemberOf(kim) = %+v, want no membership", user)
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	tests := []struct {
+		name     string
+		admins   string
+		username string
+		want     bool
+	}{
+		{"listed", "olivia,sam", "sam", true},
+		{"case-insensitive", "Olivia", "olivia", true},
+		{"spaces around names", " olivia , sam ", "sam", true},
+		{"not listed", "olivia,sam", "kim", false},
+		{"prefix of a listed name", "olivia", "oli", false},
+		{"unset", "", "olivia", false},
+		{"empty username with an empty entry", "olivia,,", "", false},
+		{"empty username", "olivia", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_USERNAMES", tt.admins)
+			if got := IsAdmin(tt.username); got != tt.want {
+				t.Errorf("IsAdmin(%q) with %q = %v, want %v", tt.username, tt.admins, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEligibility(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	born := func(year int, month time.Month, day int) *time.Time {
+		dob := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &dob
+	}
+	ptr := func(v int) *int { return &v }
+	gender := func(g string) *string { return &g }
+
+	tests := []struct {
+		name     string
+		account  models.Account
+		group    models.Group
+		eligible bool
+	}{
+		{"no restrictions", models.Account{}, models.Group{}, true},
+		{"turns the minimum age today", models.Account{DateOfBirth: born(2008, 6, 15)}, models.Group{MinAge: ptr(18)}, true},
+		{"turns the minimum age tomorrow", models.Account{DateOfBirth: born(2008, 6, 16)}, models.Group{MinAge: ptr(18)}, false},
+		{"at the maximum age", models.Account{DateOfBirth: born(1996, 6, 16)}, models.Group{MaxAge: ptr(29)}, true},
+		{"over the maximum age from today", models.Account{DateOfBirth: born(1996, 6, 15)}, models.Group{MaxAge: ptr(29)}, false},
+		{"within a range", models.Account{DateOfBirth: born(2000, 1, 1)}, models.Group{MinAge: ptr(18), MaxAge: ptr(30)}, true},
+		{"below a range", models.Account{DateOfBirth: born(2010, 1, 1)}, models.Group{MinAge: ptr(18), MaxAge: ptr(30)}, false},
+		{"no date of birth", models.Account{}, models.Group{MinAge: ptr(18)}, false},
+		{"matching gender", models.Account{Gender: gender(models.GenderFemale)}, models.Group{Gender: gender(models.GenderFemale)}, true},
+		{"other gender", models.Account{Gender: gender(models.GenderMale)}, models.Group{Gender: gender(models.GenderFemale)}, false},
+		{"no gender", models.Account{}, models.Group{Gender: gender(models.GenderNonBinary)}, false},
+		{"age fits but gender doesn't", models.Account{DateOfBirth: born(2000, 1, 1), Gender: gender(models.GenderMale)}, models.Group{MinAge: ptr(18), Gender: gender(models.GenderFemale)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := CheckEligibility(tt.account, tt.group, now)
+			if (msg == "") != tt.eligible {
+				t.Errorf("CheckEligibility = %q, want eligible = %v", msg, tt.eligible)
+			}
+		})
+	}
+}
+
+func TestEligibilityMessages(t *testing.T) {
+	ptr := func(v int) *int { return &v }
+	tests := []struct {
+		group models.Group
+		want  string
+	}{
+		{models.Group{MinAge: ptr(18), MaxAge: ptr(30)}, "This group is only open to people aged 18 to 30. Add your date of birth to your profile to join."},
+		{models.Group{MinAge: ptr(21)}, "This group is only open to people aged 21 and over. Add your date of birth to your profile to join."},
+		{models.Group{MaxAge: ptr(25)}, "This group is only open to people aged 25 and under. Add your date of birth to your profile to join."},
+	}
+	for _, tt := range tests {
+		if got := CheckEligibility(models.Account{}, tt.group, time.Now()); got != tt.want {
+			t.Errorf("CheckEligibility = %q, want %q", got, tt.want)
+		}
+	}
+
+	nonBinary := models.GenderNonBinary
+	if got, want := CheckEligibility(models.Account{}, models.Group{Gender: &nonBinary}, time.Now()), "This group is only open to non-binary members. Add your gender to your profile to join."; got != want {
+		t.Errorf("CheckEligibility = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Errors returned when two accounts can't be merged
+var (
+	ErrMergeSameAccount    = errors.New("source and target must be different accounts")
+	ErrMergeAccountMissing = errors.New("account not found")
+	ErrMergeAlreadyMerged  = errors.New("account has already been merged into another account")
+	ErrMergeTemporary      = errors.New("temporary accounts can't be merged")
+)
+
+// mergeColumn is a column holding a username that moves to the surviving account. Tables where a
+// user can only have one row per key (e.g. one membership per group) list those key columns; a
+// source row whose key the target already has is dropped rather than moved.
+type mergeColumn struct {
+	Table     string
+	Column    string
+	Conflicts []string
+}
+
+// mergeColumns lists everything reassigned when accounts are merged. Login logs, sessions,
+// consent and audit records stay with the account they describe.
+var mergeColumns = []mergeColumn{
+	{"group", "organiser_id", nil},
+	{"group_member", "username", []string{"group_id"}},
+	{"group_member", "checked_in_by", nil},
+	{"message", "username", nil},
+	{"message_revision", "username", nil},
+	{"message_read_cursor", "username", []string{"group_id"}},
+	{"chat_mute", "username", []string{"group_id"}},
+	{"chat_mute", "muted_by", nil},
+	{"notification", "recipient_username", nil},
+	{"activity_log", "username", nil},
+	{"announcement", "author", nil},
+	{"group_broadcast", "sent_by", nil},
+	{"checklist_item", "created_by", nil},
+	{"checklist_item", "claimed_by", nil},
+	{"poll", "created_by", nil},
+	{"poll_vote", "username", []string{"poll_id"}},
+	{"event_feedback", "author", []string{"group_id"}},
+	{"invitation", "invited_by", nil},
+	{"invitation", "username", nil},
+	{"waitlist_entry", "username", []string{"group_id"}},
+	{"organizer_follow", "follower", []string{"organizer"}},
+	{"organizer_follow", "organizer", []string{"follower"}},
+	{"group_integration", "created_by", nil},
+	{"group_template", "organiser_id", []string{"template_name"}},
+	{"user_skill_level", "username", []string{"activity_type"}},
+	{"streak_milestone", "username", []string{"activity_type", "weeks"}},
+	{"member_offense", "username", nil},
+	{"join_ban", "username", nil},
+	{"reminder_sent", "username", nil},
+	{"api_key", "username", nil},
+	{"webhook_subscription", "username", nil},
+}
+
+// MergeChange is what a merge does to one column
+type MergeChange struct {
+	Table      string `json:"table"`
+	Column     string `json:"column"`
+	Reassigned int64  `json:"reassigned"`
+	Dropped    int64  `json:"dropped"` // Duplicates of rows the target already has
+}
+
+// MergePlan describes a merge of Source into Target
+type MergePlan struct {
+	Source  string        `json:"source"`
+	Target  string        `json:"target"`
+	Changes []MergeChange `json:"changes"`
+}
+
+// AccountMergeService folds a duplicate account into the account the user keeps
+type AccountMergeService struct {
+	db *gorm.DB
+}
+
+func NewAccountMergeService() *AccountMergeService {
+	return &AccountMergeService{db: database.GetDB()}
+}
+
+// conflictCondition matches source rows whose conflict keys the target already has
+func (c mergeColumn) conflictCondition() string {
+	keys := make([]string, len(c.Conflicts))
+	for i, key := range c.Conflicts {
+		keys[i] = fmt.Sprintf("x.%s = s.%s", key, key)
+	}
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM "%s" x WHERE x.%s = ? AND %s)`, c.Table, c.Column, strings.Join(keys, " AND "))
+}
+
+// checkAccounts validates that source can be merged into target
+func (s *AccountMergeService) checkAccounts(tx *gorm.DB, source, target string) error {
+	if strings.EqualFold(source, target) {
+		return ErrMergeSameAccount
+	}
+	for _, username := range []string{source, target} {
+		var account models.Account
+		if err := tx.Where("username = ?", username).First(&account).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrMergeAccountMissing, username)
+			}
+			return err
+		}
+		if account.MergedInto != nil {
+			return fmt.Errorf("%w: %s", ErrMergeAlreadyMerged, username)
+		}
+		if strings.HasPrefix(username, "temp-") {
+			return ErrMergeTemporary
+		}
+	}
+	return nil
+}
+
+// plan counts what merging source into target would change
+func (s *AccountMergeService) plan(tx *gorm.DB, source, target string) (MergePlan, error) {
+	plan := MergePlan{Source: source, Target: target, Changes: []MergeChange{}}
+	for _, column := range mergeColumns {
+		var total, dropped int64
+		if err := tx.Table(fmt.Sprintf(`"%s" AS s`, column.Table)).Where("s."+column.Column+" = ?", source).Count(&total).Error; err != nil {
+			return plan, fmt.Errorf("failed to count %s.%s: %w", column.Table, column.Column, err)
+		}
+		if total > 0 && len(column.Conflicts) > 0 {
+			if err := tx.Table(fmt.Sprintf(`"%s" AS s`, column.Table)).Where("s."+column.Column+" = ?", source).
+				Where(column.conflictCondition(), target).Count(&dropped).Error; err != nil {
+				return plan, fmt.Errorf("failed to count %s.%s conflicts: %w", column.Table, column.Column, err)
+			}
+		}
+		if total > 0 {
+			plan.Changes = append(plan.Changes, MergeChange{
+				Table: column.Table, Column: column.Column, Reassigned: total - dropped, Dropped: dropped,
+			})
+		}
+	}
+	return plan, nil
+}
+
+// Plan returns what merging source into target would change without changing anything
+func (s *AccountMergeService) Plan(source, target string) (MergePlan, error) {
+	if err := s.checkAccounts(s.db, source, target); err != nil {
+		return MergePlan{}, err
+	}
+	return s.plan(s.db, source, target)
+}
+
+// Merge moves everything source owns to target in one transaction and records an audit event.
+// The source account is kept, marked as merged, so signing in with it lands on the target account.
+func (s *AccountMergeService) Merge(source, target, performedBy string) (MergePlan, error) {
+	var plan MergePlan
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkAccounts(tx, source, target); err != nil {
+			return err
+		}
+
+		var err error
+		if plan, err = s.plan(tx, source, target); err != nil {
+			return err
+		}
+
+		// Where both accounts are in the same group, keep the stronger membership on the target's row
+		if err := tx.Exec(`UPDATE group_member t SET status = s.status, role = s.role, guest_count = s.guest_count
+			FROM group_member s
+			WHERE t.username = ? AND s.username = ? AND t.group_id = s.group_id
+			AND s.status = 'approved' AND (t.status <> 'approved' OR s.role = ?)`,
+			target, source, models.RoleOrganiser).Error; err != nil {
+			return fmt.Errorf("failed to combine memberships: %w", err)
+		}
+
+		for _, column := range mergeColumns {
+			if len(column.Conflicts) > 0 {
+				if err := tx.Exec(fmt.Sprintf(`DELETE FROM "%s" s WHERE s.%s = ? AND %s`, column.Table, column.Column, column.conflictCondition()),
+					source, target).Error; err != nil {
+					return fmt.Errorf("failed to drop duplicate %s rows: %w", column.Table, err)
+				}
+			}
+			if err := tx.Exec(fmt.Sprintf(`UPDATE "%s" SET %s = ? WHERE %s = ?`, column.Table, column.Column, column.Column),
+				target, source).Error; err != nil {
+				return fmt.Errorf("failed to reassign %s.%s: %w", column.Table, column.Column, err)
+			}
+		}
+
+		// Memberships moved or were dropped, so the target's groups need fresh member counts
+		var groupIDs []string
+		if err := tx.Model(&models.GroupMember{}).Where("username = ?", target).Pluck("group_id", &groupIDs).Error; err != nil {
+			return fmt.Errorf("failed to fetch merged memberships: %w", err)
+		}
+		if err := RefreshMemberCounts(tx, groupIDs...); err != nil {
+			return fmt.Errorf("failed to refresh member counts: %w", err)
+		}
+
+		// The target may have followed the source; it can't follow itself
+		if err := tx.Where("follower = organizer").Delete(&models.OrganizerFollow{}).Error; err != nil {
+			return fmt.Errorf("failed to remove self-follow: %w", err)
+		}
+
+		// Sign the source out everywhere and point future sign-ins at the target
+		if err := tx.Where("username = ?", source).Delete(&models.Session{}).Error; err != nil {
+			return fmt.Errorf("failed to end source sessions: %w", err)
+		}
+		if err := tx.Model(&models.Account{}).Where("username = ?", source).Updates(map[string]interface{}{
+			"merged_into":   target,
+			"token_version": gorm.Expr("token_version + 1"),
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to mark source as merged: %w", err)
+		}
+
+		detail, err := json.Marshal(plan)
+		if err != nil {
+			return fmt.Errorf("failed to encode merge plan: %w", err)
+		}
+		audit := models.AuditEvent{
+			Username:  target,
+			Event:     models.AuditAccountMerged,
+			Detail:    fmt.Sprintf("%s merged into %s by %s: %s", source, target, performedBy, detail),
+			CreatedAt: time.Now(),
+		}
+		return tx.Create(&audit).Error
+	})
+	return plan, err
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"groops/internal/models"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// activityStyle is the icon and color for a known activity type
+type activityStyle struct {
+	Label string
+	Icon  string
+	Color string
+}
+
+// activityTaxonomy lists the activity types we have artwork for. Group activity types are free-form,
+// so anything else gets defaultActivityStyle.
+var activityTaxonomy = map[string]activityStyle{
+	"running":     {Label: "Running", Icon: "directions_run", Color: "#E65100"},
+	"cycling":     {Label: "Cycling", Icon: "directions_bike", Color: "#00838F"},
+	"hiking":      {Label: "Hiking", Icon: "hiking", Color: "#2E7D32"},
+	"football":    {Label: "Football", Icon: "sports_soccer", Color: "#1B5E20"},
+	"cricket":     {Label: "Cricket", Icon: "sports_cricket", Color: "#827717"},
+	"basketball":  {Label: "Basketball", Icon: "sports_basketball", Color: "#BF360C"},
+	"badminton":   {Label: "Badminton", Icon: "sports_tennis", Color: "#6A1B9A"},
+	"tennis":      {Label: "Tennis", Icon: "sports_tennis", Color: "#9E9D24"},
+	"volleyball":  {Label: "Volleyball", Icon: "sports_volleyball", Color: "#F9A825"},
+	"swimming":    {Label: "Swimming", Icon: "pool", Color: "#0277BD"},
+	"yoga":        {Label: "Yoga", Icon: "self_improvement", Color: "#AD1457"},
+	"climbing":    {Label: "Climbing", Icon: "landscape", Color: "#5D4037"},
+	"board games": {Label: "Board Games", Icon: "casino", Color: "#4527A0"},
+	"social":      {Label: "Social", Icon: "celebration", Color: "#C2185B"},
+}
+
+// activityAliases maps common alternative names onto taxonomy entries
+var activityAliases = map[string]string{
+	"run":        "running",
+	"jogging":    "running",
+	"cycle":      "cycling",
+	"biking":     "cycling",
+	"trekking":   "hiking",
+	"soccer":     "football",
+	"swim":       "swimming",
+	"bouldering": "climbing",
+	"boardgames": "board games",
+}
+
+// defaultActivityStyle is used for activity types outside the taxonomy
+var defaultActivityStyle = activityStyle{Icon: "groups", Color: "#455A64"}
+
+// activityCoverBaseURL is where the default cover images live, one "<slug>.jpg" per activity
+func activityCoverBaseURL() string {
+	if base := os.Getenv("ACTIVITY_COVER_BASE_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return "https://groops.fun/covers"
+}
+
+// ActivityDisplayFor returns the display metadata for a group's activity type
+func ActivityDisplayFor(activityType string) models.ActivityDisplay {
+	key := strings.ToLower(strings.TrimSpace(activityType))
+	if alias, ok := activityAliases[key]; ok {
+		key = alias
+	}
+
+	style, known := activityTaxonomy[key]
+	slug := strings.ReplaceAll(key, " ", "-")
+	if !known {
+		style = defaultActivityStyle
+		style.Label = titleCase(key)
+		slug = "default"
+	}
+
+	return models.ActivityDisplay{
+		ActivityType:  key,
+		Label:         style.Label,
+		Icon:          style.Icon,
+		Color:         style.Color,
+		CoverImageURL: activityCoverBaseURL() + "/" + slug + ".jpg",
+	}
+}
+
+// ActivityTaxonomy lists the display metadata of every known activity type, alphabetically
+func ActivityTaxonomy() []models.ActivityDisplay {
+	displays := make([]models.ActivityDisplay, 0, len(activityTaxonomy))
+	for activityType := range activityTaxonomy {
+		displays = append(displays, ActivityDisplayFor(activityType))
+	}
+	sort.Slice(displays, func(i, j int) bool { return displays[i].ActivityType < displays[j].ActivityType })
+	return displays
+}
+
+// titleCase capitalizes each word of a free-form activity type for display
+func titleCase(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(first)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/utils"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AdminEvent identifies something the admins want to hear about
+type AdminEvent string
+
+const (
+	AdminEventNewSignup   AdminEvent = "new_signup"
+	AdminEventFirstGroup  AdminEvent = "first_group"
+	AdminEventReportFiled AdminEvent = "report_filed"
+)
+
+// adminNotifierRateLimit is the window ADMIN_NOTIFY_MAX_PER_HOUR applies to
+const adminNotifierRateLimit = time.Hour
+
+// AdminNotifier fans admin events out to the configured channels, dropping events of a type
+// once ADMIN_NOTIFY_MAX_PER_HOUR of them have been sent in the past hour
+type AdminNotifier struct {
+	channels        map[string]bool
+	events          map[AdminEvent]bool // nil means every event is enabled
+	slackWebhookURL string
+	maxPerWindow    int
+
+	mu         sync.Mutex
+	sent       map[AdminEvent][]time.Time
+	suppressed map[AdminEvent]int
+}
+
+var (
+	adminNotifier     *AdminNotifier
+	adminNotifierOnce sync.Once
+)
+
+// GetAdminNotifier returns the process-wide notifier; rate limits only work if state is shared
+func GetAdminNotifier() *AdminNotifier {
+	adminNotifierOnce.Do(func() {
+		adminNotifier = newAdminNotifier()
+	})
+	return adminNotifier
+}
+
+func newAdminNotifier() *AdminNotifier {
+	n := &AdminNotifier{
+		channels:        parseList(os.Getenv("ADMIN_NOTIFY_CHANNELS"), "email"),
+		slackWebhookURL: os.Getenv("ADMIN_SLACK_WEBHOOK_URL"),
+		maxPerWindow:    utils.GetEnvInt("ADMIN_NOTIFY_MAX_PER_HOUR", 20),
+		sent:            make(map[AdminEvent][]time.Time),
+		suppressed:      make(map[AdminEvent]int),
+	}
+	if events := os.Getenv("ADMIN_NOTIFY_EVENTS"); events != "" {
+		n.events = make(map[AdminEvent]bool)
+		for event := range parseList(events, "") {
+			n.events[AdminEvent(event)] = true
+		}
+	}
+	return n
+}
+
+// parseList splits a comma-separated, case-insensitive list into a set
+func parseList(value, fallback string) map[string]bool {
+	if strings.TrimSpace(value) == "" {
+		value = fallback
+	}
+	set := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
+
+// Notify sends an admin event to every enabled channel. It is safe to call from request
+// handlers; delivery happens in the background and failures are only logged.
+func (n *AdminNotifier) Notify(event AdminEvent, subject, details string) {
+	if n.events != nil && !n.events[event] {
+		return
+	}
+
+	suppressed, ok := n.allow(event)
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		details = fmt.Sprintf("%s\n\n(%d more %s events were suppressed by rate limiting)", details, suppressed, event)
+	}
+
+	go n.deliver(event, subject, details)
+}
+
+// allow records a send attempt, returning how many events were dropped since the last one sent
+func (n *AdminNotifier) allow(event AdminEvent) (int, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	cutoff := time.Now().Add(-adminNotifierRateLimit)
+	recent := n.sent[event][:0]
+	for _, sentAt := range n.sent[event] {
+		if sentAt.After(cutoff) {
+			recent = append(recent, sentAt)
+		}
+	}
+
+	if len(recent) >= n.maxPerWindow {
+		n.sent[event] = recent
+		n.suppressed[event]++
+		return 0, false
+	}
+
+	n.sent[event] = append(recent, time.Now())
+	suppressed := n.suppressed[event]
+	n.suppressed[event] = 0
+	return suppressed, true
+}
+
+// deliver sends the event through each configured channel
+func (n *AdminNotifier) deliver(event AdminEvent, subject, details string) {
+	if n.channels["email"] {
+		if err := NewEmailService().SendAdminEventEmail(subject, details); err != nil {
+			log.Printf("Warning: Failed to email admin event %s: %v", event, err)
+		}
+	}
+
+	if n.channels["slack"] {
+		if n.slackWebhookURL == "" {
+			log.Printf("Warning: Slack admin channel enabled but ADMIN_SLACK_WEBHOOK_URL is not set")
+		} else if err := SendChatWebhook(n.slackWebhookURL, fmt.Sprintf("*%s*\n%s", subject, details)); err != nil {
+			log.Printf("Warning: Failed to post admin event %s to Slack: %v", event, err)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AnalyticsTracker buffers analytics events in memory and writes them in batches, every
+// ANALYTICS_FLUSH_INTERVAL (default 10s) or once ANALYTICS_BATCH_SIZE (default 500) are waiting, so
+// tracking never adds a write to the request being tracked. Events beyond ANALYTICS_MAX_BUFFERED are
+// dropped while the database is unreachable, and events still buffered are lost on restart.
+type AnalyticsTracker struct {
+	db          *gorm.DB
+	batchSize   int
+	maxBuffered int
+	interval    time.Duration
+
+	mu      sync.Mutex
+	pending []models.AnalyticsEvent
+	flushMu sync.Mutex // Keeps flushes in order so batches aren't written concurrently
+}
+
+var (
+	analyticsTracker     *AnalyticsTracker
+	analyticsTrackerOnce sync.Once
+)
+
+// GetAnalyticsTracker returns the process-wide tracker; events are only batched if it is shared
+func GetAnalyticsTracker() *AnalyticsTracker {
+	analyticsTrackerOnce.Do(func() {
+		analyticsTracker = &AnalyticsTracker{
+			db:          database.GetDB(),
+			batchSize:   utils.GetEnvInt("ANALYTICS_BATCH_SIZE", 500),
+			maxBuffered: utils.GetEnvInt("ANALYTICS_MAX_BUFFERED", 10000),
+			interval:    utils.GetEnvDuration("ANALYTICS_FLUSH_INTERVAL", 10*time.Second),
+		}
+	})
+	return analyticsTracker
+}
+
+// TrackEvent records an analytics event. username and groupID may be empty; detail is truncated to fit.
+func TrackEvent(eventType, username, groupID, detail string) {
+	GetAnalyticsTracker().Track(models.AnalyticsEvent{
+		EventType:  eventType,
+		Username:   username,
+		GroupID:    groupID,
+		Detail:     truncateEmailField(detail, 255),
+		OccurredAt: time.Now(),
+	})
+}
+
+// Start begins writing buffered events in the background
+func (t *AnalyticsTracker) Start() {
+	go t.run()
+}
+
+func (t *AnalyticsTracker) run() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		t.Flush()
+	}
+}
+
+// Track buffers an event, writing the buffer straight away once a full batch is waiting
+func (t *AnalyticsTracker) Track(event models.AnalyticsEvent) {
+	t.mu.Lock()
+	if len(t.pending) >= t.maxBuffered {
+		t.mu.Unlock()
+		return
+	}
+	t.pending = append(t.pending, event)
+	full := len(t.pending) >= t.batchSize
+	t.mu.Unlock()
+
+	if full {
+		go t.Flush()
+	}
+}
+
+// Flush writes every buffered event. A failed batch is put back to be retried on the next flush.
+func (t *AnalyticsTracker) Flush() {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.mu.Lock()
+	events := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+
+	if err := t.db.CreateInBatches(events, t.batchSize).Error; err != nil {
+		log.Printf("Warning: Failed to write %d analytics events: %v", len(events), err)
+		t.mu.Lock()
+		if room := t.maxBuffered - len(t.pending); room > 0 {
+			if len(events) > room {
+				events = events[:room]
+			}
+			t.pending = append(events, t.pending...)
+		}
+		t.mu.Unlock()
+	}
+}
+
+// AnalyticsRollupWorker adds up raw analytics events into daily per-group counts every
+// ANALYTICS_ROLLUP_INTERVAL (default 1h), and deletes raw events older than ANALYTICS_RETENTION
+// (default 90 days). Trending and organizer analytics read the rollups.
+type AnalyticsRollupWorker struct {
+	db        *gorm.DB
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewAnalyticsRollupWorker() *AnalyticsRollupWorker {
+	return &AnalyticsRollupWorker{
+		db:        database.GetDB(),
+		retention: utils.GetEnvDuration("ANALYTICS_RETENTION", 90*24*time.Hour),
+		interval:  utils.GetEnvDuration("ANALYTICS_ROLLUP_INTERVAL", time.Hour),
+	}
+}
+
+func (w *AnalyticsRollupWorker) Start() {
+	go w.run()
+}
+
+func (w *AnalyticsRollupWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.rollUp(time.Now())
+	}
+}
+
+// rollUp recounts yesterday and today, so events written after yesterday's last run are included
+func (w *AnalyticsRollupWorker) rollUp(now time.Time) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		if err := w.rollUpDay(day); err != nil {
+			log.Printf("Failed to roll up analytics for %s: %v", day.Format("2006-01-02"), err)
+		}
+	}
+
+	result := w.db.Where("occurred_at < ?", now.Add(-w.retention)).Delete(&models.AnalyticsEvent{})
+	if result.Error != nil {
+		log.Printf("Failed to delete old analytics events: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Deleted %d old analytics events", result.RowsAffected)
+	}
+}
+
+// rollUpDay replaces the rollups of one UTC day with fresh counts of its raw events
+func (w *AnalyticsRollupWorker) rollUpDay(day time.Time) error {
+	return w.db.Exec(`
+		INSERT INTO analytics_daily_rollup (day, event_type, group_id, events, unique_users)
+		SELECT ?::date, event_type, COALESCE(group_id, ''), COUNT(*), COUNT(DISTINCT NULLIF(username, ''))
+		FROM analytics_event
+		WHERE occurred_at >= ? AND occurred_at < ?
+		GROUP BY event_type, COALESCE(group_id, '')
+		ON CONFLICT (day, event_type, group_id) DO UPDATE
+		SET events = EXCLUDED.events, unique_users = EXCLUDED.unique_users
+	`, day.Format("2006-01-02"), day, day.AddDate(0, 0, 1)).Error
+}
+
+// GroupDailyAnalytics is one day of a group's views and join clicks
+type GroupDailyAnalytics struct {
+	Day           time.Time `json:"day"`
+	Views         int64     `json:"views"`
+	UniqueViewers int64     `json:"unique_viewers"`
+	JoinClicks    int64     `json:"join_clicks"`
+}
+
+// GetGroupDailyAnalytics returns a group's rolled-up views and join clicks per day since the given
+// day, oldest first. Days without any are left out.
+func GetGroupDailyAnalytics(db *gorm.DB, groupID string, since time.Time) ([]GroupDailyAnalytics, error) {
+	days := []GroupDailyAnalytics{}
+	err := db.Model(&models.AnalyticsDailyRollup{}).
+		Select(`day,
+			COALESCE(SUM(events) FILTER (WHERE event_type = ?), 0) AS views,
+			COALESCE(SUM(unique_users) FILTER (WHERE event_type = ?), 0) AS unique_viewers,
+			COALESCE(SUM(events) FILTER (WHERE event_type = ?), 0) AS join_clicks`,
+			models.AnalyticsGroupViewed, models.AnalyticsGroupViewed, models.AnalyticsJoinClicked).
+		Where("group_id = ? AND day >= ?", groupID, since.UTC().Format("2006-01-02")).
+		Group("day").
+		Order("day ASC").
+		Scan(&days).Error
+	return days, err
+}
+
+// CountRecentViewsByActivity adds up views of public groups that weren't cancelled since the given
+// time, per lowercased activity type, optionally within one city
+func CountRecentViewsByActivity(db *gorm.DB, since time.Time, city string) (map[string]int64, error) {
+	query := db.Model(&models.AnalyticsDailyRollup{}).
+		Select(`LOWER(TRIM("group".activity_type)) AS activity_type, SUM(analytics_daily_rollup.events) AS views`).
+		Joins(`JOIN "group" ON "group".id = analytics_daily_rollup.group_id AND "group".deleted_at IS NULL`).
+		Where(`"group".status IN ? AND "group".visibility = ?`, models.GroupStatusesHeld, models.VisibilityPublic).
+		Where("analytics_daily_rollup.event_type = ? AND analytics_daily_rollup.day >= ?", models.AnalyticsGroupViewed, since.UTC().Format("2006-01-02"))
+	if city != "" {
+		query = query.Where(`LOWER("group".city) = LOWER(?)`, city)
+	}
+
+	var rows []struct {
+		ActivityType string
+		Views        int64
+	}
+	if err := query.Group(`LOWER(TRIM("group".activity_type))`).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	views := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		views[row.ActivityType] = row.Views
+	}
+	return views, nil
+}
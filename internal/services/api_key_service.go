@@ -0,0 +1,104 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix marks Groops public API keys so they are easy to spot in logs and secret scanners
+const apiKeyPrefix = "gk_"
+
+var (
+	ErrAPIKeyInvalid       = errors.New("invalid or revoked API key")
+	ErrAPIKeyQuotaExceeded = errors.New("daily API quota exceeded")
+)
+
+// DefaultAPIKeyDailyQuota is the number of public API requests a new key may make per UTC day
+func DefaultAPIKeyDailyQuota() int {
+	return NewSettingsService().GetInt("public_api.daily_quota", "PUBLIC_API_DAILY_QUOTA", 1000)
+}
+
+// APIKeyService issues public API keys and meters their daily usage
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{
+		db: database.GetDB(),
+	}
+}
+
+// HashAPIKey returns the stored form of a raw API key
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey returns a new random raw key; it is shown to the owner once and never stored
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+// usageDay truncates a time to the UTC day quotas are counted in
+func usageDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// QuotaResetAt returns when the current day's quota resets
+func QuotaResetAt() time.Time {
+	return usageDay(time.Now()).Add(24 * time.Hour)
+}
+
+// Authorize looks up a raw key and counts one request against today's quota.
+// It returns the key and the number of requests used today, including this one.
+func (s *APIKeyService) Authorize(rawKey string) (models.APIKey, int, error) {
+	var key models.APIKey
+	if err := s.db.Where("key_hash = ? AND revoked_at IS NULL", HashAPIKey(rawKey)).First(&key).Error; err != nil {
+		return key, 0, ErrAPIKeyInvalid
+	}
+
+	// Increment and read back in one statement so concurrent requests can't both slip under the quota
+	var used int
+	if err := s.db.Raw(`
+		INSERT INTO api_key_usage (api_key_id, day, requests) VALUES (?, ?, 1)
+		ON CONFLICT (api_key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1
+		RETURNING requests
+	`, key.ID, usageDay(time.Now())).Scan(&used).Error; err != nil {
+		return key, 0, err
+	}
+
+	if used > key.DailyQuota {
+		return key, used, ErrAPIKeyQuotaExceeded
+	}
+
+	if err := s.db.Model(&models.APIKey{}).Where("id = ?", key.ID).Update("last_used_at", time.Now()).Error; err != nil {
+		log.Printf("Warning: Failed to record API key %d usage time: %v", key.ID, err)
+	}
+	return key, used, nil
+}
+
+// UsageSince returns a key's per-day request counts from the given day onwards, oldest first
+func (s *APIKeyService) UsageSince(keyID uint, since time.Time) ([]models.APIKeyUsage, error) {
+	usage := []models.APIKeyUsage{}
+	err := s.db.Where("api_key_id = ? AND day >= ?", keyID, usageDay(since)).Order("day ASC").Find(&usage).Error
+	return usage, err
+}
+
+// MaxAPIKeysPerUser caps how many active public API keys one account can hold
+func MaxAPIKeysPerUser() int {
+	return utils.GetEnvInt("MAX_API_KEYS_PER_USER", 5)
+}
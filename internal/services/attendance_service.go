@@ -0,0 +1,157 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// attendanceLookback bounds how far back the worker looks for events whose check-in has closed
+const attendanceLookback = 7 * 24 * time.Hour
+
+// ReliabilityStats summarizes how often a user shows up to events that tracked attendance
+type ReliabilityStats struct {
+	Attended int64 `json:"attended"`
+	NoShows  int64 `json:"no_shows"`
+	// Percentage of tracked events attended; nil until the user has any tracked events
+	Score *float64 `json:"score,omitempty"`
+}
+
+// AttendanceService handles event check-in windows and no-show tracking.
+// Check-in opens when the event starts and stays open for CHECKIN_WINDOW.
+type AttendanceService struct {
+	db     *gorm.DB
+	window time.Duration
+}
+
+func NewAttendanceService() *AttendanceService {
+	return &AttendanceService{
+		db:     database.GetDB(),
+		window: utils.GetEnvDuration("CHECKIN_WINDOW", 6*time.Hour),
+	}
+}
+
+// CheckInOpen reports whether members can currently be checked in to the group's event
+func (s *AttendanceService) CheckInOpen(group models.Group, now time.Time) bool {
+	return !now.Before(group.DateTime) && now.Before(s.CheckInClosesAt(group))
+}
+
+// CheckInClosesAt is when the group's check-in window ends, counted from the end of the event
+func (s *AttendanceService) CheckInClosesAt(group models.Group) time.Time {
+	return group.EndsAt().Add(s.window)
+}
+
+// GenerateCheckInCode returns a random 6-digit code
+func GenerateCheckInCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// GenerateTicketToken returns a random token for a member's QR check-in ticket
+func GenerateTicketToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// RecordNoShows flags approved members who never checked in once an event's check-in window closes.
+// Events where nobody checked in are skipped, since the organizer didn't take attendance.
+func (s *AttendanceService) RecordNoShows() {
+	now := time.Now()
+	closedBefore := now.Add(-s.window)
+
+	var groups []models.Group
+	if err := s.db.Where("attendance_recorded_at IS NULL AND status IN ? AND COALESCE(end_date_time, date_time) <= ? AND COALESCE(end_date_time, date_time) > ?",
+		models.GroupStatusesHeld, closedBefore, closedBefore.Add(-attendanceLookback)).
+		Find(&groups).Error; err != nil {
+		log.Printf("Failed to fetch groups for no-show tracking: %v", err)
+		return
+	}
+
+	for _, group := range groups {
+		if err := s.recordGroupNoShows(group, now); err != nil {
+			log.Printf("Failed to record no-shows for group %s: %v", group.ID, err)
+		}
+	}
+}
+
+// recordGroupNoShows marks one event's no-shows, which count against their reliability stats, and
+// applies the usual offense penalties
+func (s *AttendanceService) recordGroupNoShows(group models.Group, now time.Time) error {
+	var checkedIn int64
+	if err := s.db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND checked_in_at IS NOT NULL", group.ID).
+		Count(&checkedIn).Error; err != nil {
+		return fmt.Errorf("failed to count check-ins: %w", err)
+	}
+
+	var noShows []string
+	if checkedIn > 0 {
+		if err := s.db.Model(&models.GroupMember{}).
+			Where("group_id = ? AND status = ? AND username != ? AND checked_in_at IS NULL", group.ID, "approved", group.OrganiserID).
+			Pluck("username", &noShows).Error; err != nil {
+			return fmt.Errorf("failed to fetch no-shows: %w", err)
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if len(noShows) > 0 {
+			if err := tx.Model(&models.GroupMember{}).
+				Where("group_id = ? AND username IN ?", group.ID, noShows).
+				Update("no_show", true).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Group{}).Where("id = ?", group.ID).Update("attendance_recorded_at", now).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save attendance: %w", err)
+	}
+
+	penaltyService := NewPenaltyService()
+	for _, username := range noShows {
+		if _, err := penaltyService.RecordOffense(username, group.ID, models.OffenseNoShow); err != nil {
+			log.Printf("Failed to record no-show offense for %s: %v", username, err)
+		}
+		msg := fmt.Sprintf("You were marked as a no-show for '%s'. No-shows are shown in the reliability stats on your profile, and repeated no-shows can pause your ability to join groups.", group.Name)
+		if err := createNotification(s.db, username, "marked_no_show", msg, group.ID); err != nil {
+			log.Printf("Failed to create no-show notification for %s: %v", username, err)
+		}
+	}
+
+	if len(noShows) > 0 {
+		log.Printf("Recorded %d no-shows for group %s", len(noShows), group.ID)
+	}
+	return nil
+}
+
+// GetReliability counts a user's check-ins and no-shows across events that tracked attendance
+func (s *AttendanceService) GetReliability(username string) (ReliabilityStats, error) {
+	var stats ReliabilityStats
+	if err := s.db.Model(&models.GroupMember{}).
+		Select("COUNT(*) FILTER (WHERE checked_in_at IS NOT NULL) AS attended, COUNT(*) FILTER (WHERE no_show) AS no_shows").
+		Joins(`JOIN "group" ON "group".id = group_member.group_id`).
+		Where(`group_member.username = ? AND "group".organiser_id != ?`, username, username).
+		Scan(&stats).Error; err != nil {
+		return stats, fmt.Errorf("failed to count attendance: %w", err)
+	}
+
+	if total := stats.Attended + stats.NoShows; total > 0 {
+		score := float64(stats.Attended) / float64(total) * 100
+		stats.Score = &score
+	}
+	return stats, nil
+}
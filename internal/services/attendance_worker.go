@@ -0,0 +1,30 @@
+package services
+
+import (
+	"time"
+)
+
+type AttendanceWorker struct {
+	attendanceService *AttendanceService
+	interval          time.Duration
+}
+
+func NewAttendanceWorker() *AttendanceWorker {
+	return &AttendanceWorker{
+		attendanceService: NewAttendanceService(),
+		interval:          15 * time.Minute, // Check every 15 minutes
+	}
+}
+
+func (w *AttendanceWorker) Start() {
+	go w.run()
+}
+
+func (w *AttendanceWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.attendanceService.RecordNoShows()
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/models"
+	"strings"
+	"time"
+)
+
+// iCalendar methods used in event emails
+const (
+	CalendarMethodRequest = "REQUEST"
+	CalendarMethodCancel  = "CANCEL"
+)
+
+// calendarTimeFormat is the iCalendar UTC date-time format
+const calendarTimeFormat = "20060102T150405Z"
+
+// EventUID is the stable iCalendar UID for a group's event. Every calendar
+// file for a group must use it so clients can match updates and cancellations
+// to the entry originally added.
+func EventUID(groupID string) string {
+	return groupID + "@groops"
+}
+
+// BuildEventICS renders a single-event iCalendar file for a group.
+// With CalendarMethodCancel the event is marked cancelled so clients remove it.
+func BuildEventICS(group models.Group, organizerEmail, method string) []byte {
+	status := "CONFIRMED"
+	sequence := 0
+	if method == CalendarMethodCancel {
+		status = "CANCELLED"
+		sequence = 1
+	}
+
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//Groops//Groops Events//EN")
+	writeLine("METHOD:" + method)
+	writeLine("BEGIN:VEVENT")
+	writeLine("UID:" + EventUID(group.ID))
+	writeLine("DTSTAMP:" + time.Now().UTC().Format(calendarTimeFormat))
+	writeLine("DTSTART:" + group.DateTime.UTC().Format(calendarTimeFormat))
+	if group.EndDateTime != nil {
+		writeLine("DTEND:" + group.EndDateTime.UTC().Format(calendarTimeFormat))
+	}
+	writeLine("SUMMARY:" + escapeICSText(group.Name))
+	writeLine("LOCATION:" + escapeICSText(group.Location.FormattedAddress))
+	if organizerEmail != "" {
+		writeLine("ORGANIZER:mailto:" + organizerEmail)
+	}
+	writeLine(fmt.Sprintf("SEQUENCE:%d", sequence))
+	writeLine("STATUS:" + status)
+	writeLine("END:VEVENT")
+	writeLine("END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// escapeICSText escapes characters that have special meaning in iCalendar text values
+func escapeICSText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
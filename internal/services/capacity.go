@@ -0,0 +1,36 @@
+package services
+
+import (
+	"groops/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// MemberCountColumns are the counter caches on group maintained by RefreshMemberCounts. Whole-row
+// saves of a group must omit them so a stale copy doesn't overwrite a concurrent join.
+var MemberCountColumns = []string{"approved_member_count", "approved_guest_count", "pending_count"}
+
+// RefreshMemberCounts recomputes the member counter caches of the given groups from group_member.
+// Call it in the same transaction as any change to a group's memberships or guest counts.
+func RefreshMemberCounts(db *gorm.DB, groupIDs ...string) error {
+	if len(groupIDs) == 0 {
+		return nil
+	}
+	// Listing cards show these counts
+	database.MarkGroupCardsChanged(db)
+	return db.Exec(`UPDATE "group" SET
+		approved_member_count = (SELECT COUNT(*) FROM group_member WHERE group_id = "group".id AND status = 'approved'),
+		approved_guest_count = (SELECT COALESCE(SUM(guest_count), 0) FROM group_member WHERE group_id = "group".id AND status = 'approved'),
+		pending_count = (SELECT COUNT(*) FROM group_member WHERE group_id = "group".id AND status = 'pending')
+		WHERE id IN ?`, groupIDs).Error
+}
+
+// WithMemberCounts runs fn in a transaction and refreshes the group's member counts before committing
+func WithMemberCounts(db *gorm.DB, groupID string, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return RefreshMemberCounts(tx, groupID)
+	})
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"groops/internal/utils"
+	"strings"
+	"time"
+)
+
+// chatWebhookClient is shared by everything that posts to Slack or Discord incoming webhooks.
+// Webhook URLs are user-supplied, so it refuses to connect to internal addresses.
+var chatWebhookClient = utils.NewSafeHTTPClient(10 * time.Second)
+
+// isDiscordWebhook reports whether a webhook URL points at Discord rather than Slack
+func isDiscordWebhook(webhookURL string) bool {
+	return strings.Contains(webhookURL, "discord.com/api/webhooks") || strings.Contains(webhookURL, "discordapp.com/api/webhooks")
+}
+
+// SendChatWebhook posts a plain-text message to a Slack or Discord incoming webhook
+func SendChatWebhook(webhookURL, text string) error {
+	payload := map[string]string{"text": text}
+	if isDiscordWebhook(webhookURL) {
+		payload = map[string]string{"content": text}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	resp, err := chatWebhookClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
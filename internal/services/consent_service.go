@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrCodeConsentRequired tells clients to show the current policies and ask the user to accept them
+const ErrCodeConsentRequired = "CONSENT_REQUIRED"
+
+// PolicyVersions identifies a published terms of service and privacy policy
+type PolicyVersions struct {
+	Terms   string `json:"terms_version"`
+	Privacy string `json:"privacy_version"`
+}
+
+// CurrentPolicyVersions returns the published policy versions (TERMS_VERSION, PRIVACY_POLICY_VERSION).
+// Bumping either makes every user accept again before they can make changes.
+func CurrentPolicyVersions() PolicyVersions {
+	versions := PolicyVersions{Terms: os.Getenv("TERMS_VERSION"), Privacy: os.Getenv("PRIVACY_POLICY_VERSION")}
+	if versions.Terms == "" {
+		versions.Terms = "1"
+	}
+	if versions.Privacy == "" {
+		versions.Privacy = "1"
+	}
+	return versions
+}
+
+// ConsentService records policy acceptance
+type ConsentService struct {
+	db *gorm.DB
+}
+
+func NewConsentService() *ConsentService {
+	return &ConsentService{db: database.GetDB()}
+}
+
+// NeedsConsent reports whether the account hasn't accepted the current policy versions
+func (s *ConsentService) NeedsConsent(username string) (bool, error) {
+	var account models.Account
+	if err := s.db.Select("terms_version", "privacy_version").Where("username = ?", username).First(&account).Error; err != nil {
+		return false, err
+	}
+	current := CurrentPolicyVersions()
+	return account.TermsVersion != current.Terms || account.PrivacyVersion != current.Privacy, nil
+}
+
+// Accept records the user's acceptance of the current policy versions, adding a history entry
+// for each document whose accepted version changes
+func (s *ConsentService) Accept(username, ipAddress, userAgent string) (PolicyVersions, error) {
+	current := CurrentPolicyVersions()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var account models.Account
+		if err := tx.Select("username", "terms_version", "privacy_version").Where("username = ?", username).First(&account).Error; err != nil {
+			return fmt.Errorf("account not found: %w", err)
+		}
+
+		now := time.Now()
+		accepted := map[string]struct{ previous, version string }{
+			models.ConsentTerms:   {account.TermsVersion, current.Terms},
+			models.ConsentPrivacy: {account.PrivacyVersion, current.Privacy},
+		}
+		for _, document := range []string{models.ConsentTerms, models.ConsentPrivacy} {
+			if accepted[document].previous == accepted[document].version {
+				continue
+			}
+			record := models.ConsentRecord{
+				Username:   username,
+				Document:   document,
+				Version:    accepted[document].version,
+				AcceptedAt: now,
+				IPAddress:  ipAddress,
+				UserAgent:  userAgent,
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return fmt.Errorf("failed to record %s consent: %w", document, err)
+			}
+		}
+
+		return tx.Model(&models.Account{}).Where("username = ?", username).
+			Updates(map[string]interface{}{"terms_version": current.Terms, "privacy_version": current.Privacy}).Error
+	})
+	return current, err
+}
+
+// History returns the user's policy acceptances, newest first
+func (s *ConsentService) History(username string) ([]models.ConsentRecord, error) {
+	records := []models.ConsentRecord{}
+	err := s.db.Where("username = ?", username).Order("accepted_at DESC, id DESC").Find(&records).Error
+	return records, err
+}
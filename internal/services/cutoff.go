@@ -0,0 +1,30 @@
+package services
+
+import (
+	"groops/internal/models"
+	"time"
+)
+
+// DefaultGroupCutoff is how long before an event joining, leaving and editing lock,
+// for groups that don't set their own cutoff
+func DefaultGroupCutoff() time.Duration {
+	return NewSettingsService().GetDuration("group.cutoff", "GROUP_CUTOFF", time.Hour)
+}
+
+// GroupCutoff returns the cutoff window that applies to a group
+func GroupCutoff(group models.Group) time.Duration {
+	if group.CutoffMinutes != nil {
+		return time.Duration(*group.CutoffMinutes) * time.Minute
+	}
+	return DefaultGroupCutoff()
+}
+
+// GroupCutoffTime returns the moment joining, leaving and editing lock for a group
+func GroupCutoffTime(group models.Group) time.Time {
+	return group.DateTime.Add(-GroupCutoff(group))
+}
+
+// IsPastCutoff reports whether a group's cutoff window has started
+func IsPastCutoff(group models.Group) bool {
+	return !time.Now().Before(GroupCutoffTime(group))
+}
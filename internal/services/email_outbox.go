@@ -0,0 +1,130 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	"gorm.io/gorm"
+)
+
+// queueEmail stores a message that couldn't be sent so the outbox worker retries it
+func queueEmail(message *mail.SGMailV3, cause error) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode email: %w", err)
+	}
+
+	recipient := ""
+	if len(message.Personalizations) > 0 && len(message.Personalizations[0].To) > 0 {
+		recipient = message.Personalizations[0].To[0].Address
+	}
+	now := time.Now()
+	queued := models.QueuedEmail{
+		Recipient:     recipient,
+		Subject:       truncateEmailField(message.Subject, 255),
+		Payload:       string(payload),
+		LastError:     truncateEmailField(cause.Error(), 500),
+		NextAttemptAt: now.Add(time.Minute),
+		CreatedAt:     now,
+	}
+	if err := database.GetDB().Create(&queued).Error; err != nil {
+		return err
+	}
+	log.Printf("Queued email to %s for retry: %v", recipient, cause)
+	return nil
+}
+
+// truncateEmailField shortens s to at most n bytes for fixed-size columns
+func truncateEmailField(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// EmailOutboxWorker retries queued emails once SendGrid is reachable again, backing off
+// exponentially and giving up after EMAIL_OUTBOX_MAX_ATTEMPTS (default 10) tries
+type EmailOutboxWorker struct {
+	db           *gorm.DB
+	emailService *EmailService
+	maxAttempts  int
+	interval     time.Duration
+}
+
+func NewEmailOutboxWorker() *EmailOutboxWorker {
+	return &EmailOutboxWorker{
+		db:           database.GetDB(),
+		emailService: NewEmailService(),
+		maxAttempts:  utils.GetEnvInt("EMAIL_OUTBOX_MAX_ATTEMPTS", 10),
+		interval:     time.Minute, // Check every minute
+	}
+}
+
+func (w *EmailOutboxWorker) Start() {
+	go w.run()
+}
+
+func (w *EmailOutboxWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.retryQueuedEmails()
+	}
+}
+
+func (w *EmailOutboxWorker) retryQueuedEmails() {
+	if sendgridBreaker.Open() {
+		return
+	}
+
+	var queued []models.QueuedEmail
+	if err := w.db.Where("next_attempt_at <= ?", time.Now()).Order("id ASC").Limit(50).Find(&queued).Error; err != nil {
+		log.Printf("Error: Failed to fetch queued emails: %v", err)
+		return
+	}
+
+	for _, email := range queued {
+		var message mail.SGMailV3
+		if err := json.Unmarshal([]byte(email.Payload), &message); err != nil {
+			log.Printf("Warning: Dropping unreadable queued email %d: %v", email.ID, err)
+			w.db.Delete(&email)
+			continue
+		}
+
+		response, err := w.emailService.deliver(w.emailService.ctx, &message)
+		if errors.Is(err, utils.ErrCircuitOpen) {
+			return // SendGrid is failing again; try the rest later
+		}
+		if err == nil {
+			if response.StatusCode >= 400 {
+				log.Printf("Warning: SendGrid rejected queued email to %s: %d", email.Recipient, response.StatusCode)
+			}
+			if err := w.db.Delete(&email).Error; err != nil {
+				log.Printf("Warning: Failed to remove sent email %d from the outbox: %v", email.ID, err)
+			}
+			continue
+		}
+
+		attempts := email.Attempts + 1
+		if attempts >= w.maxAttempts {
+			log.Printf("Warning: Giving up on email to %s after %d attempts: %v", email.Recipient, attempts, err)
+			w.db.Delete(&email)
+			continue
+		}
+		if err := w.db.Model(&email).Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      truncateEmailField(err.Error(), 500),
+			"next_attempt_at": time.Now().Add(retryBackoff(attempts)),
+		}).Error; err != nil {
+			log.Printf("Warning: Failed to reschedule queued email %d: %v", email.ID, err)
+		}
+	}
+}
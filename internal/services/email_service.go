@@ -1,19 +1,35 @@
 package services
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"groops/internal/models"
+	"groops/internal/utils"
+	"html"
+	"log"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/sendgrid/rest"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	"gorm.io/gorm"
 )
 
 type EmailService struct {
 	client    *sendgrid.Client
 	fromEmail string
 	fromName  string
+
+	// Sends stop when this is cancelled; background by default so emails sent after a
+	// request has finished still go out
+	ctx context.Context
+
+	// When set, emails are written to the outbox through this transaction instead of being sent
+	outbox *gorm.DB
 }
 
 func NewEmailService() *EmailService {
@@ -27,9 +43,67 @@ func NewEmailService() *EmailService {
 		client:    client,
 		fromEmail: fromEmail,
 		fromName:  fromName,
+		ctx:       context.Background(),
 	}
 }
 
+// WithContext returns a copy of the service whose sends are cancelled with ctx
+func (s *EmailService) WithContext(ctx context.Context) *EmailService {
+	copied := *s
+	copied.ctx = ctx
+	return &copied
+}
+
+// WithOutbox returns a copy of the service that queues its emails in the outbox through tx, so
+// they are only sent once the transaction commits
+func (s *EmailService) WithOutbox(tx *gorm.DB) *EmailService {
+	copied := *s
+	copied.outbox = tx
+	return &copied
+}
+
+// sendgridBreaker trips when SendGrid keeps failing; emails are queued in the outbox meanwhile
+var sendgridBreaker = utils.NewCircuitBreaker("sendgrid")
+
+// deliver sends one message through the circuit breaker, bounded by SENDGRID_TIMEOUT. Server errors
+// count as failures; a 4xx is SendGrid rejecting this message and is returned as is.
+func (s *EmailService) deliver(ctx context.Context, message *mail.SGMailV3) (*rest.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, utils.SendGridTimeout())
+	defer cancel()
+
+	var response *rest.Response
+	err := sendgridBreaker.Do(func() (err error) {
+		response, err = s.client.SendWithContext(ctx, message)
+		if err == nil && response.StatusCode >= 500 {
+			err = fmt.Errorf("sendgrid returned status %d", response.StatusCode)
+		}
+		return err
+	})
+	return response, err
+}
+
+// send delivers one message, or queues it in the outbox when SendGrid is failing so it goes out
+// once SendGrid recovers. A queued message is reported as accepted. A service made WithOutbox
+// writes the message to the outbox dispatcher's queue instead.
+func (s *EmailService) send(message *mail.SGMailV3) (*rest.Response, error) {
+	if s.outbox != nil {
+		if err := enqueueOutboxEvent(s.outbox, outboxKindEmail, message); err != nil {
+			return nil, err
+		}
+		return &rest.Response{StatusCode: http.StatusAccepted}, nil
+	}
+
+	response, err := s.deliver(s.ctx, message)
+	if err == nil {
+		return response, nil
+	}
+	if queueErr := queueEmail(message, err); queueErr != nil {
+		log.Printf("Warning: Failed to queue email after send failure: %v", queueErr)
+		return response, err
+	}
+	return &rest.Response{StatusCode: http.StatusAccepted}, nil
+}
+
 // convertToIST converts UTC time to IST (Indian Standard Time)
 func convertToIST(utcTime time.Time) time.Time {
 	ist, err := time.LoadLocation("Asia/Kolkata")
@@ -40,6 +114,16 @@ func convertToIST(utcTime time.Time) time.Time {
 	return utcTime.In(ist)
 }
 
+// activityBannerHTML renders the activity's cover image and accent color as an email header,
+// matching how the group looks in the app
+func activityBannerHTML(activityType string) string {
+	display := ActivityDisplayFor(activityType)
+	return fmt.Sprintf(`<div style="background:%s;border-radius:8px;overflow:hidden;margin-bottom:16px">`+
+		`<img src="%s" alt="%s" width="600" style="display:block;width:100%%;max-width:600px">`+
+		`<p style="margin:0;padding:8px 16px;color:#ffffff;font-weight:bold">%s</p></div>`,
+		display.Color, html.EscapeString(display.CoverImageURL), html.EscapeString(display.Label), html.EscapeString(display.Label))
+}
+
 // SendWelcomeEmail sends a welcome email to users who register a username
 func (s *EmailService) SendWelcomeEmail(userEmail, userName string) error {
 	from := mail.NewEmail(s.fromName, s.fromEmail)
@@ -49,12 +133,12 @@ func (s *EmailService) SendWelcomeEmail(userEmail, userName string) error {
 	htmlContent := fmt.Sprintf("<p>Hello <strong>%s</strong>,</p><p>Welcome to <strong>Groops</strong>! We're excited to have you join our community.</p><p>Start exploring groups and activities now!</p>", userName)
 
 	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
-	_, err := s.client.Send(message)
+	_, err := s.send(message)
 	return err
 }
 
-// SendAdminOAuthNotification notifies admin when someone completes OAuth login
-func (s *EmailService) SendAdminOAuthNotification(userEmail, userName string) error {
+// SendAdminEventEmail delivers an admin event notification to ADMIN_NOTIFICATION_EMAIL
+func (s *EmailService) SendAdminEventEmail(subject, details string) error {
 	adminEmail := os.Getenv("ADMIN_NOTIFICATION_EMAIL")
 	if adminEmail == "" {
 		return fmt.Errorf("ADMIN_NOTIFICATION_EMAIL environment variable not set")
@@ -62,12 +146,10 @@ func (s *EmailService) SendAdminOAuthNotification(userEmail, userName string) er
 
 	from := mail.NewEmail(s.fromName, s.fromEmail)
 	to := mail.NewEmail("Admin", adminEmail)
-	subject := "New OAuth Login on Groops"
-	plainContent := fmt.Sprintf("A new user has completed OAuth login: %s (%s)", userName, userEmail)
-	htmlContent := fmt.Sprintf("<p>A new user has completed OAuth login:</p><p><strong>Name:</strong> %s</p><p><strong>Email:</strong> %s</p>", userName, userEmail)
+	htmlContent := fmt.Sprintf("<p><strong>%s</strong></p><pre>%s</pre>", html.EscapeString(subject), html.EscapeString(details))
 
-	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
-	_, err := s.client.Send(message)
+	message := mail.NewSingleEmail(from, "[Groops admin] "+subject, to, details, htmlContent)
+	_, err := s.send(message)
 	return err
 }
 
@@ -80,20 +162,25 @@ func (s *EmailService) SendJoinRequestEmail(ownerEmail, ownerName, requesterName
 	htmlContent := fmt.Sprintf("<p>%s has requested to join your group '<strong>%s</strong>'</p>", requesterName, groupName)
 
 	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
-	_, err := s.client.Send(message)
+	_, err := s.send(message)
 	return err
 }
 
-// SendJoinApprovalEmail notifies user their request was approved
-func (s *EmailService) SendJoinApprovalEmail(userEmail, userName, groupName string) error {
+// SendJoinApprovalEmail notifies user their request was approved, including the
+// organizer's approval message when the group has one
+func (s *EmailService) SendJoinApprovalEmail(userEmail, userName, groupName, organizerMessage string) error {
 	from := mail.NewEmail(s.fromName, s.fromEmail)
 	to := mail.NewEmail(userName, userEmail)
 	subject := fmt.Sprintf("You're in! Join request for %s approved", groupName)
 	plainContent := fmt.Sprintf("Your request to join '%s' has been approved!", groupName)
 	htmlContent := fmt.Sprintf("<p>Good news! Your request to join '<strong>%s</strong>' has been approved!</p>", groupName)
+	if organizerMessage != "" {
+		plainContent += fmt.Sprintf(" Message from the organizer: %s", organizerMessage)
+		htmlContent += fmt.Sprintf("<p><strong>Message from the organizer:</strong> %s</p>", html.EscapeString(organizerMessage))
+	}
 
 	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
-	_, err := s.client.Send(message)
+	_, err := s.send(message)
 	return err
 }
 
@@ -106,17 +193,177 @@ func (s *EmailService) SendMemberRemovalEmail(userEmail, userName, groupName str
 	htmlContent := fmt.Sprintf("<p>You have been removed from the group '<strong>%s</strong>'</p>", groupName)
 
 	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
-	_, err := s.client.Send(message)
+	_, err := s.send(message)
+	return err
+}
+
+// SendEventUpdatedEmail tells a member exactly which details of their event changed
+func (s *EmailService) SendEventUpdatedEmail(userEmail, userName, groupName string, changes []EventChange, needsReconfirmation bool) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(userName, userEmail)
+	subject := fmt.Sprintf("Event details changed for %s", groupName)
+
+	lines := make([]string, len(changes))
+	items := make([]string, len(changes))
+	for i, change := range changes {
+		lines[i] = "- " + change.String()
+		items[i] = "<li>" + html.EscapeString(change.String()) + "</li>"
+	}
+	plainContent := fmt.Sprintf("The organizer updated '%s':\n%s\n", groupName, strings.Join(lines, "\n"))
+	htmlContent := fmt.Sprintf("<p>The organizer updated '<strong>%s</strong>':</p><ul>%s</ul>", html.EscapeString(groupName), strings.Join(items, ""))
+
+	if needsReconfirmation {
+		plainContent += " Please confirm you can still attend, or leave the group to free up your spot."
+		htmlContent += "<p>Please confirm you can still attend, or leave the group to free up your spot.</p>"
+	}
+
+	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+	_, err := s.send(message)
+	return err
+}
+
+// SendWaitlistOfferEmail tells a waitlisted user a spot has opened and how long they have to claim it
+func (s *EmailService) SendWaitlistOfferEmail(userEmail, userName, groupName string, expiresAt time.Time) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(userName, userEmail)
+	deadline := convertToIST(expiresAt).Format("Mon Jan 2, 3:04 PM") + " IST"
+	subject := fmt.Sprintf("A spot opened up in %s", groupName)
+	plainContent := fmt.Sprintf("Good news! A spot opened up in '%s'. Request to join before %s to claim it, after which it will be offered to the next person on the waitlist.", groupName, deadline)
+	htmlContent := fmt.Sprintf("<p>Good news! A spot opened up in '<strong>%s</strong>'.</p><p>Request to join before <strong>%s</strong> to claim it, after which it will be offered to the next person on the waitlist.</p>", groupName, deadline)
+
+	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+	_, err := s.send(message)
+	return err
+}
+
+// SendBroadcastEmail delivers an organizer's day-of update to a group member
+func (s *EmailService) SendBroadcastEmail(userEmail, userName, groupName, message string) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(userName, userEmail)
+	subject := fmt.Sprintf("Update for %s", groupName)
+	plainContent := fmt.Sprintf("Your organizer for '%s' sent an update: %s", groupName, message)
+	htmlContent := fmt.Sprintf("<p>Your organizer for '<strong>%s</strong>' sent an update:</p><p>%s</p>",
+		html.EscapeString(groupName), html.EscapeString(message))
+
+	msg := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+	_, err := s.send(msg)
+	return err
+}
+
+// SendGroupInviteEmail invites someone without a Groops account to an organizer's group
+func (s *EmailService) SendGroupInviteEmail(email, organizer string, group models.Group) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail("", email)
+	timeStr := convertToIST(group.DateTime).Format("Mon Jan 2, 3:04 PM") + " IST"
+	subject := fmt.Sprintf("%s invited you to %s on Groops", organizer, group.Name)
+	plainContent := fmt.Sprintf("%s invited you to join '%s' on %s. Sign up at https://groops.fun with this email address to accept the invitation.",
+		organizer, group.Name, timeStr)
+	htmlContent := activityBannerHTML(group.ActivityType) + fmt.Sprintf("<p><strong>%s</strong> invited you to join '<strong>%s</strong>' on %s.</p>"+
+		`<p><a href="https://groops.fun/invites">Sign up</a> with this email address to accept the invitation.</p>`,
+		html.EscapeString(organizer), html.EscapeString(group.Name), timeStr)
+
+	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+	_, err := s.send(message)
+	return err
+}
+
+// SendAnnouncementEmail delivers an organizer's announcement to a group member
+func (s *EmailService) SendAnnouncementEmail(userEmail, userName, groupName, title, body string) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(userName, userEmail)
+	subject := fmt.Sprintf("[%s] %s", groupName, title)
+	plainContent := fmt.Sprintf("New announcement from the organizer of '%s':\n\n%s\n\n%s", groupName, title, body)
+	htmlContent := fmt.Sprintf("<p>New announcement from the organizer of '<strong>%s</strong>':</p><h3>%s</h3><p>%s</p>",
+		html.EscapeString(groupName), html.EscapeString(title), strings.ReplaceAll(html.EscapeString(body), "\n", "<br>"))
+
+	msg := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+	_, err := s.send(msg)
+	return err
+}
+
+// SendMentionEmail tells a member someone mentioned them in a group chat
+func (s *EmailService) SendMentionEmail(userEmail, userName, mentionedBy, groupName, message string) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(userName, userEmail)
+	subject := fmt.Sprintf("%s mentioned you in %s", mentionedBy, groupName)
+	plainContent := fmt.Sprintf("%s mentioned you in the chat for '%s': %s", mentionedBy, groupName, message)
+	htmlContent := fmt.Sprintf("<p><strong>%s</strong> mentioned you in the chat for '<strong>%s</strong>':</p><p>%s</p>",
+		html.EscapeString(mentionedBy), html.EscapeString(groupName), html.EscapeString(message))
+
+	msg := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+	_, err := s.send(msg)
+	return err
+}
+
+// SendEventCancellationEmail tells a member the event was cancelled and attaches an
+// iCalendar CANCEL so calendar clients remove the entry automatically.
+// The organizer's reason is included when non-empty.
+func (s *EmailService) SendEventCancellationEmail(userEmail, userName string, group models.Group, organizerEmail, reason string) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(userName, userEmail)
+	timeStr := convertToIST(group.DateTime).Format("Mon Jan 2, 3:04 PM") + " IST"
+	subject := fmt.Sprintf("Cancelled: %s", group.Name)
+	plainContent := fmt.Sprintf("Hello %s, the event '%s' scheduled for %s has been cancelled by the organizer.", userName, group.Name, timeStr)
+	htmlContent := activityBannerHTML(group.ActivityType) + fmt.Sprintf("<p>Hello %s,</p><p>The event '<strong>%s</strong>' scheduled for %s has been cancelled by the organizer.</p>",
+		html.EscapeString(userName), html.EscapeString(group.Name), timeStr)
+	if reason != "" {
+		plainContent += fmt.Sprintf(" Reason: %s", reason)
+		htmlContent += fmt.Sprintf("<p><strong>Reason:</strong> %s</p>", html.EscapeString(reason))
+	}
+
+	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+
+	ics := BuildEventICS(group, organizerEmail, CalendarMethodCancel)
+	attachment := mail.NewAttachment()
+	attachment.SetContent(base64.StdEncoding.EncodeToString(ics))
+	attachment.SetType("text/calendar; method=CANCEL")
+	attachment.SetFilename("cancel.ics")
+	attachment.SetDisposition("attachment")
+	message.AddAttachment(attachment)
+
+	_, err := s.send(message)
+	return err
+}
+
+// SendFinalHeadcountEmail tells the organizer who is coming once RSVPs have closed
+func (s *EmailService) SendFinalHeadcountEmail(organizerEmail, organizerName string, group models.Group, attendees []string, guests int) error {
+	from := mail.NewEmail(s.fromName, s.fromEmail)
+	to := mail.NewEmail(organizerName, organizerEmail)
+	timeStr := convertToIST(group.DateTime).Format("Mon Jan 2, 3:04 PM") + " IST"
+	headcount := len(attendees) + guests
+	subject := fmt.Sprintf("Final headcount for %s: %d", group.Name, headcount)
+
+	guestNote := ""
+	if guests > 0 {
+		guestNote = fmt.Sprintf(" (%d members plus %d guests)", len(attendees), guests)
+	}
+	plainContent := fmt.Sprintf("RSVPs for '%s' on %s have closed. Final headcount: %d%s.\n\n%s",
+		group.Name, timeStr, headcount, guestNote, strings.Join(attendees, "\n"))
+
+	escaped := make([]string, len(attendees))
+	for i, attendee := range attendees {
+		escaped[i] = "<li>" + html.EscapeString(attendee) + "</li>"
+	}
+	htmlContent := activityBannerHTML(group.ActivityType) + fmt.Sprintf("<p>RSVPs for '<strong>%s</strong>' on %s have closed.</p><p>Final headcount: <strong>%d</strong>%s</p><ul>%s</ul>",
+		html.EscapeString(group.Name), timeStr, headcount, guestNote, strings.Join(escaped, ""))
+
+	message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
+	_, err := s.send(message)
 	return err
 }
 
 // SendEventReminderToGroup sends event reminders to all members in a group
-func (s *EmailService) SendEventReminderToGroup(group models.Group, members []models.Account, reminderType string) error {
+// weatherWarning is included in the email when non-empty (e.g. rain forecast for an outdoor event), and
+// each member is reminded of the checklist items they claimed and the ones nobody has claimed yet
+func (s *EmailService) SendEventReminderToGroup(group models.Group, members []models.Account, reminderType string, weatherWarning string, checklist []models.ChecklistItem) error {
 	from := mail.NewEmail(s.fromName, s.fromEmail)
 
 	// Convert UTC time to IST for display
 	istTime := convertToIST(group.DateTime)
 	timeStr := istTime.Format("Mon Jan 2, 3:04 PM") + " IST"
+	if group.EndDateTime != nil {
+		timeStr += " (until " + convertToIST(*group.EndDateTime).Format("Mon Jan 2, 3:04 PM") + " IST)"
+	}
 
 	// Simple subject based on reminder type
 	subject := ""
@@ -134,14 +381,29 @@ func (s *EmailService) SendEventReminderToGroup(group models.Group, members []mo
 		plainContent := fmt.Sprintf("Hello %s, Your event %s is coming up soon at %s at %s. Don't miss it!",
 			member.Username, group.Name, timeStr, group.Location.Name)
 
-		htmlContent := fmt.Sprintf("<p>Hello %s,</p><p>Your event <strong>%s</strong> is coming up soon at %s at %s.</p><p>Don't miss it!</p>",
+		htmlContent := activityBannerHTML(group.ActivityType) + fmt.Sprintf("<p>Hello %s,</p><p>Your event <strong>%s</strong> is coming up soon at %s at %s.</p><p>Don't miss it!</p>",
 			member.Username, group.Name, timeStr, group.Location.Name)
 
+		if weatherWarning != "" {
+			plainContent += fmt.Sprintf(" Weather warning: %s Check with your organizer before heading out.", weatherWarning)
+			htmlContent += fmt.Sprintf("<p><strong>Weather warning:</strong> %s Check with your organizer before heading out.</p>", weatherWarning)
+		}
+
+		bringing, unclaimed := checklistForMember(checklist, member.Username)
+		if len(bringing) > 0 {
+			plainContent += " You're bringing: " + strings.Join(bringing, ", ") + "."
+			htmlContent += "<p><strong>You're bringing:</strong></p>" + htmlList(bringing)
+		}
+		if len(unclaimed) > 0 {
+			plainContent += " Still needed: " + strings.Join(unclaimed, ", ") + "."
+			htmlContent += "<p><strong>Still needed</strong> (claim them in the group's checklist):</p>" + htmlList(unclaimed)
+		}
+
 		// Create a simple email without template variables
 		message := mail.NewSingleEmail(from, subject, to, plainContent, htmlContent)
 
 		// Send email
-		response, err := s.client.Send(message)
+		response, err := s.send(message)
 		if err != nil {
 			return err
 		}
@@ -153,3 +415,30 @@ func (s *EmailService) SendEventReminderToGroup(group models.Group, members []mo
 
 	return nil
 }
+
+// checklistForMember splits a group's checklist into the items the member claimed and the ones nobody
+// has claimed, each described with its quantity
+func checklistForMember(checklist []models.ChecklistItem, username string) (bringing, unclaimed []string) {
+	for _, item := range checklist {
+		description := item.Name
+		if item.Quantity > 1 {
+			description = fmt.Sprintf("%s (x%d)", item.Name, item.Quantity)
+		}
+		switch {
+		case item.ClaimedBy == nil:
+			unclaimed = append(unclaimed, description)
+		case *item.ClaimedBy == username:
+			bringing = append(bringing, description)
+		}
+	}
+	return bringing, unclaimed
+}
+
+// htmlList renders items as an escaped HTML bullet list
+func htmlList(items []string) string {
+	escaped := make([]string, len(items))
+	for i, item := range items {
+		escaped[i] = "<li>" + html.EscapeString(item) + "</li>"
+	}
+	return "<ul>" + strings.Join(escaped, "") + "</ul>"
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/models"
+)
+
+// EventChange is one member-facing detail of an event that an update changed
+type EventChange struct {
+	Field string `json:"field"` // date, time, end or location
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// String describes the change for notifications and emails
+func (c EventChange) String() string {
+	switch c.Field {
+	case "date":
+		return fmt.Sprintf("Date changed from %s to %s", c.From, c.To)
+	case "time":
+		return fmt.Sprintf("Time changed from %s to %s", c.From, c.To)
+	case "end":
+		return fmt.Sprintf("End changed from %s to %s", c.From, c.To)
+	default:
+		return fmt.Sprintf("Venue changed from %s to %s", c.From, c.To)
+	}
+}
+
+// DiffEventDetails lists what changed in the date, start time, end and venue between two versions of a
+// group. Dates and times are compared as members see them, in IST.
+func DiffEventDetails(before, after models.Group) []EventChange {
+	var changes []EventChange
+	previous, current := convertToIST(before.DateTime), convertToIST(after.DateTime)
+
+	if previous.Format("2006-01-02") != current.Format("2006-01-02") {
+		changes = append(changes, EventChange{Field: "date", From: previous.Format("Mon Jan 2"), To: current.Format("Mon Jan 2")})
+	}
+	if previous.Format("15:04") != current.Format("15:04") {
+		changes = append(changes, EventChange{Field: "time", From: previous.Format("3:04 PM") + " IST", To: current.Format("3:04 PM") + " IST"})
+	}
+
+	// Events without an end are treated as ending when they start, so only a set end is compared
+	if before.EndDateTime != nil || after.EndDateTime != nil {
+		previousEnd, currentEnd := convertToIST(before.EndsAt()), convertToIST(after.EndsAt())
+		if !previousEnd.Equal(currentEnd) {
+			changes = append(changes, EventChange{
+				Field: "end",
+				From:  previousEnd.Format("Mon Jan 2, 3:04 PM") + " IST",
+				To:    currentEnd.Format("Mon Jan 2, 3:04 PM") + " IST",
+			})
+		}
+	}
+
+	if before.Location.PlaceID != after.Location.PlaceID {
+		distanceKm := HaversineDistanceKm(before.Location.Latitude, before.Location.Longitude,
+			after.Location.Latitude, after.Location.Longitude)
+		changes = append(changes, EventChange{
+			Field: "location",
+			From:  before.Location.FormattedAddress,
+			To:    fmt.Sprintf("%s (%.1f km away)", after.Location.FormattedAddress, distanceKm),
+		})
+	}
+
+	return changes
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"groops/internal/models"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Event is a domain event handlers publish about a change they made. Cross-cutting features
+// (notifications, emails, webhooks, activity logging) subscribe to events rather than every
+// handler calling each of them.
+type Event interface {
+	isEvent()
+}
+
+// GroupCreated is published when a group and its organizer's membership are created
+type GroupCreated struct {
+	Group    models.Group
+	Imported bool // Created by a bulk import, which sends the organizer one summary notification instead
+}
+
+// How a member was approved without an organizer or co-organizer approving them (MemberApproved.Via)
+const (
+	ApprovedAutomatically = "auto_approval" // Matched the group's auto-approval rules
+	ApprovedByInviteLink  = "invite_link"
+	ApprovedByInvitation  = "invitation"
+)
+
+// MemberApproved is published whenever someone becomes an approved member: an organizer or
+// co-organizer approving their request, the group's auto-approval rules or invite link, or
+// accepting an invitation
+type MemberApproved struct {
+	Group      models.Group
+	Username   string
+	ApprovedBy string // Empty when nobody approved them by hand
+	Bulk       bool   // Part of a bulk approval, which sends the group's members one summary notification instead
+
+	// Set when the member joined without anyone approving them: how, and what the organizer is told
+	Via    string
+	Notice string
+}
+
+// GroupCancelled is published when the organizer cancels a group
+type GroupCancelled struct {
+	Group       models.Group
+	CancelledBy string
+	Reason      string
+	Members     []models.GroupMember // Approved and pending members other than the organizer
+}
+
+// MessageSent is published when a chat message is posted
+type MessageSent struct {
+	Group   models.Group
+	Message models.Message
+	ReplyTo string // Author of the message being replied to, unless it's the sender; empty otherwise
+}
+
+func (GroupCreated) isEvent()   {}
+func (MemberApproved) isEvent() {}
+func (GroupCancelled) isEvent() {}
+func (MessageSent) isEvent()    {}
+
+// eventBus holds the subscribers of each event type, registered at startup
+type eventBus struct {
+	mu          sync.RWMutex
+	inTx        map[reflect.Type][]func(tx *gorm.DB, event Event) error
+	afterCommit map[reflect.Type][]func(event Event)
+}
+
+var events = &eventBus{
+	inTx:        make(map[reflect.Type][]func(tx *gorm.DB, event Event) error),
+	afterCommit: make(map[reflect.Type][]func(event Event)),
+}
+
+// SubscribeEvent registers fn to run inside the transaction of every published E. It should only
+// write through tx (notifications, outbox emails and webhooks); an error rolls the change back.
+func SubscribeEvent[E Event](fn func(tx *gorm.DB, event E) error) {
+	key := reflect.TypeOf((*E)(nil)).Elem()
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	events.inTx[key] = append(events.inTx[key], func(tx *gorm.DB, event Event) error {
+		return fn(tx, event.(E))
+	})
+}
+
+// SubscribeCommitted registers fn to run once a published E has committed, for side effects that
+// can't be rolled back or whose failure shouldn't undo the change. Failures are fn's to log.
+func SubscribeCommitted[E Event](fn func(event E)) {
+	key := reflect.TypeOf((*E)(nil)).Elem()
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	events.afterCommit[key] = append(events.afterCommit[key], func(event Event) {
+		fn(event.(E))
+	})
+}
+
+// PublishEvent runs the event's transactional subscribers through tx, in the order they were
+// registered, stopping at the first error. Call it inside the transaction that makes the change.
+func PublishEvent(tx *gorm.DB, event Event) error {
+	events.mu.RLock()
+	subscribers := events.inTx[reflect.TypeOf(event)]
+	events.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		if err := subscriber(tx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishCommitted runs the event's SubscribeCommitted subscribers, in the order they were
+// registered. Call it once the change has committed.
+func PublishCommitted(event Event) {
+	events.mu.RLock()
+	subscribers := events.afterCommit[reflect.TypeOf(event)]
+	events.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+)
+
+// ExperimentControl is the variant users outside an experiment's rollout get
+const ExperimentControl = "control"
+
+// Experiment is a feature rolled out to a percentage of users. The rollout percentage is read from the
+// experiment.<key>.percent setting (or EXPERIMENT_<KEY>_PERCENT), so it can be raised without a
+// redeploy; users in the rollout are split evenly between the non-control variants.
+type Experiment struct {
+	Key            string
+	Description    string
+	Variants       []string // Non-control variants
+	DefaultPercent int      // Rollout when no setting is stored
+}
+
+// experiments are the running experiments; features read their variant with ExperimentVariant
+var experiments = []Experiment{
+	{Key: "ranking_v2", Description: "New ranking algorithm for group listings", Variants: []string{"treatment"}},
+}
+
+// experimentBucketCount is the resolution of rollout percentages (0.01%)
+const experimentBucketCount = 10000
+
+// experimentBucket hashes the user into one of experimentBucketCount buckets for the experiment.
+// The same user always lands in the same bucket, and buckets are independent between experiments.
+func experimentBucket(salt, username string) int {
+	sum := sha256.Sum256([]byte(salt + ":" + strings.ToLower(username)))
+	return int(binary.BigEndian.Uint64(sum[:8]) % experimentBucketCount)
+}
+
+// rolloutPercent is the share of users, 0 to 100, who get one of the experiment's variants
+func (e Experiment) rolloutPercent() int {
+	envKey := "EXPERIMENT_" + strings.ToUpper(e.Key) + "_PERCENT"
+	percent := NewSettingsService().GetInt("experiment."+e.Key+".percent", envKey, e.DefaultPercent)
+	if percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// variantFor buckets the user into the experiment, given its rollout percentage. Raising the
+// percentage only moves users from control into a variant, never between variants.
+func (e Experiment) variantFor(username string, percent int) string {
+	if username == "" || len(e.Variants) == 0 || experimentBucket(e.Key, username) >= percent*experimentBucketCount/100 {
+		return ExperimentControl
+	}
+	return e.Variants[experimentBucket(e.Key+":variant", username)%len(e.Variants)]
+}
+
+// ExperimentVariant returns the user's variant of an experiment, or control for unknown experiments
+// and anonymous visitors
+func ExperimentVariant(key, username string) string {
+	for _, experiment := range experiments {
+		if experiment.Key == key {
+			return experiment.variantFor(username, experiment.rolloutPercent())
+		}
+	}
+	return ExperimentControl
+}
+
+// AssignExperiments returns the user's variant of every running experiment, keyed by experiment
+func AssignExperiments(username string) map[string]string {
+	assignments := make(map[string]string, len(experiments))
+	for _, experiment := range experiments {
+		assignments[experiment.Key] = experiment.variantFor(username, experiment.rolloutPercent())
+	}
+	return assignments
+}
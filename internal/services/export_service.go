@@ -0,0 +1,235 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExportService struct {
+	db *gorm.DB
+}
+
+func NewExportService() *ExportService {
+	return &ExportService{
+		db: database.GetDB(),
+	}
+}
+
+// GenerateGroupArchive builds the archive for a pending export and stores the result.
+// It is intended to run in a goroutine; failures are recorded on the export row.
+func (s *ExportService) GenerateGroupArchive(exportID string) {
+	var export models.GroupExport
+	if err := s.db.Where("id = ?", exportID).First(&export).Error; err != nil {
+		log.Printf("Failed to load export %s: %v", exportID, err)
+		return
+	}
+
+	data, err := s.buildArchive(export.GroupID, export.ExpiresAt)
+	now := time.Now()
+	if err != nil {
+		log.Printf("Failed to build archive for export %s: %v", exportID, err)
+		s.db.Model(&export).Updates(map[string]interface{}{
+			"status":       models.ExportStatusFailed,
+			"error":        "Failed to build archive",
+			"completed_at": now,
+		})
+		return
+	}
+
+	if err := s.db.Model(&export).Updates(map[string]interface{}{
+		"status":       models.ExportStatusReady,
+		"data":         data,
+		"size_bytes":   len(data),
+		"completed_at": now,
+	}).Error; err != nil {
+		log.Printf("Failed to store archive for export %s: %v", exportID, err)
+		return
+	}
+
+	var group models.Group
+	groupName := export.GroupID
+	if err := s.db.Where("id = ?", export.GroupID).First(&group).Error; err == nil {
+		groupName = group.Name
+	}
+	msg := fmt.Sprintf("Your archive of '%s' is ready to download", groupName)
+	if err := createTargetedNotification(s.db, export.RequestedBy, "export_ready", msg, export.GroupID, export.ID); err != nil {
+		log.Printf("Warning: Failed to create export ready notification: %v", err)
+	}
+
+	// Opportunistically clear out archives past their expiry
+	s.PurgeExpiredExports()
+}
+
+// PurgeExpiredExports deletes archives whose download window has passed
+func (s *ExportService) PurgeExpiredExports() {
+	if err := s.db.Where("expires_at < ?", time.Now()).Delete(&models.GroupExport{}).Error; err != nil {
+		log.Printf("Failed to purge expired exports: %v", err)
+	}
+}
+
+// buildArchive packages a group's details, members, chat, polls and checklist into a zip file.
+// Chat photos are linked by URLs that work until linksExpire.
+func (s *ExportService) buildArchive(groupID string, linksExpire time.Time) ([]byte, error) {
+	var group models.Group
+	if err := s.db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		return nil, fmt.Errorf("failed to load group: %w", err)
+	}
+
+	var members []models.GroupMember
+	if err := s.db.Where("group_id = ?", groupID).Order("joined_at ASC").Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to load members: %w", err)
+	}
+
+	usernames := make([]string, 0, len(members))
+	for _, m := range members {
+		usernames = append(usernames, m.Username)
+	}
+	var accounts []models.Account
+	if len(usernames) > 0 {
+		if err := s.db.Where("username IN ?", usernames).Find(&accounts).Error; err != nil {
+			return nil, fmt.Errorf("failed to load member accounts: %w", err)
+		}
+	}
+	fullNames := make(map[string]string, len(accounts))
+	for _, a := range accounts {
+		fullNames[a.Username] = a.FullName
+	}
+
+	var messages []models.Message
+	if err := s.db.Where("group_id = ?", groupID).Order("created_at ASC").Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+
+	var polls []models.Poll
+	if err := s.db.Preload("Options").Where("group_id = ?", groupID).Order("created_at ASC").Find(&polls).Error; err != nil {
+		return nil, fmt.Errorf("failed to load polls: %w", err)
+	}
+
+	var checklist []models.ChecklistItem
+	if err := s.db.Where("group_id = ?", groupID).Order("created_at ASC").Find(&checklist).Error; err != nil {
+		return nil, fmt.Errorf("failed to load checklist: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	// Group details
+	groupJSON, err := json.MarshalIndent(group, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(zw, "group.json", groupJSON); err != nil {
+		return nil, err
+	}
+
+	// Member list
+	memberRows := [][]string{{"username", "full_name", "status", "joined_at"}}
+	for _, m := range members {
+		memberRows = append(memberRows, []string{m.Username, fullNames[m.Username], m.Status, m.JoinedAt.UTC().Format(time.RFC3339)})
+	}
+	if err := writeZipCSV(zw, "members.csv", memberRows); err != nil {
+		return nil, err
+	}
+
+	// Chat transcript
+	messageRows := [][]string{{"id", "username", "content", "attachment_url", "created_at"}}
+	var imageService *ImageService
+	for _, m := range messages {
+		attachmentURL := ""
+		if m.AttachmentPublicID != "" {
+			if imageService == nil {
+				if imageService, err = NewImageService(); err != nil {
+					return nil, fmt.Errorf("failed to initialize image service for attachment links: %w", err)
+				}
+			}
+			if attachmentURL, err = imageService.SignedAttachmentURL(m.AttachmentPublicID, m.AttachmentFormat, linksExpire); err != nil {
+				return nil, fmt.Errorf("failed to sign attachment URL for message %d: %w", m.ID, err)
+			}
+		}
+		messageRows = append(messageRows, []string{strconv.FormatUint(uint64(m.ID), 10), m.Username, m.Content, attachmentURL, m.CreatedAt.UTC().Format(time.RFC3339)})
+	}
+	if err := writeZipCSV(zw, "chat.csv", messageRows); err != nil {
+		return nil, err
+	}
+
+	// Polls with final results
+	pollService := &PollService{db: s.db}
+	type pollExport struct {
+		models.Poll
+		Results    []PollOptionResult `json:"results"`
+		TotalVotes int64              `json:"total_votes"`
+	}
+	pollExports := make([]pollExport, 0, len(polls))
+	for _, p := range polls {
+		results, total, err := pollService.GetResults(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load poll results: %w", err)
+		}
+		pollExports = append(pollExports, pollExport{Poll: p, Results: results, TotalVotes: total})
+	}
+	pollsJSON, err := json.MarshalIndent(pollExports, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(zw, "polls.json", pollsJSON); err != nil {
+		return nil, err
+	}
+
+	// What-to-bring checklist
+	checklistRows := [][]string{{"item", "quantity", "notes", "claimed_by"}}
+	for _, item := range checklist {
+		claimedBy := ""
+		if item.ClaimedBy != nil {
+			claimedBy = *item.ClaimedBy
+		}
+		checklistRows = append(checklistRows, []string{item.Name, strconv.Itoa(item.Quantity), item.Notes, claimedBy})
+	}
+	if err := writeZipCSV(zw, "checklist.csv", checklistRows); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeZipFile adds a file with the given contents to the archive
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeZipCSV adds a CSV file with the given rows to the archive. Cells hold names, messages and
+// notes members wrote, so they are escaped to open as text rather than formulas.
+func writeZipCSV(zw *zip.Writer, name string, rows [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	for _, row := range rows {
+		for i := range row {
+			row[i] = utils.CSVSafe(row[i])
+		}
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
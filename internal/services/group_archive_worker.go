@@ -0,0 +1,56 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GroupArchiveWorker moves groups that ended more than GROUP_ARCHIVE_AFTER ago (default 24h, well
+// after check-in closes) to the archived status, taking them out of the default listings and search.
+// Archived groups stay viewable by ID and are listed with GET /groups?status=past.
+type GroupArchiveWorker struct {
+	db           *gorm.DB
+	archiveAfter time.Duration
+	interval     time.Duration
+}
+
+func NewGroupArchiveWorker() *GroupArchiveWorker {
+	return &GroupArchiveWorker{
+		db:           database.GetDB(),
+		archiveAfter: utils.GetEnvDuration("GROUP_ARCHIVE_AFTER", 24*time.Hour),
+		interval:     time.Hour, // Check every hour
+	}
+}
+
+func (w *GroupArchiveWorker) Start() {
+	go w.run()
+}
+
+func (w *GroupArchiveWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.archiveEndedGroups()
+	}
+}
+
+// archiveEndedGroups archives every active group whose event is past the archive delay
+func (w *GroupArchiveWorker) archiveEndedGroups() {
+	now := time.Now()
+	result := w.db.Model(&models.Group{}).
+		Where("status = ? AND COALESCE(end_date_time, date_time) <= ?", models.GroupStatusActive, now.Add(-w.archiveAfter)).
+		Updates(map[string]interface{}{"status": models.GroupStatusArchived, "archived_at": now})
+	if result.Error != nil {
+		log.Printf("Failed to archive ended groups: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Archived %d ended groups", result.RowsAffected)
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GroupCardWorker refreshes the group_card listing view shortly after groups change, and at least
+// every GROUP_CARD_MAX_AGE so started events drop out and organizer profile edits show up
+type GroupCardWorker struct {
+	db          *gorm.DB
+	interval    time.Duration
+	maxAge      time.Duration
+	lastRefresh time.Time
+}
+
+func NewGroupCardWorker() *GroupCardWorker {
+	return &GroupCardWorker{
+		db:          database.GetDB(),
+		interval:    utils.GetEnvDuration("GROUP_CARD_REFRESH_INTERVAL", 15*time.Second),
+		maxAge:      utils.GetEnvDuration("GROUP_CARD_MAX_AGE", 5*time.Minute),
+		lastRefresh: time.Now(),
+	}
+}
+
+func (w *GroupCardWorker) Start() {
+	go w.run()
+}
+
+func (w *GroupCardWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !database.TakeGroupCardsStale() && time.Since(w.lastRefresh) < w.maxAge {
+			continue
+		}
+		if err := database.RefreshGroupCards(w.db); err != nil {
+			log.Printf("Failed to refresh group cards: %v", err)
+			database.MarkGroupCardsStale()
+			continue
+		}
+		w.lastRefresh = time.Now()
+	}
+}
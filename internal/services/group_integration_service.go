@@ -0,0 +1,78 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// IntegrationEvent identifies a kind of group activity that can be mirrored to chat
+type IntegrationEvent string
+
+const (
+	IntegrationEventJoinRequest   IntegrationEvent = "join_request"
+	IntegrationEventApproval      IntegrationEvent = "approval"
+	IntegrationEventChatHighlight IntegrationEvent = "chat_highlight"
+)
+
+// integrationEventColumns maps each event to the integration flag that enables it
+var integrationEventColumns = map[IntegrationEvent]string{
+	IntegrationEventJoinRequest:   "notify_join_requests",
+	IntegrationEventApproval:      "notify_approvals",
+	IntegrationEventChatHighlight: "notify_chat_highlights",
+}
+
+// GroupIntegrationService posts group activity to the Slack and Discord webhooks organizers connect
+type GroupIntegrationService struct {
+	db *gorm.DB
+}
+
+func NewGroupIntegrationService() *GroupIntegrationService {
+	return &GroupIntegrationService{
+		db: database.GetDB(),
+	}
+}
+
+// DetectIntegrationPlatform returns the platform for a Slack or Discord incoming webhook URL.
+// Only the official webhook hosts are accepted so integrations can't be pointed at arbitrary servers.
+func DetectIntegrationPlatform(webhookURL string) (string, bool) {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Scheme != "https" {
+		return "", false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	switch {
+	case host == "hooks.slack.com" && strings.HasPrefix(parsed.Path, "/services/"):
+		return models.IntegrationPlatformSlack, true
+	case (host == "discord.com" || host == "discordapp.com") && strings.HasPrefix(parsed.Path, "/api/webhooks/"):
+		return models.IntegrationPlatformDiscord, true
+	}
+	return "", false
+}
+
+// Mirror posts text to every integration on the group that has the event enabled.
+// Delivery failures are logged and never surface to the user who triggered the event.
+func (s *GroupIntegrationService) Mirror(groupID string, event IntegrationEvent, text string) {
+	column, ok := integrationEventColumns[event]
+	if !ok {
+		log.Printf("Warning: Unknown integration event %s", event)
+		return
+	}
+
+	var integrations []models.GroupIntegration
+	if err := s.db.Where("group_id = ? AND "+column+" = ?", groupID, true).Find(&integrations).Error; err != nil {
+		log.Printf("Warning: Failed to load integrations for group %s: %v", groupID, err)
+		return
+	}
+
+	for _, integration := range integrations {
+		if err := SendChatWebhook(integration.WebhookURL, text); err != nil {
+			log.Printf("Warning: Failed to post %s to %s integration %d: %v", event, integration.Platform, integration.ID, err)
+		}
+	}
+}
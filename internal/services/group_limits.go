@@ -0,0 +1,30 @@
+package services
+
+import "time"
+
+// Error codes returned when group settings violate the configured limits
+const (
+	ErrCodeActiveGroupLimit   = "ACTIVE_GROUP_LIMIT"
+	ErrCodeMaxMembersExceeded = "MAX_MEMBERS_EXCEEDED"
+	ErrCodeLeadTimeTooShort   = "LEAD_TIME_TOO_SHORT"
+	ErrCodeDurationTooLong    = "DURATION_TOO_LONG"
+)
+
+// GroupLimits holds the configurable caps applied when organizers create or update groups
+type GroupLimits struct {
+	MaxActiveGroups int           // Upcoming groups a single organizer may have at once
+	MaxMembers      int           // Largest allowed MaxMembers value
+	MinLeadTime     time.Duration // Minimum time between now and a group's DateTime
+	MaxDuration     time.Duration // Longest allowed time between a group's DateTime and EndDateTime
+}
+
+// LoadGroupLimits resolves group limits from settings, environment, or defaults
+func LoadGroupLimits() GroupLimits {
+	settings := NewSettingsService()
+	return GroupLimits{
+		MaxActiveGroups: settings.GetInt("group.max_active_per_organizer", "GROUP_MAX_ACTIVE_PER_ORGANIZER", 10),
+		MaxMembers:      settings.GetInt("group.max_members", "GROUP_MAX_MEMBERS", 50),
+		MinLeadTime:     settings.GetDuration("group.min_lead_time", "GROUP_MIN_LEAD_TIME", time.Hour),
+		MaxDuration:     settings.GetDuration("group.max_duration", "GROUP_MAX_DURATION", 14*24*time.Hour),
+	}
+}
@@ -3,20 +3,66 @@ package services
 import (
 	"context"
 	"fmt"
+	"groops/internal/utils"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
+// allowedAttachmentTypes are the content types accepted for chat images
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// UploadedAttachment describes a chat image stored privately in Cloudinary
+type UploadedAttachment struct {
+	PublicID    string
+	Format      string
+	ContentType string
+}
+
 type ImageService struct {
 	cld *cloudinary.Cloudinary
 }
 
+// cloudinaryBreaker trips when Cloudinary keeps failing, so uploads fail fast instead of holding requests
+var cloudinaryBreaker = utils.NewCircuitBreaker("cloudinary")
+
+// upload sends a file to Cloudinary, bounded by CLOUDINARY_TIMEOUT and the circuit breaker
+func (s *ImageService) upload(ctx context.Context, file multipart.File, params uploader.UploadParams) (*uploader.UploadResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, utils.CloudinaryTimeout())
+	defer cancel()
+
+	var result *uploader.UploadResult
+	err := cloudinaryBreaker.Do(func() (err error) {
+		result, err = s.cld.Upload.Upload(ctx, file, params)
+		return err
+	})
+	return result, err
+}
+
+// destroy deletes an asset from Cloudinary, bounded by CLOUDINARY_TIMEOUT and the circuit breaker
+func (s *ImageService) destroy(ctx context.Context, params uploader.DestroyParams) error {
+	ctx, cancel := context.WithTimeout(ctx, utils.CloudinaryTimeout())
+	defer cancel()
+
+	return cloudinaryBreaker.Do(func() error {
+		_, err := s.cld.Upload.Destroy(ctx, params)
+		return err
+	})
+}
+
 func NewImageService() (*ImageService, error) {
 	// Get Cloudinary configuration from environment
 	cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
@@ -36,7 +82,7 @@ func NewImageService() (*ImageService, error) {
 }
 
 // UploadAvatar uploads an avatar image to Cloudinary
-func (s *ImageService) UploadAvatar(file multipart.File, filename string, userID string) (string, error) {
+func (s *ImageService) UploadAvatar(ctx context.Context, file multipart.File, filename string, userID string) (string, error) {
 	// Validate file type
 	allowedTypes := map[string]bool{
 		".jpg":  true,
@@ -64,7 +110,7 @@ func (s *ImageService) UploadAvatar(file multipart.File, filename string, userID
 	}
 
 	// Upload to Cloudinary
-	result, err := s.cld.Upload.Upload(context.Background(), file, uploadParams)
+	result, err := s.upload(ctx, file, uploadParams)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload image: %w", err)
 	}
@@ -73,11 +119,67 @@ func (s *ImageService) UploadAvatar(file multipart.File, filename string, userID
 }
 
 // DeleteAvatar deletes an avatar from Cloudinary
-func (s *ImageService) DeleteAvatar(publicID string) error {
-	_, err := s.cld.Upload.Destroy(context.Background(), uploader.DestroyParams{
+func (s *ImageService) DeleteAvatar(ctx context.Context, publicID string) error {
+	return s.destroy(ctx, uploader.DestroyParams{
+		PublicID: publicID,
+	})
+}
+
+// UploadMessageAttachment stores a chat image as a private asset, so it can only be viewed through
+// signed URLs. The type is checked from the file's contents rather than trusting its name.
+func (s *ImageService) UploadMessageAttachment(ctx context.Context, file multipart.File, groupID string) (UploadedAttachment, error) {
+	head := make([]byte, 512)
+	n, err := file.Read(head)
+	if err != nil && err != io.EOF {
+		return UploadedAttachment{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !allowedAttachmentTypes[contentType] {
+		return UploadedAttachment{}, fmt.Errorf("invalid file type: %s. Allowed types: jpg, jpeg, png, gif, webp", contentType)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return UploadedAttachment{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	uploadParams := uploader.UploadParams{
+		Folder:         "groops/messages/" + groupID,
+		Type:           api.Private,
+		ResourceType:   "image",
+		AllowedFormats: api.CldAPIArray{"jpg", "png", "gif", "webp"},
+	}
+
+	result, err := s.upload(ctx, file, uploadParams)
+	if err != nil {
+		return UploadedAttachment{}, fmt.Errorf("failed to upload image: %w", err)
+	}
+	if result.Error.Message != "" {
+		return UploadedAttachment{}, fmt.Errorf("failed to upload image: %s", result.Error.Message)
+	}
+
+	return UploadedAttachment{
+		PublicID:    result.PublicID,
+		Format:      result.Format,
+		ContentType: contentType,
+	}, nil
+}
+
+// SignedAttachmentURL returns a download URL for a private chat image that stops working at expires
+func (s *ImageService) SignedAttachmentURL(publicID, format string, expires time.Time) (string, error) {
+	return s.cld.Upload.PrivateDownloadURL(uploader.PrivateDownloadURLParams{
+		PublicID:     publicID,
+		Format:       format,
+		DeliveryType: api.Private,
+		ExpiresAt:    &expires,
+		ResourceType: api.Image,
+	})
+}
+
+// DeleteMessageAttachment removes a private chat image from Cloudinary
+func (s *ImageService) DeleteMessageAttachment(ctx context.Context, publicID string) error {
+	return s.destroy(ctx, uploader.DestroyParams{
 		PublicID: publicID,
+		Type:     api.Private,
 	})
-	return err
 }
 
 // ValidateImageFile validates if the uploaded file is a valid image
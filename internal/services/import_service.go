@@ -0,0 +1,279 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported import file formats
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatICal = "ical"
+)
+
+// MaxImportRows caps how many events a single import may create
+const MaxImportRows = 50
+
+// defaultImportActivityType is used when an iCal event has no CATEGORIES
+const defaultImportActivityType = "social"
+
+// ImportedEvent is one event read from an import file, before it becomes a group
+type ImportedEvent struct {
+	Row          int       `json:"row"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	ActivityType string    `json:"activity_type"`
+	DateTime     time.Time `json:"date_time"`
+	Address      string    `json:"address"`
+	Cost         float64   `json:"cost"`
+	MaxMembers   int       `json:"max_members"`
+	SkillLevel   *string   `json:"skill_level,omitempty"`
+	Errors       []string  `json:"errors,omitempty"`
+}
+
+// AddError records a problem that stops the event from being imported
+func (e *ImportedEvent) AddError(format string, args ...interface{}) {
+	e.Errors = append(e.Errors, fmt.Sprintf(format, args...))
+}
+
+// csvColumnAliases maps the header names we accept (including Meetup export names) to fields
+var csvColumnAliases = map[string]string{
+	"name": "name", "title": "name", "event name": "name",
+	"description": "description", "details": "description",
+	"activity_type": "activity_type", "activity": "activity_type", "category": "activity_type",
+	"date_time": "date_time", "start": "date_time", "date": "date_time", "start time": "date_time",
+	"location": "address", "address": "address", "venue": "address",
+	"cost": "cost", "price": "cost", "fee": "cost",
+	"max_members": "max_members", "capacity": "max_members", "rsvp limit": "max_members",
+	"skill_level": "skill_level",
+}
+
+// importDateLayouts are the date formats accepted in CSV files, most specific first
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"02/01/2006 15:04",
+}
+
+// ParseImportFile reads events from a CSV or iCal file. Times without a zone are read in loc.
+func ParseImportFile(format string, r io.Reader, loc *time.Location) ([]ImportedEvent, error) {
+	switch format {
+	case ImportFormatCSV:
+		return parseCSVEvents(r, loc)
+	case ImportFormatICal:
+		return parseICalEvents(r, loc)
+	}
+	return nil, fmt.Errorf("unsupported import format %q", format)
+}
+
+// parseCSVEvents reads a CSV whose first row names the columns
+func parseCSVEvents(r io.Reader, loc *time.Location) ([]ImportedEvent, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		if field, ok := csvColumnAliases[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	for _, required := range []string{"name", "date_time", "address"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing a %s column", required)
+		}
+	}
+
+	var events []ImportedEvent
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV row %d: %w", row, err)
+		}
+		if len(events) >= MaxImportRows {
+			return nil, fmt.Errorf("files can contain at most %d events", MaxImportRows)
+		}
+
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		event := ImportedEvent{
+			Row:          row,
+			Name:         value("name"),
+			Description:  value("description"),
+			ActivityType: value("activity_type"),
+			Address:      value("address"),
+			MaxMembers:   10,
+		}
+
+		if raw := value("date_time"); raw != "" {
+			if parsed, ok := parseImportTime(raw, loc); ok {
+				event.DateTime = parsed
+			} else {
+				event.AddError("unrecognized date %q (use YYYY-MM-DD HH:MM)", raw)
+			}
+		}
+		if raw := strings.TrimLeft(value("cost"), "$₹€£"); raw != "" {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				event.Cost = parsed
+			} else {
+				event.AddError("invalid cost %q", raw)
+			}
+		}
+		if raw := value("max_members"); raw != "" {
+			if parsed, err := strconv.Atoi(raw); err == nil {
+				event.MaxMembers = parsed
+			} else {
+				event.AddError("invalid max_members %q", raw)
+			}
+		}
+		if raw := strings.ToLower(value("skill_level")); raw != "" {
+			event.SkillLevel = &raw
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// parseImportTime tries each accepted layout, reading zoneless times in loc
+func parseImportTime(raw string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range importDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseICalEvents reads the VEVENTs of an iCalendar (.ics) file
+func parseICalEvents(r io.Reader, loc *time.Location) ([]ImportedEvent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ImportedEvent
+	var current *ImportedEvent
+	for i, line := range lines {
+		name, params, value := splitICalProperty(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			if len(events) >= MaxImportRows {
+				return nil, fmt.Errorf("files can contain at most %d events", MaxImportRows)
+			}
+			current = &ImportedEvent{Row: len(events) + 1, MaxMembers: 10}
+		case current == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if current.ActivityType == "" {
+				current.ActivityType = defaultImportActivityType
+			}
+			if current.Description == "" {
+				current.Description = current.Name
+			}
+			events = append(events, *current)
+			current = nil
+		case name == "SUMMARY":
+			current.Name = unescapeICalText(value)
+		case name == "DESCRIPTION":
+			current.Description = unescapeICalText(value)
+		case name == "LOCATION":
+			current.Address = unescapeICalText(value)
+		case name == "CATEGORIES":
+			current.ActivityType = strings.ToLower(strings.TrimSpace(strings.Split(unescapeICalText(value), ",")[0]))
+		case name == "DTSTART":
+			parsed, err := parseICalTime(value, params, loc)
+			if err != nil {
+				current.AddError("line %d: %v", i+1, err)
+			} else {
+				current.DateTime = parsed
+			}
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no events found in calendar file")
+	}
+	return events, nil
+}
+
+// unfoldICalLines joins continuation lines (those starting with a space or tab) per RFC 5545
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar file: %w", err)
+	}
+	return lines, nil
+}
+
+// splitICalProperty splits "NAME;PARAM=X:VALUE" into its name, parameters and value
+func splitICalProperty(line string) (string, map[string]string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		if kv := strings.SplitN(param, "=", 2); len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+// parseICalTime parses a DTSTART value in UTC ("...Z"), a TZID zone, or loc
+func parseICalTime(value string, params map[string]string, loc *time.Location) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		return time.Time{}, fmt.Errorf("all-day events need a start time")
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	if tzid := params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+// unescapeICalText reverses the TEXT escaping applied by escapeICSText
+func unescapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(value))
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginLogRetentionWorker minimizes stored login data: it anonymizes IP addresses and
+// user agents on older LoginLog rows and deletes rows past the retention period
+type LoginLogRetentionWorker struct {
+	db             *gorm.DB
+	anonymizeAfter time.Duration
+	deleteAfter    time.Duration
+	interval       time.Duration
+}
+
+func NewLoginLogRetentionWorker() *LoginLogRetentionWorker {
+	return &LoginLogRetentionWorker{
+		db:             database.GetDB(),
+		anonymizeAfter: utils.GetEnvDuration("LOGIN_LOG_ANONYMIZE_AFTER", 30*24*time.Hour),
+		deleteAfter:    utils.GetEnvDuration("LOGIN_LOG_DELETE_AFTER", 365*24*time.Hour),
+		interval:       time.Hour * 24, // Run once a day
+	}
+}
+
+func (w *LoginLogRetentionWorker) Start() {
+	go w.run()
+}
+
+func (w *LoginLogRetentionWorker) run() {
+	// Apply the policy at startup so a restart doesn't delay it by a day
+	w.applyRetentionPolicy()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.applyRetentionPolicy()
+	}
+}
+
+func (w *LoginLogRetentionWorker) applyRetentionPolicy() {
+	now := time.Now()
+
+	// Delete very old rows outright
+	result := w.db.Where("login_time < ?", now.Add(-w.deleteAfter)).Delete(&models.LoginLog{})
+	if result.Error != nil {
+		log.Printf("Failed to delete old login logs: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Deleted %d login logs older than %v", result.RowsAffected, w.deleteAfter)
+	}
+
+	// Truncate IPs to their network prefix (IPv4 /24, IPv6 /48) and drop user agents.
+	// Already-anonymized rows are skipped by checking for a non-empty user agent.
+	result = w.db.Exec(`
+		UPDATE login_log
+		SET ip_address = CASE
+				WHEN ip_address = '' THEN ''
+				WHEN family(ip_address::inet) = 4 THEN host(network(set_masklen(ip_address::inet, 24)))
+				ELSE host(network(set_masklen(ip_address::inet, 48)))
+			END,
+			user_agent = ''
+		WHERE login_time < ?
+		  AND user_agent <> ''
+		  AND (ip_address = '' OR ip_address ~ '^[0-9a-fA-F:.]+$')
+	`, now.Add(-w.anonymizeAfter))
+	if result.Error != nil {
+		log.Printf("Failed to anonymize login logs: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Anonymized %d login logs older than %v", result.RowsAffected, w.anonymizeAfter)
+	}
+
+	// Rows with unparseable IPs can't be truncated safely, so clear them entirely
+	if err := w.db.Model(&models.LoginLog{}).
+		Where("login_time < ? AND user_agent <> ''", now.Add(-w.anonymizeAfter)).
+		Updates(map[string]interface{}{"ip_address": "", "user_agent": ""}).Error; err != nil {
+		log.Printf("Failed to clear unparseable login log IPs: %v", err)
+	}
+}
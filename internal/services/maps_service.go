@@ -3,7 +3,13 @@ package services
 import (
 	"context"
 	"errors"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"math"
+	"net"
 	"os"
+	"strings"
 	"time"
 
 	"googlemaps.github.io/maps"
@@ -12,8 +18,22 @@ import (
 var (
 	mapsClient  *maps.Client
 	ErrNoAPIKey = errors.New("GOOGLE_MAPS_API_KEY environment variable not set")
+
+	// Trips when Google Maps keeps timing out or erroring, so requests skip the lookup instead of waiting on it
+	mapsBreaker = utils.NewCircuitBreaker("google_maps")
 )
 
+// isMapsOutage reports whether a Maps error means the API itself is unhealthy, rather than
+// e.g. an unknown place
+func isMapsOutage(err error) bool {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return true
+	}
+	message := err.Error()
+	return strings.Contains(message, "UNKNOWN_ERROR") || strings.Contains(message, "OVER_QUERY_LIMIT")
+}
+
 // InitMapsClient initializes the Google Maps client
 func InitMapsClient() error {
 	apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
@@ -31,14 +51,14 @@ func InitMapsClient() error {
 }
 
 // ValidateLocation validates and standardizes location data using the Place ID
-func ValidateLocation(placeID string) (*maps.PlaceDetailsResult, error) {
+func ValidateLocation(ctx context.Context, placeID string) (*maps.PlaceDetailsResult, error) {
 	if mapsClient == nil {
 		if err := InitMapsClient(); err != nil {
 			return nil, err
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, utils.MapsTimeout())
 	defer cancel()
 
 	request := &maps.PlaceDetailsRequest{
@@ -48,13 +68,137 @@ func ValidateLocation(placeID string) (*maps.PlaceDetailsResult, error) {
 			maps.PlaceDetailsFieldMaskFormattedAddress,
 			maps.PlaceDetailsFieldMaskName,
 			maps.PlaceDetailsFieldMaskPlaceID,
+			maps.PlaceDetailsFieldMaskAddressComponent,
 		},
 	}
 
-	response, err := mapsClient.PlaceDetails(ctx, request)
+	var response maps.PlaceDetailsResult
+	err := mapsBreaker.DoCounting(func() (err error) {
+		response, err = mapsClient.PlaceDetails(ctx, request)
+		return err
+	}, isMapsOutage)
 	if err != nil {
 		return nil, err
 	}
 
 	return &response, nil
 }
+
+// CityFromAddressComponents picks the city (locality) out of a place's address components,
+// falling back to the postal town or district used in some countries
+func CityFromAddressComponents(components []maps.AddressComponent) string {
+	for _, componentType := range []string{"locality", "postal_town", "administrative_area_level_2"} {
+		for _, component := range components {
+			for _, t := range component.Types {
+				if t == componentType {
+					return component.LongName
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// ResolveCity derives the city for a location from its Place ID, falling back to the
+// city supplied with the location if the lookup fails
+func ResolveCity(ctx context.Context, location models.Location) string {
+	if location.PlaceID != "" {
+		details, err := ValidateLocation(ctx, location.PlaceID)
+		if err != nil {
+			log.Printf("Warning: Failed to look up city for place %s: %v", location.PlaceID, err)
+		} else if city := CityFromAddressComponents(details.AddressComponents); city != "" {
+			return city
+		}
+	}
+	return strings.TrimSpace(location.City)
+}
+
+// StandardizeLocation replaces a client-supplied location with Google Maps' details for its Place ID,
+// so the stored name, address, coordinates and city can't be made up
+func StandardizeLocation(ctx context.Context, location models.Location) (models.Location, error) {
+	details, err := ValidateLocation(ctx, location.PlaceID)
+	if err != nil {
+		return location, err
+	}
+	return models.Location{
+		PlaceID:          details.PlaceID,
+		Name:             details.Name,
+		FormattedAddress: details.FormattedAddress,
+		Latitude:         details.Geometry.Location.Lat,
+		Longitude:        details.Geometry.Location.Lng,
+		City:             CityFromAddressComponents(details.AddressComponents),
+	}, nil
+}
+
+// HaversineDistanceKm returns the great-circle distance between two coordinates in kilometers
+func HaversineDistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKm = 6371.0
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// GeocodeAddress resolves free-form address text (e.g. from an imported event) to a location
+func GeocodeAddress(ctx context.Context, address string) (models.Location, error) {
+	if mapsClient == nil {
+		if err := InitMapsClient(); err != nil {
+			return models.Location{}, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, utils.MapsTimeout())
+	defer cancel()
+
+	var results []maps.GeocodingResult
+	err := mapsBreaker.DoCounting(func() (err error) {
+		results, err = mapsClient.Geocode(ctx, &maps.GeocodingRequest{Address: address})
+		return err
+	}, isMapsOutage)
+	if err != nil {
+		return models.Location{}, err
+	}
+	if len(results) == 0 {
+		return models.Location{}, errors.New("address not found")
+	}
+
+	result := results[0]
+	return models.Location{
+		PlaceID:          result.PlaceID,
+		Name:             strings.TrimSpace(strings.Split(address, ",")[0]),
+		FormattedAddress: result.FormattedAddress,
+		Latitude:         result.Geometry.Location.Lat,
+		Longitude:        result.Geometry.Location.Lng,
+		City:             CityFromAddressComponents(result.AddressComponents),
+	}, nil
+}
+
+// LookupTimezone returns the IANA time zone at a coordinate using the Time Zone API
+func LookupTimezone(ctx context.Context, lat, lng float64) (*time.Location, error) {
+	if mapsClient == nil {
+		if err := InitMapsClient(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, utils.MapsTimeout())
+	defer cancel()
+
+	var result *maps.TimezoneResult
+	err := mapsBreaker.DoCounting(func() (err error) {
+		result, err = mapsClient.Timezone(ctx, &maps.TimezoneRequest{
+			Location:  &maps.LatLng{Lat: lat, Lng: lng},
+			Timestamp: time.Now(),
+		})
+		return err
+	}, isMapsOutage)
+	if err != nil {
+		return nil, err
+	}
+	return time.LoadLocation(result.TimeZoneID)
+}
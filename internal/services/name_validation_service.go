@@ -0,0 +1,204 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Error codes returned when a username or group name fails content checks
+const (
+	ErrCodeNameOffensive     = "NAME_OFFENSIVE"
+	ErrCodeNameImpersonation = "NAME_IMPERSONATION"
+	ErrCodeNameMisleading    = "NAME_MISLEADING"
+)
+
+// defaultBlockedTerms are always rejected as words; deployments extend the list through the
+// moderation.name_blocklist setting or NAME_BLOCKLIST (comma-separated)
+var defaultBlockedTerms = []string{
+	"shit", "cunt", "bitch", "bastard", "wanker", "porn", "nazi",
+}
+
+// blockedSubstrings are rejected anywhere in a word, e.g. "fuckface". Only terms that never occur
+// inside ordinary words belong here; "cunt" in "Scunthorpe" is why the rest are matched as words.
+var blockedSubstrings = []string{"fuck", "asshole", "dickhead", "shithead"}
+
+// blockedSuffixes are the endings a blocked term still counts as a word with ("bitches", "shitty")
+var blockedSuffixes = []string{"s", "es", "y", "ed", "er", "ers", "ing"}
+
+// protectedBrand is the product name nobody but the team may pose as
+const protectedBrand = "groops"
+
+// staffTerms suggest a name speaks for the platform
+var staffTerms = []string{"admin", "administrator", "moderator", "staff", "support", "official", "team", "verified"}
+
+// confusables folds look-alike characters (leetspeak, Cyrillic and Greek homoglyphs) onto ASCII
+// letters; "l", "1" and "i" are indistinguishable in many fonts so they all fold to "i"
+var confusables = map[rune]rune{
+	'l': 'i', '0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '9': 'g',
+	'@': 'a', '$': 's', '!': 'i', '|': 'i',
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p',
+	'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ј': 'j', 'ӏ': 'i', 'ԁ': 'd', 'ɡ': 'g',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'ν': 'v',
+}
+
+// NameValidationService rejects offensive, impersonating, or misleading usernames and group names
+type NameValidationService struct {
+	blockedTerms      []string // Skeletons matched as words
+	blockedSubstrings []string // Skeletons matched anywhere in a word
+}
+
+func NewNameValidationService() *NameValidationService {
+	terms := append([]string{}, defaultBlockedTerms...)
+	extra := NewSettingsService().GetString("moderation.name_blocklist", "NAME_BLOCKLIST", "")
+	for _, term := range strings.Split(extra, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	return &NameValidationService{blockedTerms: nameSkeletons(terms), blockedSubstrings: nameSkeletons(blockedSubstrings)}
+}
+
+// nameSkeletons returns the non-empty skeletons of the terms
+func nameSkeletons(terms []string) []string {
+	skeletons := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if skeleton := nameSkeleton(term); skeleton != "" {
+			skeletons = append(skeletons, skeleton)
+		}
+	}
+	return skeletons
+}
+
+// ValidateUsername returns an error code and message when a username isn't allowed
+func (s *NameValidationService) ValidateUsername(username string) (string, string) {
+	skeleton := nameSkeleton(username)
+	if s.containsBlockedTerm(username) {
+		return ErrCodeNameOffensive, "This username contains language that isn't allowed"
+	}
+	if strings.Contains(skeleton, nameSkeleton(protectedBrand)) {
+		return ErrCodeNameImpersonation, "Usernames can't reference Groops"
+	}
+	for _, term := range staffTerms {
+		// Exact look-alikes only, so ordinary names like "staffordshire" stay available
+		if skeleton == nameSkeleton(term) {
+			return ErrCodeNameImpersonation, "Usernames can't imitate Groops staff"
+		}
+	}
+	return "", ""
+}
+
+// ValidateGroupName returns an error code and message when a group name isn't allowed
+func (s *NameValidationService) ValidateGroupName(name string) (string, string) {
+	skeleton := nameSkeleton(name)
+	if s.containsBlockedTerm(name) {
+		return ErrCodeNameOffensive, "This group name contains language that isn't allowed"
+	}
+	if strings.Contains(skeleton, nameSkeleton(protectedBrand)) {
+		for _, term := range staffTerms {
+			if strings.Contains(skeleton, nameSkeleton(term)) {
+				return ErrCodeNameImpersonation, "Group names can't claim to be run by Groops"
+			}
+		}
+	}
+	if isMisleadingGroupName(name) {
+		return ErrCodeNameMisleading, "Group names can't contain links or be mostly symbols"
+	}
+	return "", ""
+}
+
+// containsBlockedTerm reports whether the name uses a blocklisted term as a word ("Shit happens",
+// "bitch_99", "sh1tty"), or a blocked substring anywhere in a word. Matching words rather than
+// substrings keeps names like "Scunthorpe", "Shiitake" and "Sussex" allowed. Words are checked
+// separately so innocent phrases like "bass hit" don't match across the gap.
+func (s *NameValidationService) containsBlockedTerm(name string) bool {
+	for _, word := range strings.Fields(name) {
+		skeleton := nameSkeleton(word)
+		for _, term := range s.blockedSubstrings {
+			if strings.Contains(skeleton, term) {
+				return true
+			}
+		}
+		for _, token := range nameTokens(word) {
+			if s.isBlockedToken(token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameTokens returns the skeletons a word is matched as: the whole word ("s.h.i.t"), and each part
+// of it joined by underscores, hyphens or dots with any trailing number dropped ("bitch_99")
+func nameTokens(word string) []string {
+	tokens := []string{nameSkeleton(word)}
+	parts := strings.FieldsFunc(word, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+	for _, part := range parts {
+		tokens = append(tokens, nameSkeleton(part))
+		if trimmed := strings.TrimRightFunc(part, unicode.IsDigit); trimmed != part {
+			tokens = append(tokens, nameSkeleton(trimmed))
+		}
+	}
+	return tokens
+}
+
+// isBlockedToken reports whether a skeleton is a blocked term, or one with a blocked suffix
+func (s *NameValidationService) isBlockedToken(token string) bool {
+	for _, term := range s.blockedTerms {
+		rest, ok := strings.CutPrefix(token, term)
+		if !ok {
+			continue
+		}
+		if rest == "" {
+			return true
+		}
+		for _, suffix := range blockedSuffixes {
+			if rest == suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isMisleadingGroupName flags names that embed links or carry almost no readable text
+func isMisleadingGroupName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, marker := range []string{"http://", "https://", "www.", ".com", ".net", ".org"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	letters, others := 0, 0
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			letters++
+		case !unicode.IsSpace(r):
+			others++
+		}
+	}
+	return letters == 0 || others > letters
+}
+
+// nameSkeleton lowercases, folds homoglyphs, drops separators, and collapses repeated
+// letters so "Gr00ps", "g.r.o.o.p.s" and "grooooops" all compare equal to "groops"
+func nameSkeleton(value string) string {
+	var b strings.Builder
+	var last rune
+	for _, r := range strings.ToLower(value) {
+		if folded, ok := confusables[r]; ok {
+			r = folded
+		}
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			continue
+		}
+		if r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
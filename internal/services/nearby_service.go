@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nearby date windows
+const (
+	NearbyWindowToday   = "today"
+	NearbyWindowWeekend = "weekend"
+)
+
+const (
+	// nearbyCellDegrees is the size of the grid cells nearby results are cached under (~11km)
+	nearbyCellDegrees = 0.1
+	// NearbyMaxRadiusKm is the largest radius the nearby endpoints search
+	NearbyMaxRadiusKm = 50.0
+	// weekendStartHour is when Friday evening starts counting as the weekend
+	weekendStartHour = 17
+)
+
+// NearbyGroup is an upcoming group with its distance from the user
+type NearbyGroup struct {
+	models.Group
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// nearbyCacheEntry holds the groups in and around one cell for one date window
+type nearbyCacheEntry struct {
+	groups    []models.Group
+	start     time.Time
+	end       time.Time
+	expiresAt time.Time
+}
+
+// NearbyService answers "happening today/this weekend" queries. Windows are computed in the
+// local time zone of the user's grid cell, which nearby venues share, and each cell's candidate
+// groups are cached for NEARBY_CACHE_TTL so busy areas don't rerun the geo query on every request.
+type NearbyService struct {
+	db       *gorm.DB
+	cacheTTL time.Duration
+
+	mu        sync.Mutex
+	cache     map[string]nearbyCacheEntry
+	timezones map[string]*time.Location
+}
+
+var (
+	nearbyService     *NearbyService
+	nearbyServiceOnce sync.Once
+)
+
+// GetNearbyService returns the process-wide nearby service; the cache only helps if it is shared
+func GetNearbyService() *NearbyService {
+	nearbyServiceOnce.Do(func() {
+		nearbyService = &NearbyService{
+			db:        database.GetDB(),
+			cacheTTL:  utils.GetEnvDuration("NEARBY_CACHE_TTL", 5*time.Minute),
+			cache:     make(map[string]nearbyCacheEntry),
+			timezones: make(map[string]*time.Location),
+		}
+	})
+	return nearbyService
+}
+
+// FindGroups returns upcoming groups within radiusKm of the coordinate that start in the window,
+// soonest first, along with the window's bounds
+func (s *NearbyService) FindGroups(ctx context.Context, window string, lat, lng, radiusKm float64) ([]NearbyGroup, time.Time, time.Time, error) {
+	cellLat, cellLng := nearbyCell(lat), nearbyCell(lng)
+	cell := fmt.Sprintf("%.2f,%.2f", cellLat, cellLng)
+
+	now := time.Now()
+	entry, err := s.cellGroups(ctx, cell, window, cellLat, cellLng, now)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	groups := []NearbyGroup{}
+	for _, group := range entry.groups {
+		if !group.DateTime.After(now) {
+			continue
+		}
+		distance := HaversineDistanceKm(lat, lng, group.Location.Latitude, group.Location.Longitude)
+		if distance > radiusKm {
+			continue
+		}
+		display := ActivityDisplayFor(group.ActivityType)
+		group.ActivityDisplay = &display
+		groups = append(groups, NearbyGroup{Group: group, DistanceKm: math.Round(distance*100) / 100})
+	}
+	// Organizer profiles aren't cached with the cell so rating and avatar changes show up right away
+	usernames := make([]string, len(groups))
+	for i, group := range groups {
+		usernames[i] = group.OrganiserID
+	}
+	organizers := LoadOrganizerSummaries(s.db, usernames)
+	for i := range groups {
+		groups[i].Organizer = organizers[groups[i].OrganiserID]
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if !groups[i].DateTime.Equal(groups[j].DateTime) {
+			return groups[i].DateTime.Before(groups[j].DateTime)
+		}
+		return groups[i].DistanceKm < groups[j].DistanceKm
+	})
+
+	start := entry.start
+	if start.Before(now) {
+		start = now
+	}
+	return groups, start, entry.end, nil
+}
+
+// cellGroups returns the cached candidates for a cell and window, querying them if missing or stale
+func (s *NearbyService) cellGroups(ctx context.Context, cell, window string, cellLat, cellLng float64, now time.Time) (nearbyCacheEntry, error) {
+	key := window + "|" + cell
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) && now.Before(entry.end) {
+		return entry, nil
+	}
+
+	start, end := NearbyWindow(window, now.In(s.cellTimezone(ctx, cell, cellLat, cellLng)))
+
+	// Cover every point in the cell: the radius plus the distance from the center to a corner
+	reach := NearbyMaxRadiusKm + HaversineDistanceKm(cellLat, cellLng, cellLat+nearbyCellDegrees/2, cellLng+nearbyCellDegrees/2)
+	latDelta := reach / 111.0
+	lngDelta := reach / (111.0 * math.Max(math.Cos(cellLat*math.Pi/180), 0.01))
+
+	var groups []models.Group
+	if err := s.db.WithContext(ctx).Preload("Members").
+		Where("date_time > ? AND date_time >= ? AND date_time < ?", now, start, end).
+		Where("status = ? AND visibility = ?", models.GroupStatusActive, models.VisibilityPublic).
+		Where("public_at IS NULL OR public_at <= ?", now). // Results are shared, so early-access groups are left out
+		Where("CAST(location->>'latitude' AS FLOAT) BETWEEN ? AND ?", cellLat-latDelta, cellLat+latDelta).
+		Where("CAST(location->>'longitude' AS FLOAT) BETWEEN ? AND ?", cellLng-lngDelta, cellLng+lngDelta).
+		Order("date_time ASC").
+		Limit(500).
+		Find(&groups).Error; err != nil {
+		return nearbyCacheEntry{}, err
+	}
+
+	entry = nearbyCacheEntry{groups: groups, start: start, end: end, expiresAt: now.Add(s.cacheTTL)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for cached, old := range s.cache {
+		if !now.Before(old.expiresAt) {
+			delete(s.cache, cached)
+		}
+	}
+	s.cache[key] = entry
+	return entry, nil
+}
+
+// cellTimezone looks up (once per cell) the time zone windows are computed in,
+// falling back to NEARBY_DEFAULT_TIMEZONE when the lookup fails
+func (s *NearbyService) cellTimezone(ctx context.Context, cell string, lat, lng float64) *time.Location {
+	s.mu.Lock()
+	loc, ok := s.timezones[cell]
+	s.mu.Unlock()
+	if ok {
+		return loc
+	}
+
+	loc, err := LookupTimezone(ctx, lat, lng)
+	if err != nil {
+		log.Printf("Warning: Failed to look up time zone for cell %s: %v", cell, err)
+		name := os.Getenv("NEARBY_DEFAULT_TIMEZONE")
+		if name == "" {
+			name = "Asia/Kolkata" // Matches the IST times in our emails
+		}
+		fallback, loadErr := time.LoadLocation(name)
+		if loadErr != nil {
+			fallback = time.UTC
+		}
+		// Not cached, so the lookup is retried when the cell's results next expire
+		return fallback
+	}
+
+	s.mu.Lock()
+	s.timezones[cell] = loc
+	s.mu.Unlock()
+	return loc
+}
+
+// NearbyWindow returns the bounds of a date window relative to now, in now's location.
+// "today" runs to local midnight. "weekend" runs from Friday 5pm to Monday midnight, or the
+// upcoming weekend if it's Monday to Friday afternoon.
+func NearbyWindow(window string, now time.Time) (time.Time, time.Time) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if window == NearbyWindowToday {
+		return now, midnight.AddDate(0, 0, 1)
+	}
+
+	daysUntilFriday := (int(time.Friday) - int(now.Weekday()) + 7) % 7
+	if now.Weekday() == time.Saturday || now.Weekday() == time.Sunday {
+		daysUntilFriday -= 7
+	}
+	friday := midnight.AddDate(0, 0, daysUntilFriday)
+	return friday.Add(weekendStartHour * time.Hour), friday.AddDate(0, 0, 3)
+}
+
+// nearbyCell snaps a coordinate to the center of its grid cell
+func nearbyCell(coordinate float64) float64 {
+	return (math.Floor(coordinate/nearbyCellDegrees) + 0.5) * nearbyCellDegrees
+}
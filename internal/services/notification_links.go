@@ -0,0 +1,143 @@
+package services
+
+import (
+	"groops/internal/models"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Notification link target types
+const (
+	NotificationTargetGroup         = "group"
+	NotificationTargetMessage       = "message"
+	NotificationTargetPoll          = "poll"
+	NotificationTargetChecklistItem = "checklist_item"
+	NotificationTargetExport        = "export"
+	NotificationTargetAnnouncement  = "announcement"
+	NotificationTargetFeedback      = "feedback"
+	NotificationTargetInvitation    = "invitation"
+)
+
+// notificationLinkRule describes where a notification type leads. Route may use {group} and {target};
+// GroupRoute is used instead when the specific target isn't known (e.g. for backfilled rows).
+type notificationLinkRule struct {
+	Action     string
+	TargetType string
+	Route      string
+	GroupRoute string
+}
+
+// notificationLinkRules maps notification types to their deep links.
+// Types missing here (e.g. join_cooldown) have nothing to open.
+var notificationLinkRules = map[string]notificationLinkRule{
+	"group_created":        {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"location_changed":     {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"event_updated":        {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"join_approved":        {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"join_rejected":        {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"removed_from_group":   {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"marked_no_show":       {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"capacity_available":   {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"waitlist_offer":       {Action: "claim_spot", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"occurrence_scheduled": {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"early_access":         {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"role_changed":         {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"group_cancelled":      {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+	"group_restored":       {Action: "view_group", TargetType: NotificationTargetGroup, Route: "/groups/{group}"},
+
+	"group_invite": {Action: "respond_invite", TargetType: NotificationTargetInvitation, Route: "/invites/{target}", GroupRoute: "/groups/{group}"},
+
+	"join_request":        {Action: "review_requests", TargetType: NotificationTargetGroup, Route: "/groups/{group}/requests"},
+	"member_joined":       {Action: "view_members", TargetType: NotificationTargetGroup, Route: "/groups/{group}/members"},
+	"leave_group":         {Action: "view_members", TargetType: NotificationTargetGroup, Route: "/groups/{group}/members"},
+	"join_withdrawn":      {Action: "view_members", TargetType: NotificationTargetGroup, Route: "/groups/{group}/members"},
+	"guest_count_changed": {Action: "view_members", TargetType: NotificationTargetGroup, Route: "/groups/{group}/members"},
+	"invite_accepted":     {Action: "view_members", TargetType: NotificationTargetGroup, Route: "/groups/{group}/members"},
+	"invite_declined":     {Action: "view_members", TargetType: NotificationTargetGroup, Route: "/groups/{group}/members"},
+
+	"unread_messages":   {Action: "open_chat", TargetType: NotificationTargetGroup, Route: "/groups/{group}/chat"},
+	"group_broadcast":   {Action: "open_chat", TargetType: NotificationTargetGroup, Route: "/groups/{group}/chat"},
+	"message_reply":     {Action: "open_thread", TargetType: NotificationTargetMessage, Route: "/groups/{group}/chat/threads/{target}", GroupRoute: "/groups/{group}/chat"},
+	"message_removed":   {Action: "open_chat", TargetType: NotificationTargetGroup, Route: "/groups/{group}/chat"},
+	"chat_muted":        {Action: "open_chat", TargetType: NotificationTargetGroup, Route: "/groups/{group}/chat"},
+	"chat_lock_changed": {Action: "open_chat", TargetType: NotificationTargetGroup, Route: "/groups/{group}/chat"},
+	"mention":           {Action: "open_message", TargetType: NotificationTargetMessage, Route: "/groups/{group}/chat/messages/{target}", GroupRoute: "/groups/{group}/chat"},
+
+	"poll_opened": {Action: "view_poll", TargetType: NotificationTargetPoll, Route: "/groups/{group}/polls/{target}", GroupRoute: "/groups/{group}/polls"},
+	"poll_closed": {Action: "view_poll", TargetType: NotificationTargetPoll, Route: "/groups/{group}/polls/{target}", GroupRoute: "/groups/{group}/polls"},
+
+	"checklist_item_removed": {Action: "view_checklist", TargetType: NotificationTargetChecklistItem, Route: "/groups/{group}/checklist", GroupRoute: "/groups/{group}/checklist"},
+	"checklist_unclaimed":    {Action: "view_checklist", TargetType: NotificationTargetChecklistItem, Route: "/groups/{group}/checklist", GroupRoute: "/groups/{group}/checklist"},
+
+	"announcement": {Action: "view_announcement", TargetType: NotificationTargetAnnouncement, Route: "/groups/{group}/announcements/{target}", GroupRoute: "/groups/{group}/announcements"},
+
+	"event_feedback": {Action: "view_feedback", TargetType: NotificationTargetFeedback, Route: "/groups/{group}/feedback", GroupRoute: "/groups/{group}/feedback"},
+
+	"export_ready": {Action: "download_export", TargetType: NotificationTargetExport, Route: "/groups/{group}/exports/{target}", GroupRoute: "/groups/{group}"},
+
+	"groups_imported":  {Action: "view_my_groups", Route: "/me/groups"},
+	"streak_milestone": {Action: "view_profile", Route: "/profile"},
+	"account_merged":   {Action: "view_profile", Route: "/profile"},
+}
+
+// BuildNotificationLink returns the deep link for a notification. targetID identifies the specific
+// object (message, poll, ...) and may be empty for group-level notifications.
+func BuildNotificationLink(notifType, groupID, targetID string) models.NotificationLink {
+	rule, ok := notificationLinkRules[notifType]
+	if !ok {
+		return models.NotificationLink{}
+	}
+
+	targetType, route := rule.TargetType, rule.Route
+	if targetType == NotificationTargetGroup {
+		targetID = groupID
+	} else if targetID == "" && rule.GroupRoute != "" {
+		// Fall back to the group when we don't know which object to open
+		targetType, targetID, route = NotificationTargetGroup, groupID, rule.GroupRoute
+	}
+	if strings.Contains(route, "{group}") && groupID == "" {
+		return models.NotificationLink{}
+	}
+
+	return models.NotificationLink{
+		Action:     rule.Action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Route:      strings.NewReplacer("{group}", groupID, "{target}", targetID).Replace(route),
+	}
+}
+
+// BackfillNotificationLinks fills in links for notifications created before links existed.
+// Specific targets weren't recorded for those rows, so they link to the group-level page.
+func BackfillNotificationLinks(db *gorm.DB) {
+	for notifType, rule := range notificationLinkRules {
+		route, targetType := rule.Route, rule.TargetType
+		if targetType != NotificationTargetGroup && rule.GroupRoute != "" {
+			route, targetType = rule.GroupRoute, NotificationTargetGroup
+		}
+
+		updates := map[string]interface{}{
+			"link_action":      rule.Action,
+			"link_target_type": targetType,
+			"link_route":       gorm.Expr("REPLACE(?, '{group}', COALESCE(group_id, ''))", route),
+		}
+		if targetType == NotificationTargetGroup {
+			updates["link_target_id"] = gorm.Expr("COALESCE(group_id, '')")
+		}
+
+		query := db.Model(&models.Notification{}).Where("type = ? AND link_action = ''", notifType)
+		if strings.Contains(route, "{group}") {
+			query = query.Where("group_id IS NOT NULL AND group_id <> ''")
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			log.Printf("Warning: Failed to backfill %s notification links: %v", notifType, result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Backfilled links for %d %s notifications", result.RowsAffected, notifType)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"groops/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// createNotification stores an in-app notification for background services and workers
+func createNotification(db *gorm.DB, recipient, notifType, message, groupID string) error {
+	return createTargetedNotification(db, recipient, notifType, message, groupID, "")
+}
+
+// createTargetedNotification is createNotification for notifications that open a specific object
+// (poll, message, export...) rather than the group as a whole
+func createTargetedNotification(db *gorm.DB, recipient, notifType, message, groupID, targetID string) error {
+	notif := models.Notification{
+		RecipientUsername: recipient,
+		Type:              notifType,
+		Message:           message,
+		GroupID:           groupID,
+		CreatedAt:         time.Now(),
+		Read:              false,
+
+		Link: BuildNotificationLink(notifType, groupID, targetID),
+	}
+	return db.Create(&notif).Error
+}
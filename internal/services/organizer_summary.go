@@ -0,0 +1,50 @@
+package services
+
+import (
+	"groops/internal/models"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// LoadOrganizerSummaries fetches the public profiles of the given organizers in one query, keyed by username
+func LoadOrganizerSummaries(db *gorm.DB, usernames []string) map[string]*models.OrganizerSummary {
+	summaries := make(map[string]*models.OrganizerSummary)
+	if len(usernames) == 0 {
+		return summaries
+	}
+
+	var rows []models.OrganizerSummary
+	if err := db.Model(&models.Account{}).
+		Select("username, CASE WHEN show_full_name THEN full_name ELSE '' END AS full_name, avatar_url, rating").
+		Where("username IN ?", usernames).
+		Scan(&rows).Error; err != nil {
+		log.Printf("Warning: Failed to load organizer profiles: %v", err)
+		return summaries
+	}
+	for i := range rows {
+		summaries[rows[i].Username] = &rows[i]
+	}
+	return summaries
+}
+
+// organizerUsernames returns the distinct organizers of the given groups
+func organizerUsernames(groups []models.Group) []string {
+	seen := make(map[string]bool, len(groups))
+	usernames := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if !seen[group.OrganiserID] {
+			seen[group.OrganiserID] = true
+			usernames = append(usernames, group.OrganiserID)
+		}
+	}
+	return usernames
+}
+
+// AttachOrganizers fills in Organizer on each group with one query
+func AttachOrganizers(db *gorm.DB, groups []models.Group) {
+	summaries := LoadOrganizerSummaries(db, organizerUsernames(groups))
+	for i := range groups {
+		groups[i].Organizer = summaries[groups[i].OrganiserID]
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Outbound webhook event names
+const (
+	WebhookEventGroupCreated       = "group.created"
+	WebhookEventJoinRequestCreated = "join_request.created"
+	WebhookEventMemberApproved     = "member.approved"
+)
+
+// WebhookEventInfo documents an event in the catalog clients can subscribe to
+type WebhookEventInfo struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Sample      map[string]interface{} `json:"sample"`
+}
+
+// WebhookEventCatalog lists every event a subscription can receive, with the shape of its data.
+// Events are scoped to the subscribing user: they fire for groups that user organizes.
+var WebhookEventCatalog = []WebhookEventInfo{
+	{
+		Name:        WebhookEventGroupCreated,
+		Description: "You created a new group",
+		Sample: map[string]interface{}{
+			"group_id": "alice-20250101090000", "name": "Sunday Morning Run", "activity_type": "running",
+			"date_time": "2025-01-05T07:00:00Z", "city": "Bengaluru", "max_members": 10,
+		},
+	},
+	{
+		Name:        WebhookEventJoinRequestCreated,
+		Description: "Someone asked to join one of your groups",
+		Sample: map[string]interface{}{
+			"group_id": "alice-20250101090000", "group_name": "Sunday Morning Run", "username": "bob",
+		},
+	},
+	{
+		Name:        WebhookEventMemberApproved,
+		Description: "A member was approved into one of your groups, manually or by an auto-approval rule",
+		Sample: map[string]interface{}{
+			"group_id": "alice-20250101090000", "group_name": "Sunday Morning Run", "username": "bob", "auto_approved": false,
+		},
+	},
+}
+
+// IsWebhookEvent reports whether an event name is in the catalog
+func IsWebhookEvent(name string) bool {
+	for _, event := range WebhookEventCatalog {
+		if event.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the JSON body POSTed to subscribers
+type WebhookDelivery struct {
+	ID         string      `json:"id"`
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Test       bool        `json:"test,omitempty"`
+	Data       interface{} `json:"data"`
+}
+
+// OutboundWebhookService delivers catalog events to users' webhook subscriptions
+type OutboundWebhookService struct {
+	db         *gorm.DB
+	httpClient *http.Client
+}
+
+func NewOutboundWebhookService() *OutboundWebhookService {
+	return &OutboundWebhookService{
+		db:         database.GetDB(),
+		httpClient: utils.NewSafeHTTPClient(10 * time.Second),
+	}
+}
+
+// GenerateWebhookSecret returns a random signing secret for a new subscription
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ValidateWebhookTarget rejects target URLs that aren't public HTTPS endpoints,
+// so subscriptions can't be used to reach internal services
+func ValidateWebhookTarget(targetURL string) error {
+	parsed, err := url.Parse(targetURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return fmt.Errorf("target URL must be an https:// URL")
+	}
+
+	addrs, err := net.LookupIP(parsed.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("target host could not be resolved")
+	}
+	for _, addr := range addrs {
+		if !utils.IsPublicIP(addr) {
+			return fmt.Errorf("target host must be publicly reachable")
+		}
+	}
+	return nil
+}
+
+// Deliver signs and POSTs one delivery, recording the outcome on the subscription.
+// It returns the receiver's HTTP status (0 if the request never completed).
+func (s *OutboundWebhookService) Deliver(subscription models.WebhookSubscription, delivery WebhookDelivery) (int, error) {
+	if delivery.ID == "" {
+		id, err := GenerateWebhookSecret()
+		if err != nil {
+			return 0, fmt.Errorf("failed to generate delivery id: %w", err)
+		}
+		delivery.ID = id[:24]
+	}
+	if delivery.OccurredAt.IsZero() {
+		delivery.OccurredAt = time.Now().UTC()
+	}
+
+	// Re-checked on every delivery in case the host now resolves somewhere private
+	if err := ValidateWebhookTarget(subscription.TargetURL); err != nil {
+		return 0, err
+	}
+
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode delivery: %w", err)
+	}
+
+	status := 0
+	defer func() {
+		now := time.Now()
+		if err := s.db.Model(&models.WebhookSubscription{}).Where("id = ?", subscription.ID).
+			Updates(map[string]interface{}{"last_delivery_at": now, "last_status": status}).Error; err != nil {
+			log.Printf("Warning: Failed to record webhook %d delivery: %v", subscription.ID, err)
+		}
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, subscription.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Groops-Event", delivery.Event)
+	req.Header.Set("X-Groops-Delivery", delivery.ID)
+	req.Header.Set("X-Groops-Signature", "sha256="+utils.SignPayload(body, subscription.Secret))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	status = resp.StatusCode
+	if status < 200 || status >= 300 {
+		return status, fmt.Errorf("receiver returned status %d", status)
+	}
+	return status, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kinds of outbox events
+const (
+	outboxKindEmail   = "email"   // Payload is SendGrid v3 mail JSON
+	outboxKindWebhook = "webhook" // Payload is a webhookOutboxPayload
+)
+
+// webhookOutboxPayload is one delivery to one subscription. The delivery ID is fixed when the event is
+// queued, so receivers can recognize retries.
+type webhookOutboxPayload struct {
+	SubscriptionID uint            `json:"subscription_id"`
+	Delivery       WebhookDelivery `json:"delivery"`
+}
+
+// enqueueOutboxEvent writes an event for the outbox dispatcher through tx, so it is only delivered if
+// the transaction commits
+func enqueueOutboxEvent(tx *gorm.DB, kind string, payload interface{}) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s outbox event: %w", kind, err)
+	}
+	now := time.Now()
+	return tx.Create(&models.OutboxEvent{
+		Kind:          kind,
+		Payload:       string(encoded),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}).Error
+}
+
+// QueueWebhookEvent queues an event for every subscription of the user that wants it, through tx.
+// Call it inside the transaction that makes the change the event reports.
+func QueueWebhookEvent(tx *gorm.DB, username, event string, data interface{}) error {
+	var subscriptions []models.WebhookSubscription
+	if err := tx.Where("username = ?", username).Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+
+	occurredAt := time.Now().UTC()
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event) {
+			continue
+		}
+		id, err := GenerateWebhookSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate delivery id: %w", err)
+		}
+		payload := webhookOutboxPayload{
+			SubscriptionID: subscription.ID,
+			Delivery:       WebhookDelivery{ID: id[:24], Event: event, OccurredAt: occurredAt, Data: data},
+		}
+		if err := enqueueOutboxEvent(tx, outboxKindWebhook, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryBackoff is how long to wait before retrying a delivery that has failed attempts times:
+// doubling from two minutes, capped at an hour
+func retryBackoff(attempts int) time.Duration {
+	backoff := time.Minute << attempts
+	if backoff > time.Hour {
+		backoff = time.Hour
+	}
+	return backoff
+}
+
+// OutboxDispatcher delivers queued outbox events every OUTBOX_POLL_INTERVAL (default 5s), retrying
+// failed ones with backoff and giving up after OUTBOX_MAX_ATTEMPTS (default 10) tries. Emails are
+// handed to the email service, which queues them for its own retries while SendGrid is failing.
+// Events are claimed before delivery, so every instance can run a dispatcher.
+type OutboxDispatcher struct {
+	db             *gorm.DB
+	emailService   *EmailService
+	webhookService *OutboundWebhookService
+	maxAttempts    int
+	interval       time.Duration
+}
+
+func NewOutboxDispatcher() *OutboxDispatcher {
+	return &OutboxDispatcher{
+		db:             database.GetDB(),
+		emailService:   NewEmailService(),
+		webhookService: NewOutboundWebhookService(),
+		maxAttempts:    utils.GetEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+		interval:       utils.GetEnvDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+	}
+}
+
+func (d *OutboxDispatcher) Start() {
+	go d.run()
+}
+
+func (d *OutboxDispatcher) run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.dispatchPending()
+	}
+}
+
+// outboxClaimLease is how long a claimed event is hidden from other dispatchers while it is being
+// delivered; a dispatcher that dies mid-delivery has its events retried after it
+const outboxClaimLease = 5 * time.Minute
+
+// claimPending takes the events that are due, pushing their next attempt back by the lease so
+// dispatchers on other instances skip them. Rows another dispatcher is claiming are skipped too.
+func (d *OutboxDispatcher) claimPending() ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_attempt_at <= ?", now).Order("id ASC").Limit(100).Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(outboxClaimLease)).Error
+	})
+	return events, err
+}
+
+// errUndeliverable marks outbox events that can never be delivered and are dropped without retrying
+var errUndeliverable = errors.New("undeliverable outbox event")
+
+func (d *OutboxDispatcher) dispatchPending() {
+	events, err := d.claimPending()
+	if err != nil {
+		log.Printf("Error: Failed to claim outbox events: %v", err)
+		return
+	}
+
+	for _, event := range events {
+		err := d.deliver(event)
+		if err == nil || errors.Is(err, errUndeliverable) {
+			if err != nil {
+				log.Printf("Warning: Dropping outbox event %d: %v", event.ID, err)
+			}
+			if err := d.db.Delete(&event).Error; err != nil {
+				log.Printf("Warning: Failed to remove delivered outbox event %d: %v", event.ID, err)
+			}
+			continue
+		}
+
+		attempts := event.Attempts + 1
+		if attempts >= d.maxAttempts {
+			log.Printf("Warning: Giving up on %s outbox event %d after %d attempts: %v", event.Kind, event.ID, attempts, err)
+			d.db.Delete(&event)
+			continue
+		}
+		if err := d.db.Model(&event).Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      truncateEmailField(err.Error(), 500),
+			"next_attempt_at": time.Now().Add(retryBackoff(attempts)),
+		}).Error; err != nil {
+			log.Printf("Warning: Failed to reschedule outbox event %d: %v", event.ID, err)
+		}
+	}
+}
+
+// deliver sends one event
+func (d *OutboxDispatcher) deliver(event models.OutboxEvent) error {
+	switch event.Kind {
+	case outboxKindEmail:
+		var message mail.SGMailV3
+		if err := json.Unmarshal([]byte(event.Payload), &message); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+		_, err := d.emailService.send(&message)
+		return err
+
+	case outboxKindWebhook:
+		var payload webhookOutboxPayload
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return fmt.Errorf("%w: %v", errUndeliverable, err)
+		}
+		var subscription models.WebhookSubscription
+		if err := d.db.First(&subscription, payload.SubscriptionID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: subscription %d was deleted", errUndeliverable, payload.SubscriptionID)
+		} else if err != nil {
+			return err
+		}
+		_, err := d.webhookService.Deliver(subscription, payload.Delivery)
+		return err
+	}
+	return fmt.Errorf("%w: unknown kind %q", errUndeliverable, event.Kind)
+}
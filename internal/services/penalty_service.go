@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxJoinCooldown caps how long escalating bans can last
+const maxJoinCooldown = 90 * 24 * time.Hour
+
+type PenaltyService struct {
+	db              *gorm.DB
+	window          time.Duration // How far back offenses are counted
+	threshold       int           // Offenses within the window that trigger a cooldown
+	initialCooldown time.Duration // First cooldown length, doubled for each earlier ban in the window
+}
+
+func NewPenaltyService() *PenaltyService {
+	return &PenaltyService{
+		db:              database.GetDB(),
+		window:          utils.GetEnvDuration("PENALTY_WINDOW", 90*24*time.Hour),
+		threshold:       utils.GetEnvInt("PENALTY_OFFENSE_THRESHOLD", 3),
+		initialCooldown: utils.GetEnvDuration("PENALTY_COOLDOWN", 14*24*time.Hour),
+	}
+}
+
+// WithTx returns a copy of the service that records offenses and bans through tx, so they are only
+// kept if the change they penalize commits
+func (s *PenaltyService) WithTx(tx *gorm.DB) *PenaltyService {
+	copied := *s
+	copied.db = tx
+	return &copied
+}
+
+// RecordOffense stores an offense and applies a join ban if the user has crossed the threshold.
+// It returns the new ban, or nil if none was applied.
+func (s *PenaltyService) RecordOffense(username, groupID, offenseType string) (*models.JoinBan, error) {
+	now := time.Now()
+	offense := models.MemberOffense{
+		Username:  username,
+		GroupID:   groupID,
+		Type:      offenseType,
+		CreatedAt: now,
+	}
+	if err := s.db.Create(&offense).Error; err != nil {
+		return nil, fmt.Errorf("failed to record offense: %w", err)
+	}
+
+	since := now.Add(-s.window)
+
+	// Only offenses after the most recent ban count towards the next one
+	var lastBan models.JoinBan
+	if err := s.db.Where("username = ? AND created_at > ?", username, since).
+		Order("created_at DESC").First(&lastBan).Error; err == nil {
+		since = lastBan.CreatedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check previous bans: %w", err)
+	}
+
+	var offenseCount int64
+	if err := s.db.Model(&models.MemberOffense{}).
+		Where("username = ? AND created_at > ?", username, since).
+		Count(&offenseCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count offenses: %w", err)
+	}
+	if int(offenseCount) < s.threshold {
+		return nil, nil
+	}
+
+	// Escalate for repeat bans within the window
+	var priorBans int64
+	if err := s.db.Model(&models.JoinBan{}).
+		Where("username = ? AND created_at > ?", username, now.Add(-s.window)).
+		Count(&priorBans).Error; err != nil {
+		return nil, fmt.Errorf("failed to count previous bans: %w", err)
+	}
+	cooldown := s.initialCooldown
+	for i := int64(0); i < priorBans && cooldown < maxJoinCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > maxJoinCooldown {
+		cooldown = maxJoinCooldown
+	}
+
+	ban := models.JoinBan{
+		Username:  username,
+		Reason:    fmt.Sprintf("%d removals or no-shows within %d days", offenseCount, int(s.window.Hours()/24)),
+		ExpiresAt: now.Add(cooldown),
+		CreatedAt: now,
+	}
+	if err := s.db.Create(&ban).Error; err != nil {
+		return nil, fmt.Errorf("failed to create join ban: %w", err)
+	}
+
+	return &ban, nil
+}
+
+// GetActiveBan returns the user's current join ban, or nil if they are free to join groups
+func (s *PenaltyService) GetActiveBan(username string) (*models.JoinBan, error) {
+	var ban models.JoinBan
+	err := s.db.Where("username = ? AND expires_at > ?", username, time.Now()).
+		Order("expires_at DESC").First(&ban).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ban, nil
+}
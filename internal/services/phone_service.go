@@ -0,0 +1,96 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const twilioVerifyBaseURL = "https://verify.twilio.com/v2/Services"
+
+type PhoneVerificationService struct {
+	httpClient *http.Client
+	accountSID string
+	authToken  string
+	serviceSID string
+}
+
+func NewPhoneVerificationService() (*PhoneVerificationService, error) {
+	accountSID := os.Getenv("TWILIO_ACCOUNT_SID")
+	authToken := os.Getenv("TWILIO_AUTH_TOKEN")
+	serviceSID := os.Getenv("TWILIO_VERIFY_SERVICE_SID")
+
+	if accountSID == "" || authToken == "" || serviceSID == "" {
+		return nil, fmt.Errorf("missing Twilio Verify configuration")
+	}
+
+	return &PhoneVerificationService{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		accountSID: accountSID,
+		authToken:  authToken,
+		serviceSID: serviceSID,
+	}, nil
+}
+
+// twilioVerificationResponse is the subset of Twilio's verification response we use
+type twilioVerificationResponse struct {
+	Status  string `json:"status"`  // pending, approved, canceled
+	Message string `json:"message"` // Set on errors
+}
+
+// StartVerification sends a one-time code to the phone number via SMS
+func (s *PhoneVerificationService) StartVerification(phoneNumber string) error {
+	_, err := s.post("Verifications", url.Values{
+		"To":      {phoneNumber},
+		"Channel": {"sms"},
+	})
+	return err
+}
+
+// CheckVerification reports whether the code entered by the user is correct
+func (s *PhoneVerificationService) CheckVerification(phoneNumber, code string) (bool, error) {
+	resp, err := s.post("VerificationCheck", url.Values{
+		"To":   {phoneNumber},
+		"Code": {code},
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Status == "approved", nil
+}
+
+// post sends a form request to the Twilio Verify service
+func (s *PhoneVerificationService) post(resource string, form url.Values) (*twilioVerificationResponse, error) {
+	endpoint := fmt.Sprintf("%s/%s/%s", twilioVerifyBaseURL, s.serviceSID, resource)
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Twilio request: %w", err)
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result twilioVerificationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Twilio response: %w", err)
+	}
+
+	// Twilio returns 404 from VerificationCheck when the code has expired or was already used
+	if resp.StatusCode == http.StatusNotFound && resource == "VerificationCheck" {
+		return &twilioVerificationResponse{Status: "expired"}, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("twilio returned status %d: %s", resp.StatusCode, result.Message)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PollOptionResult holds the vote tally for a single poll option
+type PollOptionResult struct {
+	OptionID uint   `json:"option_id"`
+	Text     string `json:"text"`
+	Votes    int64  `json:"votes"`
+}
+
+type PollService struct {
+	db *gorm.DB
+}
+
+func NewPollService() *PollService {
+	return &PollService{
+		db: database.GetDB(),
+	}
+}
+
+// GetResults returns the vote tally for each option of a poll, in option order
+func (s *PollService) GetResults(poll models.Poll) ([]PollOptionResult, int64, error) {
+	var counts []struct {
+		OptionID uint
+		Votes    int64
+	}
+	if err := s.db.Model(&models.PollVote{}).
+		Select("option_id, COUNT(*) AS votes").
+		Where("poll_id = ?", poll.ID).
+		Group("option_id").
+		Scan(&counts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	votesByOption := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		votesByOption[c.OptionID] = c.Votes
+	}
+
+	var total int64
+	results := make([]PollOptionResult, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		votes := votesByOption[option.ID]
+		total += votes
+		results = append(results, PollOptionResult{
+			OptionID: option.ID,
+			Text:     option.Text,
+			Votes:    votes,
+		})
+	}
+
+	return results, total, nil
+}
+
+// ClosePoll marks a poll as closed and notifies the group's approved members of the outcome
+func (s *PollService) ClosePoll(poll *models.Poll) error {
+	now := time.Now()
+	if err := s.db.Model(poll).Updates(map[string]interface{}{
+		"status":    models.PollStatusClosed,
+		"closed_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	poll.Status = models.PollStatusClosed
+	poll.ClosedAt = &now
+
+	if len(poll.Options) == 0 {
+		if err := s.db.Where("poll_id = ?", poll.ID).Order("position ASC").Find(&poll.Options).Error; err != nil {
+			log.Printf("Warning: Failed to load options for poll %d: %v", poll.ID, err)
+		}
+	}
+
+	results, total, err := s.GetResults(*poll)
+	if err != nil {
+		log.Printf("Warning: Failed to compute results for poll %d: %v", poll.ID, err)
+	}
+
+	msg := fmt.Sprintf("The poll '%s' has closed", poll.Question)
+	if total > 0 {
+		winner := results[0]
+		for _, r := range results[1:] {
+			if r.Votes > winner.Votes {
+				winner = r
+			}
+		}
+		msg = fmt.Sprintf("The poll '%s' has closed. Top choice: %s (%d of %d votes)", poll.Question, winner.Text, winner.Votes, total)
+	}
+
+	var usernames []string
+	if err := s.db.Model(&models.GroupMember{}).
+		Where("group_id = ? AND status = ?", poll.GroupID, "approved").
+		Pluck("username", &usernames).Error; err != nil {
+		log.Printf("Warning: Failed to fetch members for poll %d close notifications: %v", poll.ID, err)
+		return nil
+	}
+
+	for _, username := range usernames {
+		if err := createTargetedNotification(s.db, username, "poll_closed", msg, poll.GroupID, strconv.FormatUint(uint64(poll.ID), 10)); err != nil {
+			log.Printf("Warning: Failed to create poll closed notification for %s: %v", username, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PollWorker struct {
+	db          *gorm.DB
+	pollService *PollService
+	interval    time.Duration
+}
+
+func NewPollWorker() *PollWorker {
+	return &PollWorker{
+		db:          database.GetDB(),
+		pollService: NewPollService(),
+		interval:    time.Minute, // Check every minute
+	}
+}
+
+func (w *PollWorker) Start() {
+	go w.run()
+}
+
+func (w *PollWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.closeExpiredPolls()
+	}
+}
+
+// closeExpiredPolls closes open polls whose closing time has passed
+func (w *PollWorker) closeExpiredPolls() {
+	var polls []models.Poll
+	if err := w.db.Preload("Options").
+		Where("status = ? AND closes_at IS NOT NULL AND closes_at <= ?", models.PollStatusOpen, time.Now()).
+		Find(&polls).Error; err != nil {
+		log.Printf("Failed to fetch expired polls: %v", err)
+		return
+	}
+
+	for i := range polls {
+		if err := w.pollService.ClosePoll(&polls[i]); err != nil {
+			log.Printf("Failed to close poll %d: %v", polls[i].ID, err)
+			continue
+		}
+		log.Printf("Closed expired poll %d for group %s", polls[i].ID, polls[i].GroupID)
+	}
+}
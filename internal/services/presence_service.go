@@ -0,0 +1,149 @@
+package services
+
+import (
+	"groops/internal/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PresenceEntry is one member currently viewing a group chat
+type PresenceEntry struct {
+	Username string    `json:"username"`
+	Typing   bool      `json:"typing"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// presenceState is what we remember about a viewer between heartbeats
+type presenceState struct {
+	lastSeen    time.Time
+	typingUntil time.Time
+}
+
+// PresenceService tracks who is viewing each group chat and who is typing.
+// Clients send a heartbeat while the chat is open; viewers that stop sending
+// heartbeats drop out after PRESENCE_TTL, and typing flags clear after PRESENCE_TYPING_TTL.
+// State is kept in memory, so it is per-process and lost on restart.
+type PresenceService struct {
+	ttl       time.Duration
+	typingTTL time.Duration
+	interval  time.Duration
+
+	mu     sync.Mutex
+	groups map[string]map[string]*presenceState
+}
+
+var (
+	presenceService     *PresenceService
+	presenceServiceOnce sync.Once
+)
+
+// GetPresenceService returns the process-wide presence tracker; every request must see the same state
+func GetPresenceService() *PresenceService {
+	presenceServiceOnce.Do(func() {
+		presenceService = &PresenceService{
+			ttl:       utils.GetEnvDuration("PRESENCE_TTL", 45*time.Second),
+			typingTTL: utils.GetEnvDuration("PRESENCE_TYPING_TTL", 6*time.Second),
+			interval:  30 * time.Second, // Sweep expired entries every 30 seconds
+			groups:    make(map[string]map[string]*presenceState),
+		}
+	})
+	return presenceService
+}
+
+// Start begins pruning expired viewers in the background
+func (s *PresenceService) Start() {
+	go s.run()
+}
+
+func (s *PresenceService) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.prune(time.Now())
+	}
+}
+
+// TTL is how long a viewer stays present without a heartbeat; clients should heartbeat well within it
+func (s *PresenceService) TTL() time.Duration {
+	return s.ttl
+}
+
+// Heartbeat marks the user as viewing the group chat, and as typing if typing is set.
+// A heartbeat without typing clears the typing flag straight away (e.g. after sending).
+func (s *PresenceService) Heartbeat(groupID, username string, typing bool) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	viewers, ok := s.groups[groupID]
+	if !ok {
+		viewers = make(map[string]*presenceState)
+		s.groups[groupID] = viewers
+	}
+	state, ok := viewers[username]
+	if !ok {
+		state = &presenceState{}
+		viewers[username] = state
+	}
+	state.lastSeen = now
+	if typing {
+		state.typingUntil = now.Add(s.typingTTL)
+	} else {
+		state.typingUntil = time.Time{}
+	}
+}
+
+// Leave removes the user from the group chat's viewers immediately
+func (s *PresenceService) Leave(groupID, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if viewers, ok := s.groups[groupID]; ok {
+		delete(viewers, username)
+		if len(viewers) == 0 {
+			delete(s.groups, groupID)
+		}
+	}
+}
+
+// Viewers lists who is currently viewing the group chat, alphabetically
+func (s *PresenceService) Viewers(groupID string) []PresenceEntry {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []PresenceEntry{}
+	for username, state := range s.groups[groupID] {
+		if now.Sub(state.lastSeen) > s.ttl {
+			continue
+		}
+		entries = append(entries, PresenceEntry{
+			Username: username,
+			Typing:   now.Before(state.typingUntil),
+			LastSeen: state.lastSeen,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Username < entries[j].Username })
+	return entries
+}
+
+// prune drops viewers whose last heartbeat is older than the TTL, and groups left empty
+func (s *PresenceService) prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for groupID, viewers := range s.groups {
+		for username, state := range viewers {
+			if now.Sub(state.lastSeen) > s.ttl {
+				delete(viewers, username)
+			}
+		}
+		if len(viewers) == 0 {
+			delete(s.groups, groupID)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recurrenceLookback bounds how long after an occurrence passes we still create the next one,
+// so groups whose series has ended aren't re-examined forever
+const recurrenceLookback = 7 * 24 * time.Hour
+
+// RecurrenceService creates the next occurrence of recurring groups once the current one passes
+type RecurrenceService struct {
+	db *gorm.DB
+}
+
+func NewRecurrenceService() *RecurrenceService {
+	return &RecurrenceService{db: database.GetDB()}
+}
+
+// MaterializeDueOccurrences creates the next occurrence for every recurring group that has passed
+func (s *RecurrenceService) MaterializeDueOccurrences() {
+	now := time.Now()
+
+	var groups []models.Group
+	if err := s.db.Where("recurrence IS NOT NULL AND next_occurrence_id IS NULL AND date_time <= ? AND date_time > ?",
+		now, now.Add(-recurrenceLookback)).
+		Find(&groups).Error; err != nil {
+		log.Printf("Failed to fetch recurring groups: %v", err)
+		return
+	}
+
+	for _, group := range groups {
+		if !group.IsRecurring() {
+			continue
+		}
+		if err := s.materializeNext(group, now); err != nil {
+			log.Printf("Failed to create next occurrence of group %s: %v", group.ID, err)
+		}
+	}
+}
+
+// materializeNext creates the occurrence following group, skipping any dates already in the past
+func (s *RecurrenceService) materializeNext(group models.Group, now time.Time) error {
+	rule := *group.Recurrence
+	next := rule.Next(group.DateTime)
+	for !next.After(now) {
+		next = rule.Next(next)
+	}
+	if rule.Ended(next) {
+		return nil
+	}
+
+	seriesID := group.ID
+	if group.SeriesID != nil {
+		seriesID = *group.SeriesID
+	}
+
+	occurrence := models.Group{
+		ID:           fmt.Sprintf("%s-%s", group.OrganiserID, next.UTC().Format("20060102150405")),
+		Name:         group.Name,
+		DateTime:     next,
+		Location:     group.Location,
+		City:         group.City,
+		Cost:         group.Cost,
+		SkillLevel:   group.SkillLevel,
+		ActivityType: group.ActivityType,
+		MaxMembers:   group.MaxMembers,
+		Description:  group.Description,
+		OrganiserID:  group.OrganiserID,
+
+		AutoApproveAll:             group.AutoApproveAll,
+		AutoApproveMinRating:       group.AutoApproveMinRating,
+		AutoApprovePreviousMembers: group.AutoApprovePreviousMembers,
+
+		MaxGuestsPerMember: group.MaxGuestsPerMember,
+		CutoffMinutes:      group.CutoffMinutes,
+
+		Recurrence: &rule,
+		SeriesID:   &seriesID,
+
+		ApprovalMessage:  group.ApprovalMessage,
+		RejectionMessage: group.RejectionMessage,
+
+		Visibility: group.Visibility,
+
+		MinAge: group.MinAge,
+		MaxAge: group.MaxAge,
+		Gender: group.Gender,
+
+		CostDetails: group.CostDetails,
+		MemberNotes: group.MemberNotes,
+
+		SecondaryLocations: group.SecondaryLocations,
+	}
+	// The RSVP deadline keeps the same lead time before each occurrence, and every occurrence lasts as long
+	if group.RSVPDeadline != nil {
+		deadline := next.Add(group.RSVPDeadline.Sub(group.DateTime))
+		occurrence.RSVPDeadline = &deadline
+	}
+	if group.EndDateTime != nil {
+		end := next.Add(group.EndDateTime.Sub(group.DateTime))
+		occurrence.EndDateTime = &end
+	}
+
+	var members []models.GroupMember
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ? AND status = ? AND username != ?", group.ID, "approved", group.OrganiserID).
+			Find(&members).Error; err != nil {
+			return fmt.Errorf("failed to fetch members: %w", err)
+		}
+
+		if err := tx.Create(&occurrence).Error; err != nil {
+			return fmt.Errorf("failed to create occurrence: %w", err)
+		}
+		if err := tx.Create(&models.GroupMember{GroupID: occurrence.ID, Username: group.OrganiserID, Status: "approved", Role: models.RoleOrganiser}).Error; err != nil {
+			return fmt.Errorf("failed to add organizer: %w", err)
+		}
+
+		if rule.CarryOverMembers {
+			for _, member := range members {
+				carried := models.GroupMember{
+					GroupID:    occurrence.ID,
+					Username:   member.Username,
+					Status:     "approved",
+					GuestCount: member.GuestCount,
+					Role:       member.Role,
+				}
+				if err := tx.Create(&carried).Error; err != nil {
+					return fmt.Errorf("failed to carry over %s: %w", member.Username, err)
+				}
+			}
+		}
+		if err := RefreshMemberCounts(tx, occurrence.ID); err != nil {
+			return fmt.Errorf("failed to count members: %w", err)
+		}
+
+		// Tags carry over; rewriting search_vector re-fires the trigger so they're searchable
+		if err := tx.Exec(`INSERT INTO group_tag (group_id, tag_id) SELECT ?, tag_id FROM group_tag WHERE group_id = ?`,
+			occurrence.ID, group.ID).Error; err != nil {
+			return fmt.Errorf("failed to copy tags: %w", err)
+		}
+		if err := tx.Exec(`UPDATE "group" SET search_vector = NULL WHERE id = ?`, occurrence.ID).Error; err != nil {
+			return fmt.Errorf("failed to index tags: %w", err)
+		}
+
+		return tx.Model(&models.Group{}).Where("id = ?", group.ID).
+			Updates(map[string]interface{}{"next_occurrence_id": occurrence.ID, "series_id": seriesID}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created occurrence %s of recurring group %s", occurrence.ID, group.ID)
+
+	when := next.Format("Mon, Jan 2 at 3:04 PM MST")
+	for _, member := range members {
+		msg := fmt.Sprintf("The next '%s' is on %s. RSVP to save your spot.", group.Name, when)
+		if rule.CarryOverMembers {
+			msg = fmt.Sprintf("You're in for the next '%s' on %s.", group.Name, when)
+		}
+		if err := createNotification(s.db, member.Username, "occurrence_scheduled", msg, occurrence.ID); err != nil {
+			log.Printf("Failed to notify %s of the next occurrence of %s: %v", member.Username, group.ID, err)
+		}
+	}
+	msg := fmt.Sprintf("The next '%s' has been scheduled for %s.", group.Name, when)
+	if err := createNotification(s.db, group.OrganiserID, "occurrence_scheduled", msg, occurrence.ID); err != nil {
+		log.Printf("Failed to notify organizer of the next occurrence of %s: %v", group.ID, err)
+	}
+
+	return nil
+}
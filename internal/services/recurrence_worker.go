@@ -0,0 +1,30 @@
+package services
+
+import (
+	"time"
+)
+
+type RecurrenceWorker struct {
+	recurrenceService *RecurrenceService
+	interval          time.Duration
+}
+
+func NewRecurrenceWorker() *RecurrenceWorker {
+	return &RecurrenceWorker{
+		recurrenceService: NewRecurrenceService(),
+		interval:          15 * time.Minute, // Check every 15 minutes
+	}
+}
+
+func (w *RecurrenceWorker) Start() {
+	go w.run()
+}
+
+func (w *RecurrenceWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.recurrenceService.MaterializeDueOccurrences()
+	}
+}
@@ -10,16 +10,18 @@ import (
 )
 
 type ReminderWorker struct {
-	db           *gorm.DB
-	emailService *EmailService
-	interval     time.Duration
+	db             *gorm.DB
+	emailService   *EmailService
+	weatherService *WeatherService
+	interval       time.Duration
 }
 
 func NewReminderWorker() *ReminderWorker {
 	return &ReminderWorker{
-		db:           database.GetDB(),
-		emailService: NewEmailService(),
-		interval:     time.Minute * 5, // Check every 5 minutes
+		db:             database.GetDB(),
+		emailService:   NewEmailService(),
+		weatherService: NewWeatherService(),
+		interval:       time.Minute * 5, // Check every 5 minutes
 	}
 }
 
@@ -68,9 +70,9 @@ func (w *ReminderWorker) recordReminders(groupID string, usernames []string, rem
 func (w *ReminderWorker) checkUpcomingEvents() {
 	now := time.Now()
 
-	// Find groups with events in the future
+	// Find groups with events in the future that are still going ahead
 	var groups []models.Group
-	w.db.Where("date_time > ?", now).Find(&groups)
+	w.db.Where("date_time > ? AND status = ?", now, models.GroupStatusActive).Find(&groups)
 
 	// For each group that needs reminders
 	for _, group := range groups {
@@ -114,8 +116,25 @@ func (w *ReminderWorker) sendRemindersForGroup(group models.Group, reminderType
 		return
 	}
 
+	// Warn members about bad weather in the day-before reminder
+	weatherWarning := ""
+	if reminderType == "24hour" {
+		forecast, err := w.weatherService.GetForecast(group.Location.Latitude, group.Location.Longitude, group.DateTime)
+		if err != nil {
+			log.Printf("Warning: Failed to fetch weather for group %s reminder: %v", group.ID, err)
+		} else {
+			weatherWarning = BadWeatherWarning(forecast)
+		}
+	}
+
+	// Remind members what they're bringing and what's still needed
+	var checklist []models.ChecklistItem
+	if err := w.db.Where("group_id = ?", group.ID).Order("created_at ASC").Find(&checklist).Error; err != nil {
+		log.Printf("Warning: Failed to fetch checklist for group %s reminder: %v", group.ID, err)
+	}
+
 	// Send batch email to all members
-	err := w.emailService.SendEventReminderToGroup(group, accounts, reminderType)
+	err := w.emailService.SendEventReminderToGroup(group, accounts, reminderType, weatherWarning, checklist)
 	if err != nil {
 		log.Printf("Failed to send %s reminders for group %s: %v", reminderType, group.ID, err)
 		return
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RSVPService handles what happens when a group's RSVP deadline passes
+type RSVPService struct {
+	db           *gorm.DB
+	emailService *EmailService
+}
+
+func NewRSVPService() *RSVPService {
+	return &RSVPService{
+		db:           database.GetDB(),
+		emailService: NewEmailService(),
+	}
+}
+
+// SendFinalHeadcounts emails organizers the final attendee list of upcoming groups whose RSVP deadline has passed
+func (s *RSVPService) SendFinalHeadcounts() {
+	now := time.Now()
+
+	var groups []models.Group
+	if err := s.db.Where("rsvp_deadline <= ? AND headcount_sent_at IS NULL AND date_time > ? AND status = ?",
+		now, now, models.GroupStatusActive).Find(&groups).Error; err != nil {
+		log.Printf("Failed to fetch groups past their RSVP deadline: %v", err)
+		return
+	}
+
+	for _, group := range groups {
+		if err := s.sendFinalHeadcount(group, now); err != nil {
+			log.Printf("Failed to send final headcount for group %s: %v", group.ID, err)
+		}
+	}
+}
+
+// sendFinalHeadcount emails one group's organizer and records that it was sent
+func (s *RSVPService) sendFinalHeadcount(group models.Group, now time.Time) error {
+	var organizer models.Account
+	if err := s.db.Where("username = ?", group.OrganiserID).First(&organizer).Error; err != nil {
+		return fmt.Errorf("organizer not found: %w", err)
+	}
+
+	var members []models.GroupMember
+	if err := s.db.Where("group_id = ? AND status = ?", group.ID, "approved").Order("joined_at ASC").Find(&members).Error; err != nil {
+		return fmt.Errorf("failed to fetch members: %w", err)
+	}
+	attendees := make([]string, len(members))
+	guests := 0
+	for i, member := range members {
+		attendees[i] = member.Username
+		guests += member.GuestCount
+	}
+
+	// Mark it first so a failing mail provider doesn't get retried every tick
+	result := s.db.Model(&models.Group{}).Where("id = ? AND headcount_sent_at IS NULL", group.ID).Update("headcount_sent_at", now)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record headcount: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if err := s.emailService.SendFinalHeadcountEmail(organizer.Email, organizer.FullName, group, attendees, guests); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	log.Printf("Sent final headcount (%d) for group %s to %s", len(attendees)+guests, group.ID, organizer.Username)
+	return nil
+}
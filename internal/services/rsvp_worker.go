@@ -0,0 +1,30 @@
+package services
+
+import (
+	"time"
+)
+
+type RSVPWorker struct {
+	rsvpService *RSVPService
+	interval    time.Duration
+}
+
+func NewRSVPWorker() *RSVPWorker {
+	return &RSVPWorker{
+		rsvpService: NewRSVPService(),
+		interval:    5 * time.Minute, // Check every 5 minutes
+	}
+}
+
+func (w *RSVPWorker) Start() {
+	go w.run()
+}
+
+func (w *RSVPWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.rsvpService.SendFinalHeadcounts()
+	}
+}
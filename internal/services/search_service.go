@@ -100,11 +100,15 @@ func (s *SearchService) fullTextSearch(searchTerm string, limit int) ([]SearchRe
 	var results []SearchResult
 
 	query := `
-		SELECT *, 
+		SELECT id, name, date_time, end_date_time, location, COALESCE(city, '') AS city, cost, cost_details, skill_level, activity_type, 
+		       max_members, description, organiser_id, created_at, updated_at,
 		       ts_rank_cd(search_vector, to_tsquery('english', ?), 1) as fts_rank
 		FROM "group" 
 		WHERE search_vector @@ to_tsquery('english', ?)
 		  AND date_time > NOW()
+		  AND status = 'active'
+		  AND visibility = 'public'
+		  AND deleted_at IS NULL
 		ORDER BY fts_rank DESC
 		LIMIT ?
 	`
@@ -119,20 +123,19 @@ func (s *SearchService) fullTextSearch(searchTerm string, limit int) ([]SearchRe
 	for rows.Next() {
 		var group models.Group
 		var rank float64
-		var searchVector interface{} // For the search_vector column
 
-		// Scan all group fields plus search_vector and the rank
+		// Scan all group fields plus the rank
 		err := rows.Scan(
-			&group.ID, &group.Name, &group.DateTime, &group.Location,
-			&group.Cost, &group.SkillLevel, &group.ActivityType, &group.MaxMembers,
+			&group.ID, &group.Name, &group.DateTime, &group.EndDateTime, &group.Location, &group.City,
+			&group.Cost, &group.CostDetails, &group.SkillLevel, &group.ActivityType, &group.MaxMembers,
 			&group.Description, &group.OrganiserID, &group.CreatedAt, &group.UpdatedAt,
-			&searchVector, // Add this for the search_vector column
 			&rank,
 		)
 		if err != nil {
 			log.Printf("Error scanning FTS result: %v", err)
 			continue
 		}
+		group.FillCostDetails()
 
 		results = append(results, SearchResult{
 			Group: group,
@@ -149,7 +152,7 @@ func (s *SearchService) fuzzySearch(searchTerm string) ([]SearchResult, error) {
 	var results []SearchResult
 
 	query := `
-		SELECT id, name, date_time, location, cost, skill_level, activity_type, 
+		SELECT id, name, date_time, end_date_time, location, COALESCE(city, '') AS city, cost, cost_details, skill_level, activity_type, 
 		       max_members, description, organiser_id, created_at, updated_at,
 			   GREATEST(
 				   similarity(name, $1),
@@ -163,6 +166,9 @@ func (s *SearchService) fuzzySearch(searchTerm string) ([]SearchResult, error) {
 			   description % $1
 		   )
 		   AND date_time > NOW()
+		   AND status = 'active'
+		   AND visibility = 'public'
+		   AND deleted_at IS NULL
 		   AND GREATEST(
 			   similarity(name, $1),
 			   similarity(activity_type, $1),
@@ -184,8 +190,8 @@ func (s *SearchService) fuzzySearch(searchTerm string) ([]SearchResult, error) {
 
 		// Scan all group fields plus the similarity score
 		err := rows.Scan(
-			&group.ID, &group.Name, &group.DateTime, &group.Location,
-			&group.Cost, &group.SkillLevel, &group.ActivityType, &group.MaxMembers,
+			&group.ID, &group.Name, &group.DateTime, &group.EndDateTime, &group.Location, &group.City,
+			&group.Cost, &group.CostDetails, &group.SkillLevel, &group.ActivityType, &group.MaxMembers,
 			&group.Description, &group.OrganiserID, &group.CreatedAt, &group.UpdatedAt,
 			&similarity,
 		)
@@ -193,6 +199,7 @@ func (s *SearchService) fuzzySearch(searchTerm string) ([]SearchResult, error) {
 			log.Printf("Error scanning fuzzy result: %v", err)
 			continue
 		}
+		group.FillCostDetails()
 
 		results = append(results, SearchResult{
 			Group: group,
@@ -211,7 +218,7 @@ func (s *SearchService) partialSearch(searchTerm string) ([]SearchResult, error)
 	searchPattern := "%" + strings.ToLower(searchTerm) + "%"
 
 	query := `
-		SELECT id, name, date_time, location, cost, skill_level, activity_type, 
+		SELECT id, name, date_time, end_date_time, location, COALESCE(city, '') AS city, cost, cost_details, skill_level, activity_type, 
 		       max_members, description, organiser_id, created_at, updated_at,
 			   CASE 
 				   WHEN LOWER(name) LIKE $1 THEN 3
@@ -224,9 +231,13 @@ func (s *SearchService) partialSearch(searchTerm string) ([]SearchResult, error)
 			   LOWER(name) LIKE $1 OR 
 			   LOWER(activity_type) LIKE $1 OR 
 			   LOWER(description) LIKE $1 OR
+			   LOWER(city) LIKE $1 OR
 			   LOWER(organiser_id) LIKE $1
 		   )
 		   AND date_time > NOW()
+		   AND status = 'active'
+		   AND visibility = 'public'
+		   AND deleted_at IS NULL
 		ORDER BY partial_score DESC
 		LIMIT 20
 	`
@@ -243,8 +254,8 @@ func (s *SearchService) partialSearch(searchTerm string) ([]SearchResult, error)
 
 		// Scan all group fields plus the partial score
 		err := rows.Scan(
-			&group.ID, &group.Name, &group.DateTime, &group.Location,
-			&group.Cost, &group.SkillLevel, &group.ActivityType, &group.MaxMembers,
+			&group.ID, &group.Name, &group.DateTime, &group.EndDateTime, &group.Location, &group.City,
+			&group.Cost, &group.CostDetails, &group.SkillLevel, &group.ActivityType, &group.MaxMembers,
 			&group.Description, &group.OrganiserID, &group.CreatedAt, &group.UpdatedAt,
 			&score,
 		)
@@ -252,6 +263,7 @@ func (s *SearchService) partialSearch(searchTerm string) ([]SearchResult, error)
 			log.Printf("Error scanning partial result: %v", err)
 			continue
 		}
+		group.FillCostDetails()
 
 		results = append(results, SearchResult{
 			Group: group,
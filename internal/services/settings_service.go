@@ -0,0 +1,71 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SettingsService resolves configuration values, preferring rows in the app_setting table
+// over environment variables so limits can be tuned without a redeploy
+type SettingsService struct {
+	db *gorm.DB
+}
+
+func NewSettingsService() *SettingsService {
+	return &SettingsService{
+		db: database.GetDB(),
+	}
+}
+
+// lookup returns the database value for a setting key, if one is stored
+func (s *SettingsService) lookup(key string) (string, bool) {
+	var setting models.AppSetting
+	if err := s.db.Where("key = ?", key).Limit(1).Find(&setting).Error; err != nil {
+		log.Printf("Warning: Failed to read setting %s: %v", key, err)
+		return "", false
+	}
+	if setting.Key == "" {
+		return "", false
+	}
+	return setting.Value, true
+}
+
+// GetInt returns the setting from the database, then the environment variable, then the fallback
+func (s *SettingsService) GetInt(key, envKey string, fallback int) int {
+	if value, ok := s.lookup(key); ok {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Warning: Invalid integer for setting %s (%q)", key, value)
+	}
+	return utils.GetEnvInt(envKey, fallback)
+}
+
+// GetDuration returns the setting from the database, then the environment variable, then the fallback
+func (s *SettingsService) GetDuration(key, envKey string, fallback time.Duration) time.Duration {
+	if value, ok := s.lookup(key); ok {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		log.Printf("Warning: Invalid duration for setting %s (%q)", key, value)
+	}
+	return utils.GetEnvDuration(envKey, fallback)
+}
+
+// GetString returns the setting from the database, then the environment variable, then the fallback
+func (s *SettingsService) GetString(key, envKey, fallback string) string {
+	if value, ok := s.lookup(key); ok {
+		return value
+	}
+	if value := os.Getenv(envKey); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"groops/internal/utils"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteSLO overrides the default objectives for one route template
+type RouteSLO struct {
+	Route             string  `json:"route"`                         // Route template, e.g. /groups/:group_id
+	P95LatencySeconds float64 `json:"p95_latency_seconds,omitempty"` // 0 keeps the default
+	ErrorRate         float64 `json:"error_rate,omitempty"`          // Allowed 5xx fraction; 0 keeps the default
+}
+
+// SLOConfig holds the latency and error-rate objectives alert rules are generated from
+type SLOConfig struct {
+	P95LatencySeconds float64       // SLO_P95_LATENCY_SECONDS
+	ErrorRate         float64       // SLO_ERROR_RATE
+	Window            time.Duration // SLO_ALERT_WINDOW: rate window of each expression
+	For               time.Duration // SLO_ALERT_FOR: how long a breach must last before firing
+	Routes            []RouteSLO    // SLO_ROUTES_FILE: JSON array of per-route overrides
+}
+
+// LoadSLOConfig reads the SLO configuration from the environment
+func LoadSLOConfig() (SLOConfig, error) {
+	config := SLOConfig{
+		P95LatencySeconds: utils.GetEnvFloat("SLO_P95_LATENCY_SECONDS", 0.5),
+		ErrorRate:         utils.GetEnvFloat("SLO_ERROR_RATE", 0.01),
+		Window:            utils.GetEnvDuration("SLO_ALERT_WINDOW", 5*time.Minute),
+		For:               utils.GetEnvDuration("SLO_ALERT_FOR", 10*time.Minute),
+	}
+
+	if path := os.Getenv("SLO_ROUTES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return config, fmt.Errorf("failed to read SLO_ROUTES_FILE: %w", err)
+		}
+		if err := json.Unmarshal(data, &config.Routes); err != nil {
+			return config, fmt.Errorf("invalid SLO_ROUTES_FILE: %w", err)
+		}
+	}
+
+	if config.P95LatencySeconds <= 0 || config.ErrorRate <= 0 || config.ErrorRate >= 1 {
+		return config, fmt.Errorf("SLO thresholds must be positive and the error rate below 1")
+	}
+	if config.Window < time.Minute {
+		return config, fmt.Errorf("SLO_ALERT_WINDOW must be at least 1m")
+	}
+	for i, route := range config.Routes {
+		if strings.TrimSpace(route.Route) == "" {
+			return config, fmt.Errorf("SLO route %d has no route template", i)
+		}
+		if route.P95LatencySeconds < 0 || route.ErrorRate < 0 || route.ErrorRate >= 1 {
+			return config, fmt.Errorf("SLO route %s has an invalid threshold", route.Route)
+		}
+	}
+	return config, nil
+}
+
+// promDuration formats a duration the way Prometheus rule files expect (e.g. 5m, 90s)
+func promDuration(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int64(d/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int64(d.Round(time.Second)/time.Second))
+}
+
+// routeMatcher quotes a route template for a PromQL regex matcher
+func routeMatcher(routes []string) string {
+	quoted := make([]string, len(routes))
+	for i, route := range routes {
+		quoted[i] = regexp.QuoteMeta(route)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// latencyExpr is the p95 latency per route over the window, restricted by selector
+func latencyExpr(selector, window string, threshold float64) string {
+	return fmt.Sprintf("histogram_quantile(0.95, sum by (le, method, route) (rate(%s_bucket%s[%s]))) > %s",
+		utils.MetricRequestDuration, selector, window, strconv.FormatFloat(threshold, 'g', -1, 64))
+}
+
+// errorRateExpr is the fraction of 5xx responses per route over the window, restricted by selector
+func errorRateExpr(selector, window string, threshold float64) string {
+	return fmt.Sprintf("sum by (method, route) (rate(%s%s[%s])) / sum by (method, route) (rate(%s%s[%s])) > %s",
+		utils.MetricRequestErrors, selector, window, utils.MetricRequestsTotal, selector, window,
+		strconv.FormatFloat(threshold, 'g', -1, 64))
+}
+
+// RenderAlertRules builds a Prometheus alerting rule file for the configured p95 latency and 5xx SLOs.
+// Routes with overrides get their own rules and are left out of the default ones.
+func RenderAlertRules(config SLOConfig) string {
+	window, pending := promDuration(config.Window), promDuration(config.For)
+
+	var b strings.Builder
+	b.WriteString("groups:\n  - name: groops-slo\n    rules:\n")
+	writeRule := func(alert, expr, summary string) {
+		fmt.Fprintf(&b, "      - alert: %s\n", alert)
+		fmt.Fprintf(&b, "        expr: %s\n", strconv.Quote(expr))
+		fmt.Fprintf(&b, "        for: %s\n", pending)
+		b.WriteString("        labels:\n          severity: page\n")
+		fmt.Fprintf(&b, "        annotations:\n          summary: %s\n", strconv.Quote(summary))
+	}
+
+	overridden := make([]string, len(config.Routes))
+	for i, route := range config.Routes {
+		overridden[i] = route.Route
+	}
+	defaultSelector := ""
+	if len(overridden) > 0 {
+		defaultSelector = fmt.Sprintf("{route!~%s}", strconv.Quote(routeMatcher(overridden)))
+	}
+
+	writeRule("RouteLatencyP95High", latencyExpr(defaultSelector, window, config.P95LatencySeconds),
+		fmt.Sprintf("p95 latency of {{ $labels.method }} {{ $labels.route }} is above %vs", config.P95LatencySeconds))
+	writeRule("RouteErrorRateHigh", errorRateExpr(defaultSelector, window, config.ErrorRate),
+		fmt.Sprintf("5xx rate of {{ $labels.method }} {{ $labels.route }} is above %v%%", config.ErrorRate*100))
+
+	for _, route := range config.Routes {
+		selector := fmt.Sprintf("{route=%s}", strconv.Quote(route.Route))
+		latency, errorRate := route.P95LatencySeconds, route.ErrorRate
+		if latency == 0 {
+			latency = config.P95LatencySeconds
+		}
+		if errorRate == 0 {
+			errorRate = config.ErrorRate
+		}
+		writeRule("RouteLatencyP95High", latencyExpr(selector, window, latency),
+			fmt.Sprintf("p95 latency of {{ $labels.method }} %s is above %vs", route.Route, latency))
+		writeRule("RouteErrorRateHigh", errorRateExpr(selector, window, errorRate),
+			fmt.Sprintf("5xx rate of {{ $labels.method }} %s is above %v%%", route.Route, errorRate*100))
+	}
+
+	return b.String()
+}
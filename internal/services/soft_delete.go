@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotDeleted is returned when restoring something that doesn't exist or isn't deleted
+var ErrNotDeleted = errors.New("no deleted record found")
+
+// groupPurgeTables hold rows kept with a deleted group so restoring it brings them back, and removed
+// when the group is purged
+var groupPurgeTables = []interface{}{
+	&models.ActivityLog{},
+	&models.GroupTag{},
+	&models.ChatMute{},
+	&models.Invitation{},
+	&models.EventFeedback{},
+	&models.Announcement{},
+	&models.MessageReadCursor{},
+	&models.GroupIntegration{},
+	&models.MessageRevision{},
+	&models.Message{},
+	&models.GroupMember{},
+	&models.AnalyticsEvent{},
+	&models.AnalyticsDailyRollup{},
+	&models.ChecklistItem{},
+	&models.WaitlistEntry{},
+	&models.GroupBroadcast{},
+	&models.GroupExport{},
+	&models.MemberOffense{},
+	&models.ReminderSent{},
+	&models.Poll{}, // After its options and votes, which purgeGroup deletes by poll
+}
+
+// SoftDeleteGroup marks a group, its memberships and its chat deleted at one shared time, which is how
+// RestoreGroup tells them apart from messages their senders deleted earlier
+func SoftDeleteGroup(tx *gorm.DB, groupID string) error {
+	// Postgres keeps microseconds, so the stored times compare equal to this one
+	deletedAt := time.Now().Truncate(time.Microsecond)
+	for _, model := range []interface{}{&models.GroupMember{}, &models.Message{}} {
+		if err := tx.Model(model).Where("group_id = ?", groupID).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(&models.Group{}).Where("id = ?", groupID).UpdateColumn("deleted_at", deletedAt).Error
+}
+
+// SoftDeleteService restores deleted groups and accounts, and purges them once they have been deleted
+// for longer than SOFT_DELETE_RETENTION (default 30 days)
+type SoftDeleteService struct {
+	db        *gorm.DB
+	retention time.Duration
+}
+
+func NewSoftDeleteService() *SoftDeleteService {
+	return &SoftDeleteService{
+		db:        database.GetDB(),
+		retention: utils.GetEnvDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+	}
+}
+
+// ListDeletedGroups returns deleted groups that haven't been purged yet, most recently deleted first
+func (s *SoftDeleteService) ListDeletedGroups(limit, offset int) ([]models.Group, int64, error) {
+	query := s.db.Unscoped().Model(&models.Group{}).Where("deleted_at IS NOT NULL")
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var groups []models.Group
+	if err := query.Order("deleted_at DESC").Limit(limit).Offset(offset).Find(&groups).Error; err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+// RestoreGroup undeletes a group with the memberships and messages deleted along with it
+func (s *SoftDeleteService) RestoreGroup(groupID string) (models.Group, error) {
+	var group models.Group
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", groupID).First(&group).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotDeleted
+			}
+			return err
+		}
+
+		deletedAt := group.DeletedAt.Time
+		for _, model := range []interface{}{&models.GroupMember{}, &models.Message{}} {
+			if err := tx.Unscoped().Model(model).Where("group_id = ? AND deleted_at = ?", groupID, deletedAt).
+				UpdateColumn("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Model(&group).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return RefreshMemberCounts(tx, groupID)
+	})
+	return group, err
+}
+
+// RestoreAccount undeletes an account
+func (s *SoftDeleteService) RestoreAccount(username string) (models.Account, error) {
+	var account models.Account
+	if err := s.db.Unscoped().Where("username = ? AND deleted_at IS NOT NULL", username).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return account, ErrNotDeleted
+		}
+		return account, err
+	}
+	if err := s.db.Unscoped().Model(&account).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return account, err
+	}
+	return account, nil
+}
+
+// PurgeExpired permanently removes groups, accounts and chat messages deleted longer ago than the
+// retention period
+func (s *SoftDeleteService) PurgeExpired() {
+	cutoff := time.Now().Add(-s.retention)
+
+	var groupIDs []string
+	if err := s.db.Unscoped().Model(&models.Group{}).Where("deleted_at < ?", cutoff).Pluck("id", &groupIDs).Error; err != nil {
+		log.Printf("Failed to fetch deleted groups to purge: %v", err)
+	}
+	for _, groupID := range groupIDs {
+		if err := s.db.Transaction(func(tx *gorm.DB) error { return purgeGroup(tx, groupID) }); err != nil {
+			log.Printf("Failed to purge group %s: %v", groupID, err)
+		}
+	}
+	if len(groupIDs) > 0 {
+		log.Printf("Purged %d deleted groups", len(groupIDs))
+	}
+
+	// Messages their senders deleted in groups that still exist
+	if err := s.db.Unscoped().Where("message_id IN (?)",
+		s.db.Unscoped().Model(&models.Message{}).Select("id").Where("deleted_at < ?", cutoff),
+	).Delete(&models.MessageRevision{}).Error; err != nil {
+		log.Printf("Failed to purge revisions of deleted messages: %v", err)
+	} else if result := s.db.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.Message{}); result.Error != nil {
+		log.Printf("Failed to purge deleted messages: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Purged %d deleted messages", result.RowsAffected)
+	}
+
+	var usernames []string
+	if err := s.db.Unscoped().Model(&models.Account{}).Where("deleted_at < ?", cutoff).Pluck("username", &usernames).Error; err != nil {
+		log.Printf("Failed to fetch deleted accounts to purge: %v", err)
+	}
+	for _, username := range usernames {
+		// Fails, and is retried on the next run, while the account still organizes groups
+		if err := s.db.Transaction(func(tx *gorm.DB) error { return purgeAccount(tx, username) }); err != nil {
+			log.Printf("Failed to purge account %s: %v", username, err)
+		}
+	}
+}
+
+// purgeGroup permanently deletes a group and everything kept with it
+func purgeGroup(tx *gorm.DB, groupID string) error {
+	if err := tx.Where("group_id = ?", groupID).Delete(&models.Notification{}).Error; err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
+	}
+	polls := tx.Model(&models.Poll{}).Select("id").Where("group_id = ?", groupID)
+	for _, model := range []interface{}{&models.PollVote{}, &models.PollOption{}} {
+		if err := tx.Where("poll_id IN (?)", polls).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete %T rows: %w", model, err)
+		}
+	}
+	// Webhook deliveries still waiting in the outbox would report on a group that no longer exists
+	if err := tx.Where("kind = ? AND payload::jsonb #>> '{delivery,data,group_id}' = ?", outboxKindWebhook, groupID).
+		Delete(&models.OutboxEvent{}).Error; err != nil {
+		return fmt.Errorf("failed to delete queued webhooks: %w", err)
+	}
+	for _, model := range groupPurgeTables {
+		if err := tx.Unscoped().Where("group_id = ?", groupID).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete %T rows: %w", model, err)
+		}
+	}
+	return tx.Unscoped().Where("id = ?", groupID).Delete(&models.Group{}).Error
+}
+
+// purgeAccount permanently deletes an account with its sign-in credentials, memberships and activity,
+// and recounts the members of the groups they belonged to. Content they wrote in groups stays,
+// attributed to the username.
+func purgeAccount(tx *gorm.DB, username string) error {
+	var groupIDs []string
+	if err := tx.Unscoped().Model(&models.GroupMember{}).Where("username = ?", username).Pluck("group_id", &groupIDs).Error; err != nil {
+		return fmt.Errorf("failed to fetch memberships: %w", err)
+	}
+
+	for _, model := range []interface{}{
+		&models.Session{}, &models.APIKey{}, &models.WebhookSubscription{}, &models.ActivityLog{}, &models.GroupMember{},
+		&models.AnalyticsEvent{},
+	} {
+		if err := tx.Unscoped().Where("username = ?", username).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete %T rows: %w", model, err)
+		}
+	}
+	if err := RefreshMemberCounts(tx, groupIDs...); err != nil {
+		return fmt.Errorf("failed to refresh member counts: %w", err)
+	}
+	return tx.Unscoped().Where("username = ?", username).Delete(&models.Account{}).Error
+}
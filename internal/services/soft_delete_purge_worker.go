@@ -0,0 +1,31 @@
+package services
+
+import (
+	"time"
+)
+
+// SoftDeletePurgeWorker runs the purge once a day
+type SoftDeletePurgeWorker struct {
+	softDeleteService *SoftDeleteService
+	interval          time.Duration
+}
+
+func NewSoftDeletePurgeWorker() *SoftDeletePurgeWorker {
+	return &SoftDeletePurgeWorker{
+		softDeleteService: NewSoftDeleteService(),
+		interval:          24 * time.Hour, // Run once a day
+	}
+}
+
+func (w *SoftDeletePurgeWorker) Start() {
+	go w.run()
+}
+
+func (w *SoftDeletePurgeWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.softDeleteService.PurgeExpired()
+	}
+}
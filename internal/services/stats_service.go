@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ActivityStats is how many groups one activity type has had in a city, and how many people took part
+type ActivityStats struct {
+	models.ActivityDisplay
+	Groups         int64 `json:"groups"`
+	UpcomingGroups int64 `json:"upcoming_groups"`
+	Members        int64 `json:"members"` // Approved members, organizers included
+}
+
+// CityStats is how many groups a city has had, and how many people took part, by activity type
+type CityStats struct {
+	City           string          `json:"city"`
+	Groups         int64           `json:"groups"`
+	UpcomingGroups int64           `json:"upcoming_groups"`
+	Members        int64           `json:"members"` // Distinct people across the city's groups
+	Activities     []ActivityStats `json:"activities"`
+}
+
+// StatsBreakdown is every city's group and member counts, busiest city first
+type StatsBreakdown struct {
+	Cities      []CityStats `json:"cities"`
+	GeneratedAt time.Time   `json:"generated_at"`
+}
+
+// StatsService computes the public per-city and per-activity statistics. Only public groups that
+// weren't cancelled count. The breakdown is cached for STATS_CACHE_TTL (default 15m) since the
+// marketing site asks for it on every page view.
+type StatsService struct {
+	db       *gorm.DB
+	cacheTTL time.Duration
+
+	mu        sync.Mutex
+	breakdown *StatsBreakdown
+	expiresAt time.Time
+}
+
+var (
+	statsService     *StatsService
+	statsServiceOnce sync.Once
+)
+
+// GetStatsService returns the process-wide stats service; the cache only helps if it is shared
+func GetStatsService() *StatsService {
+	statsServiceOnce.Do(func() {
+		statsService = &StatsService{
+			db:       database.GetDB(),
+			cacheTTL: utils.GetEnvDuration("STATS_CACHE_TTL", 15*time.Minute),
+		}
+	})
+	return statsService
+}
+
+// GetBreakdown returns the cached breakdown, computing it if missing or stale
+func (s *StatsService) GetBreakdown() (StatsBreakdown, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.breakdown != nil && now.Before(s.expiresAt) {
+		return *s.breakdown, nil
+	}
+
+	breakdown, err := s.computeBreakdown(now)
+	if err != nil {
+		return StatsBreakdown{}, err
+	}
+	s.breakdown = &breakdown
+	s.expiresAt = now.Add(s.cacheTTL)
+	return breakdown, nil
+}
+
+// statsRow is the counts for one city, or one activity type in a city
+type statsRow struct {
+	City           string
+	ActivityType   string
+	Groups         int64
+	UpcomingGroups int64
+	Members        int64
+}
+
+// countGroups counts the held public groups and their distinct approved members per city, and per
+// activity type within each city when byActivity is set
+func (s *StatsService) countGroups(now time.Time, byActivity bool) ([]statsRow, error) {
+	activityType, groupBy := "''", `"group".city`
+	if byActivity {
+		activityType = `LOWER(TRIM("group".activity_type))`
+		groupBy += ", " + activityType
+	}
+
+	var rows []statsRow
+	err := s.db.Model(&models.Group{}).
+		Select(`"group".city, `+activityType+` AS activity_type,
+			COUNT(DISTINCT "group".id) AS groups,
+			COUNT(DISTINCT "group".id) FILTER (WHERE "group".date_time > ?) AS upcoming_groups,
+			COUNT(DISTINCT group_member.username) AS members`, now).
+		Joins(`LEFT JOIN group_member ON group_member.group_id = "group".id AND group_member.status = 'approved' AND group_member.deleted_at IS NULL`).
+		Where(`"group".city <> '' AND "group".status IN ? AND "group".visibility = ?`, models.GroupStatusesHeld, models.VisibilityPublic).
+		Group(groupBy).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (s *StatsService) computeBreakdown(now time.Time) (StatsBreakdown, error) {
+	cityRows, err := s.countGroups(now, false)
+	if err != nil {
+		return StatsBreakdown{}, fmt.Errorf("failed to count groups per city: %w", err)
+	}
+	activityRows, err := s.countGroups(now, true)
+	if err != nil {
+		return StatsBreakdown{}, fmt.Errorf("failed to count groups per activity type: %w", err)
+	}
+
+	cities := make(map[string]*CityStats, len(cityRows))
+	for _, row := range cityRows {
+		cities[row.City] = &CityStats{
+			City:           row.City,
+			Groups:         row.Groups,
+			UpcomingGroups: row.UpcomingGroups,
+			Members:        row.Members,
+			Activities:     []ActivityStats{},
+		}
+	}
+
+	// Aliases (e.g. "soccer") are counted under the type they display as. Someone who joined groups
+	// under two aliases in one city is counted under both.
+	activities := make(map[string]map[string]*ActivityStats)
+	for _, row := range activityRows {
+		if row.ActivityType == "" || cities[row.City] == nil {
+			continue
+		}
+		display := ActivityDisplayFor(row.ActivityType)
+		if activities[row.City] == nil {
+			activities[row.City] = make(map[string]*ActivityStats)
+		}
+		entry, ok := activities[row.City][display.ActivityType]
+		if !ok {
+			entry = &ActivityStats{ActivityDisplay: display}
+			activities[row.City][display.ActivityType] = entry
+		}
+		entry.Groups += row.Groups
+		entry.UpcomingGroups += row.UpcomingGroups
+		entry.Members += row.Members
+	}
+
+	breakdown := StatsBreakdown{Cities: make([]CityStats, 0, len(cities)), GeneratedAt: now}
+	for city, stats := range cities {
+		for _, entry := range activities[city] {
+			stats.Activities = append(stats.Activities, *entry)
+		}
+		sort.Slice(stats.Activities, func(i, j int) bool {
+			if stats.Activities[i].Groups != stats.Activities[j].Groups {
+				return stats.Activities[i].Groups > stats.Activities[j].Groups
+			}
+			return stats.Activities[i].ActivityType < stats.Activities[j].ActivityType
+		})
+		breakdown.Cities = append(breakdown.Cities, *stats)
+	}
+	sort.Slice(breakdown.Cities, func(i, j int) bool {
+		if breakdown.Cities[i].Groups != breakdown.Cities[j].Groups {
+			return breakdown.Cities[i].Groups > breakdown.Cities[j].Groups
+		}
+		return breakdown.Cities[i].City < breakdown.Cities[j].City
+	})
+	return breakdown, nil
+}
+
+// FindCity returns the breakdown's stats for a city, matched case-insensitively
+func (b StatsBreakdown) FindCity(city string) (CityStats, bool) {
+	for _, stats := range b.Cities {
+		if strings.EqualFold(stats.City, city) {
+			return stats, true
+		}
+	}
+	return CityStats{}, false
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StreakMilestones are the weekly streak lengths that earn a congratulatory notification
+var StreakMilestones = []int{4, 8, 12, 26, 52}
+
+// ActivityStreak summarizes a user's participation overall or in one activity type.
+// A week counts when the user attended at least one event in it (weeks start on Monday, UTC).
+type ActivityStreak struct {
+	ActivityType  string     `json:"activity_type,omitempty"`
+	Events        int        `json:"events"`
+	CurrentWeeks  int        `json:"current_streak_weeks"`
+	LongestWeeks  int        `json:"longest_streak_weeks"`
+	LastAttended  *time.Time `json:"last_attended,omitempty"`
+	activeWeekSet map[time.Time]bool
+}
+
+// ParticipationStats is the overall streak plus one streak per activity type, most attended first
+type ParticipationStats struct {
+	ActivityStreak
+	Activities []ActivityStreak `json:"activities"`
+}
+
+// StreakService computes participation streaks from attended (past, approved) groups
+type StreakService struct {
+	db *gorm.DB
+}
+
+func NewStreakService() *StreakService {
+	return &StreakService{
+		db: database.GetDB(),
+	}
+}
+
+// weekStart returns the Monday 00:00 UTC that begins t's week
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7 // Days since Monday
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// record adds one attended event to the streak
+func (s *ActivityStreak) record(at time.Time) {
+	s.Events++
+	if s.LastAttended == nil || at.After(*s.LastAttended) {
+		attended := at
+		s.LastAttended = &attended
+	}
+	if s.activeWeekSet == nil {
+		s.activeWeekSet = make(map[time.Time]bool)
+	}
+	s.activeWeekSet[weekStart(at)] = true
+}
+
+// finish works out the current and longest runs of consecutive active weeks. The current streak
+// survives a week that hasn't had an event yet, so it only breaks once a whole week is missed.
+func (s *ActivityStreak) finish(now time.Time) {
+	weeks := make([]time.Time, 0, len(s.activeWeekSet))
+	for week := range s.activeWeekSet {
+		weeks = append(weeks, week)
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+
+	run := 0
+	for i, week := range weeks {
+		if i > 0 && week.Sub(weeks[i-1]) == 7*24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		if run > s.LongestWeeks {
+			s.LongestWeeks = run
+		}
+	}
+
+	thisWeek := weekStart(now)
+	if len(weeks) > 0 {
+		if last := weeks[len(weeks)-1]; last.Equal(thisWeek) || last.Equal(thisWeek.AddDate(0, 0, -7)) {
+			s.CurrentWeeks = run
+		}
+	}
+}
+
+// GetParticipationStats returns the user's overall and per-activity participation streaks
+func (s *StreakService) GetParticipationStats(username string) (ParticipationStats, error) {
+	var attended []struct {
+		DateTime     time.Time
+		ActivityType string
+	}
+	if err := s.db.Model(&models.GroupMember{}).
+		Select(`"group".date_time, LOWER(TRIM("group".activity_type)) AS activity_type`).
+		Joins(`JOIN "group" ON "group".id = group_member.group_id AND "group".deleted_at IS NULL`).
+		Where(`group_member.username = ? AND group_member.status = ? AND "group".date_time < ?`, username, "approved", time.Now()).
+		Where(`"group".status IN ?`, models.GroupStatusesHeld). // Cancelled events weren't attended
+		Scan(&attended).Error; err != nil {
+		return ParticipationStats{}, fmt.Errorf("failed to fetch attended groups: %w", err)
+	}
+
+	now := time.Now()
+	stats := ParticipationStats{Activities: []ActivityStreak{}}
+	byActivity := make(map[string]*ActivityStreak)
+	for _, event := range attended {
+		stats.record(event.DateTime)
+		streak, ok := byActivity[event.ActivityType]
+		if !ok {
+			streak = &ActivityStreak{ActivityType: event.ActivityType}
+			byActivity[event.ActivityType] = streak
+		}
+		streak.record(event.DateTime)
+	}
+
+	stats.finish(now)
+	for _, streak := range byActivity {
+		streak.finish(now)
+		stats.Activities = append(stats.Activities, *streak)
+	}
+	sort.Slice(stats.Activities, func(i, j int) bool {
+		if stats.Activities[i].Events != stats.Activities[j].Events {
+			return stats.Activities[i].Events > stats.Activities[j].Events
+		}
+		return stats.Activities[i].ActivityType < stats.Activities[j].ActivityType
+	})
+
+	return stats, nil
+}
+
+// CelebrateMilestones records milestones the user's current streaks have reached and notifies them
+// about the highest new one per streak; milestones reached earlier are never announced twice
+func (s *StreakService) CelebrateMilestones(username string) error {
+	stats, err := s.GetParticipationStats(username)
+	if err != nil {
+		return err
+	}
+
+	streaks := append([]ActivityStreak{stats.ActivityStreak}, stats.Activities...)
+	for _, streak := range streaks {
+		highest := 0
+		for _, weeks := range StreakMilestones {
+			if streak.CurrentWeeks < weeks {
+				break
+			}
+			milestone := models.StreakMilestone{
+				Username:     username,
+				ActivityType: streak.ActivityType,
+				Weeks:        weeks,
+				ReachedAt:    time.Now(),
+			}
+			result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&milestone)
+			if result.Error != nil {
+				return fmt.Errorf("failed to record %d week milestone: %w", weeks, result.Error)
+			}
+			if result.RowsAffected > 0 {
+				highest = weeks
+			}
+		}
+		if highest == 0 {
+			continue
+		}
+
+		msg := fmt.Sprintf("You've joined a groop %d weeks in a row - keep it up!", highest)
+		if streak.ActivityType != "" {
+			msg = fmt.Sprintf("%d weeks of %s in a row - nice streak!", highest, ActivityDisplayFor(streak.ActivityType).Label)
+		}
+		if err := createNotification(s.db, username, "streak_milestone", msg, ""); err != nil {
+			log.Printf("Warning: Failed to create streak notification for %s: %v", username, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"groops/internal/database"
+	"groops/internal/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StreakWorker struct {
+	db            *gorm.DB
+	streakService *StreakService
+	interval      time.Duration
+	lastRun       time.Time
+}
+
+func NewStreakWorker() *StreakWorker {
+	interval := time.Hour // Check every hour
+	return &StreakWorker{
+		db:            database.GetDB(),
+		streakService: NewStreakService(),
+		interval:      interval,
+		lastRun:       time.Now().Add(-interval),
+	}
+}
+
+func (w *StreakWorker) Start() {
+	go w.run()
+}
+
+func (w *StreakWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.checkMilestones()
+	}
+}
+
+// checkMilestones looks for new streak milestones among members of events that ended since the last run
+func (w *StreakWorker) checkMilestones() {
+	now := time.Now()
+
+	var usernames []string
+	if err := w.db.Model(&models.GroupMember{}).
+		Distinct("group_member.username").
+		Joins(`JOIN "group" ON "group".id = group_member.group_id`).
+		Where(`group_member.status = ? AND "group".status IN ? AND COALESCE("group".end_date_time, "group".date_time) > ? AND COALESCE("group".end_date_time, "group".date_time) <= ?`, "approved", models.GroupStatusesHeld, w.lastRun, now).
+		Pluck("group_member.username", &usernames).Error; err != nil {
+		log.Printf("Failed to fetch recent attendees for streaks: %v", err)
+		return
+	}
+	w.lastRun = now
+
+	for _, username := range usernames {
+		if err := w.streakService.CelebrateMilestones(username); err != nil {
+			log.Printf("Failed to check streak milestones for %s: %v", username, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"groops/internal/utils"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// System modes, set with the system.mode setting or SYSTEM_MODE; anything else runs normally
+const (
+	SystemModeNormal      = ""
+	SystemModeMaintenance = "maintenance" // Every request gets 503
+	SystemModeReadOnly    = "read_only"   // Reads work, writes get 503
+)
+
+// Error codes returned while the API is switched out of normal mode
+const (
+	ErrCodeMaintenance = "MAINTENANCE"
+	ErrCodeReadOnly    = "READ_ONLY"
+)
+
+// SystemStatus is the mode the API is in and how long clients should wait before retrying
+type SystemStatus struct {
+	Mode       string
+	RetryAfter time.Duration
+	Message    string // Overrides the default message shown to users, e.g. with the expected end time
+}
+
+// SystemModeService reads the maintenance and read-only switches. They are checked on every request,
+// so the status is cached for SYSTEM_MODE_CHECK_INTERVAL (default 10s) rather than read from the
+// database each time; a switch flipped in app_setting takes effect within that interval. While the
+// database is unreachable the switches fall back to the environment.
+type SystemModeService struct {
+	checkInterval time.Duration
+
+	mu         sync.Mutex
+	status     SystemStatus
+	checkedAt  time.Time
+	refreshing bool // A request is reading the switches again
+}
+
+var (
+	systemModeService     *SystemModeService
+	systemModeServiceOnce sync.Once
+)
+
+// GetSystemModeService returns the process-wide service; the cache only helps if it is shared
+func GetSystemModeService() *SystemModeService {
+	systemModeServiceOnce.Do(func() {
+		systemModeService = &SystemModeService{
+			checkInterval: utils.GetEnvDuration("SYSTEM_MODE_CHECK_INTERVAL", 10*time.Second),
+		}
+	})
+	return systemModeService
+}
+
+// Status returns the cached system status, reading the switches again once the cache is stale.
+// The database is read without holding the lock, and only by one request at a time; the others keep
+// the cached status meanwhile rather than queueing behind a slow query.
+func (s *SystemModeService) Status() SystemStatus {
+	s.mu.Lock()
+	now := time.Now()
+	checked := !s.checkedAt.IsZero()
+	if checked && (s.refreshing || now.Sub(s.checkedAt) < s.checkInterval) {
+		status := s.status
+		s.mu.Unlock()
+		return status
+	}
+	s.refreshing = true
+	s.mu.Unlock()
+
+	status := readSystemStatus()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	if status.Mode != s.status.Mode {
+		log.Printf("System mode changed from %q to %q", s.status.Mode, status.Mode)
+	}
+	s.status = status
+	s.checkedAt = now
+	return status
+}
+
+// readSystemStatus reads the switches from app_setting, falling back to the environment
+func readSystemStatus() SystemStatus {
+	settings := NewSettingsService()
+	status := SystemStatus{
+		Mode:       strings.ToLower(strings.TrimSpace(settings.GetString("system.mode", "SYSTEM_MODE", SystemModeNormal))),
+		RetryAfter: settings.GetDuration("system.retry_after", "SYSTEM_MODE_RETRY_AFTER", 5*time.Minute),
+		Message:    strings.TrimSpace(settings.GetString("system.mode_message", "SYSTEM_MODE_MESSAGE", "")),
+	}
+	switch status.Mode {
+	case SystemModeNormal, SystemModeMaintenance, SystemModeReadOnly:
+	default:
+		log.Printf("Warning: Ignoring unknown system mode %q", status.Mode)
+		status.Mode = SystemModeNormal
+	}
+	return status
+}
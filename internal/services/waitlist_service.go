@@ -0,0 +1,170 @@
+package services
+
+import (
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"groops/internal/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WaitlistService struct {
+	db           *gorm.DB
+	emailService *EmailService
+	claimWindow  time.Duration
+}
+
+func NewWaitlistService() *WaitlistService {
+	return &WaitlistService{
+		db:           database.GetDB(),
+		emailService: NewEmailService(),
+		claimWindow:  utils.GetEnvDuration("WAITLIST_CLAIM_WINDOW", 2*time.Hour),
+	}
+}
+
+// CountActiveOffers returns how many unexpired spot offers are outstanding for a group,
+// ignoring any offer held by excludeUsername
+func (s *WaitlistService) CountActiveOffers(groupID, excludeUsername string) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.WaitlistEntry{}).
+		Where("group_id = ? AND status = ? AND offer_expires_at > ? AND username != ?",
+			groupID, models.WaitlistStatusOffered, time.Now(), excludeUsername).
+		Count(&count).Error
+	return count, err
+}
+
+// OnCapacityAvailable offers newly opened spots to waitlisted users in order and
+// lets watchers know the group has room again
+func (s *WaitlistService) OnCapacityAvailable(groupID string) {
+	// The group row is locked so concurrent calls (a leave and a removal, say) count the open
+	// spots one after the other instead of offering the same ones twice
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", groupID).First(&group).Error; err != nil {
+			return fmt.Errorf("failed to load group: %w", err)
+		}
+		return s.withTx(tx).offerSpots(group)
+	}); err != nil {
+		log.Printf("Warning: Failed to offer waitlist spots for group %s: %v", groupID, err)
+	}
+}
+
+// withTx returns a copy of the service that works through tx and queues its emails in the outbox
+func (s *WaitlistService) withTx(tx *gorm.DB) *WaitlistService {
+	copied := *s
+	copied.db = tx
+	copied.emailService = s.emailService.WithOutbox(tx)
+	return &copied
+}
+
+// offerSpots does the work of OnCapacityAvailable for a locked group
+func (s *WaitlistService) offerSpots(group models.Group) error {
+	groupID := group.ID
+
+	// Spots aren't offered once joining has closed
+	if IsPastCutoff(group) {
+		return nil
+	}
+
+	activeOffers, err := s.CountActiveOffers(groupID, "")
+	if err != nil {
+		return fmt.Errorf("failed to count waitlist offers: %w", err)
+	}
+
+	openSpots := group.MaxMembers - group.OccupiedSpots() - int(activeOffers)
+	if openSpots <= 0 {
+		return nil
+	}
+
+	// Offer spots to the longest-waiting users first
+	var next []models.WaitlistEntry
+	if err := s.db.Where("group_id = ? AND type = ? AND status = ?", groupID, models.WaitlistTypeWaitlist, models.WaitlistStatusWaiting).
+		Order("created_at ASC").Limit(openSpots).Find(&next).Error; err != nil {
+		return fmt.Errorf("failed to fetch waitlist: %w", err)
+	}
+
+	now := time.Now()
+	expires := now.Add(s.claimWindow)
+	for _, entry := range next {
+		// Only entries still waiting are offered, so nobody hears about the same spot twice
+		result := s.db.Model(&entry).Where("status = ?", models.WaitlistStatusWaiting).Updates(map[string]interface{}{
+			"status":           models.WaitlistStatusOffered,
+			"offered_at":       now,
+			"offer_expires_at": expires,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to offer spot to %s: %w", entry.Username, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		msg := fmt.Sprintf("A spot opened up in '%s'! Request to join before %s to claim it.", group.Name, convertToIST(expires).Format("Jan 2, 3:04 PM")+" IST")
+		if err := createNotification(s.db, entry.Username, "waitlist_offer", msg, groupID); err != nil {
+			return fmt.Errorf("failed to create waitlist offer notification: %w", err)
+		}
+
+		var account models.Account
+		if err := s.db.Where("username = ?", entry.Username).Limit(1).Find(&account).Error; err != nil {
+			return fmt.Errorf("failed to load account of %s: %w", entry.Username, err)
+		}
+		if account.Username != "" {
+			if err := s.emailService.SendWaitlistOfferEmail(account.Email, account.Username, group.Name, expires); err != nil {
+				return fmt.Errorf("failed to queue waitlist offer email to %s: %w", entry.Username, err)
+			}
+		}
+	}
+
+	// Watchers only hear about capacity that hasn't been offered to someone in the queue
+	if openSpots > len(next) {
+		var watchers []models.WaitlistEntry
+		if err := s.db.Where("group_id = ? AND type = ? AND status = ?", groupID, models.WaitlistTypeWatch, models.WaitlistStatusWaiting).
+			Order("created_at ASC").Find(&watchers).Error; err != nil {
+			return fmt.Errorf("failed to fetch watchers: %w", err)
+		}
+		msg := fmt.Sprintf("'%s' has room again - request to join now", group.Name)
+		for _, watcher := range watchers {
+			if err := createNotification(s.db, watcher.Username, "capacity_available", msg, groupID); err != nil {
+				return fmt.Errorf("failed to create capacity notification for %s: %w", watcher.Username, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MarkClaimed records that a user took up their offer by becoming an approved member. A pending
+// join request keeps the offer, so the spot stays held until an organizer decides.
+func (s *WaitlistService) MarkClaimed(groupID, username string) {
+	if err := s.db.Model(&models.WaitlistEntry{}).
+		Where("group_id = ? AND username = ?", groupID, username).
+		Update("status", models.WaitlistStatusClaimed).Error; err != nil {
+		log.Printf("Warning: Failed to mark waitlist entry claimed for %s: %v", username, err)
+	}
+}
+
+// ExpireOffers expires unclaimed offers and passes the spots to the next person in line
+func (s *WaitlistService) ExpireOffers() {
+	var expired []models.WaitlistEntry
+	if err := s.db.Where("status = ? AND offer_expires_at <= ?", models.WaitlistStatusOffered, time.Now()).
+		Find(&expired).Error; err != nil {
+		log.Printf("Failed to fetch expired waitlist offers: %v", err)
+		return
+	}
+
+	groupIDs := make(map[string]bool)
+	for _, entry := range expired {
+		if err := s.db.Model(&entry).Update("status", models.WaitlistStatusExpired).Error; err != nil {
+			log.Printf("Failed to expire waitlist offer %d: %v", entry.ID, err)
+			continue
+		}
+		groupIDs[entry.GroupID] = true
+	}
+
+	for groupID := range groupIDs {
+		s.OnCapacityAvailable(groupID)
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"time"
+)
+
+type WaitlistWorker struct {
+	waitlistService *WaitlistService
+	interval        time.Duration
+}
+
+func NewWaitlistWorker() *WaitlistWorker {
+	return &WaitlistWorker{
+		waitlistService: NewWaitlistService(),
+		interval:        time.Minute, // Check every minute
+	}
+}
+
+func (w *WaitlistWorker) Start() {
+	go w.run()
+}
+
+func (w *WaitlistWorker) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.waitlistService.ExpireOffers()
+	}
+}
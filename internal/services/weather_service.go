@@ -0,0 +1,187 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"groops/internal/database"
+	"groops/internal/models"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// WeatherForecastHorizon is how far ahead forecasts are available for events
+	WeatherForecastHorizon = 7 * 24 * time.Hour
+	// weatherCacheTTL is how long a cached forecast is considered fresh
+	weatherCacheTTL = 3 * time.Hour
+	// defaultWeatherAPIURL is the Open-Meteo forecast endpoint (no API key required)
+	defaultWeatherAPIURL = "https://api.open-meteo.com/v1/forecast"
+)
+
+var ErrForecastUnavailable = errors.New("forecast not available for the requested time")
+
+type WeatherService struct {
+	db         *gorm.DB
+	httpClient *http.Client
+	baseURL    string
+}
+
+func NewWeatherService() *WeatherService {
+	baseURL := os.Getenv("WEATHER_API_URL")
+	if baseURL == "" {
+		baseURL = defaultWeatherAPIURL
+	}
+
+	return &WeatherService{
+		db:         database.GetDB(),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		baseURL:    baseURL,
+	}
+}
+
+// openMeteoResponse is the subset of the Open-Meteo hourly forecast response we use
+type openMeteoResponse struct {
+	Hourly struct {
+		Time                     []string  `json:"time"`
+		Temperature              []float64 `json:"temperature_2m"`
+		PrecipitationProbability []int     `json:"precipitation_probability"`
+		WeatherCode              []int     `json:"weathercode"`
+		WindSpeed                []float64 `json:"windspeed_10m"`
+	} `json:"hourly"`
+}
+
+// GetForecast returns the forecast for a location at the given time, using the cache when fresh
+func (s *WeatherService) GetForecast(lat, lng float64, at time.Time) (*models.WeatherForecast, error) {
+	if time.Until(at) > WeatherForecastHorizon || time.Now().After(at) {
+		return nil, ErrForecastUnavailable
+	}
+
+	// Round coordinates (~1km) and time (to the hour) so nearby events share cache entries
+	lat = math.Round(lat*100) / 100
+	lng = math.Round(lng*100) / 100
+	hour := at.UTC().Truncate(time.Hour)
+
+	var cached models.WeatherForecast
+	err := s.db.Where("latitude = ? AND longitude = ? AND forecast_time = ?", lat, lng, hour).First(&cached).Error
+	if err == nil && time.Since(cached.FetchedAt) < weatherCacheTTL {
+		return &cached, nil
+	}
+
+	forecast, fetchErr := s.fetchForecast(lat, lng, hour)
+	if fetchErr != nil {
+		// Serve a stale forecast rather than nothing if the provider is down
+		if err == nil {
+			return &cached, nil
+		}
+		return nil, fetchErr
+	}
+
+	if err == nil {
+		forecast.ID = cached.ID
+	}
+	if err := s.db.Save(forecast).Error; err != nil {
+		return forecast, fmt.Errorf("failed to cache forecast: %w", err)
+	}
+
+	return forecast, nil
+}
+
+// fetchForecast requests the hourly forecast for the day of the given hour from the provider
+func (s *WeatherService) fetchForecast(lat, lng float64, hour time.Time) (*models.WeatherForecast, error) {
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%.2f", lat))
+	params.Set("longitude", fmt.Sprintf("%.2f", lng))
+	params.Set("hourly", "temperature_2m,precipitation_probability,weathercode,windspeed_10m")
+	params.Set("timezone", "UTC")
+	params.Set("start_date", hour.Format("2006-01-02"))
+	params.Set("end_date", hour.Format("2006-01-02"))
+
+	resp, err := s.httpClient.Get(s.baseURL + "?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forecast: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("weather provider returned status %d", resp.StatusCode)
+	}
+
+	var data openMeteoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode forecast: %w", err)
+	}
+
+	target := hour.Format("2006-01-02T15:04")
+	for i, t := range data.Hourly.Time {
+		if t != target {
+			continue
+		}
+		if i >= len(data.Hourly.Temperature) || i >= len(data.Hourly.PrecipitationProbability) ||
+			i >= len(data.Hourly.WeatherCode) || i >= len(data.Hourly.WindSpeed) {
+			break
+		}
+		code := data.Hourly.WeatherCode[i]
+		return &models.WeatherForecast{
+			Latitude:                 lat,
+			Longitude:                lng,
+			ForecastTime:             hour,
+			TemperatureC:             data.Hourly.Temperature[i],
+			PrecipitationProbability: data.Hourly.PrecipitationProbability[i],
+			WindSpeedKmh:             data.Hourly.WindSpeed[i],
+			WeatherCode:              code,
+			Summary:                  weatherCodeSummary(code),
+			FetchedAt:                time.Now(),
+		}, nil
+	}
+
+	return nil, ErrForecastUnavailable
+}
+
+// BadWeatherWarning returns a human-readable warning if the forecast looks bad for an outdoor event
+func BadWeatherWarning(f *models.WeatherForecast) string {
+	if f == nil {
+		return ""
+	}
+	switch {
+	case f.WeatherCode >= 95:
+		return "Thunderstorms are forecast around the event time."
+	case (f.WeatherCode >= 71 && f.WeatherCode <= 77) || f.WeatherCode == 85 || f.WeatherCode == 86:
+		return "Snow is forecast around the event time."
+	case f.WeatherCode == 65 || f.WeatherCode == 82 || f.PrecipitationProbability >= 60:
+		return fmt.Sprintf("Rain is likely around the event time (%d%% chance).", f.PrecipitationProbability)
+	case f.WindSpeedKmh >= 40:
+		return fmt.Sprintf("Strong winds are forecast around the event time (%.0f km/h).", f.WindSpeedKmh)
+	}
+	return ""
+}
+
+// weatherCodeSummary maps WMO weather codes to a short description
+func weatherCodeSummary(code int) string {
+	switch {
+	case code == 0:
+		return "Clear sky"
+	case code <= 3:
+		return "Partly cloudy"
+	case code == 45 || code == 48:
+		return "Fog"
+	case code >= 51 && code <= 57:
+		return "Drizzle"
+	case code >= 61 && code <= 67:
+		return "Rain"
+	case code >= 71 && code <= 77:
+		return "Snow"
+	case code >= 80 && code <= 82:
+		return "Rain showers"
+	case code == 85 || code == 86:
+		return "Snow showers"
+	case code >= 95:
+		return "Thunderstorm"
+	}
+	return "Unknown"
+}
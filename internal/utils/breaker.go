@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of calling a dependency whose circuit breaker is open
+var ErrCircuitOpen = errors.New("service temporarily unavailable")
+
+// CircuitBreaker stops calling a dependency after it fails BREAKER_FAILURE_THRESHOLD times in a row
+// (default 5). Calls then fail fast with ErrCircuitOpen until BREAKER_COOLDOWN (default 30s) has
+// passed, when a single trial call decides whether to close the breaker again.
+type CircuitBreaker struct {
+	name string
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool // A trial call is in flight after the cooldown
+}
+
+// NewCircuitBreaker returns a closed breaker for the named dependency. Settings are read on use,
+// so package-level breakers see the environment loaded at startup.
+func NewCircuitBreaker(name string) *CircuitBreaker {
+	return &CircuitBreaker{name: name}
+}
+
+// breakerThreshold is how many consecutive failures open a breaker
+func breakerThreshold() int {
+	if threshold := GetEnvInt("BREAKER_FAILURE_THRESHOLD", 5); threshold > 0 {
+		return threshold
+	}
+	return 1
+}
+
+// Allow reports whether a call may go ahead. Every allowed call must be followed by Record.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < breakerThreshold() {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// Record reports the outcome of an allowed call. A cancelled caller says nothing about the dependency.
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	threshold := breakerThreshold()
+	if err == nil {
+		if b.failures >= threshold {
+			log.Printf("Circuit breaker for %s closed", b.name)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= threshold {
+		if b.failures == threshold {
+			log.Printf("Warning: Circuit breaker for %s opened after %d failures: %v", b.name, b.failures, err)
+		}
+		b.openUntil = time.Now().Add(GetEnvDuration("BREAKER_COOLDOWN", 30*time.Second))
+	}
+}
+
+// Open reports whether calls are currently being refused
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= breakerThreshold() && (time.Now().Before(b.openUntil) || b.trial)
+}
+
+// Do runs fn if the breaker allows it and records the result, or returns ErrCircuitOpen
+func (b *CircuitBreaker) Do(fn func() error) error {
+	return b.DoCounting(fn, func(err error) bool { return true })
+}
+
+// DoCounting is Do where only errors isOutage accepts count as failures, so e.g. a "not found"
+// from a healthy dependency doesn't open the breaker
+func (b *CircuitBreaker) DoCounting(fn func() error, isOutage func(error) bool) error {
+	if !b.Allow() {
+		return ErrCircuitOpen
+	}
+	err := fn()
+	if err != nil && !isOutage(err) {
+		b.Record(nil)
+	} else {
+		b.Record(err)
+	}
+	return err
+}
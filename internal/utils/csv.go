@@ -0,0 +1,15 @@
+package utils
+
+import "strings"
+
+// csvFormulaPrefixes are the leading characters spreadsheets treat as the start of a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// CSVSafe escapes a user-provided CSV cell so spreadsheets open it as text instead of running
+// it as a formula
+func CSVSafe(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
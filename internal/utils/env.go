@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetEnvInt returns an integer environment variable, or the fallback if unset or invalid
+func GetEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: Invalid integer for %s (%q), using default %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// GetEnvFloat returns a float environment variable, or the fallback if unset or invalid
+func GetEnvFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Warning: Invalid number for %s (%q), using default %v", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// GetEnvDuration returns a duration environment variable (e.g. "90m", "24h"), or the fallback if unset or invalid
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: Invalid duration for %s (%q), using default %v", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// GetEnvBool returns a boolean environment variable ("true", "0", ...), or the fallback if unset or invalid
+func GetEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: Invalid boolean for %s (%q), using default %v", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Errors returned by SafeFetch so callers can tell a refused fetch from a failed one
+var (
+	ErrBlockedAddress        = errors.New("destination address is not allowed")
+	ErrContentTypeNotAllowed = errors.New("response content type is not allowed")
+	ErrResponseTooLarge      = errors.New("response is too large")
+)
+
+// maxFetchRedirects bounds how many redirects a safe fetch follows
+const maxFetchRedirects = 3
+
+// blockedNetworks are ranges that aren't covered by net.IP's own classification helpers
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "This" network
+	"100.64.0.0/10", // Carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved
+	"64:ff9b::/96",  // NAT64, which can reach private IPv4 addresses
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+// IsPublicIP reports whether an address is publicly routable, i.e. not loopback, private,
+// link-local, multicast or otherwise reserved
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSafeHTTPClient returns an HTTP client that refuses to connect to non-public addresses.
+// The check runs on the resolved address at connect time, so DNS rebinding and redirects
+// to internal hosts are blocked too. Environment proxies are ignored for the same reason.
+func NewSafeHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to %s URL", ErrBlockedAddress, req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// FetchOptions limits what SafeFetch will download
+type FetchOptions struct {
+	Timeout  time.Duration
+	MaxBytes int64
+	// Accepted Content-Type prefixes (e.g. "image/"); empty accepts any type
+	AllowedContentTypes []string
+}
+
+// FetchResult is a response downloaded by SafeFetch
+type FetchResult struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// SafeFetch GETs a user-supplied http(s) URL without reaching internal addresses, enforcing the
+// timeout, size limit and content-type allowlist in opts. Non-2xx responses are returned with an
+// empty body and no error so callers can map them to their own status.
+func SafeFetch(rawURL string, opts FetchOptions) (*FetchResult, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("%w: only http and https URLs can be fetched", ErrBlockedAddress)
+	}
+
+	resp, err := NewSafeHTTPClient(opts.Timeout).Get(parsed.String())
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, ErrBlockedAddress
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &FetchResult{StatusCode: resp.StatusCode, ContentType: resp.Header.Get("Content-Type")}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, nil
+	}
+
+	if len(opts.AllowedContentTypes) > 0 {
+		allowed := false
+		contentType := strings.ToLower(result.ContentType)
+		for _, prefix := range opts.AllowedContentTypes {
+			if strings.HasPrefix(contentType, prefix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%w: %q", ErrContentTypeNotAllowed, result.ContentType)
+		}
+	}
+
+	if opts.MaxBytes > 0 && resp.ContentLength > opts.MaxBytes {
+		return nil, ErrResponseTooLarge
+	}
+	reader := io.Reader(resp.Body)
+	if opts.MaxBytes > 0 {
+		reader = io.LimitReader(resp.Body, opts.MaxBytes+1)
+	}
+	result.Body, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if opts.MaxBytes > 0 && int64(len(result.Body)) > opts.MaxBytes {
+		return nil, ErrResponseTooLarge
+	}
+
+	return result, nil
+}
@@ -1,43 +1,148 @@
 package utils
 
 import (
+	"log"
+	"net"
+	"net/http"
+	"os"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 )
 
-// GetRealClientIP extracts the real client IP from the request headers
-// It prioritizes X-Real-IP, then X-Forwarded-For, and finally falls back to c.ClientIP()
-func GetRealClientIP(c *gin.Context) string {
-	// Try X-Real-IP header first (most reliable)
-	if ip := c.GetHeader("X-Real-IP"); ip != "" {
-		return ip
-	}
+// defaultTrustedProxies covers loopback and private ranges, where platform load balancers
+// (e.g. Railway's edge) connect from. Override with a comma-separated TRUSTED_PROXIES list.
+const defaultTrustedProxies = "127.0.0.0/8,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,100.64.0.0/10,::1/128,fc00::/7"
+
+var (
+	trustedProxyOnce     sync.Once
+	trustedProxyCIDRs    []string
+	trustedProxyNetworks []*net.IPNet
+)
 
-	// Try X-Forwarded-For header next
-	if xff := c.GetHeader("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
+// loadTrustedProxies parses TRUSTED_PROXIES once. Entries may be CIDRs or single IPs;
+// invalid entries are skipped with a warning.
+func loadTrustedProxies() {
+	trustedProxyOnce.Do(func() {
+		value := os.Getenv("TRUSTED_PROXIES")
+		if value == "" {
+			value = defaultTrustedProxies
 		}
-	}
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			if !strings.Contains(entry, "/") {
+				ip := net.ParseIP(entry)
+				if ip == nil {
+					log.Printf("Warning: Ignoring invalid trusted proxy %q", entry)
+					continue
+				}
+				if ip.To4() != nil {
+					entry += "/32"
+				} else {
+					entry += "/128"
+				}
+			}
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				log.Printf("Warning: Ignoring invalid trusted proxy %q", entry)
+				continue
+			}
+			trustedProxyCIDRs = append(trustedProxyCIDRs, network.String())
+			trustedProxyNetworks = append(trustedProxyNetworks, network)
+		}
+	})
+}
 
-	// Check if c.Request is available
-	if c.Request != nil {
-		// Try to get IP from RemoteAddr directly
-		if c.Request.RemoteAddr != "" {
-			// RemoteAddr is typically in the format "IP:port"
-			ip := strings.Split(c.Request.RemoteAddr, ":")[0]
-			return ip
+// TrustedProxies returns the configured trusted proxy CIDRs, for passing to gin's SetTrustedProxies
+func TrustedProxies() []string {
+	loadTrustedProxies()
+	return trustedProxyCIDRs
+}
+
+// isTrustedProxy reports whether an address belongs to a configured trusted proxy
+func isTrustedProxy(ip net.IP) bool {
+	loadTrustedProxies()
+	for _, network := range trustedProxyNetworks {
+		if network.Contains(ip) {
+			return true
 		}
 	}
+	return false
+}
+
+// ClientIPFromRequest returns the address of the client that made a request. Forwarding headers
+// are only believed when the connection comes from a trusted proxy, and X-Forwarded-For is read
+// right to left, skipping trusted hops, so a client can't spoof its address by sending the header.
+func ClientIPFromRequest(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+	remote := net.ParseIP(remoteIP)
+	if remote == nil || !isTrustedProxy(remote) {
+		return remoteIP
+	}
 
-	// Try ClientIP as a last resort, with panic recovery
-	defer func() {
-		if r := recover(); r != nil {
-			// If we panic here, just return a fallback IP
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		closest := remoteIP
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				// Nothing left of a malformed hop can be believed
+				return closest
+			}
+			if i == 0 || !isTrustedProxy(hop) {
+				return hop.String()
+			}
+			closest = hop.String()
 		}
-	}()
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return remoteIP
+}
+
+// ClientCountryFromRequest returns the two-letter country a trusted proxy resolved the client's IP to,
+// read from the IP_COUNTRY_HEADER header (default CF-IPCountry), or "" when it isn't known
+func ClientCountryFromRequest(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
 
-	return c.ClientIP()
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+	if remote := net.ParseIP(remoteIP); remote == nil || !isTrustedProxy(remote) {
+		return ""
+	}
+
+	header := os.Getenv("IP_COUNTRY_HEADER")
+	if header == "" {
+		header = "CF-IPCountry"
+	}
+	country := strings.ToUpper(strings.TrimSpace(r.Header.Get(header)))
+	// XX and T1 are Cloudflare's unknown and Tor markers
+	if len(country) != 2 || country == "XX" || country == "T1" {
+		return ""
+	}
+	return country
+}
+
+// GetRealClientIP extracts the real client IP for a request, honouring forwarding
+// headers only from trusted proxies (see ClientIPFromRequest)
+func GetRealClientIP(c *gin.Context) string {
+	return ClientIPFromRequest(c.Request)
 }
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric names exposed in the Prometheus text format
+const (
+	MetricRequestDuration = "groops_http_request_duration_seconds"
+	MetricRequestsTotal   = "groops_http_requests_total"
+	MetricRequestErrors   = "groops_http_request_errors_total"
+)
+
+// LatencyBuckets are the request duration histogram bounds in seconds. SLO latency thresholds
+// should sit on one of these so histogram_quantile doesn't have to interpolate across a bucket.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// routeKey identifies one route template; raw paths are never used so IDs don't create new series
+type routeKey struct {
+	Method string
+	Route  string
+}
+
+// routeStats is the histogram and counters for one route
+type routeStats struct {
+	buckets  []uint64 // Non-cumulative counts per LatencyBuckets bound, plus +Inf
+	sum      float64
+	count    uint64
+	statuses map[int]uint64
+	errors   uint64 // 5xx responses
+}
+
+var (
+	requestMetricsMu sync.Mutex
+	requestMetrics   = make(map[routeKey]*routeStats)
+)
+
+// ObserveRequest records one request against its route template
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	seconds := duration.Seconds()
+	key := routeKey{Method: method, Route: route}
+
+	requestMetricsMu.Lock()
+	defer requestMetricsMu.Unlock()
+
+	stats, ok := requestMetrics[key]
+	if !ok {
+		stats = &routeStats{buckets: make([]uint64, len(LatencyBuckets)+1), statuses: make(map[int]uint64)}
+		requestMetrics[key] = stats
+	}
+	stats.buckets[sort.SearchFloat64s(LatencyBuckets, seconds)]++
+	stats.sum += seconds
+	stats.count++
+	stats.statuses[status]++
+	if status >= 500 {
+		stats.errors++
+	}
+}
+
+// escapeLabel escapes a label value for the Prometheus text format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// WriteRequestMetrics writes the per-route request metrics in the Prometheus text exposition format
+func WriteRequestMetrics(w io.Writer) error {
+	requestMetricsMu.Lock()
+	keys := make([]routeKey, 0, len(requestMetrics))
+	snapshot := make(map[routeKey]routeStats, len(requestMetrics))
+	for key, stats := range requestMetrics {
+		keys = append(keys, key)
+		copied := *stats
+		copied.buckets = append([]uint64(nil), stats.buckets...)
+		copied.statuses = make(map[int]uint64, len(stats.statuses))
+		for status, count := range stats.statuses {
+			copied.statuses[status] = count
+		}
+		snapshot[key] = copied
+	}
+	requestMetricsMu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Route != keys[j].Route {
+			return keys[i].Route < keys[j].Route
+		}
+		return keys[i].Method < keys[j].Method
+	})
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# HELP %s Request latency by route template.\n# TYPE %s histogram\n", MetricRequestDuration, MetricRequestDuration)
+	for _, key := range keys {
+		stats := snapshot[key]
+		labels := fmt.Sprintf(`method="%s",route="%s"`, escapeLabel(key.Method), escapeLabel(key.Route))
+		var cumulative uint64
+		for i, bound := range LatencyBuckets {
+			cumulative += stats.buckets[i]
+			fmt.Fprintf(&b, "%s_bucket{%s,le=\"%s\"} %d\n", MetricRequestDuration, labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", MetricRequestDuration, labels, stats.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", MetricRequestDuration, labels, formatFloat(stats.sum))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", MetricRequestDuration, labels, stats.count)
+	}
+
+	fmt.Fprintf(&b, "# HELP %s Requests by route template and status code.\n# TYPE %s counter\n", MetricRequestsTotal, MetricRequestsTotal)
+	for _, key := range keys {
+		stats := snapshot[key]
+		statuses := make([]int, 0, len(stats.statuses))
+		for status := range stats.statuses {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "%s{method=\"%s\",route=\"%s\",status=\"%d\"} %d\n",
+				MetricRequestsTotal, escapeLabel(key.Method), escapeLabel(key.Route), status, stats.statuses[status])
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP %s Requests that failed with a 5xx status, by route template.\n# TYPE %s counter\n", MetricRequestErrors, MetricRequestErrors)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s{method=\"%s\",route=\"%s\"} %d\n",
+			MetricRequestErrors, escapeLabel(key.Method), escapeLabel(key.Route), snapshot[key].errors)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// StartPprofServer serves net/http/pprof on its own listener, separate from the public router, so
+// profiles are only reachable from inside the deployment. Addresses that aren't loopback or private
+// are refused.
+func StartPprofServer(addr string) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		log.Printf("Warning: Invalid PPROF_ADDR %q, profiling disabled: %v", addr, err)
+		return
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || (!ip.IsLoopback() && !ip.IsPrivate())) {
+		log.Printf("Warning: PPROF_ADDR %q is not a loopback or private address, profiling disabled", addr)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		log.Printf("pprof listening on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Warning: pprof server stopped: %v", err)
+		}
+	}()
+}
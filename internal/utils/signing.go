@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SignPath returns an HMAC-SHA256 signature for a path that is valid until expires
+func SignPath(path string, expires time.Time, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%s:%d", path, expires.Unix())))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedURL appends expiry and signature query parameters to a path
+func SignedURL(path string, expires time.Time, secret string) string {
+	return fmt.Sprintf("%s?expires=%d&signature=%s", path, expires.Unix(), SignPath(path, expires, secret))
+}
+
+// VerifySignedPath checks a signature produced by SignPath and that it hasn't expired
+func VerifySignedPath(path, expiresParam, signature, secret string) bool {
+	expiresUnix, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil {
+		return false
+	}
+	expires := time.Unix(expiresUnix, 0)
+	if time.Now().After(expires) {
+		return false
+	}
+	expected := SignPath(path, expires, secret)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// SignPayload returns the hex HMAC-SHA256 of a request body, for receivers to verify webhook deliveries
+func SignPayload(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,25 @@
+package utils
+
+import "time"
+
+// Per-dependency timeouts. Each bounds a single call, on top of any deadline the caller's context has.
+
+// DBQueryTimeout bounds one database statement (DB_QUERY_TIMEOUT, default 10s)
+func DBQueryTimeout() time.Duration {
+	return GetEnvDuration("DB_QUERY_TIMEOUT", 10*time.Second)
+}
+
+// MapsTimeout bounds one Google Maps API call (MAPS_TIMEOUT, default 5s)
+func MapsTimeout() time.Duration {
+	return GetEnvDuration("MAPS_TIMEOUT", 5*time.Second)
+}
+
+// CloudinaryTimeout bounds one Cloudinary upload or delete (CLOUDINARY_TIMEOUT, default 30s)
+func CloudinaryTimeout() time.Duration {
+	return GetEnvDuration("CLOUDINARY_TIMEOUT", 30*time.Second)
+}
+
+// SendGridTimeout bounds sending one email through SendGrid (SENDGRID_TIMEOUT, default 10s)
+func SendGridTimeout() time.Duration {
+	return GetEnvDuration("SENDGRID_TIMEOUT", 10*time.Second)
+}